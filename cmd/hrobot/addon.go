@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aquasecurity/table"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func listAddonTransactions(ctx context.Context, client *hrobot.Client, outputFormat string) error {
+	transactions, err := client.Ordering.ListAddonTransactions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list addon transactions: %w", err)
+	}
+
+	if outputFormat == "json" {
+		return printJSON(transactions)
+	}
+
+	if len(transactions) == 0 {
+		fmt.Println("no addon transactions found in the last 30 days")
+		return nil
+	}
+
+	fmt.Printf("Addon Transactions (%d):\n", len(transactions))
+	t := table.New(os.Stdout)
+	t.SetHeaders("ID", "Date", "Status", "Server", "Product", "Assigned IP/Subnet")
+
+	for _, tx := range transactions {
+		server := "-"
+		if tx.ServerNumber != nil {
+			server = strconv.Itoa(*tx.ServerNumber)
+		}
+
+		assigned := "-"
+		if ips := tx.AssignedIPs(); len(ips) > 0 {
+			assigned = strings.Join(ips, ", ")
+		}
+
+		t.AddRow(
+			tx.ID,
+			tx.Date.Format("2006-01-02 15:04"),
+			tx.Status,
+			server,
+			tx.Product.Name,
+			assigned,
+		)
+	}
+	t.Render()
+
+	return nil
+}
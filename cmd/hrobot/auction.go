@@ -5,14 +5,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/aquasecurity/table"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
@@ -172,84 +175,308 @@ func parseDiskDescription(description []string) string {
 	return strings.Join(groups, " | ")
 }
 
-func listAuctionServers(ctx context.Context, client *hrobot.Client, location string, memoryMin float64, cpu string, cpuBenchmarkMin uint32, diskSpaceMin float64, priceMax float64, gpuOnly bool) error {
-	servers, err := client.Auction.List(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to list auction servers: %w", err)
+// auctionFilter holds the criteria for selecting auction servers. It is also
+// used for product listings, which ignore the datacenter and CPU benchmark.
+// Zero values match everything.
+type auctionFilter struct {
+	Location        string // location prefix, e.g. FSN or HEL1
+	Datacenter      string // exact datacenter, e.g. FSN1-DC14
+	MemoryMin       float64
+	CPU             string
+	CPUBenchmarkMin uint32
+	DiskSpaceMin    float64
+	PriceMax        float64
+	GPUOnly         bool
+	ECCOnly         bool
+	MemoryType      string // ddr4, ddr5, ...
+	DiskType        string // nvme, sata or hdd
+}
+
+// isEmpty reports whether no filter criteria are set.
+func (f auctionFilter) isEmpty() bool {
+	return f == auctionFilter{}
+}
+
+// memoryTypePattern matches the values accepted by --memory-type.
+var memoryTypePattern = regexp.MustCompile(`^ddr\d$`)
+
+// matchesMemoryType reports whether a parsed memory type such as "DDR4 ECC"
+// satisfies the --ecc and --memory-type filters.
+func (f auctionFilter) matchesMemoryType(memType string) bool {
+	fields := strings.Fields(strings.ToUpper(memType))
+	if f.ECCOnly && !slices.Contains(fields, "ECC") {
+		return false
+	}
+	if f.MemoryType != "" && (len(fields) == 0 || fields[0] != strings.ToUpper(f.MemoryType)) {
+		return false
+	}
+	return true
+}
+
+// matchesDiskType reports whether a parsed disk description satisfies the
+// --disk-type filter. It accepts both the auction ("SSD NVMe: 2x 1TB") and the
+// product ("2x1TB NVMe SSD") formats. sata only matches SATA SSDs.
+func (f auctionFilter) matchesDiskType(disks string) bool {
+	switch f.DiskType {
+	case "nvme":
+		return strings.Contains(disks, "NVMe")
+	case "sata":
+		return strings.Contains(disks, "SSD SATA") || strings.Contains(disks, "SATA SSD")
+	case "hdd":
+		return strings.Contains(disks, "HDD")
+	default:
+		return true
+	}
+}
+
+// parseAuctionFilterFlags reads the auction filter flags from args.
+// Flags can be given as --flag=value or --flag value.
+func parseAuctionFilterFlags(args []string) (auctionFilter, error) {
+	filter := auctionFilter{
+		Location:   parseFlagString(args, "--location"),
+		Datacenter: parseFlagString(args, "--datacenter"),
+		CPU:        parseFlagString(args, "--cpu"),
+		GPUOnly:    parseFlagBool(args, "--gpu"),
+		ECCOnly:    parseFlagBool(args, "--ecc"),
+		MemoryType: strings.ToLower(parseFlagString(args, "--memory-type")),
+		DiskType:   strings.ToLower(parseFlagString(args, "--disk-type")),
+	}
+
+	if filter.MemoryType != "" && !memoryTypePattern.MatchString(filter.MemoryType) {
+		return filter, fmt.Errorf("invalid memory-type value: %s (use e.g. ddr4 or ddr5)", filter.MemoryType)
+	}
+	switch filter.DiskType {
+	case "", "nvme", "sata", "hdd":
+	default:
+		return filter, fmt.Errorf("invalid disk-type value: %s (use nvme, sata or hdd)", filter.DiskType)
+	}
+
+	if v := parseFlagString(args, "--memory-min"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid memory-min value: %s", v)
+		}
+		filter.MemoryMin = val
+	}
+	if v := parseFlagString(args, "--cpu-benchmark-min"); v != "" {
+		val, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cpu-benchmark-min value: %s", v)
+		}
+		filter.CPUBenchmarkMin = uint32(val)
+	}
+	if v := parseFlagString(args, "--disk-space-min"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid disk-space-min value: %s", v)
+		}
+		filter.DiskSpaceMin = val
+	}
+	if v := parseFlagString(args, "--price-max"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid price-max value: %s", v)
+		}
+		filter.PriceMax = val
 	}
 
-	// Apply filters
+	return filter, nil
+}
+
+// filterAuctionServers returns the servers matching the filter, in their original order.
+func filterAuctionServers(servers []hrobot.AuctionServer, filter auctionFilter) []hrobot.AuctionServer {
 	var filteredServers []hrobot.AuctionServer
 	for _, server := range servers {
-		// Filter by location
-		if location != "" {
-			if server.Datacenter == nil || !strings.Contains(strings.ToUpper(*server.Datacenter), strings.ToUpper(location)) {
+		// Filter by location prefix
+		if filter.Location != "" {
+			if server.Datacenter == nil || !strings.HasPrefix(strings.ToUpper(*server.Datacenter), strings.ToUpper(filter.Location)) {
+				continue
+			}
+		}
+
+		// Filter by exact datacenter
+		if filter.Datacenter != "" {
+			if server.Datacenter == nil || !strings.EqualFold(*server.Datacenter, filter.Datacenter) {
 				continue
 			}
 		}
 
 		// Filter by minimum memory
-		if memoryMin > 0 && server.MemorySize < memoryMin {
+		if filter.MemoryMin > 0 && server.MemorySize < filter.MemoryMin {
 			continue
 		}
 
 		// Filter by CPU vendor
-		if cpu != "" {
+		if filter.CPU != "" {
 			cpuLower := strings.ToLower(server.CPU)
-			cpuFilter := strings.ToLower(cpu)
+			cpuFilter := strings.ToLower(filter.CPU)
 			if !strings.Contains(cpuLower, cpuFilter) {
 				continue
 			}
 		}
 
 		// Filter by minimum CPU benchmark score
-		if cpuBenchmarkMin > 0 && server.CPUBenchmark < cpuBenchmarkMin {
+		if filter.CPUBenchmarkMin > 0 && server.CPUBenchmark < filter.CPUBenchmarkMin {
 			continue
 		}
 
 		// Filter by minimum disk space
-		if diskSpaceMin > 0 && server.HDDSize < diskSpaceMin {
+		if filter.DiskSpaceMin > 0 && server.HDDSize < filter.DiskSpaceMin {
 			continue
 		}
 
 		// Filter by maximum price
-		if priceMax > 0 && server.Price.Float64() > priceMax {
+		if filter.PriceMax > 0 && server.Price.Float64() > filter.PriceMax {
 			continue
 		}
 
 		// Filter by GPU presence
-		if gpuOnly {
+		if filter.GPUOnly {
 			gpuInfo := parseAuctionGPU(server.Description)
 			if gpuInfo == "-" {
 				continue
 			}
 		}
 
+		// Filter by memory type and ECC
+		if !filter.matchesMemoryType(parseAuctionMemoryType(server.Description)) {
+			continue
+		}
+
+		// Filter by disk technology
+		if !filter.matchesDiskType(parseDiskDescription(server.Description)) {
+			continue
+		}
+
 		filteredServers = append(filteredServers, server)
 	}
+	return filteredServers
+}
+
+// listSortKeys are the values accepted by --sort.
+var listSortKeys = []string{"price", "memory", "benchmark", "disk"}
+
+// listSort is the order selected by --sort and --desc. An empty key keeps the
+// API order.
+type listSort struct {
+	Key  string
+	Desc bool
+}
+
+// parseListSort reads the --sort and --desc flags from args.
+func parseListSort(args []string) (listSort, error) {
+	order := listSort{
+		Key:  strings.ToLower(parseFlagString(args, "--sort")),
+		Desc: parseFlagBool(args, "--desc"),
+	}
+	if order.Key != "" && !slices.Contains(listSortKeys, order.Key) {
+		return order, fmt.Errorf("invalid sort value: %s (use %s)", order.Key, strings.Join(listSortKeys, ", "))
+	}
+	return order, nil
+}
+
+// sortByValue sorts items by the value of each item, ascending unless desc is
+// set. The sort is stable, so items with equal values keep their order.
+func sortByValue[T any](items []T, value func(T) float64, desc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return value(items[i]) > value(items[j])
+		}
+		return value(items[i]) < value(items[j])
+	})
+}
+
+// sortAuctionServers sorts auction servers in place by the selected key.
+func sortAuctionServers(servers []hrobot.AuctionServer, order listSort) {
+	var value func(hrobot.AuctionServer) float64
+	switch order.Key {
+	case "price":
+		value = func(s hrobot.AuctionServer) float64 { return s.Price.Float64() }
+	case "memory":
+		value = func(s hrobot.AuctionServer) float64 { return s.MemorySize }
+	case "benchmark":
+		value = func(s hrobot.AuctionServer) float64 { return float64(s.CPUBenchmark) }
+	case "disk":
+		value = func(s hrobot.AuctionServer) float64 { return s.HDDSize }
+	default:
+		return
+	}
+	sortByValue(servers, value, order.Desc)
+}
+
+// auctionLocation returns the location part of a datacenter name, e.g. FSN1
+// for FSN1-DC14.
+func auctionLocation(datacenter string) string {
+	location, _, _ := strings.Cut(datacenter, "-")
+	return location
+}
+
+// selectBestAuctionServer returns the cheapest server matching the filter.
+// Servers with the same price are ranked by CPU benchmark, then by ID.
+func selectBestAuctionServer(servers []hrobot.AuctionServer, filter auctionFilter) (*hrobot.AuctionServer, error) {
+	candidates := filterAuctionServers(servers, filter)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no auction server matches the given criteria (%d servers available)", len(servers))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Price.Float64() != b.Price.Float64() {
+			return a.Price.Float64() < b.Price.Float64()
+		}
+		if a.CPUBenchmark != b.CPUBenchmark {
+			return a.CPUBenchmark > b.CPUBenchmark
+		}
+		return a.ID < b.ID
+	})
+
+	return &candidates[0], nil
+}
+
+func listAuctionServers(ctx context.Context, client *hrobot.Client, filter auctionFilter, order listSort, prices priceMode, outputFormat string) error {
+	servers, err := client.Auction.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list auction servers: %w", err)
+	}
 
-	fmt.Printf("Found %d auction server(s)", len(filteredServers))
-	if location != "" || memoryMin > 0 || cpu != "" || cpuBenchmarkMin > 0 || diskSpaceMin > 0 || priceMax > 0 || gpuOnly {
-		fmt.Printf(" (filtered from %d total)", len(servers))
+	filteredServers := filterAuctionServers(servers, filter)
+	sortAuctionServers(filteredServers, order)
+
+	if outputFormat == "json" {
+		return printJSON(filteredServers)
 	}
-	fmt.Println(":")
 
-	// Create table
-	t := table.New(os.Stdout)
-	t.SetHeaders("ID", "CPU", "GPU", "Memory", "Mem Type", "Storage", "Price/mo", "Setup", "Location", "Next cut")
+	if outputFormat != "csv" {
+		fmt.Printf("Found %d auction server(s)", len(filteredServers))
+		if !filter.isEmpty() {
+			fmt.Printf(" (filtered from %d total)", len(servers))
+		}
+		fmt.Println(":")
+	}
 
-	for _, server := range filteredServers {
-		location := "-"
+	return renderAuctionTable(os.Stdout, filteredServers, prices, outputFormat)
+}
+
+// renderAuctionTable writes auction servers as a table, or as CSV, with the
+// selected price columns.
+func renderAuctionTable(w io.Writer, servers []hrobot.AuctionServer, prices priceMode, outputFormat string) error {
+	headers := []string{"ID", "CPU", "GPU", "Memory", "Mem Type", "Storage"}
+	headers = append(headers, prices.headers("Price/mo")...)
+	headers = append(headers, prices.headers("Setup")...)
+	headers = append(headers, "Location", "Datacenter", "Next cut")
+
+	rows := make([][]string, 0, len(servers))
+	for _, server := range servers {
+		location, datacenter := "-", "-"
 		if server.Datacenter != nil {
-			location = *server.Datacenter
+			location = auctionLocation(*server.Datacenter)
+			datacenter = *server.Datacenter
 		}
 
 		cpuInfo := fmt.Sprintf("%s (Benchmark: %d)", server.CPU, server.CPUBenchmark)
 		gpuInfo := parseAuctionGPU(server.Description)
 		memory := fmt.Sprintf("%.0f GB", server.MemorySize)
 		memType := parseAuctionMemoryType(server.Description)
-		price := fmt.Sprintf("%.2f €", server.Price.Float64())
-		setup := fmt.Sprintf("%.2f €", server.PriceSetup.Float64())
 
 		nextCut := "Auction"
 		if server.FixedPrice {
@@ -260,39 +487,53 @@ func listAuctionServers(ctx context.Context, client *hrobot.Client, location str
 			nextCut = fmt.Sprintf("%dh %dm", hours, minutes)
 		}
 
-		t.AddRow(
+		row := []string{
 			fmt.Sprintf("%d", server.ID),
 			cpuInfo,
 			gpuInfo,
 			memory,
 			memType,
 			parseDiskDescription(server.Description),
-			price,
-			setup,
-			location,
-			nextCut,
-		)
+		}
+		row = append(row, prices.cells(server.Price.Float64(), server.PriceVAT.Float64())...)
+		row = append(row, prices.cells(server.PriceSetup.Float64(), server.PriceSetupVAT.Float64())...)
+		row = append(row, location, datacenter, nextCut)
+		rows = append(rows, row)
 	}
 
-	t.Render()
-	return nil
+	return renderRows(w, outputFormat, headers, rows)
 }
 
-func describeAuctionServer(ctx context.Context, client *hrobot.Client, serverID uint32) error {
-	// Fetch all auction servers and find the one with matching ID
+// getAuctionServer fetches a single auction server from the product endpoint.
+// If the endpoint answers with "not found" or an error without an error code,
+// the full auction list is scanned instead. It returns nil without an error if
+// the server is not on the market.
+func getAuctionServer(ctx context.Context, client *hrobot.Client, id uint32) (*hrobot.AuctionServer, error) {
+	server, err := client.Auction.Get(ctx, id)
+	if err == nil {
+		return server, nil
+	}
+	if !hrobot.IsAPIError(err, hrobot.ErrProductNotFound) && !hrobot.IsAPIError(err, hrobot.ErrNotFound) && !hrobot.IsAPIError(err, hrobot.ErrUnknown) {
+		return nil, err
+	}
+
 	servers, err := client.Auction.List(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list auction servers: %w", err)
+		return nil, err
 	}
-
-	// Find the server with matching ID
-	var server *hrobot.AuctionServer
 	for i := range servers {
-		if servers[i].ID == serverID {
-			server = &servers[i]
-			break
+		if servers[i].ID == id {
+			return &servers[i], nil
 		}
 	}
+	return nil, nil
+}
+
+func describeAuctionServer(ctx context.Context, client *hrobot.Client, serverID uint32, prices priceMode) error {
+	server, err := getAuctionServer(ctx, client, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get auction server: %w", err)
+	}
 
 	if server == nil {
 		return fmt.Errorf("auction server with ID %d not found", serverID)
@@ -331,8 +572,8 @@ func describeAuctionServer(ctx context.Context, client *hrobot.Client, serverID
 
 	// Show pricing
 	fmt.Printf("  Pricing:\n")
-	fmt.Printf("    Monthly:   %.2f €\n", server.Price.Float64())
-	fmt.Printf("    Setup:     %.2f €\n", server.PriceSetup.Float64())
+	fmt.Printf("    Monthly:   %s\n", prices.format(server.Price.Float64(), server.PriceVAT.Float64()))
+	fmt.Printf("    Setup:     %s\n", prices.format(server.PriceSetup.Float64(), server.PriceSetupVAT.Float64()))
 
 	// Show status
 	if server.FixedPrice {
@@ -348,25 +589,22 @@ func describeAuctionServer(ctx context.Context, client *hrobot.Client, serverID
 	return nil
 }
 
-func orderMarketServer(ctx context.Context, client *hrobot.Client, productID uint32, sshKeyFingerprints []string, testMode bool, skipConfirmation bool) error {
+// auctionUnavailableHint explains an order for an auction server that was
+// sold in the meantime.
+const auctionUnavailableHint = "this auction server is no longer available; run 'auction list' to find another."
+
+// orderMarketServer orders an auction server. When maxPrice is greater than
+// zero, the order is aborted if the current monthly price exceeds it.
+func orderMarketServer(ctx context.Context, client *hrobot.Client, productID uint32, auth hrobot.AuthorizationMethod, image orderImage, comment string, testMode bool, skipConfirmation bool, maxPrice float64, wait orderWait) error {
 	// First, fetch the auction server details to show the user what they're ordering
 	fmt.Printf("Fetching server details...\n\n")
-	servers, err := client.Auction.List(ctx)
+	server, err := getAuctionServer(ctx, client, productID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch auction servers: %w", err)
-	}
-
-	// Find the server with matching product ID
-	var server *hrobot.AuctionServer
-	for i := range servers {
-		if servers[i].ID == productID {
-			server = &servers[i]
-			break
-		}
+		return fmt.Errorf("failed to fetch auction server: %w", err)
 	}
 
 	if server == nil {
-		return fmt.Errorf("server with product ID %d not found in auction list", productID)
+		return fmt.Errorf("server with product ID %d not found in auction list\n\n%s", productID, auctionUnavailableHint)
 	}
 
 	// Display server details
@@ -391,61 +629,225 @@ func orderMarketServer(ctx context.Context, client *hrobot.Client, productID uin
 	}
 	fmt.Println()
 
+	// The auction list was fetched just now, so this catches price changes
+	// since the user picked the server
+	if maxPrice > 0 && server.Price.Float64() > maxPrice {
+		return fmt.Errorf("current price %.2f €/month exceeds --max-price %.2f €/month; order not placed", server.Price.Float64(), maxPrice)
+	}
+
+	image, err = image.resolve(server.Distributions, server.Languages)
+	if err != nil {
+		return err
+	}
+
 	// Show order configuration
 	fmt.Printf("Order Configuration:\n")
-	if len(sshKeyFingerprints) == 1 {
-		fmt.Printf("  SSH Key:     %s\n", sshKeyFingerprints[0])
-	} else {
-		fmt.Printf("  SSH Keys:    %d keys\n", len(sshKeyFingerprints))
+	printOrderAuth(auth)
+	fmt.Printf("  Image:       %s (%s)\n", image.Distribution, image.Language)
+	if comment != "" {
+		fmt.Printf("  Comment:     %s\n", comment)
 	}
 	if testMode {
 		fmt.Printf("  Test Mode:   enabled (order will not be placed)\n")
 	}
 	fmt.Println()
+	printOrderCommentWarning(comment)
 
 	// Ask for confirmation unless --yes flag was used
+	if !confirm("Do you want to proceed with this order?", skipConfirmation) {
+		fmt.Println("Order cancelled.")
+		return nil
+	}
 	if !skipConfirmation {
-		fmt.Printf("Do you want to proceed with this order? (y/N): ")
-		var response string
-		// Read response, treating any error (e.g., EOF) as empty input
-		if _, err := fmt.Scanln(&response); err != nil {
-			response = ""
-		}
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("Order cancelled.")
-			return nil
-		}
 		fmt.Println()
 	}
 
 	// Proceed with the order
 	order := hrobot.MarketProductOrder{
-		ProductID: productID,
-		Auth: hrobot.AuthorizationMethod{
-			Keys: sshKeyFingerprints,
-		},
-		Distribution: "Rescue system",
-		Language:     "en",
+		ProductID:    productID,
+		Auth:         auth,
+		Distribution: image.Distribution,
+		Language:     image.Language,
+		Comment:      comment,
 		Test:         testMode,
 	}
 
 	fmt.Printf("Placing order...\n")
 	tx, err := client.Ordering.PlaceMarketOrder(ctx, order)
 	if err != nil {
+		if hrobot.IsAuctionUnavailableError(err) {
+			return fmt.Errorf("failed to place order: %w\n\n%s", err, auctionUnavailableHint)
+		}
 		return fmt.Errorf("failed to place order: %w", err)
 	}
 
-	fmt.Printf("\n✓ Order placed successfully!\n")
-	fmt.Printf("  Transaction ID: %s\n", tx.ID)
-	fmt.Printf("  Status:         %s\n", tx.Status)
-	fmt.Printf("  Date:           %s\n", tx.Date.Format("2006-01-02 15:04:05"))
+	printPlacedOrder(tx, testMode)
+
+	return waitForPlacedOrder(ctx, client.Ordering.WaitForMarketTransactionCompletionWithProgress, tx, wait, testMode)
+}
+
+// orderWaitInterval is the time between status checks while waiting for an
+// order to complete.
+var orderWaitInterval = 30 * time.Second
+
+// orderWait holds the --wait, --wait-timeout and --quiet flags of the order
+// commands.
+type orderWait struct {
+	Enabled bool
+	Timeout time.Duration // 0 waits without a limit
+	Quiet   bool
+}
+
+// orderWaitFlagsHelp describes the wait flags in the help of the order
+// commands.
+const orderWaitFlagsHelp = `  --wait            Wait until the server is ready and show its number and IP
+  --wait-timeout <d> Give up waiting after this long, e.g. 2h (default: no limit)
+  --quiet           Do not show progress while waiting`
+
+// removeOrderWaitFlags removes --wait, --wait-timeout and --quiet from args.
+func removeOrderWaitFlags(args []string) (orderWait, []string, error) {
+	timeout, err := parseWaitTimeout(args)
+	if err != nil {
+		return orderWait{}, nil, err
+	}
+	wait := orderWait{
+		Enabled: parseFlagBool(args, "--wait"),
+		Timeout: timeout,
+		Quiet:   parseFlagBool(args, "--quiet"),
+	}
+
+	_, args = removeFlagString(args, "--wait-timeout")
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--wait" || arg == "--quiet" || strings.HasPrefix(arg, "--wait=") || strings.HasPrefix(arg, "--quiet=") {
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return wait, remaining, nil
+}
+
+// transactionWaiter is the SDK wait function for a type of order, e.g.
+// client.Ordering.WaitForMarketTransactionCompletionWithProgress.
+type transactionWaiter func(ctx context.Context, transactionID string, checkInterval time.Duration, progress hrobot.MarketTransactionProgressFunc) (*hrobot.MarketTransaction, error)
+
+// waitForTransaction waits for an order to complete while showing its
+// progress.
+func waitForTransaction(ctx context.Context, waitFn transactionWaiter, transactionID string, quiet bool) (*hrobot.MarketTransaction, error) {
+	progress := newProgressReporter(fmt.Sprintf("transaction %s", transactionID), quiet)
+	progress.start()
+	tx, err := waitFn(ctx, transactionID, orderWaitInterval,
+		func(tx *hrobot.MarketTransaction, elapsed time.Duration) {
+			progress.update(tx.Status, elapsed)
+		})
+	progress.stop()
+	return tx, err
+}
+
+// printReadyTransaction prints the server of a completed order.
+func printReadyTransaction(tx *hrobot.MarketTransaction) {
+	fmt.Printf("✓ transaction %s is ready\n", tx.ID)
+	if tx.ServerNumber != nil {
+		fmt.Printf("  Server Number:  %d\n", *tx.ServerNumber)
+	}
+	if tx.ServerIP != nil {
+		fmt.Printf("  Server IP:      %s\n", *tx.ServerIP)
+	}
+}
+
+// printPlacedOrder prints the transaction of a placed order. Hetzner only
+// validates a test order, so its transaction has no server and may lack
+// other fields.
+func printPlacedOrder(tx *hrobot.MarketTransaction, testMode bool) {
+	if testMode {
+		fmt.Printf("\nTEST MODE: no order placed\n")
+		fmt.Printf("Hetzner accepted the order configuration:\n")
+	} else {
+		fmt.Printf("\n✓ Order placed successfully!\n")
+	}
+	if tx.ID != "" {
+		fmt.Printf("  Transaction ID: %s\n", tx.ID)
+	}
+	if tx.Status != "" {
+		fmt.Printf("  Status:         %s\n", tx.Status)
+	}
+	if !tx.Date.IsZero() {
+		fmt.Printf("  Date:           %s\n", tx.Date.Format("2006-01-02 15:04:05"))
+	}
+	if tx.Product.Name != "" {
+		fmt.Printf("  Product:        %s\n", tx.Product.Name)
+	}
+	if tx.Product.Dist != "" {
+		fmt.Printf("  Image:          %s (%s)\n", tx.Product.Dist, tx.Product.Lang)
+	}
 	if tx.ServerNumber != nil {
 		fmt.Printf("  Server Number:  %d\n", *tx.ServerNumber)
 	}
 	if tx.ServerIP != nil {
 		fmt.Printf("  Server IP:      %s\n", *tx.ServerIP)
 	}
+}
+
+// waitForPlacedOrder waits for a just placed order when --wait was given.
+// Test orders are not processed, so there is nothing to wait for.
+func waitForPlacedOrder(ctx context.Context, waitFn transactionWaiter, tx *hrobot.MarketTransaction, wait orderWait, testMode bool) error {
+	if !wait.Enabled {
+		return nil
+	}
+	fmt.Println()
+	if testMode {
+		fmt.Printf("Test mode: not waiting for transaction %s\n", tx.ID)
+		return nil
+	}
+
+	if wait.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wait.Timeout)
+		defer cancel()
+	}
+
+	ready, err := waitForTransaction(ctx, waitFn, tx.ID, wait.Quiet)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s waiting for transaction %s; the order was placed and is still being processed", wait.Timeout, tx.ID)
+		}
+		return fmt.Errorf("failed to wait for transaction %s: %w", tx.ID, err)
+	}
+	printReadyTransaction(ready)
+	return nil
+}
+
+func waitAuctionTransaction(ctx context.Context, client *hrobot.Client, transactionID string, quiet bool) error {
+	tx, err := waitForTransaction(ctx, client.Ordering.WaitForMarketTransactionCompletionWithProgress, transactionID, quiet)
+	if err != nil {
+		return fmt.Errorf("failed to wait for transaction %s: %w", transactionID, err)
+	}
 
+	printReadyTransaction(tx)
 	return nil
 }
+
+// orderBestAuctionServer orders the cheapest auction server matching the filter.
+func orderBestAuctionServer(ctx context.Context, client *hrobot.Client, filter auctionFilter, auth hrobot.AuthorizationMethod, image orderImage, testMode bool, skipConfirmation bool, maxPrice float64, wait orderWait) error {
+	servers, err := client.Auction.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch auction servers: %w", err)
+	}
+
+	if maxPrice > 0 && (filter.PriceMax == 0 || maxPrice < filter.PriceMax) {
+		filter.PriceMax = maxPrice
+	}
+
+	server, err := selectBestAuctionServer(servers, filter)
+	if err != nil {
+		return err
+	}
+
+	location := "-"
+	if server.Datacenter != nil {
+		location = *server.Datacenter
+	}
+	fmt.Printf("selected auction server %d: %s, %.0f GB, %s, %.2f €/month\n\n", server.ID, server.CPU, server.MemorySize, location, server.Price.Float64())
+
+	return orderMarketServer(ctx, client, server.ID, auth, image, "", testMode, skipConfirmation, maxPrice, wait)
+}
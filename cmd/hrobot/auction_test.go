@@ -0,0 +1,511 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// newAuctionTestServer serves the given auction products and counts order requests.
+func newAuctionTestServer(t *testing.T, products []map[string]interface{}, orders *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/order/server_market/product":
+			response := make([]map[string]interface{}, len(products))
+			for i, product := range products {
+				response[i] = map[string]interface{}{"product": product}
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/order/server_market/product/"):
+			id := strings.TrimPrefix(r.URL.Path, "/order/server_market/product/")
+			for _, product := range products {
+				if fmt.Sprint(product["id"]) == id {
+					if err := json.NewEncoder(w).Encode(map[string]interface{}{"product": product}); err != nil {
+						t.Fatalf("failed to encode response: %v", err)
+					}
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"status": 404, "code": "PRODUCT_NOT_FOUND", "message": "Product not found"}}`)
+		case r.Method == "POST":
+			*orders++
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestOrderMarketServer_MaxPriceExceeded(t *testing.T) {
+	orders := 0
+	server := newAuctionTestServer(t, []map[string]interface{}{
+		{
+			"id":          1234,
+			"name":        "SB",
+			"cpu":         "AMD Ryzen 7 3700X",
+			"memory_size": 64,
+			"datacenter":  "HEL1-DC2",
+			"price":       "45.00",
+			"price_vat":   "53.55",
+		},
+	}, &orders)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	captureStdout(t, func() {
+		err = orderMarketServer(context.Background(), client, 1234, hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}, orderImage{}, "", false, true, 40, orderWait{})
+	})
+	if err == nil {
+		t.Fatal("expected order to be aborted, got nil")
+	}
+	if !strings.Contains(err.Error(), "exceeds --max-price") {
+		t.Errorf("error message should mention the price limit, got: %s", err.Error())
+	}
+	if orders != 0 {
+		t.Errorf("expected no order to be placed, got %d order request(s)", orders)
+	}
+}
+
+func TestOrderMarketServer_UnavailableImage(t *testing.T) {
+	orders := 0
+	server := newAuctionTestServer(t, []map[string]interface{}{
+		{
+			"id":    1234,
+			"name":  "SB",
+			"price": "45.00",
+			"dist":  []string{"Rescue system", "Debian 12 base", "Ubuntu 24.04 LTS base"},
+			"lang":  []string{"en"},
+		},
+	}, &orders)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	auth := hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}
+
+	for _, image := range []orderImage{
+		{Distribution: "Windows Server 2022"},
+		{Distribution: "debian 12 base", Language: "de"},
+	} {
+		var err error
+		captureStdout(t, func() {
+			err = orderMarketServer(context.Background(), client, 1234, auth, image, "", false, true, 0, orderWait{})
+		})
+		if err == nil || !strings.Contains(err.Error(), "is not available for this product") {
+			t.Errorf("expected %+v to be rejected, got %v", image, err)
+		}
+	}
+	if orders != 0 {
+		t.Errorf("expected no order to be placed, got %d order request(s)", orders)
+	}
+}
+
+func TestOrderMarketServer_Wait(t *testing.T) {
+	origInterval := orderWaitInterval
+	t.Cleanup(func() { orderWaitInterval = origInterval })
+	orderWaitInterval = 10 * time.Millisecond
+
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/order/server_market/product/1234":
+			fmt.Fprint(w, `{"product": {"id": 1234, "name": "SB", "price": "45.00"}}`)
+		case r.Method == "POST" && r.URL.Path == "/order/server_market/transaction":
+			fmt.Fprint(w, `{"transaction": {"id": "B20250101-1", "date": "2025-01-01T10:00:00+01:00", "status": "in process"}}`)
+		case r.Method == "GET" && r.URL.Path == "/order/server_market/transaction/B20250101-1":
+			polls++
+			if polls == 1 {
+				fmt.Fprint(w, `{"transaction": {"id": "B20250101-1", "date": "2025-01-01T10:00:00+01:00", "status": "in process"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"transaction": {"id": "B20250101-1", "date": "2025-01-01T10:00:00+01:00", "status": "ready", "server_number": 321, "server_ip": "123.123.123.123"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	auth := hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = orderMarketServer(context.Background(), client, 1234, auth, orderImage{}, "", false, true, 0, orderWait{Enabled: true, Quiet: true})
+	})
+	if err != nil {
+		t.Fatalf("orderMarketServer returned error: %v", err)
+	}
+	if polls != 2 {
+		t.Errorf("expected 2 status checks, got %d", polls)
+	}
+	if !strings.Contains(out, "transaction B20250101-1 is ready") || !strings.Contains(out, "Server Number:  321") || !strings.Contains(out, "Server IP:      123.123.123.123") {
+		t.Errorf("expected the ready server to be printed, got:\n%s", out)
+	}
+}
+
+func TestOrderMarketServer_Sold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/order/server_market/product/1234":
+			fmt.Fprint(w, `{"product": {"id": 1234, "name": "SB", "price": "45.00"}}`)
+		case r.Method == "POST" && r.URL.Path == "/order/server_market/transaction":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"status": 404, "code": "PRODUCT_NOT_FOUND", "message": "Product with id 1234 not found"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	auth := hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}
+
+	var err error
+	captureStdout(t, func() {
+		err = orderMarketServer(context.Background(), client, 1234, auth, orderImage{}, "", false, true, 0, orderWait{})
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "this auction server is no longer available; run 'auction list' to find another.") {
+		t.Errorf("error should explain that the server is sold, got: %s", err.Error())
+	}
+	if !hrobot.IsAuctionUnavailableError(errors.Unwrap(err)) {
+		t.Errorf("error should wrap the API error, got: %v", err)
+	}
+}
+
+func TestGetAuctionServer_FallsBackToList(t *testing.T) {
+	listed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/order/server_market/product" {
+			listed++
+			fmt.Fprint(w, `[{"product": {"id": 1234, "name": "SB", "price": "45.00"}}]`)
+			return
+		}
+		// The product endpoint answers 404 without an error body.
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "not found")
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	got, err := getAuctionServer(context.Background(), client, 1234)
+	if err != nil {
+		t.Fatalf("getAuctionServer returned error: %v", err)
+	}
+	if got == nil || got.ID != 1234 {
+		t.Fatalf("expected auction server 1234, got %+v", got)
+	}
+	if listed != 1 {
+		t.Errorf("expected a single list request, got %d", listed)
+	}
+
+	missing, err := getAuctionServer(context.Background(), client, 9999)
+	if err != nil || missing != nil {
+		t.Errorf("expected nil for unknown server, got %+v, %v", missing, err)
+	}
+}
+
+func auctionCandidate(id uint32, cpu string, memory float64, datacenter string, price string, benchmark uint32) hrobot.AuctionServer {
+	var sf hrobot.StringFloat
+	if err := json.Unmarshal([]byte(`"`+price+`"`), &sf); err != nil {
+		panic(err)
+	}
+	return hrobot.AuctionServer{
+		ID:           id,
+		CPU:          cpu,
+		MemorySize:   memory,
+		Datacenter:   &datacenter,
+		Price:        sf,
+		CPUBenchmark: benchmark,
+	}
+}
+
+func TestSelectBestAuctionServer(t *testing.T) {
+	candidates := []hrobot.AuctionServer{
+		auctionCandidate(1, "AMD Ryzen 9 5950X", 128, "HEL1-DC2", "89.00", 46000),
+		auctionCandidate(2, "AMD Ryzen 7 3700X", 64, "HEL1-DC2", "39.00", 24000),
+		auctionCandidate(3, "Intel Core i9-9900K", 128, "HEL1-DC6", "59.00", 18000),
+		auctionCandidate(4, "AMD EPYC 7502P", 256, "FSN1-DC14", "79.00", 47000),
+		auctionCandidate(5, "AMD Ryzen 9 3900", 128, "HEL1-DC6", "69.00", 31000),
+		auctionCandidate(6, "AMD Ryzen 9 7950X3D", 128, "HEL1-DC8", "69.00", 62000),
+	}
+
+	tests := []struct {
+		name    string
+		filter  auctionFilter
+		wantID  uint32
+		wantErr bool
+	}{
+		{
+			name:   "cheapest overall",
+			filter: auctionFilter{},
+			wantID: 2,
+		},
+		{
+			name:   "amd with 128GB in HEL, tie broken by benchmark",
+			filter: auctionFilter{CPU: "amd", MemoryMin: 128, Location: "HEL"},
+			wantID: 6,
+		},
+		{
+			name:   "intel",
+			filter: auctionFilter{CPU: "intel"},
+			wantID: 3,
+		},
+		{
+			name:    "price limit excludes all matches",
+			filter:  auctionFilter{MemoryMin: 256, PriceMax: 50},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := selectBestAuctionServer(candidates, tt.filter)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got server %d", server.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectBestAuctionServer returned error: %v", err)
+			}
+			if server.ID != tt.wantID {
+				t.Errorf("expected server %d, got %d", tt.wantID, server.ID)
+			}
+		})
+	}
+}
+
+func TestFilterAuctionServers_LocationAndDatacenter(t *testing.T) {
+	servers := []hrobot.AuctionServer{
+		auctionCandidate(1, "Intel Core i7", 64, "FSN1-DC14", "40.00", 10000),
+		auctionCandidate(2, "Intel Core i7", 64, "FSN1-DC1", "40.00", 10000),
+		auctionCandidate(3, "Intel Core i7", 64, "HEL1-DC14", "40.00", 10000),
+		auctionCandidate(4, "Intel Core i7", 64, "NBG1-DC3", "40.00", 10000),
+	}
+
+	tests := []struct {
+		name    string
+		filter  auctionFilter
+		wantIDs []uint32
+	}{
+		{name: "location prefix", filter: auctionFilter{Location: "fsn"}, wantIDs: []uint32{1, 2}},
+		{name: "location with number", filter: auctionFilter{Location: "HEL1"}, wantIDs: []uint32{3}},
+		{name: "location is not a substring match", filter: auctionFilter{Location: "DC14"}, wantIDs: nil},
+		{name: "exact datacenter", filter: auctionFilter{Datacenter: "fsn1-dc1"}, wantIDs: []uint32{2}},
+		{name: "datacenter suffix does not match", filter: auctionFilter{Datacenter: "DC14"}, wantIDs: nil},
+		{name: "location and datacenter", filter: auctionFilter{Location: "FSN", Datacenter: "FSN1-DC14"}, wantIDs: []uint32{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []uint32
+			for _, server := range filterAuctionServers(servers, tt.filter) {
+				gotIDs = append(gotIDs, server.ID)
+			}
+			if fmt.Sprint(gotIDs) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected servers %v, got %v", tt.wantIDs, gotIDs)
+			}
+		})
+	}
+}
+
+func TestFilterAuctionServers_MemoryAndDisk(t *testing.T) {
+	withDescription := func(id uint32, description ...string) hrobot.AuctionServer {
+		server := auctionCandidate(id, "Intel Xeon", 64, "FSN1-DC14", "40.00", 10000)
+		server.Description = description
+		return server
+	}
+	servers := []hrobot.AuctionServer{
+		withDescription(1, "4x RAM 16384 MB DDR4 ECC", "2x SSD U.2 NVMe 960 GB Datacenter"),
+		withDescription(2, "2x RAM 32768 MB DDR5", "2x SSD SATA 480 GB Datacenter"),
+		withDescription(3, "4x RAM 8192 MB DDR4", "2x HDD SATA 4,0 TB Enterprise"),
+		withDescription(4, "4x RAM 32768 MB DDR5 ECC", "2x SSD M.2 NVMe 512 GB", "2x HDD SATA 16,0 TB Enterprise"),
+	}
+
+	tests := []struct {
+		name    string
+		filter  auctionFilter
+		wantIDs []uint32
+	}{
+		{name: "ecc", filter: auctionFilter{ECCOnly: true}, wantIDs: []uint32{1, 4}},
+		{name: "ddr4", filter: auctionFilter{MemoryType: "ddr4"}, wantIDs: []uint32{1, 3}},
+		{name: "ddr5 with ecc", filter: auctionFilter{MemoryType: "ddr5", ECCOnly: true}, wantIDs: []uint32{4}},
+		{name: "nvme", filter: auctionFilter{DiskType: "nvme"}, wantIDs: []uint32{1, 4}},
+		{name: "sata ssd", filter: auctionFilter{DiskType: "sata"}, wantIDs: []uint32{2}},
+		{name: "hdd", filter: auctionFilter{DiskType: "hdd"}, wantIDs: []uint32{3, 4}},
+		{name: "combined with existing filters", filter: auctionFilter{DiskType: "hdd", ECCOnly: true, Location: "FSN"}, wantIDs: []uint32{4}},
+		{name: "no match", filter: auctionFilter{MemoryType: "ddr3"}, wantIDs: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []uint32
+			for _, server := range filterAuctionServers(servers, tt.filter) {
+				gotIDs = append(gotIDs, server.ID)
+			}
+			if fmt.Sprint(gotIDs) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected servers %v, got %v", tt.wantIDs, gotIDs)
+			}
+		})
+	}
+}
+
+func TestSortAuctionServers(t *testing.T) {
+	withDisk := func(server hrobot.AuctionServer, disk float64) hrobot.AuctionServer {
+		server.HDDSize = disk
+		return server
+	}
+	servers := []hrobot.AuctionServer{
+		withDisk(auctionCandidate(1, "Intel", 64, "FSN1-DC1", "50.00", 20000), 4000),
+		withDisk(auctionCandidate(2, "AMD", 128, "FSN1-DC1", "40.00", 30000), 1000),
+		withDisk(auctionCandidate(3, "Intel", 32, "FSN1-DC1", "40.00", 10000), 8000),
+		withDisk(auctionCandidate(4, "AMD", 128, "FSN1-DC1", "70.00", 30000), 2000),
+	}
+
+	tests := []struct {
+		order   listSort
+		wantIDs []uint32
+	}{
+		{order: listSort{}, wantIDs: []uint32{1, 2, 3, 4}},
+		{order: listSort{Key: "price"}, wantIDs: []uint32{2, 3, 1, 4}},
+		{order: listSort{Key: "price", Desc: true}, wantIDs: []uint32{4, 1, 2, 3}},
+		{order: listSort{Key: "memory"}, wantIDs: []uint32{3, 1, 2, 4}},
+		{order: listSort{Key: "memory", Desc: true}, wantIDs: []uint32{2, 4, 1, 3}},
+		{order: listSort{Key: "benchmark"}, wantIDs: []uint32{3, 1, 2, 4}},
+		{order: listSort{Key: "disk"}, wantIDs: []uint32{2, 4, 1, 3}},
+		{order: listSort{Key: "disk", Desc: true}, wantIDs: []uint32{3, 1, 4, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s desc=%v", tt.order.Key, tt.order.Desc), func(t *testing.T) {
+			sorted := append([]hrobot.AuctionServer(nil), servers...)
+			sortAuctionServers(sorted, tt.order)
+
+			var gotIDs []uint32
+			for _, server := range sorted {
+				gotIDs = append(gotIDs, server.ID)
+			}
+			if fmt.Sprint(gotIDs) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected order %v, got %v", tt.wantIDs, gotIDs)
+			}
+		})
+	}
+}
+
+func TestParseListSort(t *testing.T) {
+	order, err := parseListSort([]string{"--sort", "Price", "--desc"})
+	if err != nil {
+		t.Fatalf("parseListSort returned error: %v", err)
+	}
+	if order != (listSort{Key: "price", Desc: true}) {
+		t.Errorf("expected price descending, got %+v", order)
+	}
+	if _, err := parseListSort([]string{"--sort=cores"}); err == nil {
+		t.Error("expected error for invalid sort key, got nil")
+	}
+}
+
+func TestParseAuctionFilterFlags(t *testing.T) {
+	filter, err := parseAuctionFilterFlags([]string{"--cpu", "amd", "--memory-min=128", "--location", "HEL", "--datacenter=HEL1-DC2", "--gpu"})
+	if err != nil {
+		t.Fatalf("parseAuctionFilterFlags returned error: %v", err)
+	}
+
+	want := auctionFilter{CPU: "amd", MemoryMin: 128, Location: "HEL", Datacenter: "HEL1-DC2", GPUOnly: true}
+	if filter != want {
+		t.Errorf("expected %+v, got %+v", want, filter)
+	}
+
+	if _, err := parseAuctionFilterFlags([]string{"--memory-min", "lots"}); err == nil {
+		t.Error("expected error for invalid memory-min, got nil")
+	}
+
+	filter, err = parseAuctionFilterFlags([]string{"--ecc", "--memory-type=DDR5", "--disk-type", "NVMe"})
+	if err != nil {
+		t.Fatalf("parseAuctionFilterFlags returned error: %v", err)
+	}
+	if want := (auctionFilter{ECCOnly: true, MemoryType: "ddr5", DiskType: "nvme"}); filter != want {
+		t.Errorf("expected %+v, got %+v", want, filter)
+	}
+	if _, err := parseAuctionFilterFlags([]string{"--memory-type", "ecc"}); err == nil {
+		t.Error("expected error for invalid memory-type, got nil")
+	}
+	if _, err := parseAuctionFilterFlags([]string{"--disk-type", "ssd"}); err == nil {
+		t.Error("expected error for invalid disk-type, got nil")
+	}
+}
+
+func TestRenderAuctionTable_GrossPrices(t *testing.T) {
+	server := auctionCandidate(1, "AMD Ryzen 7 3700X", 64, "HEL1-DC2", "39.00", 24000)
+	if err := json.Unmarshal([]byte(`"46.41"`), &server.PriceVAT); err != nil {
+		t.Fatalf("failed to set price_vat: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`"0.00"`), &server.PriceSetupVAT); err != nil {
+		t.Fatalf("failed to set price_setup_vat: %v", err)
+	}
+
+	var gross bytes.Buffer
+	_ = renderAuctionTable(&gross, []hrobot.AuctionServer{server}, priceGross, "")
+	if !strings.Contains(gross.String(), "Price/mo (gross)") {
+		t.Errorf("expected gross price header, got:\n%s", gross.String())
+	}
+	if !strings.Contains(gross.String(), "46.41 €") {
+		t.Errorf("expected gross price from price_vat, got:\n%s", gross.String())
+	}
+	if strings.Contains(gross.String(), "39.00 €") {
+		t.Errorf("expected net price to be hidden, got:\n%s", gross.String())
+	}
+
+	for _, want := range []string{"Location", "Datacenter", "HEL1 ", "HEL1-DC2"} {
+		if !strings.Contains(gross.String(), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, gross.String())
+		}
+	}
+
+	var both bytes.Buffer
+	_ = renderAuctionTable(&both, []hrobot.AuctionServer{server}, priceBoth, "")
+	for _, want := range []string{"Price/mo (net)", "Price/mo (gross)", "39.00 €", "46.41 €"} {
+		if !strings.Contains(both.String(), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, both.String())
+		}
+	}
+}
+
+func TestParsePriceMode(t *testing.T) {
+	mode, err := parsePriceMode([]string{"--cpu", "amd"})
+	if err != nil || mode != priceNet {
+		t.Errorf("expected default net, got %q (err: %v)", mode, err)
+	}
+
+	mode, err = parsePriceMode([]string{"--prices", "gross"})
+	if err != nil || mode != priceGross {
+		t.Errorf("expected gross, got %q (err: %v)", mode, err)
+	}
+
+	if _, err := parsePriceMode([]string{"--prices=vat"}); err == nil {
+		t.Error("expected error for invalid --prices value, got nil")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/aquasecurity/table"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
@@ -250,14 +251,30 @@ func getBootConfig(ctx context.Context, client *hrobot.Client, serverID hrobot.S
 	return nil
 }
 
-func activateRescue(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, os string, usePassword bool) error {
+// activateRescue activates the rescue system, authorizing the SSH keys named
+// by keyNames or, without names, all keys of the account.
+func activateRescue(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, os string, usePassword bool, keyNames []string) error {
+	if usePassword && len(keyNames) > 0 {
+		return fmt.Errorf("cannot combine --password with --key, choose one")
+	}
+
 	fmt.Printf("Activating rescue system for server #%d...\n", serverID)
 	fmt.Printf("  OS: %s\n", os)
 
 	var authorizedKeys []string
-	if !usePassword {
+	if len(keyNames) > 0 {
+		keys, err := listKeysCached(ctx, client)
+		if err != nil {
+			return fmt.Errorf("failed to query SSH keys: %w", err)
+		}
+		authorizedKeys, err = resolveKeyFingerprints(keys, keyNames)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  Adding %d SSH key(s) for authentication\n", len(authorizedKeys))
+	} else if !usePassword {
 		// Query all SSH keys from the API
-		keys, err := client.Key.List(ctx)
+		keys, err := listKeysCached(ctx, client)
 		if err != nil {
 			fmt.Printf("  Warning: Failed to query SSH keys: %v\n", err)
 			fmt.Println("  Falling back to password-based authentication")
@@ -341,16 +358,36 @@ func installOS(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 		return fmt.Errorf("failed to get boot configuration: %w", err)
 	}
 
+	keyNames := parseFlagStringSlice(args, "--key")
 	if linuxDist != "" {
-		return installLinux(ctx, client, serverID, config, linuxDist, lang, skipConfirmation)
+		return installLinux(ctx, client, serverID, config, linuxDist, lang, keyNames, skipConfirmation)
 	} else {
+		if len(keyNames) > 0 {
+			return fmt.Errorf("--key is only supported with --linux")
+		}
 		return installVNC(ctx, client, serverID, config, vncDist, lang, skipConfirmation)
 	}
 }
 
-func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, config *hrobot.BootConfig, searchTerm string, lang string, skipConfirmation bool) error {
+func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, config *hrobot.BootConfig, searchTerm string, lang string, keyNames []string, skipConfirmation bool) error {
+	activated, err := activateLinuxInstall(ctx, client, serverID, config, searchTerm, lang, keyNames, skipConfirmation)
+	if err != nil || !activated {
+		return err
+	}
+
+	fmt.Println("\nThe server will boot into the installer on next reboot.")
+	fmt.Printf("You can reboot the server using: ./hrobot server reboot %d\n", serverID)
+
+	return nil
+}
+
+// activateLinuxInstall activates the installation of the newest
+// distribution matching searchTerm after showing the format warning and
+// asking for confirmation. It authorizes the SSH keys named by keyNames or,
+// without names, all keys. It reports false if the user cancelled.
+func activateLinuxInstall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, config *hrobot.BootConfig, searchTerm string, lang string, keyNames []string, skipConfirmation bool) (bool, error) {
 	if config.Linux == nil {
-		return fmt.Errorf("linux installation not available for this server")
+		return false, fmt.Errorf("linux installation not available for this server")
 	}
 
 	// Extract available distributions
@@ -366,7 +403,7 @@ func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 	}
 
 	if len(availableDists) == 0 {
-		return fmt.Errorf("no linux distributions available")
+		return false, fmt.Errorf("no linux distributions available")
 	}
 
 	// Find matching distribution (case-insensitive, pick newest)
@@ -384,7 +421,7 @@ func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 		for _, dist := range availableDists {
 			fmt.Printf("  - %s\n", dist)
 		}
-		return fmt.Errorf("no matching distribution found")
+		return false, fmt.Errorf("no matching distribution found")
 	}
 
 	// Sort matches and pick the last one (likely the newest)
@@ -397,14 +434,21 @@ func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 	}
 
 	// Get SSH keys for authorization
-	keys, err := client.Key.List(ctx)
+	keys, err := listKeysCached(ctx, client)
 	if err != nil {
-		return fmt.Errorf("failed to query SSH keys: %w", err)
+		return false, fmt.Errorf("failed to query SSH keys: %w", err)
 	}
 
 	var keyFingerprints []string
-	for _, key := range keys {
-		keyFingerprints = append(keyFingerprints, key.Fingerprint)
+	if len(keyNames) > 0 {
+		keyFingerprints, err = resolveKeyFingerprints(keys, keyNames)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		for _, key := range keys {
+			keyFingerprints = append(keyFingerprints, key.Fingerprint)
+		}
 	}
 
 	// Show installation details
@@ -430,17 +474,11 @@ func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 	fmt.Printf("⚠️  WARNING: The server will be rebooted automatically!\n\n")
 
 	// Confirmation
+	if !confirm(fmt.Sprintf("Are you sure you want to install %s?", selectedDist), skipConfirmation) {
+		fmt.Println("Installation cancelled.")
+		return false, nil
+	}
 	if !skipConfirmation {
-		fmt.Printf("Are you sure you want to install %s? (yes/no): ", selectedDist)
-		var response string
-		if _, err := fmt.Scanln(&response); err != nil {
-			response = ""
-		}
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "yes" {
-			fmt.Println("Installation cancelled.")
-			return nil
-		}
 		fmt.Println()
 	}
 
@@ -448,7 +486,7 @@ func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 	fmt.Printf("Installing %s...\n", selectedDist)
 	result, err := client.Boot.ActivateLinux(ctx, serverID, selectedDist, 64, lang, keyFingerprints)
 	if err != nil {
-		return fmt.Errorf("failed to activate linux installation: %w", err)
+		return false, fmt.Errorf("failed to activate linux installation: %w", err)
 	}
 
 	fmt.Printf("\n✓ Linux installation activated successfully!\n")
@@ -460,12 +498,95 @@ func installLinux(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 	if result.Password != nil && *result.Password != "" {
 		fmt.Printf("  Password:     %s\n", *result.Password)
 	}
-	fmt.Println("\nThe server will boot into the installer on next reboot.")
-	fmt.Printf("You can reboot the server using: ./hrobot server reboot %d\n", serverID)
 
+	return true, nil
+}
+
+// installPollInterval is how often the boot configuration is polled while
+// waiting for an installation to finish.
+var installPollInterval = 30 * time.Second
+
+// defaultInstallWaitTimeout bounds server reinstall --wait when no
+// --wait-timeout is given.
+const defaultInstallWaitTimeout = 30 * time.Minute
+
+// reinstallServer activates a Linux installation and reboots the server into
+// it. With wait, it waits until the installation has finished, which Hetzner
+// signals by deactivating the installation again.
+func reinstallServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, dist string, lang string, keyNames []string, skipConfirmation bool, wait bool, timeout time.Duration) error {
+	if dist == "" {
+		return fmt.Errorf("must specify --linux=<distribution>")
+	}
+
+	config, err := client.Boot.Get(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get boot configuration: %w", err)
+	}
+
+	activated, err := activateLinuxInstall(ctx, client, serverID, config, dist, lang, keyNames, skipConfirmation)
+	if err != nil || !activated {
+		return err
+	}
+
+	fmt.Printf("\nRebooting server #%d into the installer...\n", serverID)
+	if _, err := executeAndRecordReset(ctx, client, serverID, hrobot.ResetTypeHardware); err != nil {
+		return fmt.Errorf("installation is activated, but the reboot failed: %w\nReboot the server using: ./hrobot server reboot %d", err, serverID)
+	}
+	fmt.Printf("✓ Server #%d is rebooting into the installer\n", serverID)
+
+	if !wait {
+		fmt.Println("\nThe installation takes a few minutes; use --wait to wait for it.")
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = defaultInstallWaitTimeout
+	}
+	fmt.Println()
+	if err := waitForInstall(ctx, client, serverID, timeout); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Installation on server #%d finished; it is booting the new system\n", serverID)
 	return nil
 }
 
+// waitForInstall polls the boot configuration until the Linux installation
+// is no longer active.
+func waitForInstall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	progress := newProgressReporter(fmt.Sprintf("server #%d", serverID), false)
+	progress.start()
+	defer progress.stop()
+
+	started := time.Now()
+	ticker := time.NewTicker(installPollInterval)
+	defer ticker.Stop()
+
+	for {
+		config, err := client.Boot.Get(waitCtx, serverID)
+		if err != nil {
+			if waitCtx.Err() != nil && ctx.Err() == nil {
+				return fmt.Errorf("timed out after %s waiting for the installation on server #%d", timeout, serverID)
+			}
+			return fmt.Errorf("failed to get boot configuration: %w", err)
+		}
+		if config.Linux == nil || !config.Linux.Active {
+			return nil
+		}
+		progress.update("installing", time.Since(started))
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("timed out after %s waiting for the installation on server #%d", timeout, serverID)
+		case <-ticker.C:
+		}
+	}
+}
+
 func installVNC(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, config *hrobot.BootConfig, searchTerm string, lang string, skipConfirmation bool) error {
 	if config.VNC == nil {
 		return fmt.Errorf("VNC installation not available for this server")
@@ -536,17 +657,11 @@ func installVNC(ctx context.Context, client *hrobot.Client, serverID hrobot.Serv
 	fmt.Printf("⚠️  WARNING: The server will be rebooted automatically!\n\n")
 
 	// Confirmation
+	if !confirm(fmt.Sprintf("Are you sure you want to install %s via VNC?", selectedDist), skipConfirmation) {
+		fmt.Println("Installation cancelled.")
+		return nil
+	}
 	if !skipConfirmation {
-		fmt.Printf("Are you sure you want to install %s via VNC? (yes/no): ", selectedDist)
-		var response string
-		if _, err := fmt.Scanln(&response); err != nil {
-			response = ""
-		}
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "yes" {
-			fmt.Println("Installation cancelled.")
-			return nil
-		}
 		fmt.Println()
 	}
 
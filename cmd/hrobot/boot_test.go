@@ -0,0 +1,178 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestReinstallServer_InstallThenReboot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	origInterval := installPollInterval
+	installPollInterval = 10 * time.Millisecond
+	defer func() { installPollInterval = origInterval }()
+
+	var calls []string
+	installActive := false
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/boot/321":
+			if installActive {
+				// The installation finishes after the first poll.
+				polls++
+				if polls > 1 {
+					installActive = false
+				}
+			}
+			fmt.Fprintf(w, `{"boot": {"linux": {"server_ip": "123.123.123.123", "server_number": 321,
+				"dist": ["Debian 11 base", "Debian 12 base", "Ubuntu 24.04 LTS base"], "arch": [64], "lang": ["en", "de"], "active": %t}}}`, installActive)
+		case r.Method == http.MethodGet && r.URL.Path == "/key":
+			fmt.Fprint(w, `[{"key": {"name": "laptop", "fingerprint": "aa:bb", "type": "ED25519", "size": 256, "data": "ssh-ed25519 AAAA"}}]`)
+		case r.Method == http.MethodPost && r.URL.Path == "/boot/321/linux":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			if got := r.PostForm.Get("dist"); got != "Debian 12 base" {
+				t.Errorf("expected newest debian to be installed, got %q", got)
+			}
+			installActive = true
+			fmt.Fprint(w, `{"linux": {"server_ip": "123.123.123.123", "server_number": 321, "dist": "Debian 12 base", "arch": 64, "lang": "en", "active": true, "authorized_key": ["aa:bb"]}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/reset/321":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			if got := r.PostForm.Get("type"); got != "hw" {
+				t.Errorf("expected a hardware reset, got %q", got)
+			}
+			fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": "hw"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = reinstallServer(context.Background(), client, hrobot.ServerID(321), "debian", "", nil, true, true, time.Second)
+	})
+	if err != nil {
+		t.Fatalf("reinstallServer returned error: %v", err)
+	}
+
+	want := []string{
+		"GET /boot/321",
+		"GET /key",
+		"POST /boot/321/linux",
+		"POST /reset/321",
+		"GET /boot/321",
+		"GET /boot/321",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("unexpected request sequence:\n got %v\nwant %v", calls, want)
+	}
+	if !strings.Contains(out, "WARNING: This will format ALL drives") {
+		t.Errorf("expected the format warning, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Installation on server #321 finished") {
+		t.Errorf("expected the installation to be reported as finished, got:\n%s", out)
+	}
+}
+
+func TestActivateRescue_KeySelection(t *testing.T) {
+	tests := []struct {
+		name        string
+		keyNames    []string
+		usePassword bool
+		want        []string
+		wantErr     string
+	}{
+		{
+			name: "all keys by default",
+			want: []string{"aa:bb", "cc:dd"},
+		},
+		{
+			name:     "single key",
+			keyNames: []string{"desktop"},
+			want:     []string{"cc:dd"},
+		},
+		{
+			name:     "multiple keys",
+			keyNames: []string{"desktop", "laptop"},
+			want:     []string{"cc:dd", "aa:bb"},
+		},
+		{
+			name:     "unknown key",
+			keyNames: []string{"laptop", "tablet"},
+			wantErr:  "SSH key(s) not found: tablet",
+		},
+		{
+			name:        "key and password",
+			keyNames:    []string{"laptop"},
+			usePassword: true,
+			wantErr:     "cannot combine --password with --key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorized []string
+			activated := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/key":
+					fmt.Fprint(w, `[
+						{"key": {"name": "laptop", "fingerprint": "aa:bb", "type": "ED25519", "size": 256, "data": "ssh-ed25519 AAAA"}},
+						{"key": {"name": "desktop", "fingerprint": "cc:dd", "type": "ED25519", "size": 256, "data": "ssh-ed25519 BBBB"}}
+					]`)
+				case r.Method == http.MethodPost && r.URL.Path == "/boot/321/rescue":
+					if err := r.ParseForm(); err != nil {
+						t.Fatalf("failed to parse form: %v", err)
+					}
+					activated = true
+					authorized = r.PostForm["authorized_key[]"]
+					fmt.Fprint(w, `{"rescue": {"server_ip": "123.123.123.123", "server_number": 321, "os": "linux", "arch": 64, "active": true}}`)
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+			var err error
+			captureStdout(t, func() {
+				err = activateRescue(context.Background(), client, hrobot.ServerID(321), "linux", tt.usePassword, tt.keyNames)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if activated {
+					t.Error("rescue system must not be activated on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("activateRescue returned error: %v", err)
+			}
+			if !reflect.DeepEqual(authorized, tt.want) {
+				t.Errorf("authorized keys = %v, want %v", authorized, tt.want)
+			}
+		})
+	}
+}
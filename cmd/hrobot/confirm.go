@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// confirmInput, confirmOutput and confirmInteractive are the stdin, stdout and
+// terminal check used by confirm. Tests replace them.
+var (
+	confirmInput       io.Reader = os.Stdin
+	confirmOutput      io.Writer = os.Stdout
+	confirmInteractive           = func() bool { return isTerminal(os.Stdin) }
+)
+
+// confirm asks the user to confirm an action with "y" or "yes".
+//
+// It returns true without prompting when skip is set (--yes). When stdin is
+// not a terminal nobody can answer, so instead of blocking on a pipe or
+// reading an unintended answer it prints that it is refusing without --yes
+// and returns false. Any answer other than "y" or "yes", including EOF,
+// counts as "no".
+func confirm(prompt string, skip bool) bool {
+	if skip {
+		return true
+	}
+
+	if !confirmInteractive() {
+		fmt.Fprintf(confirmOutput, "%s\n", prompt)
+		fmt.Fprintln(confirmOutput, "refusing without --yes: stdin is not a terminal")
+		return false
+	}
+
+	fmt.Fprintf(confirmOutput, "%s (y/N): ", prompt)
+	response, err := bufio.NewReader(confirmInput).ReadString('\n')
+	if err != nil && response == "" {
+		fmt.Fprintln(confirmOutput)
+		return false
+	}
+
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
+// readPassword prompts for a password without echoing it. Tests replace it.
+var readPassword = func(prompt string) (string, error) {
+	if !confirmInteractive() {
+		return "", fmt.Errorf("cannot prompt for a password: stdin is not a terminal (use --password=<password>)")
+	}
+
+	fmt.Fprint(confirmOutput, prompt)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(confirmOutput)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// stubConfirm replaces the confirm prompt's stdin, stdout and terminal check
+// for the duration of a test.
+func stubConfirm(t *testing.T, input string, interactive bool) *bytes.Buffer {
+	t.Helper()
+
+	origInput, origOutput, origInteractive := confirmInput, confirmOutput, confirmInteractive
+	t.Cleanup(func() {
+		confirmInput, confirmOutput, confirmInteractive = origInput, origOutput, origInteractive
+	})
+
+	out := &bytes.Buffer{}
+	confirmInput = strings.NewReader(input)
+	confirmOutput = out
+	confirmInteractive = func() bool { return interactive }
+	return out
+}
+
+func TestConfirm_Terminal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: "  yes  \n", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "", want: false},
+		{input: "y", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.input), func(t *testing.T) {
+			out := stubConfirm(t, tt.input, true)
+
+			if got := confirm("Proceed?", false); got != tt.want {
+				t.Errorf("confirm() with input %q = %v, want %v", tt.input, got, tt.want)
+			}
+			if !strings.Contains(out.String(), "Proceed? (y/N): ") {
+				t.Errorf("expected prompt in output, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestConfirm_NonInteractive(t *testing.T) {
+	out := stubConfirm(t, "yes\n", false)
+
+	if confirm("Proceed?", false) {
+		t.Error("expected confirm to refuse on a non-interactive stdin")
+	}
+	if !strings.Contains(out.String(), "refusing without --yes") {
+		t.Errorf("expected refusal message, got %q", out.String())
+	}
+}
+
+func TestConfirm_Skip(t *testing.T) {
+	out := stubConfirm(t, "", false)
+
+	if !confirm("Proceed?", true) {
+		t.Error("expected confirm to return true with --yes")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no prompt with --yes, got %q", out.String())
+	}
+}
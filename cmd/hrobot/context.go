@@ -4,11 +4,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
 // Config represents the CLI configuration.
@@ -163,14 +165,23 @@ func listContexts() error {
 	return nil
 }
 
-// createContext creates a new context.
-func createContext(name, username, password string) error {
+// contextExistsError is returned when creating a context whose name is taken.
+func contextExistsError(name string) error {
+	return fmt.Errorf("context '%s' already exists (use --force to overwrite its credentials)", name)
+}
+
+// createContext creates a new context. An existing context of the same name
+// is only overwritten with force.
+func createContext(name, username, password string, force bool) error {
 	if name == "" {
 		return fmt.Errorf("context name cannot be empty")
 	}
 	if username == "" {
 		return fmt.Errorf("username cannot be empty")
 	}
+	if err := hrobot.ValidateUsername(username); err != nil {
+		return err
+	}
 	if password == "" {
 		return fmt.Errorf("password cannot be empty")
 	}
@@ -181,8 +192,9 @@ func createContext(name, username, password string) error {
 	}
 
 	// Check if context already exists
-	if existing := config.getContext(name); existing != nil {
-		return fmt.Errorf("context '%s' already exists", name)
+	existing := config.getContext(name) != nil
+	if existing && !force {
+		return contextExistsError(name)
 	}
 
 	config.addContext(Context{
@@ -200,6 +212,11 @@ func createContext(name, username, password string) error {
 		return err
 	}
 
+	if existing {
+		fmt.Printf("Context '%s' overwritten\n", name)
+		return nil
+	}
+
 	fmt.Printf("Context '%s' created\n", name)
 	if config.ActiveContext == name {
 		fmt.Printf("Context '%s' is now active\n", name)
@@ -280,18 +297,67 @@ func showActiveContext() error {
 	return nil
 }
 
-// getCredentialsFromContext returns credentials from the active context.
-// Returns empty strings if no active context is found.
-func getCredentialsFromContext() (username, password string) {
+// testContext checks the credentials of a context, without activating it, by
+// listing the SSH keys of the account.
+func testContext(ctx context.Context, name string, clientOpts []hrobot.ClientOption) error {
+	if name == "" {
+		return fmt.Errorf("context name cannot be empty")
+	}
+
 	config, err := loadConfig()
 	if err != nil {
-		return "", ""
+		return err
 	}
 
-	ctx := config.getActiveContext()
-	if ctx == nil {
-		return "", ""
+	stored := config.getContext(name)
+	if stored == nil {
+		return fmt.Errorf("context '%s' not found", name)
 	}
 
-	return ctx.Username, ctx.Password
+	client := hrobot.New(stored.Username, stored.Password, clientOpts...)
+	// An account without SSH keys answers NOT_FOUND, which still proves the
+	// credentials were accepted
+	if _, err := client.Key.List(ctx); err != nil && !hrobot.IsAPIError(err, hrobot.ErrNotFound) {
+		return enhanceAuthError(fmt.Errorf("context '%s' failed: %w", name, err))
+	}
+
+	fmt.Printf("Context '%s' works (authenticated as %s)\n", name, stored.Username)
+	return nil
+}
+
+// getCredentialsFromContext returns the credentials to use, in order of
+// precedence:
+//
+//  1. the context named by the --context flag, which is used as is
+//  2. HROBOT_USERNAME and HROBOT_PASSWORD, each overriding its part of the
+//     active context, e.g. to try a rotated password
+//  3. the active context
+//
+// Returns empty strings for credentials none of them provide.
+func getCredentialsFromContext(explicitContext string) (username, password string, err error) {
+	config, err := loadConfig()
+	if explicitContext != "" {
+		if err != nil {
+			return "", "", err
+		}
+		ctx := config.getContext(explicitContext)
+		if ctx == nil {
+			return "", "", fmt.Errorf("context '%s' not found (see 'hrobot context list')", explicitContext)
+		}
+		return ctx.Username, ctx.Password, nil
+	}
+
+	// An unreadable config leaves the environment variables
+	if err == nil {
+		if ctx := config.getActiveContext(); ctx != nil {
+			username, password = ctx.Username, ctx.Password
+		}
+	}
+	if env := os.Getenv("HROBOT_USERNAME"); env != "" {
+		username = env
+	}
+	if env := os.Getenv("HROBOT_PASSWORD"); env != "" {
+		password = env
+	}
+	return username, password, nil
 }
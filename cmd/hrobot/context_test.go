@@ -0,0 +1,223 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestCreateContext_Existing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	captureStdout(t, func() {
+		if err := createContext("prod", "#ws+AbCdEfGh", "old-password", false); err != nil {
+			t.Fatalf("createContext returned error: %v", err)
+		}
+	})
+
+	// Without --force the existing credentials are kept
+	err := createContext("prod", "#ws+IjKlMnOp", "new-password", false)
+	if err == nil || !strings.Contains(err.Error(), "context 'prod' already exists") || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected already exists error mentioning --force, got %v", err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if ctx := config.getContext("prod"); ctx == nil || ctx.Password != "old-password" {
+		t.Fatalf("expected the original credentials to be kept, got %+v", ctx)
+	}
+
+	// With --force the credentials are overwritten in place
+	out := captureStdout(t, func() {
+		if err := createContext("prod", "#ws+IjKlMnOp", "new-password", true); err != nil {
+			t.Fatalf("createContext with force returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Context 'prod' overwritten") {
+		t.Errorf("expected overwrite message, got %q", out)
+	}
+	config, err = loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if len(config.Contexts) != 1 || config.Contexts[0].Username != "#ws+IjKlMnOp" || config.Contexts[0].Password != "new-password" {
+		t.Errorf("expected one context with the new credentials, got %+v", config.Contexts)
+	}
+	if config.ActiveContext != "prod" {
+		t.Errorf("expected 'prod' to stay active, got %q", config.ActiveContext)
+	}
+}
+
+func TestTestContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/key" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if username, _, _ := r.BasicAuth(); username != "#ws+AbCdEfGh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"status":401,"code":"UNAUTHORIZED","message":"Unable to authenticate"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	clientOpts := []hrobot.ClientOption{hrobot.WithBaseURL(server.URL)}
+
+	captureStdout(t, func() {
+		if err := createContext("prod", "#ws+AbCdEfGh", "test-pass", false); err != nil {
+			t.Fatalf("createContext returned error: %v", err)
+		}
+		if err := createContext("stale", "#ws+IjKlMnOp", "old-pass", false); err != nil {
+			t.Fatalf("createContext returned error: %v", err)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := testContext(context.Background(), "prod", clientOpts); err != nil {
+			t.Errorf("expected context 'prod' to work, got %v", err)
+		}
+	})
+	if !strings.Contains(out, "Context 'prod' works (authenticated as #ws+AbCdEfGh)") {
+		t.Errorf("expected success message, got %q", out)
+	}
+
+	err := testContext(context.Background(), "stale", clientOpts)
+	if err == nil {
+		t.Fatal("expected error for rejected credentials, got nil")
+	}
+	for _, want := range []string{"context 'stale' failed", "Authentication failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got %v", want, err)
+		}
+	}
+	if exitCode(err) != 2 {
+		t.Errorf("expected exit code 2 for rejected credentials, got %d", exitCode(err))
+	}
+
+	// Testing a context does not activate it
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if config.ActiveContext != "prod" {
+		t.Errorf("expected 'prod' to stay active, got %q", config.ActiveContext)
+	}
+
+	if err := testContext(context.Background(), "missing", clientOpts); err == nil || !strings.Contains(err.Error(), "context 'missing' not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestGetCredentialsFromContext_Precedence(t *testing.T) {
+	tests := []struct {
+		name            string
+		contexts        []Context
+		active          string
+		envUsername     string
+		envPassword     string
+		explicitContext string
+		wantUsername    string
+		wantPassword    string
+		wantErr         string
+	}{
+		{
+			name:         "environment only",
+			envUsername:  "#ws+EnvUser1",
+			envPassword:  "env-pass",
+			wantUsername: "#ws+EnvUser1",
+			wantPassword: "env-pass",
+		},
+		{
+			name:         "active context only",
+			contexts:     []Context{{Name: "prod", Username: "#ws+CtxUser1", Password: "ctx-pass"}},
+			active:       "prod",
+			wantUsername: "#ws+CtxUser1",
+			wantPassword: "ctx-pass",
+		},
+		{
+			name:         "environment password overrides the context password",
+			contexts:     []Context{{Name: "prod", Username: "#ws+CtxUser1", Password: "ctx-pass"}},
+			active:       "prod",
+			envPassword:  "rotated-pass",
+			wantUsername: "#ws+CtxUser1",
+			wantPassword: "rotated-pass",
+		},
+		{
+			name:         "environment username overrides the context username",
+			contexts:     []Context{{Name: "prod", Username: "#ws+CtxUser1", Password: "ctx-pass"}},
+			active:       "prod",
+			envUsername:  "#ws+EnvUser1",
+			wantUsername: "#ws+EnvUser1",
+			wantPassword: "ctx-pass",
+		},
+		{
+			name:         "environment overrides the whole context",
+			contexts:     []Context{{Name: "prod", Username: "#ws+CtxUser1", Password: "ctx-pass"}},
+			active:       "prod",
+			envUsername:  "#ws+EnvUser1",
+			envPassword:  "env-pass",
+			wantUsername: "#ws+EnvUser1",
+			wantPassword: "env-pass",
+		},
+		{
+			name: "explicit context beats the environment and the active context",
+			contexts: []Context{
+				{Name: "prod", Username: "#ws+CtxUser1", Password: "ctx-pass"},
+				{Name: "staging", Username: "#ws+CtxUser2", Password: "staging-pass"},
+			},
+			active:          "prod",
+			envUsername:     "#ws+EnvUser1",
+			envPassword:     "env-pass",
+			explicitContext: "staging",
+			wantUsername:    "#ws+CtxUser2",
+			wantPassword:    "staging-pass",
+		},
+		{
+			name:            "unknown explicit context",
+			contexts:        []Context{{Name: "prod", Username: "#ws+CtxUser1", Password: "ctx-pass"}},
+			envPassword:     "env-pass",
+			explicitContext: "staging",
+			wantErr:         "context 'staging' not found",
+		},
+		{
+			name: "nothing set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("HROBOT_USERNAME", tt.envUsername)
+			t.Setenv("HROBOT_PASSWORD", tt.envPassword)
+			if len(tt.contexts) > 0 {
+				if err := saveConfig(&Config{ActiveContext: tt.active, Contexts: tt.contexts}); err != nil {
+					t.Fatalf("saveConfig returned error: %v", err)
+				}
+			}
+
+			username, password, err := getCredentialsFromContext(tt.explicitContext)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getCredentialsFromContext returned error: %v", err)
+			}
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("got credentials %q/%q, want %q/%q", username, password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// doctorCheck is the outcome of one check of the doctor command. A check
+// that could not run because an earlier one failed is skipped.
+type doctorCheck struct {
+	Name    string
+	OK      bool
+	Skipped bool
+	Detail  string
+	Hint    string
+}
+
+// runDoctor checks the webservice setup and prints a checklist with hints
+// for every failed check.
+func runDoctor(ctx context.Context, username, password string, clientOpts []hrobot.ClientOption) error {
+	checks := doctorChecks(ctx, username, password, clientOpts)
+
+	failed := 0
+	for _, check := range checks {
+		mark := "✓"
+		switch {
+		case check.Skipped:
+			mark = "-"
+		case !check.OK:
+			mark = "✗"
+			failed++
+		}
+
+		line := fmt.Sprintf("%s %s", mark, check.Name)
+		if check.Detail != "" {
+			line += fmt.Sprintf(" (%s)", check.Detail)
+		}
+		fmt.Println(line)
+		if !check.OK && check.Hint != "" {
+			fmt.Printf("\n%s\n\n", indentLines(check.Hint, "    "))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d check(s) failed", failed, len(checks))
+	}
+	fmt.Println("\nall checks passed")
+	return nil
+}
+
+// doctorChecks runs the checks in order: credentials, an authenticated call,
+// IP access and ordering.
+func doctorChecks(ctx context.Context, username, password string, clientOpts []hrobot.ClientOption) []doctorCheck {
+	credentials := doctorCheck{Name: "Credentials are set", OK: true, Detail: username}
+	if username == "" || password == "" {
+		credentials = doctorCheck{
+			Name: "Credentials are set",
+			Hint: "Set HROBOT_USERNAME and HROBOT_PASSWORD, or run 'hrobot context create <name>'.\n\n" + resetCredentialsSteps,
+		}
+	} else if err := hrobot.ValidateUsername(username); err != nil {
+		credentials = doctorCheck{Name: "Credentials are set", Detail: err.Error(), Hint: resetCredentialsSteps}
+	}
+	if !credentials.OK {
+		return []doctorCheck{
+			credentials,
+			{Name: "Authenticated API call succeeds", Skipped: true},
+			{Name: "Webservice access is allowed from your IP", Skipped: true},
+			{Name: "Ordering over the webservice is enabled", Skipped: true},
+		}
+	}
+
+	client := hrobot.New(username, password, clientOpts...)
+	auth := doctorCheck{Name: "Authenticated API call succeeds", OK: true}
+	access := doctorCheck{Name: "Webservice access is allowed from your IP", OK: true}
+	if ip, err := detectPublicIP(); err == nil {
+		access.Detail = ip
+	}
+
+	_, err := client.Key.List(ctx)
+	var hrobotErr *hrobot.Error
+	switch {
+	case err == nil:
+	case errors.As(err, &hrobotErr) && hrobot.IsForbiddenError(hrobotErr):
+		auth = doctorCheck{Name: auth.Name, Skipped: true}
+		access = doctorCheck{Name: access.Name, Detail: access.Detail, Hint: publicIPNote() + "\n\n" + allowIPSteps}
+	case errors.As(err, &hrobotErr) && hrobot.IsUnauthorizedError(hrobotErr):
+		auth = doctorCheck{Name: auth.Name, Detail: "credentials were rejected", Hint: resetCredentialsSteps}
+		access = doctorCheck{Name: access.Name, Skipped: true}
+	case errors.As(err, &hrobotErr) && hrobot.IsNetworkError(hrobotErr):
+		auth = doctorCheck{
+			Name:   auth.Name,
+			Detail: networkErrorReason(err),
+			Hint:   "Check your network connectivity and whether the Robot API is down: https://status.hetzner.com",
+		}
+		access = doctorCheck{Name: access.Name, Skipped: true}
+	default:
+		auth = doctorCheck{Name: auth.Name, Detail: firstLine(err.Error())}
+		access = doctorCheck{Name: access.Name, Skipped: true}
+	}
+	if err != nil {
+		return []doctorCheck{credentials, auth, access, {Name: "Ordering over the webservice is enabled", Skipped: true}}
+	}
+
+	return []doctorCheck{credentials, auth, access, orderingCheck(ctx, client)}
+}
+
+// orderingCheck probes the product endpoint, which requires "ordering over
+// the webservice". It must only run after the credentials were verified,
+// since Hetzner also answers UNAUTHORIZED when ordering is disabled.
+func orderingCheck(ctx context.Context, client *hrobot.Client) doctorCheck {
+	check := doctorCheck{Name: "Ordering over the webservice is enabled", OK: true}
+
+	_, err := client.Ordering.ListProducts(ctx)
+	if err == nil {
+		return check
+	}
+
+	var hrobotErr *hrobot.Error
+	if errors.As(err, &hrobotErr) && (hrobot.IsOrderingDisabledError(hrobotErr) || hrobot.IsUnauthorizedError(hrobotErr)) {
+		return doctorCheck{Name: check.Name, Detail: "ordering is disabled", Hint: enableOrderingSteps}
+	}
+	return doctorCheck{Name: check.Name, Detail: firstLine(err.Error())}
+}
+
+// indentLines prefixes every non-empty line of s with indent.
+func indentLines(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func stubPublicIP(t *testing.T, ip string) {
+	t.Helper()
+	origDetect := detectPublicIP
+	t.Cleanup(func() { detectPublicIP = origDetect })
+	detectPublicIP = func() (string, error) { return ip, nil }
+}
+
+func TestRunDoctor_OrderingDisabled(t *testing.T) {
+	stubPublicIP(t, "203.0.113.7")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/key":
+			_, _ = w.Write([]byte(`[]`))
+		case "/order/server/product":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"status":401,"code":"UNAUTHORIZED","message":"Unable to authenticate"}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDoctor(context.Background(), "#ws+test", "test-pass", []hrobot.ClientOption{hrobot.WithBaseURL(server.URL)})
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 check(s) failed") {
+		t.Errorf("expected one failed check, got %v", err)
+	}
+	for _, want := range []string{
+		"✓ Credentials are set (#ws+test)",
+		"✓ Authenticated API call succeeds",
+		"✓ Webservice access is allowed from your IP (203.0.113.7)",
+		"✗ Ordering over the webservice is enabled (ordering is disabled)",
+		"Enable 'ordering over the webservice'",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunDoctor_Forbidden(t *testing.T) {
+	stubPublicIP(t, "203.0.113.7")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	out := captureStdout(t, func() {
+		_ = runDoctor(context.Background(), "#ws+test", "test-pass", []hrobot.ClientOption{hrobot.WithBaseURL(server.URL)})
+	})
+
+	for _, want := range []string{
+		"✗ Webservice access is allowed from your IP (203.0.113.7)",
+		"Your public IP address: 203.0.113.7",
+		"'Webservice/app access'",
+		"- Ordering over the webservice is enabled",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunDoctor_MissingCredentials(t *testing.T) {
+	var err error
+	out := captureStdout(t, func() {
+		err = runDoctor(context.Background(), "", "", nil)
+	})
+
+	if err == nil {
+		t.Fatal("expected an error for missing credentials")
+	}
+	if !strings.Contains(out, "✗ Credentials are set") || !strings.Contains(out, "- Authenticated API call succeeds") {
+		t.Errorf("expected the credentials check to fail and the rest to be skipped, got:\n%s", out)
+	}
+}
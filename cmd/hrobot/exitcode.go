@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// Exit codes of the CLI. Scripts can rely on these; keep them stable and keep
+// the "Exit Codes" section of printHelp in sync.
+const (
+	exitOK          = 0
+	exitError       = 1   // any other error
+	exitAuth        = 2   // invalid credentials or access denied
+	exitNotFound    = 3   // server, IP, product or other resource not found
+	exitValidation  = 4   // the API rejected the input
+	exitRateLimited = 5   // too many requests
+	exitNetwork     = 6   // the API could not be reached
+	exitCancelled   = 130 // interrupted with Ctrl-C (SIGINT) or SIGTERM
+)
+
+// timeoutError replaces an error caused by the --timeout deadline of ctx with
+// a clear message. Errors from the per-request HTTP timeout or --wait-timeout
+// are returned unchanged, as the deadline of ctx has not passed for those.
+func timeoutError(ctx context.Context, timeout time.Duration, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("operation timed out after %s (--timeout)", timeout)
+}
+
+// exitCode maps an error returned by run to the exit code of the CLI.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var hrobotErr *hrobot.Error
+	if !errors.As(err, &hrobotErr) {
+		return exitError
+	}
+
+	switch hrobotErr.Kind {
+	case hrobot.ErrKindAuth:
+		return exitAuth
+	case hrobot.ErrKindNetwork:
+		return exitNetwork
+	case hrobot.ErrKindAPI:
+	default:
+		return exitError
+	}
+
+	code := hrobotErr.Code()
+	switch {
+	case code == hrobot.ErrUnauthorized || code == hrobot.ErrForbidden || code == hrobot.ErrInsufficientPermissions:
+		return exitAuth
+	case code == hrobot.ErrNotFound || strings.HasSuffix(string(code), "_NOT_FOUND"):
+		return exitNotFound
+	case strings.HasPrefix(string(code), string(hrobot.ErrInvalidInput)) || strings.HasSuffix(string(code), "_INVALID"),
+		code == hrobot.ErrFirewallRuleLimitExceeded || code == hrobot.ErrServerNameAmbiguous:
+		return exitValidation
+	case code == hrobot.ErrRateLimitExceeded:
+		return exitRateLimited
+	default:
+		return exitError
+	}
+}
+
+// jsonError is the error envelope printed instead of a plain error message
+// when a command runs with --output json.
+type jsonError struct {
+	Error jsonErrorDetail `json:"error"`
+}
+
+// jsonErrorDetail describes a failed command.
+type jsonErrorDetail struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// errorCode returns a machine-readable code for an error: the API error code
+// (e.g. SERVER_NOT_FOUND) for API errors, the error kind for other hrobot
+// errors and ERROR for everything else.
+func errorCode(err error) string {
+	var hrobotErr *hrobot.Error
+	if !errors.As(err, &hrobotErr) {
+		return "ERROR"
+	}
+	if code := hrobotErr.Code(); code != "" {
+		return string(code)
+	}
+	return strings.ToUpper(string(hrobotErr.Kind))
+}
+
+// writeJSONError writes the JSON error envelope for err to w.
+func writeJSONError(w io.Writer, err error) error {
+	data, marshalErr := json.MarshalIndent(jsonError{Error: jsonErrorDetail{
+		Code:     errorCode(err),
+		Message:  err.Error(),
+		ExitCode: exitCode(err),
+	}}, "", "  ")
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", marshalErr)
+	}
+	_, writeErr := fmt.Fprintln(w, string(data))
+	return writeErr
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "no error", err: nil, want: exitOK},
+		{name: "plain error", err: errors.New("invalid server ID: abc"), want: exitError},
+		{name: "unauthorized", err: hrobot.NewAPIError(hrobot.ErrUnauthorized, "Unable to authenticate"), want: exitAuth},
+		{name: "forbidden", err: hrobot.NewAPIError(hrobot.ErrForbidden, "HTTP 403: Forbidden"), want: exitAuth},
+		{name: "auth error", err: hrobot.NewAuthError("invalid credentials"), want: exitAuth},
+		{name: "server not found", err: hrobot.NewAPIError(hrobot.ErrServerNotFound, "server not found"), want: exitNotFound},
+		{name: "product not found", err: hrobot.NewAPIError(hrobot.ErrProductNotFound, "product not found"), want: exitNotFound},
+		{name: "generic not found", err: hrobot.NewAPIError(hrobot.ErrNotFound, "not found"), want: exitNotFound},
+		{name: "invalid input", err: hrobot.NewAPIError(hrobot.ErrInvalidInput, "invalid input"), want: exitValidation},
+		{name: "invalid server ip", err: hrobot.NewAPIError(hrobot.ErrInvalidInputServerIP, "invalid server ip"), want: exitValidation},
+		{name: "invalid firewall config", err: hrobot.NewAPIError(hrobot.ErrFirewallConfigInvalid, "invalid config"), want: exitValidation},
+		{name: "rate limited", err: hrobot.NewAPIError(hrobot.ErrRateLimitExceeded, "rate limit exceeded"), want: exitRateLimited},
+		{name: "network error", err: hrobot.NewNetworkError("request failed", errors.New("connection refused")), want: exitNetwork},
+		{name: "other API error", err: hrobot.NewAPIError(hrobot.ErrFirewallInProcess, "in process"), want: exitError},
+		{name: "parse error", err: hrobot.NewParseError("invalid JSON", nil), want: exitError},
+		{
+			name: "wrapped and enhanced auth error",
+			err:  enhanceAuthError(fmt.Errorf("failed to list servers: %w", hrobot.NewAPIError(hrobot.ErrUnauthorized, "Unable to authenticate"))),
+			want: exitAuth,
+		},
+		{
+			name: "wrapped not found error",
+			err:  fmt.Errorf("failed to get server: %w", hrobot.NewAPIError(hrobot.ErrServerNotFound, "server not found")),
+			want: exitNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJSONError_ServerNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"status":404,"code":"SERVER_NOT_FOUND","message":"server not found"}}`))
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var cmdErr error
+	out := captureStdout(t, func() {
+		cmdErr = enhanceAuthError(getServer(context.Background(), client, hrobot.ServerID(999), "json"))
+	})
+	if cmdErr == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if out != "" {
+		t.Errorf("expected no output before the error, got %q", out)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONError(&buf, cmdErr); err != nil {
+		t.Fatalf("writeJSONError returned error: %v", err)
+	}
+
+	var envelope struct {
+		Error struct {
+			Code     string `json:"code"`
+			Message  string `json:"message"`
+			ExitCode int    `json:"exit_code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if envelope.Error.Code != "SERVER_NOT_FOUND" {
+		t.Errorf("expected code SERVER_NOT_FOUND, got %q", envelope.Error.Code)
+	}
+	if !strings.Contains(envelope.Error.Message, "server not found") {
+		t.Errorf("expected API message in error message, got %q", envelope.Error.Message)
+	}
+	if envelope.Error.ExitCode != exitNotFound {
+		t.Errorf("expected exit code %d, got %d", exitNotFound, envelope.Error.ExitCode)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: hrobot.NewAPIError(hrobot.ErrRateLimitExceeded, "slow down"), want: "RATE_LIMIT_EXCEEDED"},
+		{err: fmt.Errorf("failed: %w", hrobot.NewNetworkError("request failed", nil)), want: "NETWORK"},
+		{err: errors.New("invalid server ID: abc"), want: "ERROR"},
+	}
+
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
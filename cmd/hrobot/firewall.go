@@ -5,15 +5,17 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aquasecurity/table"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
@@ -40,64 +42,190 @@ func detectIPVersion(ipStr string) hrobot.IPVersion {
 	return hrobot.IPv6
 }
 
-// isAutoAddedMailRule checks if a rule is one of Hetzner's automatically-added mail blocking rules.
-func isAutoAddedMailRule(rule hrobot.FirewallRule) bool {
-	// Hetzner auto-adds "Block mail ports" rules for ports 25,465
-	// These appear in output rules
-	if rule.Name == "Block mail ports" &&
-		rule.Action == hrobot.ActionDiscard &&
-		rule.Protocol == hrobot.ProtocolTCP &&
-		rule.DestPort == "25,465" {
-		return true
-	}
-	return false
+// firewallOptions holds the flags shared by the firewall commands.
+type firewallOptions struct {
+	keepMailBlock  bool          // --keep-mail-block: keep Hetzner's auto-added mail rules in submitted configs
+	waitTimeout    time.Duration // --wait-timeout: how long to wait for an "in process" firewall; zero waits without limit
+	checkUnchanged bool          // --check-unchanged: refuse to write if the firewall changed since it was read
 }
 
 // filterAutoAddedRules removes Hetzner's automatically-added rules from a rule list.
 // These rules are added by Hetzner automatically, so we don't need to send them back.
-func filterAutoAddedRules(rules []hrobot.FirewallRule) []hrobot.FirewallRule {
+// With keepMailBlock the rules are returned unchanged.
+func filterAutoAddedRules(rules []hrobot.FirewallRule, keepMailBlock bool) []hrobot.FirewallRule {
+	if keepMailBlock {
+		return rules
+	}
 	filtered := make([]hrobot.FirewallRule, 0, len(rules))
 	for _, rule := range rules {
-		if !isAutoAddedMailRule(rule) {
+		if !rule.IsMailBlock() {
 			filtered = append(filtered, rule)
 		}
 	}
 	return filtered
 }
 
-// ruleExists checks if a similar rule already exists in the rule list.
+// ruleExists checks if a similar rule already exists in the rule list: one
+// with the same name or one that is functionally the same.
 func ruleExists(rules []hrobot.FirewallRule, newRule hrobot.FirewallRule) bool {
 	for _, rule := range rules {
-		// Check if rules match on key fields
-		if rule.Name == newRule.Name {
+		if rule.Name == newRule.Name || rule.Equal(newRule) {
 			return true
 		}
-		// Also check for functional duplicates (same action, protocol, IPs, port, flags)
-		if rule.Action == newRule.Action &&
-			rule.Protocol == newRule.Protocol &&
-			rule.SourceIP == newRule.SourceIP &&
-			rule.DestIP == newRule.DestIP &&
-			rule.DestPort == newRule.DestPort &&
-			rule.IPVersion == newRule.IPVersion &&
-			rule.TCPFlags == newRule.TCPFlags {
+	}
+	return false
+}
+
+// RuleChangeInfo contains information about the result of adding or deleting
+// rules. It is printed as is with --output json.
+type RuleChangeInfo struct {
+	Added   int                   `json:"added"`
+	Skipped int                   `json:"skipped"`
+	Deleted int                   `json:"deleted,omitempty"`
+	DryRun  bool                  `json:"dry_run,omitempty"` // rules were only previewed, nothing was sent to the API
+	Rules   []hrobot.FirewallRule `json:"rules"`             // rules of the changed direction after the change
+	Removed []hrobot.FirewallRule `json:"removed,omitempty"` // rules deleted by the change
+}
+
+// firewallProgress returns where firewall changes write their progress
+// messages. With --output json they go to stderr so that stdout only carries
+// the JSON result.
+func firewallProgress(jsonOutput bool) io.Writer {
+	if jsonOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printRuleChange prints the result of a firewall change, either as JSON or
+// as the text summary written by printSummary.
+func printRuleChange(info *RuleChangeInfo, jsonOutput bool, printSummary func(w io.Writer)) error {
+	if jsonOutput {
+		return printJSON(info)
+	}
+	printSummary(os.Stdout)
+	return nil
+}
+
+// formatRule renders a firewall rule on a single line.
+func formatRule(rule hrobot.FirewallRule) string {
+	parts := []string{string(rule.Action)}
+	if rule.IPVersion != "" {
+		parts = append(parts, string(rule.IPVersion))
+	}
+	if rule.Protocol != "" {
+		parts = append(parts, string(rule.Protocol))
+	}
+	if rule.SourceIP != "" {
+		parts = append(parts, "src "+rule.SourceIP)
+	}
+	if rule.SourcePort != "" {
+		parts = append(parts, "src port "+rule.SourcePort)
+	}
+	if rule.DestIP != "" {
+		parts = append(parts, "dst "+rule.DestIP)
+	}
+	if rule.DestPort != "" {
+		parts = append(parts, "port "+rule.DestPort)
+	}
+	if rule.TCPFlags != "" {
+		parts = append(parts, "flags "+rule.TCPFlags)
+	}
+	name := rule.Name
+	if name == "" {
+		name = "(unnamed)"
+	}
+	return fmt.Sprintf("%-28s %s", name, strings.Join(parts, " "))
+}
+
+// containsRule reports whether rules contains the given rule with the same name.
+func containsRule(rules []hrobot.FirewallRule, rule hrobot.FirewallRule) bool {
+	for _, r := range rules {
+		if r.Name == rule.Name && r.Equal(rule) {
 			return true
 		}
 	}
 	return false
 }
 
-// RulesAddedInfo contains information about the result of adding rules.
-type RulesAddedInfo struct {
-	Added   int
-	Skipped int
+// formatFirewallDiff renders the rule set of one direction as it would look
+// after a change. New rules are marked with "+", removed rules with "-" and
+// rules that were skipped as duplicates with "⊘".
+func formatFirewallDiff(direction string, before, after, skipped []hrobot.FirewallRule) string {
+	var b strings.Builder
+	added, removed := 0, 0
+
+	fmt.Fprintf(&b, "%s rules after change (%d):\n", direction, len(after))
+	for i, rule := range after {
+		marker := " "
+		if !containsRule(before, rule) {
+			marker = "+"
+			added++
+		}
+		fmt.Fprintf(&b, "  %s [%d] %s\n", marker, i, formatRule(rule))
+	}
+	for _, rule := range before {
+		if !containsRule(after, rule) {
+			fmt.Fprintf(&b, "  -     %s\n", formatRule(rule))
+			removed++
+		}
+	}
+	for _, rule := range skipped {
+		fmt.Fprintf(&b, "  ⊘     %s (duplicate, skipped)\n", formatRule(rule))
+	}
+	fmt.Fprintf(&b, "\nsummary: %d to add, %d to remove, %d skipped\n", added, removed, len(skipped))
+
+	return b.String()
+}
+
+// printDryRun prints the preview of a firewall change without applying it.
+func printDryRun(w io.Writer, serverID hrobot.ServerID, direction string, before, after, skipped []hrobot.FirewallRule) {
+	fmt.Fprintf(w, "\ndry run: no changes will be made to the firewall of server %d\n\n", serverID)
+	fmt.Fprint(w, formatFirewallDiff(direction, before, after, skipped))
+}
+
+// parseWaitTimeout reads the --wait-timeout flag, e.g. "5m" or "90s".
+func parseWaitTimeout(args []string) (time.Duration, error) {
+	return parseDurationFlag(args, "--wait-timeout")
+}
+
+// parseDurationFlag reads a positive duration flag such as --timeout 5m. It
+// returns zero if the flag is not set.
+func parseDurationFlag(args []string, flag string) (time.Duration, error) {
+	value := parseFlagString(args, flag)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s value: %s (use a duration like 90s or 5m)", flag, value)
+	}
+	return d, nil
+}
+
+// waitForFirewallReady waits until the firewall is no longer "in process",
+// giving up after timeout if it is set.
+func waitForFirewallReady(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, timeout time.Duration) error {
+	if timeout <= 0 {
+		return client.Firewall.WaitForFirewallReady(ctx, serverID)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := client.Firewall.WaitForFirewallReady(waitCtx, serverID)
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s waiting for the firewall of server %d to leave the \"in process\" state", timeout, serverID)
+	}
+	return err
 }
 
 // ensureFirewallReady checks if firewall is in "in process" state and waits for it to be ready.
 // It returns the updated firewall config after waiting (if necessary).
-func ensureFirewallReady(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, currentFw *hrobot.FirewallConfig) (*hrobot.FirewallConfig, error) {
+func ensureFirewallReady(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, currentFw *hrobot.FirewallConfig, timeout time.Duration) (*hrobot.FirewallConfig, error) {
 	if currentFw.Status == "in process" {
-		fmt.Println("⏳ firewall is processing previous changes, waiting for it to be ready...")
-		if err := client.Firewall.WaitForFirewallReady(ctx, serverID); err != nil {
+		fmt.Fprintln(w, "⏳ firewall is processing previous changes, waiting for it to be ready...")
+		if err := waitForFirewallReady(ctx, client, serverID, timeout); err != nil {
 			return nil, fmt.Errorf("failed while waiting for firewall to be ready: %w", err)
 		}
 		// Re-fetch firewall config after waiting
@@ -105,48 +233,74 @@ func ensureFirewallReady(ctx context.Context, client *hrobot.Client, serverID hr
 		if err != nil {
 			return nil, fmt.Errorf("failed to get firewall after waiting: %w", err)
 		}
-		fmt.Println("✓ firewall is ready, applying changes...")
+		fmt.Fprintln(w, "✓ firewall is ready, applying changes...")
 		return updatedFw, nil
 	}
 	return currentFw, nil
 }
 
-// addFirewallRules is a helper that adds new input rules to the firewall.
-// Returns information about how many rules were added/skipped.
-func addFirewallRules(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, newRules []hrobot.FirewallRule) (*RulesAddedInfo, error) {
+// updateFirewall writes the result of a read-modify-write cycle based on fw.
+// With --check-unchanged it is only written if the firewall still matches fw.
+func updateFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, fw *hrobot.FirewallConfig, config hrobot.UpdateConfig, opts firewallOptions) error {
+	if !opts.checkUnchanged {
+		_, err := client.Firewall.Update(ctx, serverID, config)
+		return err
+	}
+	_, err := client.Firewall.UpdateIfUnchanged(ctx, serverID, fw.Fingerprint(), config)
+	return err
+}
+
+// firewallModifiedError explains that a read-modify-write cycle was aborted because
+// the firewall rules changed on the server in the meantime (e.g. a concurrent hrobot run).
+func firewallModifiedError(serverID hrobot.ServerID) error {
+	return fmt.Errorf(`firewall rules were modified while this command was running, no changes were made
+
+To resolve this:
+  1. Check the current rules: hrobot firewall list-rules %d
+  2. Run the command again`, serverID)
+}
+
+// addFirewallRulesTo is a helper that adds new input rules to the firewall,
+// writing progress output to w. Returns information about how many rules were
+// added/skipped and the resulting input rules.
+// When dryRun is set, the resulting rule set is printed instead of being applied.
+func addFirewallRulesTo(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, newRules []hrobot.FirewallRule, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
 	fw, err := client.Firewall.Get(ctx, serverID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get firewall: %w", err)
 	}
 
 	// Ensure firewall is ready before making changes
-	fw, err = ensureFirewallReady(ctx, client, serverID, fw)
-	if err != nil {
-		return nil, err
+	if !dryRun {
+		fw, err = ensureFirewallReady(ctx, client, w, serverID, fw, opts.waitTimeout)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Filter out duplicate rules
 	var rulesToAdd []hrobot.FirewallRule
-	var skippedCount int
+	var skippedRules []hrobot.FirewallRule
 	for _, newRule := range newRules {
 		if ruleExists(fw.Rules.Input, newRule) {
-			skippedCount++
-			fmt.Printf("⊘ skipping duplicate rule: %s\n", newRule.Name)
+			skippedRules = append(skippedRules, newRule)
+			fmt.Fprintf(w, "⊘ skipping duplicate rule: %s\n", newRule.Name)
 		} else {
 			rulesToAdd = append(rulesToAdd, newRule)
 		}
 	}
+	skippedCount := len(skippedRules)
 
 	// If all rules were duplicates, nothing to do
 	if len(rulesToAdd) == 0 {
 		if skippedCount > 0 {
-			fmt.Printf("\nℹ all %d rule(s) already exist, no changes made\n", skippedCount)
+			fmt.Fprintf(w, "\nℹ all %d rule(s) already exist, no changes made\n", skippedCount)
 		}
-		return &RulesAddedInfo{Added: 0, Skipped: skippedCount}, nil
+		return &RuleChangeInfo{Added: 0, Skipped: skippedCount, Rules: filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock)}, nil
 	}
 
 	// Filter out auto-added mail rules from existing rules before sending update
-	filteredInput := filterAutoAddedRules(fw.Rules.Input)
+	filteredInput := filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock)
 
 	// Check if adding new rules would exceed the 10 rule limit
 	const maxFirewallRules = 10
@@ -172,18 +326,27 @@ Note: Hetzner enforces a maximum of 10 inbound firewall rules per server`,
 	// Add new rules to the beginning of input rules
 	updatedRules := append(rulesToAdd, filteredInput...)
 
+	if dryRun {
+		printDryRun(w, serverID, "input", filteredInput, updatedRules, skippedRules)
+		return &RuleChangeInfo{Added: len(rulesToAdd), Skipped: skippedCount, DryRun: true, Rules: updatedRules}, nil
+	}
+
 	updateConfig := hrobot.UpdateConfig{
 		Status:       fw.Status,
 		WhitelistHOS: fw.WhitelistHOS,
 		FilterIPv6:   fw.FilterIPv6,
 		Rules: hrobot.FirewallRules{
 			Input:  updatedRules,
-			Output: filterAutoAddedRules(fw.Rules.Output),
+			Output: filterAutoAddedRules(fw.Rules.Output, opts.keepMailBlock),
 		},
 	}
 
-	_, err = client.Firewall.Update(ctx, serverID, updateConfig)
+	err = updateFirewall(ctx, client, serverID, fw, updateConfig, opts)
 	if err != nil {
+		if errors.Is(err, hrobot.ErrFirewallChanged) {
+			return nil, firewallModifiedError(serverID)
+		}
+
 		// Check if this is a rule limit error
 		var hrobotErr *hrobot.Error
 		if errors.As(err, &hrobotErr) && hrobot.IsFirewallRuleLimitExceededError(hrobotErr) {
@@ -237,7 +400,7 @@ Original error: %v`,
 		return nil, fmt.Errorf("failed to update firewall: %w", err)
 	}
 
-	return &RulesAddedInfo{Added: len(rulesToAdd), Skipped: skippedCount}, nil
+	return &RuleChangeInfo{Added: len(rulesToAdd), Skipped: skippedCount, Rules: updatedRules}, nil
 }
 
 // getMyIP attempts to get the user's current public IP.
@@ -264,20 +427,30 @@ func getMyIP() (string, error) {
 
 // Phase 1: Essential convenience commands
 
-func allowSSH(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, sourceIPs []string, myIP bool) error {
+// resolveSourceIPs returns the source IPs of an allow command. With myIP set,
+// the caller's public IP replaces the given list.
+func resolveSourceIPs(w io.Writer, sourceIPs []string, myIP bool) ([]string, error) {
 	ips := sourceIPs
-
 	if myIP {
 		ip, err := getMyIP()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		fmt.Printf("detected your public IP: %s\n", ip)
+		fmt.Fprintf(w, "detected your public IP: %s\n", ip)
 		ips = []string{ip + "/32"}
 	}
 
 	if len(ips) == 0 {
-		return fmt.Errorf("no source IPs specified")
+		return nil, fmt.Errorf("no source IPs specified")
+	}
+	return ips, nil
+}
+
+// allowSSH adds rules accepting SSH from the given IPs. Progress is written to
+// w; the summary is left to the caller.
+func allowSSH(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, ips []string, group string, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no source IPs specified")
 	}
 
 	var rules []hrobot.FirewallRule
@@ -293,32 +466,32 @@ func allowSSH(ctx context.Context, client *hrobot.Client, serverID hrobot.Server
 			IPVersion: ipVersion,
 			Action:    hrobot.ActionAccept,
 			Protocol:  hrobot.ProtocolTCP,
-			SourceIP:  ip,
+			SourceIP:  hrobot.NormalizeCIDR(ip),
 			DestPort:  "22",
 		}
 		rules = append(rules, rule)
 	}
 
-	info, err := addFirewallRules(ctx, client, serverID, rules)
-	if err != nil {
-		return err
-	}
+	return addFirewallRulesTo(ctx, client, w, serverID, groupRules(group, rules), dryRun, opts)
+}
 
+// printAllowSSHResult prints the summary of allowSSH.
+func printAllowSSHResult(w io.Writer, info *RuleChangeInfo, ips []string) {
 	// Only show success message if rules were actually added
-	if info.Added > 0 {
-		fmt.Printf("✓ successfully added %d SSH rule(s)\n", info.Added)
-		for _, ip := range ips {
-			fmt.Printf("  - allowed SSH from %s\n", ip)
-		}
-		fmt.Println("\nnote: firewall changes may take 30-40 seconds to apply")
+	if info.DryRun || info.Added == 0 {
+		return
 	}
-
-	return nil
+	fmt.Fprintf(w, "✓ successfully added %d SSH rule(s)\n", info.Added)
+	for _, ip := range ips {
+		fmt.Fprintf(w, "  - allowed SSH from %s\n", ip)
+	}
+	fmt.Fprintln(w, "\nnote: firewall changes may take 30-40 seconds to apply")
 }
 
-func allowHTTPS(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, sourceIPs []string) error {
+// allowHTTPS adds rules accepting HTTPS from the given IPs.
+func allowHTTPS(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, sourceIPs []string, group string, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
 	if len(sourceIPs) == 0 {
-		return fmt.Errorf("no source IPs specified")
+		return nil, fmt.Errorf("no source IPs specified")
 	}
 
 	var rules []hrobot.FirewallRule
@@ -334,42 +507,31 @@ func allowHTTPS(ctx context.Context, client *hrobot.Client, serverID hrobot.Serv
 			IPVersion: ipVersion,
 			Action:    hrobot.ActionAccept,
 			Protocol:  hrobot.ProtocolTCP,
-			SourceIP:  ip,
+			SourceIP:  hrobot.NormalizeCIDR(ip),
 			DestPort:  "443",
 		}
 		rules = append(rules, rule)
 	}
 
-	info, err := addFirewallRules(ctx, client, serverID, rules)
-	if err != nil {
-		return err
-	}
-
-	if info.Added > 0 {
-		fmt.Printf("✓ successfully added %d HTTPS rule(s)\n", info.Added)
-		for _, ip := range sourceIPs {
-			fmt.Printf("  - allowed HTTPS from %s (%s)\n", ip, detectIPVersion(ip))
-		}
-		fmt.Println("\nnote: firewall changes may take 30-40 seconds to apply")
-	}
-
-	return nil
+	return addFirewallRulesTo(ctx, client, w, serverID, groupRules(group, rules), dryRun, opts)
 }
 
-func allowMOSH(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, sourceIPs []string, myIP bool) error {
-	// Determine IPs
-	ips := sourceIPs
-	if myIP {
-		ip, err := getMyIP()
-		if err != nil {
-			return err
-		}
-		fmt.Printf("detected your public IP: %s\n", ip)
-		ips = []string{ip + "/32"}
+// printAllowHTTPSResult prints the summary of allowHTTPS.
+func printAllowHTTPSResult(w io.Writer, info *RuleChangeInfo, sourceIPs []string) {
+	if info.DryRun || info.Added == 0 {
+		return
+	}
+	fmt.Fprintf(w, "✓ successfully added %d HTTPS rule(s)\n", info.Added)
+	for _, ip := range sourceIPs {
+		fmt.Fprintf(w, "  - allowed HTTPS from %s (%s)\n", ip, detectIPVersion(ip))
 	}
+	fmt.Fprintln(w, "\nnote: firewall changes may take 30-40 seconds to apply")
+}
 
+// allowMOSH adds the SSH, MOSH UDP and TCP established rules needed for MOSH.
+func allowMOSH(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, ips []string, group string, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
 	if len(ips) == 0 {
-		return fmt.Errorf("no source IPs specified")
+		return nil, fmt.Errorf("no source IPs specified")
 	}
 
 	// Build all MOSH rules (SSH TCP, MOSH UDP, TCP established)
@@ -389,7 +551,7 @@ func allowMOSH(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 			IPVersion: ipVersion,
 			Action:    hrobot.ActionAccept,
 			Protocol:  hrobot.ProtocolTCP,
-			SourceIP:  ip,
+			SourceIP:  hrobot.NormalizeCIDR(ip),
 			DestPort:  "22",
 		}
 		rules = append(rules, sshRule)
@@ -400,7 +562,7 @@ func allowMOSH(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 			IPVersion: ipVersion,
 			Action:    hrobot.ActionAccept,
 			Protocol:  hrobot.ProtocolUDP,
-			SourceIP:  ip,
+			SourceIP:  hrobot.NormalizeCIDR(ip),
 			DestPort:  "60000-61000",
 		}
 		rules = append(rules, moshRule)
@@ -419,47 +581,39 @@ func allowMOSH(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 	rules = append(rules, tcpEstablishedRule)
 
 	// Add all rules at once
-	info, err := addFirewallRules(ctx, client, serverID, rules)
-	if err != nil {
-		return err
+	return addFirewallRulesTo(ctx, client, w, serverID, groupRules(group, rules), dryRun, opts)
+}
+
+// printAllowMOSHResult prints the summary of allowMOSH.
+func printAllowMOSHResult(w io.Writer, info *RuleChangeInfo, ips []string) {
+	if info.DryRun {
+		return
 	}
 
 	// Show summary of what was added
 	if info.Added > 0 {
-		fmt.Printf("\n✓ successfully configured MOSH access (%d rule(s) added)\n", info.Added)
+		fmt.Fprintf(w, "\n✓ successfully configured MOSH access (%d rule(s) added)\n", info.Added)
 		for _, ip := range ips {
-			fmt.Printf("  - SSH from %s\n", ip)
-			fmt.Printf("  - MOSH UDP (60000-61000) from %s\n", ip)
+			fmt.Fprintf(w, "  - SSH from %s\n", ip)
+			fmt.Fprintf(w, "  - MOSH UDP (60000-61000) from %s\n", ip)
 		}
-		fmt.Printf("  - TCP established connections\n")
-		fmt.Println("\nnote: firewall changes may take 30-40 seconds to apply")
+		fmt.Fprintf(w, "  - TCP established connections\n")
+		fmt.Fprintln(w, "\nnote: firewall changes may take 30-40 seconds to apply")
 	}
 
 	if info.Skipped > 0 {
-		fmt.Printf("\nℹ %d rule(s) already existed\n", info.Skipped)
+		fmt.Fprintf(w, "\nℹ %d rule(s) already existed\n", info.Skipped)
 	}
 
 	if info.Added == 0 && info.Skipped > 0 {
-		fmt.Println("\n✓ MOSH already configured for this IP")
+		fmt.Fprintln(w, "\n✓ MOSH already configured for this IP")
 	}
-
-	return nil
 }
 
-func allowAll(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, sourceIPs []string, myIP bool) error {
-	// Determine IPs
-	ips := sourceIPs
-	if myIP {
-		ip, err := getMyIP()
-		if err != nil {
-			return err
-		}
-		fmt.Printf("detected your public IP: %s\n", ip)
-		ips = []string{ip + "/32"}
-	}
-
+// allowAll adds rules accepting all traffic from the given IPs.
+func allowAll(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, ips []string, group string, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
 	if len(ips) == 0 {
-		return fmt.Errorf("no source IPs specified")
+		return nil, fmt.Errorf("no source IPs specified")
 	}
 
 	// Build rules that allow ALL traffic
@@ -477,43 +631,44 @@ func allowAll(ctx context.Context, client *hrobot.Client, serverID hrobot.Server
 			Name:      fmt.Sprintf("Allow all %s", nameIP),
 			IPVersion: ipVersion,
 			Action:    hrobot.ActionAccept,
-			SourceIP:  ip,
+			SourceIP:  hrobot.NormalizeCIDR(ip),
 			// No Protocol, DestPort, or other restrictions = allow all
 		}
 		rules = append(rules, rule)
 	}
 
 	// Add all rules at once
-	info, err := addFirewallRules(ctx, client, serverID, rules)
-	if err != nil {
-		return err
+	return addFirewallRulesTo(ctx, client, w, serverID, groupRules(group, rules), dryRun, opts)
+}
+
+// printAllowAllResult prints the summary of allowAll.
+func printAllowAllResult(w io.Writer, info *RuleChangeInfo, ips []string) {
+	if info.DryRun {
+		return
 	}
 
 	// Show summary of what was added
 	if info.Added > 0 {
-		fmt.Printf("\n✓ successfully configured allow-all access (%d rule(s) added)\n", info.Added)
+		fmt.Fprintf(w, "\n✓ successfully configured allow-all access (%d rule(s) added)\n", info.Added)
 		for _, ip := range ips {
-			fmt.Printf("  - Allow ALL traffic from %s\n", ip)
+			fmt.Fprintf(w, "  - Allow ALL traffic from %s\n", ip)
 		}
-		fmt.Println("\nwarning: these rules allow unrestricted access from the specified IPs")
-		fmt.Println("note: firewall changes may take 30-40 seconds to apply")
+		fmt.Fprintln(w, "\nwarning: these rules allow unrestricted access from the specified IPs")
+		fmt.Fprintln(w, "note: firewall changes may take 30-40 seconds to apply")
 	}
 
 	if info.Skipped > 0 {
-		fmt.Printf("\nℹ %d rule(s) already existed\n", info.Skipped)
+		fmt.Fprintf(w, "\nℹ %d rule(s) already existed\n", info.Skipped)
 	}
 
 	if info.Added == 0 && info.Skipped > 0 {
-		fmt.Println("\n✓ allow-all already configured for this IP")
+		fmt.Fprintln(w, "\n✓ allow-all already configured for this IP")
 	}
-
-	return nil
 }
 
-func blockHTTP(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
+// blockHTTPRules returns the rules that discard insecure HTTP on IPv4 and IPv6.
+func blockHTTPRules() []hrobot.FirewallRule {
 	var rules []hrobot.FirewallRule
-
-	// Block HTTP on both IPv4 and IPv6
 	for _, ipVersion := range []hrobot.IPVersion{hrobot.IPv4, hrobot.IPv6} {
 		rule := hrobot.FirewallRule{
 			Name:      "block insecure HTTP",
@@ -524,91 +679,197 @@ func blockHTTP(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 		}
 		rules = append(rules, rule)
 	}
+	return rules
+}
 
-	info, err := addFirewallRules(ctx, client, serverID, rules)
-	if err != nil {
-		return err
+// blockHTTP adds the rules that discard insecure HTTP.
+func blockHTTP(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
+	return addFirewallRulesTo(ctx, client, w, serverID, blockHTTPRules(), dryRun, opts)
+}
+
+// printBlockHTTPResult prints the summary of blockHTTP.
+func printBlockHTTPResult(w io.Writer, info *RuleChangeInfo) {
+	if info.DryRun || info.Added == 0 {
+		return
 	}
+	fmt.Fprintln(w, "✓ successfully blocked insecure HTTP (port 80)")
+	fmt.Fprintln(w, "note: firewall changes may take 30-40 seconds to apply")
+}
 
-	if info.Added > 0 {
-		fmt.Println("✓ successfully blocked insecure HTTP (port 80)")
-		fmt.Println("note: firewall changes may take 30-40 seconds to apply")
+// hardenFirewall applies the selected hardening rules to one server.
+func hardenFirewall(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, blockHTTPFlag bool, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
+	if !blockHTTPFlag {
+		return nil, fmt.Errorf("specify --block-http flag")
 	}
 
-	return nil
+	return blockHTTP(ctx, client, w, serverID, dryRun, opts)
+}
+
+// printHardenResult prints the summary of hardenFirewall.
+func printHardenResult(w io.Writer, info *RuleChangeInfo) {
+	printBlockHTTPResult(w, info)
+	if info.DryRun {
+		return
+	}
+	fmt.Fprintln(w, "\n✓ firewall hardening completed")
 }
 
-func hardenFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, blockHTTPFlag bool) error {
+// defaultHardenConcurrency is how many servers harden-all updates at once.
+const defaultHardenConcurrency = 4
+
+// hardenResult is the outcome of hardening a single server.
+type hardenResult struct {
+	Server hrobot.Server
+	Result string
+	Err    error
+}
+
+// hardenAllServers applies hardening to every server in the account. Servers
+// are processed concurrently and failures do not stop the remaining servers;
+// an error is returned after the result table if any server failed.
+func hardenAllServers(ctx context.Context, client *hrobot.Client, blockHTTPFlag bool, concurrency int, dryRun bool, opts firewallOptions) error {
 	if !blockHTTPFlag {
 		return fmt.Errorf("specify --block-http flag")
 	}
+	if concurrency < 1 {
+		concurrency = defaultHardenConcurrency
+	}
 
-	if err := blockHTTP(ctx, client, serverID); err != nil {
-		return err
+	servers, err := listServersCached(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to list servers: %w", err)
 	}
+	if len(servers) == 0 {
+		fmt.Println("no servers found")
+		return nil
+	}
+
+	results := make([]hardenResult, len(servers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = hardenServer(ctx, client, servers[idx], dryRun, opts)
+			}
+		}()
+	}
+	for idx := range servers {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Server.ServerNumber < results[j].Server.ServerNumber
+	})
+
+	failed := 0
+	t := table.New(os.Stdout)
+	t.SetHeaders("Server", "Name", "Result")
+	for _, result := range results {
+		status := result.Result
+		if result.Err != nil {
+			failed++
+			status = fmt.Sprintf("failed: %v", firstLine(result.Err.Error()))
+		}
+		t.AddRow(strconv.Itoa(result.Server.ServerNumber), result.Server.ServerName, status)
+	}
+	t.Render()
 
-	fmt.Println("\n✓ firewall hardening completed")
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed", failed, len(results))
+	}
+	if !dryRun {
+		fmt.Println("\nnote: firewall changes may take 30-40 seconds to apply")
+	}
 	return nil
 }
 
+// hardenServer applies the block-http rules to one server without printing.
+func hardenServer(ctx context.Context, client *hrobot.Client, server hrobot.Server, dryRun bool, opts firewallOptions) hardenResult {
+	result := hardenResult{Server: server}
+	info, err := addFirewallRulesTo(ctx, client, io.Discard, hrobot.ServerID(server.ServerNumber), blockHTTPRules(), dryRun, opts)
+	switch {
+	case err != nil:
+		result.Err = err
+	case info.Added == 0:
+		result.Result = "already hardened"
+	case info.DryRun:
+		result.Result = fmt.Sprintf("would add %d rule(s)", info.Added)
+	default:
+		result.Result = "hardened"
+	}
+	return result
+}
+
+// firstLine returns the first line of s, for showing multi-line errors in a table.
+func firstLine(s string) string {
+	if idx := strings.Index(s, "\n"); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
+
 // Phase 2: Granular rule management
 
-func addRule(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, direction, protocol, action, name string, sourceIPs, destIPs []string, port string) error {
+// validateICMPType checks the --icmp-type flag of add-rule. The Robot API has
+// no field for ICMP types, so an ICMP rule always matches every type and the
+// flag is rejected instead of being silently ignored.
+func validateICMPType(protocol, icmpType string) error {
+	if icmpType == "" {
+		return nil
+	}
+	if protocol != "icmp" {
+		return fmt.Errorf("--icmp-type is only valid with --protocol icmp")
+	}
+	return fmt.Errorf(`--icmp-type is not supported: the Hetzner Robot firewall cannot filter by ICMP type
+
+An icmp rule always matches all ICMP types (echo-request, echo-reply, unreachable, ...).
+To allow ICMP from specific sources only, add an icmp rule with --source-ips instead`)
+}
+
+// addRule adds a custom rule for each source (in) or destination (out) IP.
+// Progress is written to w; the summary is left to the caller.
+func addRule(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, direction, protocol, action, name string, sourceIPs, destIPs []string, sourcePort, port string, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
 	if direction != "in" && direction != "out" {
-		return fmt.Errorf("direction must be 'in' or 'out'")
+		return nil, fmt.Errorf("direction must be 'in' or 'out'")
 	}
 
 	if action == "" {
 		action = "accept" // default
 	}
 
-	if action != "accept" && action != "discard" {
-		return fmt.Errorf("action must be 'accept' or 'discard'")
+	actionTyped, err := hrobot.ParseAction(action)
+	if err != nil {
+		return nil, err
 	}
-
-	// Validate protocol
-	validProtocols := map[string]bool{"tcp": true, "udp": true, "icmp": true, "esp": true, "gre": true}
-	if !validProtocols[protocol] {
-		return fmt.Errorf("protocol must be one of: tcp, udp, icmp, esp, gre")
+	protocolTyped, err := hrobot.ParseProtocol(protocol)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate port requirements
-	if (protocol == "tcp" || protocol == "udp") && port == "" && direction == "in" {
-		return fmt.Errorf("port is required for TCP/UDP rules")
+	if !protocolTyped.HasPorts() && (port != "" || sourcePort != "") {
+		return nil, fmt.Errorf("%s rules have no ports, remove --port and --source-port", protocolTyped)
+	}
+	if (protocolTyped == hrobot.ProtocolTCP || protocolTyped == hrobot.ProtocolUDP) && port == "" && direction == "in" {
+		return nil, fmt.Errorf("port is required for TCP/UDP rules")
 	}
 
 	fw, err := client.Firewall.Get(ctx, serverID)
 	if err != nil {
-		return fmt.Errorf("failed to get firewall: %w", err)
+		return nil, fmt.Errorf("failed to get firewall: %w", err)
 	}
 
 	// Ensure firewall is ready before making changes
-	fw, err = ensureFirewallReady(ctx, client, serverID, fw)
-	if err != nil {
-		return err
-	}
-
-	// Convert action string to typed constant
-	var actionTyped hrobot.Action
-	if action == "accept" {
-		actionTyped = hrobot.ActionAccept
-	} else {
-		actionTyped = hrobot.ActionDiscard
-	}
-
-	// Convert protocol string to typed constant
-	var protocolTyped hrobot.Protocol
-	switch protocol {
-	case "tcp":
-		protocolTyped = hrobot.ProtocolTCP
-	case "udp":
-		protocolTyped = hrobot.ProtocolUDP
-	case "icmp":
-		protocolTyped = hrobot.ProtocolICMP
-	case "esp":
-		protocolTyped = hrobot.ProtocolESP
-	case "gre":
-		protocolTyped = hrobot.ProtocolGRE
+	if !dryRun {
+		fw, err = ensureFirewallReady(ctx, client, w, serverID, fw, opts.waitTimeout)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var rules []hrobot.FirewallRule
@@ -622,12 +883,13 @@ func addRule(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerI
 		for _, sourceIP := range sourceIPs {
 			ipVersion := detectIPVersion(sourceIP)
 			rule := hrobot.FirewallRule{
-				Name:      name,
-				IPVersion: ipVersion,
-				Action:    actionTyped,
-				Protocol:  protocolTyped,
-				SourceIP:  sourceIP,
-				DestPort:  port,
+				Name:       name,
+				IPVersion:  ipVersion,
+				Action:     actionTyped,
+				Protocol:   protocolTyped,
+				SourceIP:   hrobot.NormalizeCIDR(sourceIP),
+				SourcePort: sourcePort,
+				DestPort:   port,
 			}
 			rules = append(rules, rule)
 		}
@@ -640,12 +902,13 @@ func addRule(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerI
 		for _, destIP := range destIPs {
 			ipVersion := detectIPVersion(destIP)
 			rule := hrobot.FirewallRule{
-				Name:      name,
-				IPVersion: ipVersion,
-				Action:    actionTyped,
-				Protocol:  protocolTyped,
-				DestIP:    destIP,
-				DestPort:  port,
+				Name:       name,
+				IPVersion:  ipVersion,
+				Action:     actionTyped,
+				Protocol:   protocolTyped,
+				DestIP:     hrobot.NormalizeCIDR(destIP),
+				SourcePort: sourcePort,
+				DestPort:   port,
 			}
 			rules = append(rules, rule)
 		}
@@ -653,7 +916,7 @@ func addRule(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerI
 
 	// Check for duplicates
 	var rulesToAdd []hrobot.FirewallRule
-	var skippedCount int
+	var skippedRules []hrobot.FirewallRule
 	existingRules := fw.Rules.Input
 	if direction == "out" {
 		existingRules = fw.Rules.Output
@@ -661,73 +924,141 @@ func addRule(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerI
 
 	for _, newRule := range rules {
 		if ruleExists(existingRules, newRule) {
-			skippedCount++
-			fmt.Printf("⊘ skipping duplicate rule: %s\n", newRule.Name)
+			skippedRules = append(skippedRules, newRule)
+			fmt.Fprintf(w, "⊘ skipping duplicate rule: %s\n", newRule.Name)
 		} else {
 			rulesToAdd = append(rulesToAdd, newRule)
 		}
 	}
+	skippedCount := len(skippedRules)
 
+	filteredExisting := filterAutoAddedRules(existingRules, opts.keepMailBlock)
 	if len(rulesToAdd) == 0 {
 		if skippedCount > 0 {
-			fmt.Printf("\nℹ all %d rule(s) already exist, no changes made\n", skippedCount)
+			fmt.Fprintf(w, "\nℹ all %d rule(s) already exist, no changes made\n", skippedCount)
 		}
-		return nil
+		return &RuleChangeInfo{Skipped: skippedCount, Rules: filteredExisting}, nil
 	}
 
-	// Add rules based on direction
-	if direction == "in" {
-		// Filter out auto-added mail rules from existing input rules
-		filteredInput := filterAutoAddedRules(fw.Rules.Input)
-		updatedRules := append(rulesToAdd, filteredInput...)
-		updateConfig := hrobot.UpdateConfig{
-			Status:       fw.Status,
-			WhitelistHOS: fw.WhitelistHOS,
-			FilterIPv6:   fw.FilterIPv6,
-			Rules: hrobot.FirewallRules{
-				Input:  updatedRules,
-				Output: filterAutoAddedRules(fw.Rules.Output),
-			},
+	updatedRules := append(rulesToAdd, filteredExisting...)
+	if dryRun {
+		directionName := "input"
+		if direction == "out" {
+			directionName = "output"
 		}
-		_, err = client.Firewall.Update(ctx, serverID, updateConfig)
-	} else {
-		filteredOutput := filterAutoAddedRules(fw.Rules.Output)
-		updatedRules := append(rulesToAdd, filteredOutput...)
-		updateConfig := hrobot.UpdateConfig{
-			Status:       fw.Status,
-			WhitelistHOS: fw.WhitelistHOS,
-			FilterIPv6:   fw.FilterIPv6,
-			Rules: hrobot.FirewallRules{
-				Input:  filterAutoAddedRules(fw.Rules.Input), // Filter here too
-				Output: updatedRules,
-			},
-		}
-		_, err = client.Firewall.Update(ctx, serverID, updateConfig)
+		printDryRun(w, serverID, directionName, filteredExisting, updatedRules, skippedRules)
+		return &RuleChangeInfo{Added: len(rulesToAdd), Skipped: skippedCount, DryRun: true, Rules: updatedRules}, nil
 	}
 
+	// Add rules based on direction, filtering out auto-added mail rules
+	rulesConfig := hrobot.FirewallRules{
+		Input:  filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock),
+		Output: updatedRules,
+	}
+	if direction == "in" {
+		rulesConfig = hrobot.FirewallRules{
+			Input:  updatedRules,
+			Output: filterAutoAddedRules(fw.Rules.Output, opts.keepMailBlock),
+		}
+	}
+	updateConfig := hrobot.UpdateConfig{
+		Status:       fw.Status,
+		WhitelistHOS: fw.WhitelistHOS,
+		FilterIPv6:   fw.FilterIPv6,
+		Rules:        rulesConfig,
+	}
+	err = updateFirewall(ctx, client, serverID, fw, updateConfig, opts)
 	if err != nil {
-		return fmt.Errorf("failed to update firewall: %w", err)
+		if errors.Is(err, hrobot.ErrFirewallChanged) {
+			return nil, firewallModifiedError(serverID)
+		}
+		return nil, fmt.Errorf("failed to update firewall: %w", err)
 	}
 
-	fmt.Printf("✓ successfully added %d %s rule(s)\n", len(rulesToAdd), direction)
-	if skippedCount > 0 {
-		fmt.Printf("  (%d duplicate(s) skipped)\n", skippedCount)
+	return &RuleChangeInfo{Added: len(rulesToAdd), Skipped: skippedCount, Rules: updatedRules}, nil
+}
+
+// printAddRuleResult prints the summary of addRule.
+func printAddRuleResult(w io.Writer, info *RuleChangeInfo, direction string) {
+	if info.DryRun || info.Added == 0 {
+		return
 	}
-	fmt.Println("\nnote: firewall changes may take 30-40 seconds to apply")
+	fmt.Fprintf(w, "✓ successfully added %d %s rule(s)\n", info.Added, direction)
+	if info.Skipped > 0 {
+		fmt.Fprintf(w, "  (%d duplicate(s) skipped)\n", info.Skipped)
+	}
+	fmt.Fprintln(w, "\nnote: firewall changes may take 30-40 seconds to apply")
+}
 
+// ruleMatcher selects firewall rules by their content. Empty fields match any value.
+type ruleMatcher struct {
+	Protocol string
+	Port     string
+	SourceIP string
+	Action   string
+}
+
+// validate checks the protocol and action of the matcher.
+func (m ruleMatcher) validate() error {
+	if m.Protocol != "" {
+		if _, err := hrobot.ParseProtocol(m.Protocol); err != nil {
+			return err
+		}
+	}
+	if m.Action != "" {
+		if _, err := hrobot.ParseAction(m.Action); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func deleteRule(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, name string, index int, direction string) error {
+// isEmpty reports whether no matcher field is set.
+func (m ruleMatcher) isEmpty() bool {
+	return m.Protocol == "" && m.Port == "" && m.SourceIP == "" && m.Action == ""
+}
+
+// matches reports whether the rule matches all set fields of the matcher.
+func (m ruleMatcher) matches(rule hrobot.FirewallRule) bool {
+	if m.Protocol != "" && !strings.EqualFold(string(rule.Protocol), m.Protocol) {
+		return false
+	}
+	if m.Port != "" && rule.DestPort != m.Port {
+		return false
+	}
+	if m.Action != "" && !strings.EqualFold(string(rule.Action), m.Action) {
+		return false
+	}
+	if m.SourceIP != "" && !sourceIPMatches(rule.SourceIP, m.SourceIP) {
+		return false
+	}
+	return true
+}
+
+// sourceIPMatches compares a rule's source IP with a user-supplied IP.
+// A plain address also matches the same address as a single-host CIDR.
+func sourceIPMatches(ruleIP, ip string) bool {
+	return hrobot.NormalizeCIDR(ruleIP) == hrobot.NormalizeCIDR(ip)
+}
+
+// deleteRule removes rules selected by name, index or matcher. Progress is
+// written to w; the summary is left to the caller.
+func deleteRule(ctx context.Context, client *hrobot.Client, w io.Writer, serverID hrobot.ServerID, name string, index int, matcher ruleMatcher, direction string, dryRun bool, opts firewallOptions) (*RuleChangeInfo, error) {
+	if err := matcher.validate(); err != nil {
+		return nil, err
+	}
+
 	fw, err := client.Firewall.Get(ctx, serverID)
 	if err != nil {
-		return fmt.Errorf("failed to get firewall: %w", err)
+		return nil, fmt.Errorf("failed to get firewall: %w", err)
 	}
 
 	// Ensure firewall is ready before making changes
-	fw, err = ensureFirewallReady(ctx, client, serverID, fw)
-	if err != nil {
-		return err
+	if !dryRun {
+		fw, err = ensureFirewallReady(ctx, client, w, serverID, fw, opts.waitTimeout)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if direction == "" {
@@ -742,7 +1073,7 @@ func deleteRule(ctx context.Context, client *hrobot.Client, serverID hrobot.Serv
 	}
 
 	var updatedRules []hrobot.FirewallRule
-	deleted := 0
+	var removedRules []hrobot.FirewallRule
 
 	if name != "" {
 		// Delete by name
@@ -750,27 +1081,46 @@ func deleteRule(ctx context.Context, client *hrobot.Client, serverID hrobot.Serv
 			if rule.Name != name {
 				updatedRules = append(updatedRules, rule)
 			} else {
-				deleted++
+				removedRules = append(removedRules, rule)
 			}
 		}
 	} else if index >= 0 {
 		// Delete by index
 		if index >= len(rules) {
-			return fmt.Errorf("index %d out of range (total rules: %d)", index, len(rules))
+			return nil, fmt.Errorf("index %d out of range (total rules: %d)", index, len(rules))
 		}
 		for i, rule := range rules {
 			if i != index {
 				updatedRules = append(updatedRules, rule)
 			} else {
-				deleted++
+				removedRules = append(removedRules, rule)
+			}
+		}
+	} else if !matcher.isEmpty() {
+		// Delete every rule matching the given content
+		for _, rule := range rules {
+			if matcher.matches(rule) {
+				removedRules = append(removedRules, rule)
+			} else {
+				updatedRules = append(updatedRules, rule)
 			}
 		}
 	} else {
-		return fmt.Errorf("specify either --name or --index")
+		return nil, fmt.Errorf("specify --name, --index or at least one of --protocol, --port, --source-ip, --action")
 	}
+	deleted := len(removedRules)
 
 	if deleted == 0 {
-		return fmt.Errorf("no matching rules found")
+		return nil, fmt.Errorf("no matching rules found")
+	}
+
+	if dryRun {
+		directionName := "input"
+		if direction == "out" {
+			directionName = "output"
+		}
+		printDryRun(w, serverID, directionName, filterAutoAddedRules(rules, opts.keepMailBlock), filterAutoAddedRules(updatedRules, opts.keepMailBlock), nil)
+		return &RuleChangeInfo{Deleted: deleted, DryRun: true, Rules: filterAutoAddedRules(updatedRules, opts.keepMailBlock), Removed: removedRules}, nil
 	}
 
 	// Update firewall
@@ -784,27 +1134,282 @@ func deleteRule(ctx context.Context, client *hrobot.Client, serverID hrobot.Serv
 	if direction == "in" {
 		updateConfig.Rules.Input = updatedRules
 		// Always filter auto-added mail rules from input
-		updateConfig.Rules.Input = filterAutoAddedRules(updateConfig.Rules.Input)
+		updateConfig.Rules.Input = filterAutoAddedRules(updateConfig.Rules.Input, opts.keepMailBlock)
 		// Filter output rules too
-		updateConfig.Rules.Output = filterAutoAddedRules(fw.Rules.Output)
+		updateConfig.Rules.Output = filterAutoAddedRules(fw.Rules.Output, opts.keepMailBlock)
 	} else {
 		updateConfig.Rules.Output = updatedRules
 		// Filter auto-added mail rules from both input and output
-		updateConfig.Rules.Input = filterAutoAddedRules(fw.Rules.Input)
-		updateConfig.Rules.Output = filterAutoAddedRules(updateConfig.Rules.Output)
+		updateConfig.Rules.Input = filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock)
+		updateConfig.Rules.Output = filterAutoAddedRules(updateConfig.Rules.Output, opts.keepMailBlock)
 	}
 
-	_, err = client.Firewall.Update(ctx, serverID, updateConfig)
+	err = updateFirewall(ctx, client, serverID, fw, updateConfig, opts)
+	if err != nil {
+		if errors.Is(err, hrobot.ErrFirewallChanged) {
+			return nil, firewallModifiedError(serverID)
+		}
+		return nil, fmt.Errorf("failed to update firewall: %w", err)
+	}
+
+	return &RuleChangeInfo{Deleted: deleted, Rules: filterAutoAddedRules(updatedRules, opts.keepMailBlock), Removed: removedRules}, nil
+}
+
+// printDeleteRuleResult prints the summary of deleteRule.
+func printDeleteRuleResult(w io.Writer, info *RuleChangeInfo, direction string) {
+	if info.DryRun {
+		return
+	}
+	if direction == "" {
+		direction = "in"
+	}
+	fmt.Fprintf(w, "✓ successfully deleted %d rule(s) from %s rules\n", info.Deleted, direction)
+	for _, rule := range info.Removed {
+		fmt.Fprintf(w, "  - %s\n", formatRule(rule))
+	}
+	fmt.Fprintln(w, "note: firewall changes may take 30-40 seconds to apply")
+}
+
+// groupRuleName prefixes a rule name with a group label, e.g.
+// "[office VPN] Allow SSH 1.2.3.4". The Robot API has no field for labels,
+// so the group is stored in the rule name.
+func groupRuleName(group, name string) string {
+	if group == "" {
+		return name
+	}
+	prefix := "[" + group + "]"
+	if name == "" {
+		return prefix
+	}
+	return prefix + " " + name
+}
+
+// groupRules returns the rules with their names prefixed by the group label.
+func groupRules(group string, rules []hrobot.FirewallRule) []hrobot.FirewallRule {
+	if group == "" {
+		return rules
+	}
+	grouped := make([]hrobot.FirewallRule, len(rules))
+	for i, rule := range rules {
+		rule.Name = groupRuleName(group, rule.Name)
+		grouped[i] = rule
+	}
+	return grouped
+}
+
+// ruleInGroup reports whether the rule name carries the given group label.
+func ruleInGroup(rule hrobot.FirewallRule, group string) bool {
+	prefix := "[" + group + "]"
+	return rule.Name == prefix || strings.HasPrefix(rule.Name, prefix+" ")
+}
+
+// validateGroup checks a --group label.
+func validateGroup(group string) error {
+	if strings.ContainsAny(group, "[]") {
+		return fmt.Errorf("--group must not contain '[' or ']'")
+	}
+	return nil
+}
+
+// deleteGroup removes every input and output rule in the given group.
+func deleteGroup(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, group string, dryRun bool, opts firewallOptions) error {
+	if group == "" {
+		return fmt.Errorf("group label is required")
+	}
+
+	fw, err := client.Firewall.Get(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get firewall: %w", err)
+	}
+
+	// Ensure firewall is ready before making changes
+	if !dryRun {
+		fw, err = ensureFirewallReady(ctx, client, os.Stdout, serverID, fw, opts.waitTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	split := func(rules []hrobot.FirewallRule) (kept, removed []hrobot.FirewallRule) {
+		kept = []hrobot.FirewallRule{}
+		for _, rule := range filterAutoAddedRules(rules, opts.keepMailBlock) {
+			if ruleInGroup(rule, group) {
+				removed = append(removed, rule)
+			} else {
+				kept = append(kept, rule)
+			}
+		}
+		return kept, removed
+	}
+	input, removedInput := split(fw.Rules.Input)
+	output, removedOutput := split(fw.Rules.Output)
+
+	if len(removedInput)+len(removedOutput) == 0 {
+		return fmt.Errorf("no rules found in group %q", group)
+	}
+
+	if dryRun {
+		if len(removedInput) > 0 {
+			printDryRun(os.Stdout, serverID, "input", filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock), input, nil)
+		}
+		if len(removedOutput) > 0 {
+			printDryRun(os.Stdout, serverID, "output", filterAutoAddedRules(fw.Rules.Output, opts.keepMailBlock), output, nil)
+		}
+		return nil
+	}
+
+	updateConfig := hrobot.UpdateConfig{
+		Status:       fw.Status,
+		WhitelistHOS: fw.WhitelistHOS,
+		FilterIPv6:   fw.FilterIPv6,
+		Rules: hrobot.FirewallRules{
+			Input:  input,
+			Output: output,
+		},
+	}
+
+	err = updateFirewall(ctx, client, serverID, fw, updateConfig, opts)
 	if err != nil {
+		if errors.Is(err, hrobot.ErrFirewallChanged) {
+			return firewallModifiedError(serverID)
+		}
 		return fmt.Errorf("failed to update firewall: %w", err)
 	}
 
-	fmt.Printf("✓ successfully deleted %d rule(s) from %s rules\n", deleted, direction)
+	fmt.Printf("✓ successfully deleted %d rule(s) in group %q\n", len(removedInput)+len(removedOutput), group)
+	for _, rule := range append(removedInput, removedOutput...) {
+		fmt.Printf("  - %s\n", formatRule(rule))
+	}
 	fmt.Println("note: firewall changes may take 30-40 seconds to apply")
 
 	return nil
 }
 
+// readRulesFile reads a rules JSON file, or stdin when path is "-".
+func readRulesFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// validateFirewallRules checks a complete rule set before it is sent to the API.
+func validateFirewallRules(rules hrobot.FirewallRules) error {
+	const maxFirewallRules = 10
+	if len(rules.Input) > maxFirewallRules {
+		return fmt.Errorf("too many input rules: %d (maximum allowed: %d inbound rules)", len(rules.Input), maxFirewallRules)
+	}
+
+	check := func(direction string, list []hrobot.FirewallRule) error {
+		for i, rule := range list {
+			if _, err := hrobot.ParseAction(string(rule.Action)); err != nil {
+				return fmt.Errorf("%s rule %d (%q): %w", direction, i, rule.Name, err)
+			}
+			if rule.IPVersion != "" && rule.IPVersion != hrobot.IPv4 && rule.IPVersion != hrobot.IPv6 {
+				return fmt.Errorf("%s rule %d (%q): ip_version must be 'ipv4' or 'ipv6', got %q", direction, i, rule.Name, rule.IPVersion)
+			}
+			if rule.Protocol != "" {
+				protocol, err := hrobot.ParseProtocol(string(rule.Protocol))
+				if err != nil {
+					return fmt.Errorf("%s rule %d (%q): %w", direction, i, rule.Name, err)
+				}
+				if !protocol.HasPorts() && (rule.SourcePort != "" || rule.DestPort != "") {
+					return fmt.Errorf("%s rule %d (%q): %s rules have no ports", direction, i, rule.Name, protocol)
+				}
+			}
+			for _, ip := range []string{rule.SourceIP, rule.DestIP} {
+				if ip == "" {
+					continue
+				}
+				if _, _, err := net.ParseCIDR(ip); err != nil && net.ParseIP(ip) == nil {
+					return fmt.Errorf("%s rule %d (%q): invalid IP address or CIDR %q", direction, i, rule.Name, ip)
+				}
+			}
+			for _, port := range []string{rule.SourcePort, rule.DestPort} {
+				if port == "" {
+					continue
+				}
+				if err := validatePortSpec(port); err != nil {
+					return fmt.Errorf("%s rule %d (%q): %w", direction, i, rule.Name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := check("input", rules.Input); err != nil {
+		return err
+	}
+	return check("output", rules.Output)
+}
+
+// replaceFirewall replaces all input and output rules of a server with the
+// rules from a file in a single update. The firewall status and settings are kept.
+func replaceFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, rulesFile string, opts firewallOptions) error {
+	rf, err := loadRulesFile(rulesFile)
+	if err != nil {
+		return err
+	}
+
+	rules := hrobot.FirewallRules{
+		Input:  filterAutoAddedRules(rf.Rules.Input, opts.keepMailBlock),
+		Output: filterAutoAddedRules(rf.Rules.Output, opts.keepMailBlock),
+	}
+
+	fw, err := client.Firewall.Get(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get firewall: %w", err)
+	}
+
+	// Ensure firewall is ready before making changes
+	fw, err = ensureFirewallReady(ctx, client, os.Stdout, serverID, fw, opts.waitTimeout)
+	if err != nil {
+		return err
+	}
+
+	updateConfig := hrobot.UpdateConfig{
+		Status:       fw.Status,
+		WhitelistHOS: fw.WhitelistHOS,
+		FilterIPv6:   fw.FilterIPv6,
+		Rules:        rules,
+	}
+
+	err = updateFirewall(ctx, client, serverID, fw, updateConfig, opts)
+	if err != nil {
+		if errors.Is(err, hrobot.ErrFirewallChanged) {
+			return firewallModifiedError(serverID)
+		}
+		return fmt.Errorf("failed to update firewall: %w", err)
+	}
+
+	fmt.Printf("✓ successfully replaced firewall rules (%d input, %d output)\n", len(rules.Input), len(rules.Output))
+	fmt.Println("note: firewall changes may take 30-40 seconds to apply")
+
+	return nil
+}
+
+// renderRulesTable prints firewall rules as a table.
+func renderRulesTable(w io.Writer, rules []hrobot.FirewallRule) {
+	t := table.New(w)
+	t.SetHeaders("#", "Name", "Action", "IP Ver", "Protocol", "Source IP", "Source Port", "Dest IP", "Dest Port", "TCP Flags")
+
+	for i, rule := range rules {
+		t.AddRow(
+			strconv.Itoa(i),
+			rule.Name,
+			string(rule.Action),
+			string(rule.IPVersion),
+			string(rule.Protocol),
+			rule.SourceIP,
+			rule.SourcePort,
+			rule.DestIP,
+			rule.DestPort,
+			rule.TCPFlags,
+		)
+	}
+	t.Render()
+}
+
 func listRules(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, direction string, outputFormat string) error {
 	fw, err := client.Firewall.Get(ctx, serverID)
 	if err != nil {
@@ -812,12 +1417,7 @@ func listRules(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 	}
 
 	if outputFormat == "json" {
-		data, err := json.MarshalIndent(fw, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-		fmt.Println(string(data))
-		return nil
+		return printJSON(fw)
 	}
 
 	// Show firewall status
@@ -830,23 +1430,7 @@ func listRules(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 	if direction == "" || direction == "in" {
 		if len(fw.Rules.Input) > 0 {
 			fmt.Printf("\nInput Rules (%d):\n", len(fw.Rules.Input))
-			t := table.New(os.Stdout)
-			t.SetHeaders("#", "Name", "Action", "IP Ver", "Protocol", "Source IP", "Dest IP", "Port", "TCP Flags")
-
-			for i, rule := range fw.Rules.Input {
-				t.AddRow(
-					strconv.Itoa(i),
-					rule.Name,
-					string(rule.Action),
-					string(rule.IPVersion),
-					string(rule.Protocol),
-					rule.SourceIP,
-					rule.DestIP,
-					rule.DestPort,
-					rule.TCPFlags,
-				)
-			}
-			t.Render()
+			renderRulesTable(os.Stdout, fw.Rules.Input)
 		} else {
 			fmt.Println("\nNo input rules configured")
 		}
@@ -856,23 +1440,7 @@ func listRules(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 	if direction == "" || direction == "out" {
 		if len(fw.Rules.Output) > 0 {
 			fmt.Printf("\nOutput Rules (%d):\n", len(fw.Rules.Output))
-			t := table.New(os.Stdout)
-			t.SetHeaders("#", "Name", "Action", "IP Ver", "Protocol", "Source IP", "Dest IP", "Port", "TCP Flags")
-
-			for i, rule := range fw.Rules.Output {
-				t.AddRow(
-					strconv.Itoa(i),
-					rule.Name,
-					string(rule.Action),
-					string(rule.IPVersion),
-					string(rule.Protocol),
-					rule.SourceIP,
-					rule.DestIP,
-					rule.DestPort,
-					rule.TCPFlags,
-				)
-			}
-			t.Render()
+			renderRulesTable(os.Stdout, fw.Rules.Output)
 		} else if direction == "out" {
 			fmt.Println("\nNo output rules configured")
 		}
@@ -890,12 +1458,7 @@ func listTemplates(ctx context.Context, client *hrobot.Client, outputFormat stri
 	}
 
 	if outputFormat == "json" {
-		data, err := json.MarshalIndent(templates, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-		fmt.Println(string(data))
-		return nil
+		return printJSON(templates)
 	}
 
 	if len(templates) == 0 {
@@ -930,12 +1493,7 @@ func describeTemplate(ctx context.Context, client *hrobot.Client, templateID int
 	}
 
 	if outputFormat == "json" {
-		data, err := json.MarshalIndent(tmpl, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-		fmt.Println(string(data))
-		return nil
+		return printJSON(tmpl)
 	}
 
 	fmt.Printf("Firewall Template #%d:\n", tmpl.ID)
@@ -946,46 +1504,14 @@ func describeTemplate(ctx context.Context, client *hrobot.Client, templateID int
 
 	if len(tmpl.Rules.Input) > 0 {
 		fmt.Printf("\nInput Rules (%d):\n", len(tmpl.Rules.Input))
-		t := table.New(os.Stdout)
-		t.SetHeaders("#", "Name", "Action", "IP Ver", "Protocol", "Source IP", "Dest IP", "Port", "TCP Flags")
-
-		for i, rule := range tmpl.Rules.Input {
-			t.AddRow(
-				strconv.Itoa(i),
-				rule.Name,
-				string(rule.Action),
-				string(rule.IPVersion),
-				string(rule.Protocol),
-				rule.SourceIP,
-				rule.DestIP,
-				rule.DestPort,
-				rule.TCPFlags,
-			)
-		}
-		t.Render()
+		renderRulesTable(os.Stdout, tmpl.Rules.Input)
 	} else {
 		fmt.Println("\nNo input rules configured")
 	}
 
 	if len(tmpl.Rules.Output) > 0 {
 		fmt.Printf("\nOutput Rules (%d):\n", len(tmpl.Rules.Output))
-		t := table.New(os.Stdout)
-		t.SetHeaders("#", "Name", "Action", "IP Ver", "Protocol", "Source IP", "Dest IP", "Port", "TCP Flags")
-
-		for i, rule := range tmpl.Rules.Output {
-			t.AddRow(
-				strconv.Itoa(i),
-				rule.Name,
-				string(rule.Action),
-				string(rule.IPVersion),
-				string(rule.Protocol),
-				rule.SourceIP,
-				rule.DestIP,
-				rule.DestPort,
-				rule.TCPFlags,
-			)
-		}
-		t.Render()
+		renderRulesTable(os.Stdout, tmpl.Rules.Output)
 	} else {
 		fmt.Println("\nNo output rules configured")
 	}
@@ -1024,24 +1550,24 @@ func createTemplate(ctx context.Context, client *hrobot.Client, name string, fro
 		}
 	} else if rulesFile != "" {
 		// Create from rules file
-		var fileData []byte
-		var err error
-
-		if rulesFile == "-" {
-			fileData, err = io.ReadAll(os.Stdin)
-		} else {
-			fileData, err = os.ReadFile(rulesFile)
-		}
-
+		rf, err := loadRulesFile(rulesFile)
 		if err != nil {
-			return fmt.Errorf("failed to read rules file: %w", err)
+			return err
 		}
 
-		if err := json.Unmarshal(fileData, &config); err != nil {
-			return fmt.Errorf("failed to parse rules file: %w", err)
+		config = hrobot.TemplateConfig{
+			Name:         name,
+			WhitelistHOS: whitelistHOS,
+			FilterIPv6:   filterIPv6,
+			Rules:        rf.Rules,
+		}
+		// Settings in the file take precedence over the flags
+		if rf.WhitelistHOS != nil {
+			config.WhitelistHOS = *rf.WhitelistHOS
+		}
+		if rf.FilterIPv6 != nil {
+			config.FilterIPv6 = *rf.FilterIPv6
 		}
-
-		config.Name = name
 	} else {
 		// Create empty template
 		config = hrobot.TemplateConfig{
@@ -1063,6 +1589,77 @@ func createTemplate(ctx context.Context, client *hrobot.Client, name string, fro
 	return nil
 }
 
+// updateTemplate changes a template in place, keeping its ID. Only the given
+// settings change; rules are replaced when a rules file is given. Settings in
+// the rules file apply unless overridden by a flag.
+func updateTemplate(ctx context.Context, client *hrobot.Client, templateID int, name string, rulesPath string, whitelistHOS, filterIPv6 *bool) error {
+	if name == "" && rulesPath == "" && whitelistHOS == nil && filterIPv6 == nil {
+		return fmt.Errorf("nothing to update: specify --name, --rules-file, --whitelist-hos or --filter-ipv6")
+	}
+
+	var rf *rulesFile
+	if rulesPath != "" {
+		var err error
+		rf, err = loadRulesFile(rulesPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpl, err := client.Firewall.GetTemplate(ctx, strconv.Itoa(templateID))
+	if err != nil {
+		return fmt.Errorf("failed to get template: %w", err)
+	}
+
+	config := hrobot.TemplateConfig{
+		Name:         tmpl.Name,
+		FilterIPv6:   tmpl.FilterIPv6,
+		WhitelistHOS: tmpl.WhitelistHOS,
+		IsDefault:    tmpl.IsDefault,
+		Rules:        tmpl.Rules,
+	}
+	if name != "" {
+		config.Name = name
+	}
+	if rf != nil {
+		config.Rules = rf.Rules
+		if rf.WhitelistHOS != nil {
+			config.WhitelistHOS = *rf.WhitelistHOS
+		}
+		if rf.FilterIPv6 != nil {
+			config.FilterIPv6 = *rf.FilterIPv6
+		}
+	}
+	if whitelistHOS != nil {
+		config.WhitelistHOS = *whitelistHOS
+	}
+	if filterIPv6 != nil {
+		config.FilterIPv6 = *filterIPv6
+	}
+
+	updated, err := client.Firewall.UpdateTemplate(ctx, strconv.Itoa(templateID), config)
+	if err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+
+	fmt.Printf("✓ successfully updated template #%d: %s\n", updated.ID, updated.Name)
+	fmt.Printf("  input rules:  %d\n", len(updated.Rules.Input))
+	fmt.Printf("  output rules: %d\n", len(updated.Rules.Output))
+
+	return nil
+}
+
+// setDefaultTemplate marks a template as the account default.
+func setDefaultTemplate(ctx context.Context, client *hrobot.Client, templateID int) error {
+	tmpl, err := client.Firewall.SetDefaultTemplate(ctx, strconv.Itoa(templateID))
+	if err != nil {
+		return fmt.Errorf("failed to set default template: %w", err)
+	}
+
+	fmt.Printf("✓ template #%d (%s) is now the default template\n", tmpl.ID, tmpl.Name)
+	return nil
+}
+
 func deleteTemplate(ctx context.Context, client *hrobot.Client, templateID int, confirm bool) error {
 	if !confirm {
 		return fmt.Errorf("template deletion requires --confirm flag")
@@ -1080,7 +1677,7 @@ func deleteTemplate(ctx context.Context, client *hrobot.Client, templateID int,
 
 // Phase 4: Status management
 
-func enableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, filterIPv6 *bool) error {
+func enableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, filterIPv6 *bool, opts firewallOptions) error {
 	fmt.Printf("enabling firewall for server #%d...\n", serverID)
 
 	// Get current firewall config
@@ -1090,7 +1687,7 @@ func enableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.
 	}
 
 	// Ensure firewall is ready before making changes
-	fw, err = ensureFirewallReady(ctx, client, serverID, fw)
+	fw, err = ensureFirewallReady(ctx, client, os.Stdout, serverID, fw, opts.waitTimeout)
 	if err != nil {
 		return err
 	}
@@ -1107,8 +1704,8 @@ func enableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.
 		WhitelistHOS: fw.WhitelistHOS,
 		FilterIPv6:   ipv6Filter,
 		Rules: hrobot.FirewallRules{
-			Input:  filterAutoAddedRules(fw.Rules.Input),
-			Output: filterAutoAddedRules(fw.Rules.Output),
+			Input:  filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock),
+			Output: filterAutoAddedRules(fw.Rules.Output, opts.keepMailBlock),
 		},
 	}
 
@@ -1130,7 +1727,7 @@ func enableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.
 	return nil
 }
 
-func disableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
+func disableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, opts firewallOptions) error {
 	fmt.Printf("disabling firewall for server #%d...\n", serverID)
 
 	// Get current firewall config
@@ -1140,7 +1737,7 @@ func disableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot
 	}
 
 	// Ensure firewall is ready before making changes
-	fw, err = ensureFirewallReady(ctx, client, serverID, fw)
+	fw, err = ensureFirewallReady(ctx, client, os.Stdout, serverID, fw, opts.waitTimeout)
 	if err != nil {
 		return err
 	}
@@ -1151,8 +1748,8 @@ func disableFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot
 		WhitelistHOS: fw.WhitelistHOS,
 		FilterIPv6:   fw.FilterIPv6,
 		Rules: hrobot.FirewallRules{
-			Input:  filterAutoAddedRules(fw.Rules.Input),
-			Output: filterAutoAddedRules(fw.Rules.Output),
+			Input:  filterAutoAddedRules(fw.Rules.Input, opts.keepMailBlock),
+			Output: filterAutoAddedRules(fw.Rules.Output, opts.keepMailBlock),
 		},
 	}
 
@@ -1183,10 +1780,10 @@ func getFirewallStatus(ctx context.Context, client *hrobot.Client, serverID hrob
 	return nil
 }
 
-func waitForFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
+func waitForFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, timeout time.Duration) error {
 	fmt.Printf("waiting for firewall to be ready for server #%d...\n", serverID)
 
-	err := client.Firewall.WaitForFirewallReady(ctx, serverID)
+	err := waitForFirewallReady(ctx, client, serverID, timeout)
 	if err != nil {
 		return fmt.Errorf("failed while waiting for firewall: %w", err)
 	}
@@ -1196,9 +1793,99 @@ func waitForFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot
 	return nil
 }
 
-func resetFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, confirm bool) error {
-	if !confirm {
-		return fmt.Errorf("firewall reset requires --confirm flag (this will delete all rules)")
+// defaultWaitAllConcurrency is how many firewalls wait --all polls at once.
+const defaultWaitAllConcurrency = 4
+
+// firewallWaitResult is the outcome of waiting for the firewall of one server.
+type firewallWaitResult struct {
+	Server hrobot.Server
+	Err    error
+}
+
+// waitForAllFirewalls waits until the firewalls of all servers in the account
+// have left the "in process" state. A timeout of zero waits without limit. On
+// timeout it reports the servers whose firewall is still in process.
+func waitForAllFirewalls(ctx context.Context, client *hrobot.Client, timeout time.Duration, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = defaultWaitAllConcurrency
+	}
+
+	servers, err := listServersCached(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to list servers: %w", err)
+	}
+	if len(servers) == 0 {
+		fmt.Println("no servers found")
+		return nil
+	}
+
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	fmt.Printf("waiting for the firewalls of %d server(s) to be ready...\n", len(servers))
+
+	results := make([]firewallWaitResult, len(servers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				serverID := hrobot.ServerID(servers[idx].ServerNumber)
+				results[idx] = firewallWaitResult{
+					Server: servers[idx],
+					Err:    client.Firewall.WaitForFirewallReady(waitCtx, serverID),
+				}
+			}
+		}()
+	}
+	for idx := range servers {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Server.ServerNumber < results[j].Server.ServerNumber
+	})
+
+	var inProcess []string
+	failed := 0
+	t := table.New(os.Stdout)
+	t.SetHeaders("Server", "Name", "Firewall")
+	for _, result := range results {
+		status := "ready"
+		switch {
+		case result.Err == nil:
+		case errors.Is(result.Err, context.DeadlineExceeded):
+			status = "still in process"
+			inProcess = append(inProcess, strconv.Itoa(result.Server.ServerNumber))
+		default:
+			failed++
+			status = fmt.Sprintf("failed: %v", firstLine(result.Err.Error()))
+		}
+		t.AddRow(strconv.Itoa(result.Server.ServerNumber), result.Server.ServerName, status)
+	}
+	t.Render()
+
+	if len(inProcess) > 0 {
+		return fmt.Errorf("timed out after %s: %d of %d firewall(s) still in process (server %s)",
+			timeout, len(inProcess), len(results), strings.Join(inProcess, ", "))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+func resetFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, skipConfirmation bool) error {
+	if !confirm(fmt.Sprintf("Delete all firewall rules of server #%d?", serverID), skipConfirmation) {
+		return fmt.Errorf("firewall reset cancelled")
 	}
 
 	fmt.Printf("resetting firewall for server #%d...\n", serverID)
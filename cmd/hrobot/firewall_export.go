@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// hclAttribute is a single "key = value" line of an HCL block. The value is
+// already rendered as HCL.
+type hclAttribute struct {
+	key   string
+	value string
+}
+
+// hclString renders s as a quoted HCL string. Besides the usual escapes,
+// template sequences are escaped so that they are not interpolated.
+func hclString(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
+
+// writeHCLAttributes writes attributes with their "=" aligned the way
+// terraform fmt does.
+func writeHCLAttributes(b *strings.Builder, indent string, attrs []hclAttribute) {
+	width := 0
+	for _, attr := range attrs {
+		if len(attr.key) > width {
+			width = len(attr.key)
+		}
+	}
+	for _, attr := range attrs {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, attr.key, attr.value)
+	}
+}
+
+// firewallRuleHCLAttributes returns the hrobot_firewall rule attributes of
+// a rule. Empty fields are left out.
+func firewallRuleHCLAttributes(rule hrobot.FirewallRule) []hclAttribute {
+	var attrs []hclAttribute
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, hclAttribute{key: key, value: hclString(value)})
+		}
+	}
+
+	add("name", rule.Name)
+	add("ip_version", string(rule.IPVersion))
+	add("action", string(rule.Action))
+	add("protocol", string(rule.Protocol))
+	if rule.SourceIP != "" {
+		attrs = append(attrs, hclAttribute{key: "source_ips", value: "[" + hclString(rule.SourceIP) + "]"})
+	}
+	if rule.DestIP != "" {
+		attrs = append(attrs, hclAttribute{key: "destination_ips", value: "[" + hclString(rule.DestIP) + "]"})
+	}
+	add("source_port", rule.SourcePort)
+	add("destination_port", rule.DestPort)
+	add("tcp_flags", rule.TCPFlags)
+
+	return attrs
+}
+
+// writeFirewallRulesHCL writes a list of rules as an HCL list attribute.
+func writeFirewallRulesHCL(b *strings.Builder, key string, rules []hrobot.FirewallRule) {
+	if len(rules) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n  %s = [\n", key)
+	for _, rule := range rules {
+		b.WriteString("    {\n")
+		writeHCLAttributes(b, "      ", firewallRuleHCLAttributes(rule))
+		b.WriteString("    },\n")
+	}
+	b.WriteString("  ]\n")
+}
+
+// firewallHCL renders a live firewall configuration as an hrobot_firewall
+// resource block. Hetzner's auto-added mail rule is not exported as a rule;
+// keep_mail_block is set instead so that the provider keeps it.
+func firewallHCL(fw *hrobot.FirewallConfig) string {
+	var outputRules []hrobot.FirewallRule
+	mailBlock := false
+	for _, rule := range fw.Rules.Output {
+		if rule.IsMailBlock() {
+			mailBlock = true
+			continue
+		}
+		outputRules = append(outputRules, rule)
+	}
+
+	attrs := []hclAttribute{
+		{key: "server_id", value: strconv.Itoa(fw.ServerNumber)},
+		{key: "whitelist_hetzner_services", value: strconv.FormatBool(fw.WhitelistHOS)},
+		{key: "filter_ipv6", value: strconv.FormatBool(fw.FilterIPv6)},
+	}
+	if mailBlock {
+		attrs = append(attrs, hclAttribute{key: "keep_mail_block", value: "true"})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "resource \"hrobot_firewall\" \"server_%d\" {\n", fw.ServerNumber)
+	writeHCLAttributes(&b, "  ", attrs)
+	writeFirewallRulesHCL(&b, "input_rules", fw.Rules.Input)
+	writeFirewallRulesHCL(&b, "output_rules", outputRules)
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// exportFirewall prints the live firewall of a server in the given format.
+func exportFirewall(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, format string) error {
+	if format == "" {
+		format = "hcl"
+	}
+	if format != "hcl" {
+		return fmt.Errorf("unsupported export format: %s (supported: hcl)", format)
+	}
+
+	fw, err := client.Firewall.Get(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get firewall: %w", err)
+	}
+
+	fmt.Print(firewallHCL(fw))
+	return nil
+}
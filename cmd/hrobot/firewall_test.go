@@ -6,10 +6,19 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
@@ -52,54 +61,6 @@ func TestDetectIPVersion(t *testing.T) {
 	}
 }
 
-func TestIsAutoAddedMailRule(t *testing.T) {
-	tests := []struct {
-		name     string
-		rule     hrobot.FirewallRule
-		expected bool
-	}{
-		{
-			name: "auto-added mail rule",
-			rule: hrobot.FirewallRule{
-				Name:     "Block mail ports",
-				Action:   hrobot.ActionDiscard,
-				Protocol: hrobot.ProtocolTCP,
-				DestPort: "25,465",
-			},
-			expected: true,
-		},
-		{
-			name: "regular rule",
-			rule: hrobot.FirewallRule{
-				Name:     "Allow SSH",
-				Action:   hrobot.ActionAccept,
-				Protocol: hrobot.ProtocolTCP,
-				DestPort: "22",
-			},
-			expected: false,
-		},
-		{
-			name: "different mail port",
-			rule: hrobot.FirewallRule{
-				Name:     "Block mail ports",
-				Action:   hrobot.ActionDiscard,
-				Protocol: hrobot.ProtocolTCP,
-				DestPort: "25",
-			},
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isAutoAddedMailRule(tt.rule)
-			if result != tt.expected {
-				t.Errorf("isAutoAddedMailRule() = %v, expected %v", result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestFilterAutoAddedRules(t *testing.T) {
 	rules := []hrobot.FirewallRule{
 		{
@@ -122,7 +83,7 @@ func TestFilterAutoAddedRules(t *testing.T) {
 		},
 	}
 
-	filtered := filterAutoAddedRules(rules)
+	filtered := filterAutoAddedRules(rules, false)
 
 	if len(filtered) != 2 {
 		t.Errorf("expected 2 rules after filtering, got %d", len(filtered))
@@ -145,6 +106,14 @@ func TestRuleExists(t *testing.T) {
 			SourceIP:  "1.2.3.4/32",
 			DestPort:  "22",
 		},
+		{
+			Name:      "Allow SSH v6",
+			IPVersion: hrobot.IPv6,
+			Action:    hrobot.ActionAccept,
+			Protocol:  hrobot.ProtocolTCP,
+			SourceIP:  "2001:db8::1",
+			DestPort:  "22",
+		},
 	}
 
 	tests := []struct {
@@ -176,6 +145,42 @@ func TestRuleExists(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "duplicate without CIDR suffix",
+			newRule: hrobot.FirewallRule{
+				Name:      "SSH rule",
+				IPVersion: hrobot.IPv4,
+				Action:    hrobot.ActionAccept,
+				Protocol:  hrobot.ProtocolTCP,
+				SourceIP:  "1.2.3.4",
+				DestPort:  "22",
+			},
+			expected: true,
+		},
+		{
+			name: "duplicate of IPv6 rule stored without CIDR suffix",
+			newRule: hrobot.FirewallRule{
+				Name:      "SSH rule v6",
+				IPVersion: hrobot.IPv6,
+				Action:    hrobot.ActionAccept,
+				Protocol:  hrobot.ProtocolTCP,
+				SourceIP:  "2001:db8::1/128",
+				DestPort:  "22",
+			},
+			expected: true,
+		},
+		{
+			name: "different prefix length",
+			newRule: hrobot.FirewallRule{
+				Name:      "SSH rule",
+				IPVersion: hrobot.IPv4,
+				Action:    hrobot.ActionAccept,
+				Protocol:  hrobot.ProtocolTCP,
+				SourceIP:  "1.2.3.4/24",
+				DestPort:  "22",
+			},
+			expected: false,
+		},
 		{
 			name: "unique rule",
 			newRule: hrobot.FirewallRule{
@@ -275,9 +280,9 @@ func TestAddFirewallRules_WaitsForPendingChanges(t *testing.T) {
 		},
 	}
 
-	info, err := addFirewallRules(ctx, client, hrobot.ServerID(321), newRules)
+	info, err := addFirewallRulesTo(ctx, client, os.Stdout, hrobot.ServerID(321), newRules, false, firewallOptions{})
 	if err != nil {
-		t.Fatalf("addFirewallRules returned error: %v", err)
+		t.Fatalf("addFirewallRulesTo returned error: %v", err)
 	}
 
 	if info.Added != 1 {
@@ -285,9 +290,9 @@ func TestAddFirewallRules_WaitsForPendingChanges(t *testing.T) {
 	}
 
 	// Should have made 3 GET requests:
-	// 1. Initial GET in addFirewallRules (returns "in process")
+	// 1. Initial GET in addFirewallRulesTo (returns "in process")
 	// 2. GET in WaitForFirewallReady (returns "active")
-	// 3. Re-fetch GET in addFirewallRules after waiting
+	// 3. Re-fetch GET in addFirewallRulesTo after waiting
 	// Plus 1 POST to update
 	if callCount != 3 {
 		t.Errorf("expected 3 GET calls (initial + wait + re-fetch), got %d", callCount)
@@ -347,7 +352,7 @@ func TestAddFirewallRules_InvalidInputError(t *testing.T) {
 		},
 	}
 
-	_, err := addFirewallRules(ctx, client, hrobot.ServerID(321), newRules)
+	_, err := addFirewallRulesTo(ctx, client, os.Stdout, hrobot.ServerID(321), newRules, false, firewallOptions{})
 	if err == nil {
 		t.Fatal("expected error for INVALID_INPUT, got nil")
 	}
@@ -364,3 +369,1592 @@ func TestAddFirewallRules_InvalidInputError(t *testing.T) {
 		t.Errorf("error message should suggest listing rules, got: %s", errMsg)
 	}
 }
+
+func TestAddFirewallRules_ConcurrentModification(t *testing.T) {
+	getCount := 0
+	postCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			getCount++
+			// Simulate another hrobot invocation adding a rule between our
+			// read and our write.
+			input := []map[string]interface{}{}
+			if getCount > 1 {
+				input = append(input, map[string]interface{}{
+					"name":       "Allow HTTPS",
+					"ip_version": "ipv4",
+					"action":     "accept",
+					"protocol":   "tcp",
+					"dst_port":   "443",
+				})
+			}
+			response := map[string]interface{}{
+				"firewall": map[string]interface{}{
+					"server_ip":     "123.123.123.123",
+					"server_number": 321,
+					"status":        "active",
+					"whitelist_hos": true,
+					"filter_ipv6":   false,
+					"port":          "main",
+					"rules": map[string]interface{}{
+						"input":  input,
+						"output": []map[string]interface{}{},
+					},
+				},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+		case "POST":
+			postCount++
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	newRules := []hrobot.FirewallRule{
+		{
+			Name:      "Allow SSH 1.2.3.4",
+			IPVersion: hrobot.IPv4,
+			Action:    hrobot.ActionAccept,
+			Protocol:  hrobot.ProtocolTCP,
+			SourceIP:  "1.2.3.4/32",
+			DestPort:  "22",
+		},
+	}
+
+	_, err := addFirewallRulesTo(ctx, client, os.Stdout, hrobot.ServerID(321), newRules, false, firewallOptions{checkUnchanged: true})
+	if err == nil {
+		t.Fatal("expected stale write to be rejected, got nil")
+	}
+	if !strings.Contains(err.Error(), "modified") {
+		t.Errorf("error message should mention the concurrent modification, got: %s", err.Error())
+	}
+	if postCount != 0 {
+		t.Errorf("expected no update to be sent, got %d POST request(s)", postCount)
+	}
+
+	// Without --check-unchanged the update is sent without re-reading.
+	getCount = 0
+	_, _ = addFirewallRulesTo(ctx, client, os.Stdout, hrobot.ServerID(321), newRules, false, firewallOptions{})
+	if getCount != 1 || postCount != 1 {
+		t.Errorf("expected 1 GET and 1 POST without --check-unchanged, got %d and %d", getCount, postCount)
+	}
+}
+
+func TestAddFirewallRules_DryRun(t *testing.T) {
+	postCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			// "in process" must not make a dry run wait for the firewall
+			response := map[string]interface{}{
+				"firewall": map[string]interface{}{
+					"server_ip":     "123.123.123.123",
+					"server_number": 321,
+					"status":        "in process",
+					"whitelist_hos": true,
+					"filter_ipv6":   false,
+					"port":          "main",
+					"rules": map[string]interface{}{
+						"input": []map[string]interface{}{
+							{
+								"name":       "Allow SSH 1.2.3.4",
+								"ip_version": "ipv4",
+								"action":     "accept",
+								"protocol":   "tcp",
+								"src_ip":     "1.2.3.4/32",
+								"dst_port":   "22",
+							},
+						},
+						"output": []map[string]interface{}{},
+					},
+				},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+		default:
+			postCount++
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	newRules := []hrobot.FirewallRule{
+		{
+			Name:      "Allow SSH 1.2.3.4",
+			IPVersion: hrobot.IPv4,
+			Action:    hrobot.ActionAccept,
+			Protocol:  hrobot.ProtocolTCP,
+			SourceIP:  "1.2.3.4/32",
+			DestPort:  "22",
+		},
+		{
+			Name:      "Allow SSH 5.6.7.8",
+			IPVersion: hrobot.IPv4,
+			Action:    hrobot.ActionAccept,
+			Protocol:  hrobot.ProtocolTCP,
+			SourceIP:  "5.6.7.8/32",
+			DestPort:  "22",
+		},
+	}
+
+	info, err := addFirewallRulesTo(ctx, client, os.Stdout, hrobot.ServerID(321), newRules, true, firewallOptions{})
+	if err != nil {
+		t.Fatalf("addFirewallRulesTo returned error: %v", err)
+	}
+
+	if !info.DryRun {
+		t.Error("expected result to be marked as dry run")
+	}
+	if info.Added != 1 || info.Skipped != 1 {
+		t.Errorf("expected 1 added and 1 skipped, got %d added and %d skipped", info.Added, info.Skipped)
+	}
+	if postCount != 0 {
+		t.Errorf("expected no write under dry run, got %d write request(s)", postCount)
+	}
+}
+
+func TestFormatFirewallDiff(t *testing.T) {
+	existing := hrobot.FirewallRule{
+		Name:      "Allow HTTPS",
+		IPVersion: hrobot.IPv4,
+		Action:    hrobot.ActionAccept,
+		Protocol:  hrobot.ProtocolTCP,
+		DestPort:  "443",
+	}
+	added := hrobot.FirewallRule{
+		Name:      "Allow SSH 5.6.7.8",
+		IPVersion: hrobot.IPv4,
+		Action:    hrobot.ActionAccept,
+		Protocol:  hrobot.ProtocolTCP,
+		SourceIP:  "5.6.7.8/32",
+		DestPort:  "22",
+	}
+	skipped := hrobot.FirewallRule{
+		Name:      "Allow SSH 1.2.3.4",
+		IPVersion: hrobot.IPv4,
+		Action:    hrobot.ActionAccept,
+		Protocol:  hrobot.ProtocolTCP,
+		SourceIP:  "1.2.3.4/32",
+		DestPort:  "22",
+	}
+
+	diff := formatFirewallDiff("input",
+		[]hrobot.FirewallRule{existing},
+		[]hrobot.FirewallRule{added, existing},
+		[]hrobot.FirewallRule{skipped})
+
+	lines := strings.Split(diff, "\n")
+	findLine := func(substr string) string {
+		for _, line := range lines {
+			if strings.Contains(line, substr) {
+				return line
+			}
+		}
+		t.Fatalf("diff does not mention %q:\n%s", substr, diff)
+		return ""
+	}
+
+	if line := findLine("Allow SSH 5.6.7.8"); !strings.HasPrefix(strings.TrimSpace(line), "+") {
+		t.Errorf("expected added rule to be marked with '+', got: %s", line)
+	}
+	if line := findLine("Allow SSH 1.2.3.4"); !strings.HasPrefix(strings.TrimSpace(line), "⊘") {
+		t.Errorf("expected skipped rule to be marked with '⊘', got: %s", line)
+	}
+	if line := findLine("Allow HTTPS"); strings.HasPrefix(strings.TrimSpace(line), "+") {
+		t.Errorf("expected existing rule to be unmarked, got: %s", line)
+	}
+	if !strings.Contains(diff, "summary: 1 to add, 0 to remove, 1 skipped") {
+		t.Errorf("expected summary line, got:\n%s", diff)
+	}
+}
+
+// captureStdout runs fn and returns everything it printed to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	_ = w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read captured output: %v", err)
+	}
+	return string(out)
+}
+
+func TestReplaceFirewall_RoundTrip(t *testing.T) {
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"whitelist_hos": true,
+				"filter_ipv6":   false,
+				"port":          "main",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{
+							"name":       "Allow SSH 1.2.3.4",
+							"ip_version": "ipv4",
+							"action":     "accept",
+							"protocol":   "tcp",
+							"src_ip":     "1.2.3.4/32",
+							"dst_port":   "22",
+						},
+						{
+							"name":       "Allow HTTPS",
+							"ip_version": "ipv6",
+							"action":     "accept",
+							"protocol":   "tcp",
+							"src_port":   "1024-65535",
+							"dst_port":   "443",
+						},
+					},
+					"output": []map[string]interface{}{
+						{
+							"name":       "Block mail ports",
+							"ip_version": "ipv4",
+							"action":     "discard",
+							"protocol":   "tcp",
+							"dst_port":   "25,465",
+						},
+						{
+							"name":   "Allow all",
+							"action": "accept",
+						},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	exported := captureStdout(t, func() {
+		if err := listRules(ctx, client, hrobot.ServerID(321), "", "json"); err != nil {
+			t.Fatalf("listRules returned error: %v", err)
+		}
+	})
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(rulesFile, []byte(exported), 0o600); err != nil {
+		t.Fatalf("failed to write rules file: %v", err)
+	}
+
+	if err := replaceFirewall(ctx, client, hrobot.ServerID(321), rulesFile, firewallOptions{}); err != nil {
+		t.Fatalf("replaceFirewall returned error: %v", err)
+	}
+
+	if posted == nil {
+		t.Fatal("expected a single update to be sent")
+	}
+
+	expected := map[string]string{
+		"rules[input][0][name]":       "Allow SSH 1.2.3.4",
+		"rules[input][0][src_ip]":     "1.2.3.4/32",
+		"rules[input][0][dst_port]":   "22",
+		"rules[input][1][name]":       "Allow HTTPS",
+		"rules[input][1][ip_version]": "ipv6",
+		"rules[input][1][src_port]":   "1024-65535",
+		"rules[output][0][name]":      "Allow all",
+		"status":                      "active",
+		"whitelist_hos":               "true",
+	}
+	for key, want := range expected {
+		if got := posted.Get(key); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+
+	// The auto-added mail rule must not be sent back
+	if posted.Get("rules[output][1][name]") != "" {
+		t.Errorf("expected auto-added mail rule to be filtered, got output rule %q", posted.Get("rules[output][1][name]"))
+	}
+}
+
+func TestReplaceFirewall_InvalidRules(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	content := `{"rules": {"input": [{"name": "bad", "action": "allow", "protocol": "tcp", "dst_port": "22"}]}}`
+	if err := os.WriteFile(rulesFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rules file: %v", err)
+	}
+
+	err := replaceFirewall(context.Background(), client, hrobot.ServerID(321), rulesFile, firewallOptions{})
+	if err == nil {
+		t.Fatal("expected error for invalid action, got nil")
+	}
+	if !strings.Contains(err.Error(), "action must be") {
+		t.Errorf("expected action validation error, got: %s", err.Error())
+	}
+	if requests != 0 {
+		t.Errorf("expected no API requests for an invalid rules file, got %d", requests)
+	}
+}
+
+// newDeleteRuleTestServer serves a firewall with three input rules and records
+// the form of the update request.
+func newDeleteRuleTestServer(t *testing.T, posted *url.Values) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			*posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"whitelist_hos": true,
+				"filter_ipv6":   false,
+				"port":          "main",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{
+							"name":       "rule-a",
+							"ip_version": "ipv4",
+							"action":     "accept",
+							"protocol":   "tcp",
+							"src_ip":     "1.2.3.4/32",
+							"dst_port":   "22",
+						},
+						{
+							"name":       "rule-b",
+							"ip_version": "ipv4",
+							"action":     "accept",
+							"protocol":   "tcp",
+							"src_ip":     "5.6.7.8/32",
+							"dst_port":   "22",
+						},
+						{
+							"name":       "rule-c",
+							"ip_version": "ipv4",
+							"action":     "accept",
+							"protocol":   "tcp",
+							"dst_port":   "443",
+						},
+					},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+}
+
+func TestDeleteRule_MatcherSingleMatch(t *testing.T) {
+	var posted url.Values
+	server := newDeleteRuleTestServer(t, &posted)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	matcher := ruleMatcher{Port: "22", SourceIP: "5.6.7.8"}
+	info, err := deleteRule(context.Background(), client, io.Discard, hrobot.ServerID(321), "", -1, matcher, "in", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("deleteRule returned error: %v", err)
+	}
+	var buf strings.Builder
+	printDeleteRuleResult(&buf, info, "in")
+	out := buf.String()
+
+	if info.Deleted != 1 {
+		t.Errorf("expected 1 deleted rule, got %d", info.Deleted)
+	}
+	if got := posted.Get("rules[input][0][name]"); got != "rule-a" {
+		t.Errorf("expected first remaining rule 'rule-a', got %q", got)
+	}
+	if got := posted.Get("rules[input][1][name]"); got != "rule-c" {
+		t.Errorf("expected second remaining rule 'rule-c', got %q", got)
+	}
+	if got := posted.Get("rules[input][2][name]"); got != "" {
+		t.Errorf("expected two remaining rules, got third rule %q", got)
+	}
+	if !strings.Contains(out, "rule-b") || !strings.Contains(out, "deleted 1 rule(s)") {
+		t.Errorf("expected output to list the removed rule, got:\n%s", out)
+	}
+}
+
+func TestDeleteRule_MatcherMultiMatch(t *testing.T) {
+	var posted url.Values
+	server := newDeleteRuleTestServer(t, &posted)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	matcher := ruleMatcher{Protocol: "tcp", Port: "22"}
+	info, err := deleteRule(context.Background(), client, io.Discard, hrobot.ServerID(321), "", -1, matcher, "in", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("deleteRule returned error: %v", err)
+	}
+	var buf strings.Builder
+	printDeleteRuleResult(&buf, info, "in")
+	out := buf.String()
+
+	if got := posted.Get("rules[input][0][name]"); got != "rule-c" {
+		t.Errorf("expected only 'rule-c' to remain, got %q", got)
+	}
+	if got := posted.Get("rules[input][1][name]"); got != "" {
+		t.Errorf("expected one remaining rule, got second rule %q", got)
+	}
+	for _, name := range []string{"rule-a", "rule-b"} {
+		if !strings.Contains(out, name) {
+			t.Errorf("expected output to list removed rule %q, got:\n%s", name, out)
+		}
+	}
+}
+
+func TestDeleteRule_RequiresSelector(t *testing.T) {
+	var posted url.Values
+	server := newDeleteRuleTestServer(t, &posted)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	_, err := deleteRule(context.Background(), client, io.Discard, hrobot.ServerID(321), "", -1, ruleMatcher{}, "in", false, firewallOptions{})
+	if err == nil {
+		t.Fatal("expected error without name, index or matcher, got nil")
+	}
+	if posted != nil {
+		t.Error("expected no update to be sent")
+	}
+}
+
+func TestAddRule_SourcePort(t *testing.T) {
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"whitelist_hos": true,
+				"filter_ipv6":   false,
+				"port":          "main",
+				"rules": map[string]interface{}{
+					"input":  []map[string]interface{}{},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	_, err := addRule(context.Background(), client, io.Discard, hrobot.ServerID(321), "in", "udp", "accept", "dns replies",
+		[]string{"9.9.9.9/32"}, nil, "53", "32768-65535", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("addRule returned error: %v", err)
+	}
+
+	if got := posted.Get("rules[input][0][src_port]"); got != "53" {
+		t.Errorf("expected src_port '53', got %q", got)
+	}
+	if got := posted.Get("rules[input][0][dst_port]"); got != "32768-65535" {
+		t.Errorf("expected dst_port '32768-65535', got %q", got)
+	}
+}
+
+func TestRenderRulesTable_SourcePort(t *testing.T) {
+	var buf strings.Builder
+	renderRulesTable(&buf, []hrobot.FirewallRule{
+		{
+			Name:       "dns replies",
+			IPVersion:  hrobot.IPv4,
+			Action:     hrobot.ActionAccept,
+			Protocol:   hrobot.ProtocolUDP,
+			SourceIP:   "9.9.9.9/32",
+			SourcePort: "53",
+			DestPort:   "32768-65535",
+		},
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, "Source Port") {
+		t.Errorf("expected 'Source Port' column, got:\n%s", out)
+	}
+	if !strings.Contains(out, " 53 ") {
+		t.Errorf("expected source port '53' in table, got:\n%s", out)
+	}
+}
+
+func TestValidateICMPType(t *testing.T) {
+	tests := []struct {
+		name      string
+		protocol  string
+		icmpType  string
+		wantError string
+	}{
+		{name: "no icmp type", protocol: "icmp", icmpType: ""},
+		{name: "no icmp type with tcp", protocol: "tcp", icmpType: ""},
+		{name: "icmp type with tcp", protocol: "tcp", icmpType: "echo-request", wantError: "only valid with --protocol icmp"},
+		{name: "icmp type with icmp", protocol: "icmp", icmpType: "echo-request", wantError: "cannot filter by ICMP type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateICMPType(tt.protocol, tt.icmpType)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("expected error containing %q, got: %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestHardenAllServers_ContinuesPastFailures(t *testing.T) {
+	var mu sync.Mutex
+	updated := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/server" {
+			response := []map[string]interface{}{
+				{"server": map[string]interface{}{"server_number": 3, "server_name": "db", "server_ip": "10.0.0.3"}},
+				{"server": map[string]interface{}{"server_number": 1, "server_name": "web", "server_ip": "10.0.0.1"}},
+				{"server": map[string]interface{}{"server_number": 2, "server_name": "app", "server_ip": "10.0.0.2"}},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/firewall/")
+		if id == "3" {
+			w.WriteHeader(http.StatusNotFound)
+			response := map[string]interface{}{
+				"error": map[string]interface{}{
+					"status":  404,
+					"code":    "FIREWALL_NOT_FOUND",
+					"message": "firewall not found",
+				},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+			return
+		}
+
+		if r.Method == "POST" {
+			mu.Lock()
+			updated[id] = true
+			mu.Unlock()
+		}
+
+		input := []map[string]interface{}{}
+		if id == "1" {
+			// Server 1 is already hardened.
+			for _, rule := range blockHTTPRules() {
+				input = append(input, map[string]interface{}{
+					"name":       rule.Name,
+					"ip_version": string(rule.IPVersion),
+					"action":     string(rule.Action),
+					"protocol":   string(rule.Protocol),
+					"dst_port":   rule.DestPort,
+				})
+			}
+		}
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip": "10.0.0." + id,
+				"status":    "active",
+				"rules": map[string]interface{}{
+					"input":  input,
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	var err error
+	output := captureStdout(t, func() {
+		err = hardenAllServers(ctx, client, true, 2, false, firewallOptions{})
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 server(s) failed") {
+		t.Errorf("expected '1 of 3 server(s) failed' error, got %v", err)
+	}
+	if updated["1"] {
+		t.Error("expected already hardened server 1 not to be updated")
+	}
+	if !updated["2"] {
+		t.Error("expected server 2 to be updated")
+	}
+
+	lines := strings.Split(output, "\n")
+	expected := map[string]string{
+		"web": "already hardened",
+		"app": "hardened",
+		"db":  "failed: failed to get firewall",
+	}
+	for name, result := range expected {
+		found := false
+		for _, line := range lines {
+			if strings.Contains(line, name) && strings.Contains(line, result) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected row for %s with result '%s', got:\n%s", name, result, output)
+		}
+	}
+	if strings.Index(output, "web") > strings.Index(output, "db") {
+		t.Errorf("expected rows sorted by server number, got:\n%s", output)
+	}
+}
+
+func TestAllowSSH_Group(t *testing.T) {
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"rules": map[string]interface{}{
+					"input":  []map[string]interface{}{},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	_, err := allowSSH(context.Background(), client, io.Discard, hrobot.ServerID(321), []string{"1.2.3.4/32", "2001:db8::/64"}, "office VPN", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("allowSSH returned error: %v", err)
+	}
+
+	expected := map[string]string{
+		"rules[input][0][name]": "[office VPN] Allow SSH 1.2.3.4",
+		"rules[input][1][name]": "[office VPN] Allow SSH 2001:db8::",
+	}
+	for key, value := range expected {
+		if got := posted.Get(key); got != value {
+			t.Errorf("expected %s = %q, got %q", key, value, got)
+		}
+	}
+}
+
+// newAddResultTestServer returns a firewall with a single SSH rule for
+// 1.2.3.4/32 and records whether an update was posted.
+func newAddResultTestServer(t *testing.T, posted *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			*posted = true
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{
+							"name":       "Allow SSH 1.2.3.4",
+							"ip_version": "ipv4",
+							"action":     "accept",
+							"protocol":   "tcp",
+							"src_ip":     "1.2.3.4/32",
+							"dst_port":   "22",
+						},
+					},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+}
+
+func TestAllowSSH_Result(t *testing.T) {
+	tests := []struct {
+		name        string
+		ips         []string
+		dryRun      bool
+		wantAdded   int
+		wantSkipped int
+		wantRules   []string
+		wantPost    bool
+	}{
+		{
+			name:        "add one, skip one",
+			ips:         []string{"1.2.3.4/32", "5.6.7.8/32"},
+			wantAdded:   1,
+			wantSkipped: 1,
+			wantRules:   []string{"Allow SSH 5.6.7.8", "Allow SSH 1.2.3.4"},
+			wantPost:    true,
+		},
+		{
+			name:        "all skipped",
+			ips:         []string{"1.2.3.4/32"},
+			wantSkipped: 1,
+			wantRules:   []string{"Allow SSH 1.2.3.4"},
+		},
+		{
+			name:      "dry run",
+			ips:       []string{"5.6.7.8/32"},
+			dryRun:    true,
+			wantAdded: 1,
+			wantRules: []string{"Allow SSH 5.6.7.8", "Allow SSH 1.2.3.4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted := false
+			server := newAddResultTestServer(t, &posted)
+			defer server.Close()
+
+			client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+			info, err := allowSSH(context.Background(), client, io.Discard, hrobot.ServerID(321), tt.ips, "", tt.dryRun, firewallOptions{})
+			if err != nil {
+				t.Fatalf("allowSSH returned error: %v", err)
+			}
+
+			if info.Added != tt.wantAdded || info.Skipped != tt.wantSkipped {
+				t.Errorf("added/skipped = %d/%d, want %d/%d", info.Added, info.Skipped, tt.wantAdded, tt.wantSkipped)
+			}
+			if info.DryRun != tt.dryRun {
+				t.Errorf("DryRun = %v, want %v", info.DryRun, tt.dryRun)
+			}
+			var names []string
+			for _, rule := range info.Rules {
+				names = append(names, rule.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantRules) {
+				t.Errorf("resulting rules = %v, want %v", names, tt.wantRules)
+			}
+			if posted != tt.wantPost {
+				t.Errorf("posted update = %v, want %v", posted, tt.wantPost)
+			}
+		})
+	}
+}
+
+func TestAddRule_Result(t *testing.T) {
+	tests := []struct {
+		name        string
+		direction   string
+		sourceIPs   []string
+		wantAdded   int
+		wantSkipped int
+		wantRules   int
+	}{
+		{
+			name:      "output rule added",
+			direction: "out",
+			wantAdded: 1,
+			wantRules: 1,
+		},
+		{
+			name:        "duplicate input rule skipped",
+			direction:   "in",
+			sourceIPs:   []string{"1.2.3.4/32"},
+			wantSkipped: 1,
+			wantRules:   1,
+		},
+		{
+			name:        "input rule added next to duplicate",
+			direction:   "in",
+			sourceIPs:   []string{"1.2.3.4/32", "5.6.7.8/32"},
+			wantAdded:   1,
+			wantSkipped: 1,
+			wantRules:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted := false
+			server := newAddResultTestServer(t, &posted)
+			defer server.Close()
+
+			client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+			info, err := addRule(context.Background(), client, io.Discard, hrobot.ServerID(321), tt.direction, "tcp", "accept", "",
+				tt.sourceIPs, nil, "", "22", false, firewallOptions{})
+			if err != nil {
+				t.Fatalf("addRule returned error: %v", err)
+			}
+
+			if info.Added != tt.wantAdded || info.Skipped != tt.wantSkipped {
+				t.Errorf("added/skipped = %d/%d, want %d/%d", info.Added, info.Skipped, tt.wantAdded, tt.wantSkipped)
+			}
+			if len(info.Rules) != tt.wantRules {
+				t.Errorf("expected %d resulting rule(s), got %d: %+v", tt.wantRules, len(info.Rules), info.Rules)
+			}
+			if posted != (tt.wantAdded > 0) {
+				t.Errorf("posted update = %v, want %v", posted, tt.wantAdded > 0)
+			}
+		})
+	}
+}
+
+func TestHandleAllowSSH_JSONOutput(t *testing.T) {
+	posted := false
+	server := newAddResultTestServer(t, &posted)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+	os.Args = []string{"hrobot", "firewall", "allow-ssh", "321", "--source-ips", "1.2.3.4/32,5.6.7.8/32", "--output", "json"}
+
+	// Progress messages go to stderr and must not end up in the JSON.
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+	origStderr := os.Stderr
+	os.Stderr = devNull
+	defer func() { os.Stderr = origStderr }()
+
+	out := captureStdout(t, func() {
+		if err := handleAllowSSH(context.Background(), client, firewallOptions{}); err != nil {
+			t.Fatalf("handleAllowSSH returned error: %v", err)
+		}
+	})
+
+	var got struct {
+		Added   int                   `json:"added"`
+		Skipped int                   `json:"skipped"`
+		Rules   []hrobot.FirewallRule `json:"rules"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", out, err)
+	}
+	if got.Added != 1 || got.Skipped != 1 {
+		t.Errorf("added/skipped = %d/%d, want 1/1", got.Added, got.Skipped)
+	}
+	if len(got.Rules) != 2 || got.Rules[0].SourceIP != "5.6.7.8/32" || got.Rules[1].SourceIP != "1.2.3.4/32" {
+		t.Errorf("unexpected resulting rules: %+v", got.Rules)
+	}
+	if !posted {
+		t.Error("expected the new rule to be posted")
+	}
+}
+
+func TestFirewallCommands_NormalizeIPs(t *testing.T) {
+	posted := false
+	server := newAddResultTestServer(t, &posted)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	info, err := allowSSH(ctx, client, io.Discard, hrobot.ServerID(321), []string{"5.6.7.8"}, "", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("allowSSH returned error: %v", err)
+	}
+	if got := info.Rules[0].SourceIP; got != "5.6.7.8/32" {
+		t.Errorf("allowSSH source IP = %q, want 5.6.7.8/32", got)
+	}
+
+	info, err = addRule(ctx, client, io.Discard, hrobot.ServerID(321), "out", "tcp", "accept", "",
+		nil, []string{"2001:db8::1"}, "", "443", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("addRule returned error: %v", err)
+	}
+	if got := info.Rules[0].DestIP; got != "2001:db8::1/128" {
+		t.Errorf("addRule destination IP = %q, want 2001:db8::1/128", got)
+	}
+
+	info, err = addRule(ctx, client, io.Discard, hrobot.ServerID(321), "in", "tcp", "accept", "",
+		[]string{"10.0.0.0/8"}, nil, "", "443", false, firewallOptions{})
+	if err != nil {
+		t.Fatalf("addRule returned error: %v", err)
+	}
+	if got := info.Rules[0].SourceIP; got != "10.0.0.0/8" {
+		t.Errorf("addRule source IP = %q, want the CIDR unchanged", got)
+	}
+}
+
+func TestPrintAllowSSHResult(t *testing.T) {
+	var buf strings.Builder
+	printAllowSSHResult(&buf, &RuleChangeInfo{Added: 1, Skipped: 1}, []string{"5.6.7.8/32"})
+	if !strings.Contains(buf.String(), "successfully added 1 SSH rule(s)") {
+		t.Errorf("expected summary of added rules, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printAllowSSHResult(&buf, &RuleChangeInfo{Skipped: 1}, []string{"1.2.3.4/32"})
+	if buf.Len() != 0 {
+		t.Errorf("expected no summary when nothing was added, got:\n%s", buf.String())
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{"name": "[office VPN] Allow SSH 1.2.3.4", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "src_ip": "1.2.3.4/32", "dst_port": "22"},
+						{"name": "Allow HTTPS", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "dst_port": "443"},
+						{"name": "[office VPN] MOSH UDP 1.2.3.4", "ip_version": "ipv4", "action": "accept", "protocol": "udp", "src_ip": "1.2.3.4/32", "dst_port": "60000-61000"},
+						{"name": "[office VPN2] Allow SSH 5.6.7.8", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "src_ip": "5.6.7.8/32", "dst_port": "22"},
+					},
+					"output": []map[string]interface{}{
+						{"name": "[office VPN]", "action": "accept", "dst_ip": "1.2.3.4/32"},
+						{"name": "Allow all", "action": "accept"},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	output := captureStdout(t, func() {
+		if err := deleteGroup(ctx, client, hrobot.ServerID(321), "office VPN", false, firewallOptions{}); err != nil {
+			t.Fatalf("deleteGroup returned error: %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "deleted 3 rule(s)") {
+		t.Errorf("expected 3 deleted rules, got:\n%s", output)
+	}
+
+	expected := map[string]string{
+		"rules[input][0][name]":  "Allow HTTPS",
+		"rules[input][1][name]":  "[office VPN2] Allow SSH 5.6.7.8",
+		"rules[input][2][name]":  "",
+		"rules[output][0][name]": "Allow all",
+		"rules[output][1][name]": "",
+	}
+	for key, value := range expected {
+		if got := posted.Get(key); got != value {
+			t.Errorf("expected %s = %q, got %q", key, value, got)
+		}
+	}
+
+	if err := deleteGroup(ctx, client, hrobot.ServerID(321), "unknown", false, firewallOptions{}); err == nil {
+		t.Error("expected error for a group without rules")
+	}
+}
+
+func TestAddFirewallRules_KeepMailBlock(t *testing.T) {
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{},
+					"output": []map[string]interface{}{
+						{"name": "Block mail ports", "ip_version": "ipv4", "action": "discard", "protocol": "tcp", "dst_port": "25,465"},
+						{"name": "Allow all", "action": "accept"},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	for _, keep := range []bool{false, true} {
+		opts := firewallOptions{keepMailBlock: keep}
+		captureStdout(t, func() {
+			if _, err := addFirewallRulesTo(context.Background(), client, os.Stdout, hrobot.ServerID(321), blockHTTPRules(), false, opts); err != nil {
+				t.Fatalf("addFirewallRulesTo returned error: %v", err)
+			}
+		})
+
+		expected := "Allow all"
+		if keep {
+			expected = "Block mail ports"
+		}
+		if got := posted.Get("rules[output][0][name]"); got != expected {
+			t.Errorf("keep=%v: expected first output rule %q, got %q", keep, expected, got)
+		}
+	}
+}
+
+func TestWaitForFirewall_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The firewall never leaves the "in process" state
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "in process",
+				"rules": map[string]interface{}{
+					"input":  []map[string]interface{}{},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	start := time.Now()
+	var err error
+	captureStdout(t, func() {
+		err = waitForFirewall(context.Background(), client, hrobot.ServerID(321), 50*time.Millisecond)
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected wait to time out promptly, took %s", elapsed)
+	}
+}
+
+func TestWaitForFirewall_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The firewall never leaves the "in process" state
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "in process",
+				"rules": map[string]interface{}{
+					"input":  []map[string]interface{}{},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	// Cancelling the context is what the SIGINT handler in main does.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	var err error
+	captureStdout(t, func() {
+		err = waitForFirewall(ctx, client, hrobot.ServerID(321), 0)
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a context.Canceled error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected wait to stop promptly after cancelling, took %s", elapsed)
+	}
+}
+
+func TestWaitForAllFirewalls_ReportsInProcess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/server" {
+			response := []map[string]interface{}{
+				{"server": map[string]interface{}{"server_number": 2, "server_name": "busy", "server_ip": "10.0.0.2"}},
+				{"server": map[string]interface{}{"server_number": 1, "server_name": "idle", "server_ip": "10.0.0.1"}},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+			return
+		}
+
+		// The firewall of server 2 never leaves the "in process" state.
+		id := strings.TrimPrefix(r.URL.Path, "/firewall/")
+		status := "active"
+		if id == "2" {
+			status = "in process"
+		}
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip": "10.0.0." + id,
+				"status":    status,
+				"rules": map[string]interface{}{
+					"input":  []map[string]interface{}{},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	start := time.Now()
+	var err error
+	output := captureStdout(t, func() {
+		err = waitForAllFirewalls(context.Background(), client, 100*time.Millisecond, 2)
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 firewall(s) still in process (server 2)") {
+		t.Errorf("expected server 2 to be reported as still in process, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected wait to time out promptly, took %s", elapsed)
+	}
+
+	expected := map[string]string{
+		"idle": "ready",
+		"busy": "still in process",
+	}
+	for name, status := range expected {
+		found := false
+		for _, line := range strings.Split(output, "\n") {
+			if strings.Contains(line, name) && strings.Contains(line, status) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected %q to be reported as %q, got:\n%s", name, status, output)
+		}
+	}
+}
+
+func TestParseWaitTimeout(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{args: []string{"hrobot", "firewall", "wait", "1"}, expected: 0},
+		{args: []string{"hrobot", "firewall", "wait", "1", "--wait-timeout", "5m"}, expected: 5 * time.Minute},
+		{args: []string{"hrobot", "firewall", "wait", "1", "--wait-timeout=90s"}, expected: 90 * time.Second},
+		{args: []string{"hrobot", "firewall", "wait", "1", "--wait-timeout", "soon"}, wantErr: true},
+		{args: []string{"hrobot", "firewall", "wait", "1", "--wait-timeout", "-1s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseWaitTimeout(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseWaitTimeout(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseWaitTimeout(%v) = %s, expected %s", tt.args, got, tt.expected)
+		}
+	}
+}
+
+func TestFirewallHCL(t *testing.T) {
+	fw := &hrobot.FirewallConfig{
+		ServerNumber: 321,
+		WhitelistHOS: true,
+		FilterIPv6:   false,
+		Rules: hrobot.FirewallRules{
+			Input: []hrobot.FirewallRule{
+				{Name: "allow ssh", IPVersion: hrobot.IPv4, Action: hrobot.ActionAccept, Protocol: hrobot.ProtocolTCP, SourceIP: "1.2.3.4/32", DestPort: "22"},
+				{Name: "allow ${var}", IPVersion: hrobot.IPv6, Action: hrobot.ActionAccept, Protocol: hrobot.ProtocolTCP, DestPort: "443", TCPFlags: "syn"},
+			},
+			Output: []hrobot.FirewallRule{
+				{Name: "Block mail ports", IPVersion: hrobot.IPv4, Action: hrobot.ActionDiscard, Protocol: hrobot.ProtocolTCP, DestPort: "25,465"},
+				{Name: "allow all", Action: hrobot.ActionAccept},
+			},
+		},
+	}
+
+	hcl := firewallHCL(fw)
+
+	for _, want := range []string{
+		`resource "hrobot_firewall" "server_321" {`,
+		"  server_id                  = 321\n",
+		"  whitelist_hetzner_services = true\n",
+		"  filter_ipv6                = false\n",
+		"  keep_mail_block            = true\n",
+		"  input_rules = [\n",
+		"      name             = \"allow ssh\"\n",
+		"      ip_version       = \"ipv4\"\n",
+		"      action           = \"accept\"\n",
+		"      protocol         = \"tcp\"\n",
+		"      source_ips       = [\"1.2.3.4/32\"]\n",
+		"      destination_port = \"22\"\n",
+		"      name             = \"allow $${var}\"\n",
+		"      tcp_flags        = \"syn\"\n",
+		"  output_rules = [\n",
+		"      name   = \"allow all\"\n",
+		"      action = \"accept\"\n",
+	} {
+		if !strings.Contains(hcl, want) {
+			t.Errorf("expected HCL to contain %q, got:\n%s", want, hcl)
+		}
+	}
+
+	if strings.Contains(hcl, "Block mail ports") {
+		t.Errorf("expected the auto-added mail rule to be replaced by keep_mail_block, got:\n%s", hcl)
+	}
+	if strings.Count(hcl, "{") != strings.Count(hcl, "}") || strings.Count(hcl, "[") != strings.Count(hcl, "]") {
+		t.Errorf("unbalanced HCL:\n%s", hcl)
+	}
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/firewall/template/5" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Method == "POST" {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			posted = r.PostForm
+		}
+
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":            5,
+				"name":          "web",
+				"filter_ipv6":   false,
+				"whitelist_hos": true,
+				"is_default":    true,
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{"name": "allow ssh", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "dst_port": "22"},
+					},
+					"output": []map[string]interface{}{
+						{"name": "allow all", "action": "accept"},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	// Renaming keeps the rules and settings of the template
+	disable := false
+	captureStdout(t, func() {
+		if err := updateTemplate(ctx, client, 5, "web-v2", "", &disable, nil); err != nil {
+			t.Fatalf("updateTemplate returned error: %v", err)
+		}
+	})
+	for key, want := range map[string]string{
+		"name":                      "web-v2",
+		"whitelist_hos":             "false",
+		"filter_ipv6":               "false",
+		"is_default":                "true",
+		"rules[input][0][name]":     "allow ssh",
+		"rules[input][0][dst_port]": "22",
+		"rules[output][0][name]":    "allow all",
+	} {
+		if got := posted.Get(key); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+
+	// A rules file replaces the rules
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	content := `{"filter_ipv6": true, "rules": {"input": [{"name": "allow https", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "dst_port": "443"}]}}`
+	if err := os.WriteFile(rulesFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rules file: %v", err)
+	}
+	posted = nil
+	captureStdout(t, func() {
+		if err := updateTemplate(ctx, client, 5, "", rulesFile, nil, nil); err != nil {
+			t.Fatalf("updateTemplate returned error: %v", err)
+		}
+	})
+	for key, want := range map[string]string{
+		"name":                      "web",
+		"whitelist_hos":             "true",
+		"filter_ipv6":               "true",
+		"rules[input][0][name]":     "allow https",
+		"rules[input][0][dst_port]": "443",
+		"rules[input][1][name]":     "",
+		"rules[output][0][name]":    "",
+	} {
+		if got := posted.Get(key); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+
+	if err := updateTemplate(ctx, client, 5, "", "", nil, nil); err == nil {
+		t.Error("expected error when nothing is updated, got nil")
+	}
+}
+
+func TestParseFlagOptionalBool(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"update", "5"}, want: "<nil>"},
+		{args: []string{"update", "5", "--filter-ipv6"}, want: "true"},
+		{args: []string{"update", "5", "--filter-ipv6=false"}, want: "false"},
+		{args: []string{"update", "5", "--filter-ipv6=yes"}, want: "true"},
+	}
+
+	for _, tt := range tests {
+		got := "<nil>"
+		if value := parseFlagOptionalBool(tt.args, "--filter-ipv6"); value != nil {
+			got = strconv.FormatBool(*value)
+		}
+		if got != tt.want {
+			t.Errorf("parseFlagOptionalBool(%v) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeTemplate_Ports(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/firewall/template/5" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":   5,
+				"name": "dns",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{"name": "dns replies", "ip_version": "ipv4", "action": "accept", "protocol": "udp", "src_port": "53", "dst_port": "32768-65535"},
+					},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	out := captureStdout(t, func() {
+		if err := describeTemplate(ctx, client, 5, ""); err != nil {
+			t.Fatalf("describeTemplate returned error: %v", err)
+		}
+	})
+	for _, want := range []string{"Source Port", "Dest Port", " 53 ", " 32768-65535 "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in table, got:\n%s", want, out)
+		}
+	}
+
+	// The JSON output can be used as a rules file and keeps both ports
+	out = captureStdout(t, func() {
+		if err := describeTemplate(ctx, client, 5, "json"); err != nil {
+			t.Fatalf("describeTemplate returned error: %v", err)
+		}
+	})
+	rf, err := parseRulesFile([]byte(out))
+	if err != nil {
+		t.Fatalf("failed to parse JSON output as rules file: %v", err)
+	}
+	if rule := rf.Rules.Input[0]; rule.SourcePort != "53" || rule.DestPort != "32768-65535" {
+		t.Errorf("expected both ports to round-trip, got %+v", rule)
+	}
+}
+
+func TestAddRule_PortlessProtocols(t *testing.T) {
+	tests := []struct {
+		name       string
+		direction  string
+		protocol   string
+		port       string
+		sourcePort string
+		wantPrefix string
+		wantErr    string
+	}{
+		{
+			name:       "inbound esp",
+			direction:  "in",
+			protocol:   "esp",
+			wantPrefix: "rules[input][0]",
+		},
+		{
+			name:       "inbound gre",
+			direction:  "in",
+			protocol:   "GRE",
+			wantPrefix: "rules[input][0]",
+		},
+		{
+			name:       "outbound esp",
+			direction:  "out",
+			protocol:   "esp",
+			wantPrefix: "rules[output][0]",
+		},
+		{
+			name:      "esp with port",
+			direction: "in",
+			protocol:  "esp",
+			port:      "500",
+			wantErr:   "esp rules have no ports",
+		},
+		{
+			name:       "gre with source port",
+			direction:  "out",
+			protocol:   "gre",
+			sourcePort: "1024",
+			wantErr:    "gre rules have no ports",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted url.Values
+			server := newDeleteRuleTestServer(t, &posted)
+			defer server.Close()
+
+			client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+			info, err := addRule(context.Background(), client, io.Discard, hrobot.ServerID(321), tt.direction, tt.protocol, "accept", "vpn",
+				[]string{"1.2.3.4"}, []string{"1.2.3.4"}, tt.sourcePort, tt.port, false, firewallOptions{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if posted != nil {
+					t.Error("firewall must not be updated on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("addRule returned error: %v", err)
+			}
+			if info.Added != 1 {
+				t.Errorf("expected 1 added rule, got %d", info.Added)
+			}
+
+			want := strings.ToLower(tt.protocol)
+			if got := posted.Get(tt.wantPrefix + "[protocol]"); got != want {
+				t.Errorf("protocol = %q, want %q", got, want)
+			}
+			if got := posted.Get(tt.wantPrefix + "[ip_version]"); got != "ipv4" {
+				t.Errorf("ip_version = %q, want ipv4", got)
+			}
+			for _, key := range []string{"[dst_port]", "[src_port]"} {
+				if posted.Has(tt.wantPrefix + key) {
+					t.Errorf("expected no %s for a %s rule, got %q", key, want, posted.Get(tt.wantPrefix+key))
+				}
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"syscall"
 
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
@@ -29,6 +31,19 @@ func verifyCredentials(ctx context.Context, client *hrobot.Client) bool {
 	return err == nil
 }
 
+// resetCredentialsSteps lists the steps to get or reset the webservice
+// credentials.
+const resetCredentialsSteps = `To get or reset your credentials:
+  1. Visit: https://robot.hetzner.com/preferences/index
+  2. Navigate to the 'Webservice and app settings' section
+  3. Retrieve username and set new password`
+
+// allowIPSteps lists the steps to allow an IP address for webservice access.
+const allowIPSteps = `To allow your IP address:
+  1. Visit: https://robot.hetzner.com/preferences/index
+  2. Go to 'Webservice and app settings' -> 'Webservice/app access'
+  3. Add your IP and save`
+
 // enhanceAuthError checks if an error is an authentication error and adds helpful instructions.
 func enhanceAuthError(err error) error {
 	if err == nil {
@@ -42,10 +57,7 @@ func enhanceAuthError(err error) error {
 
 Authentication failed. Please verify your credentials are correct.
 
-To get or reset your credentials:
-  1. Visit: https://robot.hetzner.com/preferences/index
-  2. Navigate to the 'Webservice and app settings' section
-  3. Retrieve username and set new password
+%s
 
 Note: If you want to order servers via the API, you also need to:
   1. Go to 'Webservice and app settings' -> 'Ordering'
@@ -58,12 +70,74 @@ To limit API access to certain IP-address:
 
 Example usage:
   export HROBOT_USERNAME='#ws+XXXXXXX'
-  export HROBOT_PASSWORD='YYYYYY'`, err)
+  export HROBOT_PASSWORD='YYYYYY'`, err, resetCredentialsSteps)
+	}
+
+	if errors.As(err, &hrobotErr) && hrobot.IsForbiddenError(hrobotErr) {
+		return fmt.Errorf(`%w
+
+The request was rejected. Webservice access is probably limited to certain
+IP addresses and your IP address is not one of them.
+
+%s
+
+%s`, err, publicIPNote(), allowIPSteps)
+	}
+
+	if errors.As(err, &hrobotErr) && hrobot.IsNetworkError(hrobotErr) {
+		return fmt.Errorf(`%w
+
+Could not reach the Hetzner Robot API (%s).
+
+Please check:
+  - your network connectivity and DNS settings
+  - whether the Robot API is down: https://status.hetzner.com
+  - whether your IP is whitelisted for webservice access:
+    https://robot.hetzner.com/preferences/index -> 'Webservice and app settings' -> 'Webservice/app access'`, err, networkErrorReason(err))
 	}
 
 	return err
 }
 
+// detectPublicIP returns the caller's public IP address. Tests replace it.
+var detectPublicIP = getMyIP
+
+// publicIPNote reports the caller's public IP address for the IP allowlist
+// guidance.
+func publicIPNote() string {
+	ip, err := detectPublicIP()
+	if err != nil {
+		return fmt.Sprintf("Your public IP address could not be detected: %v", err)
+	}
+	return fmt.Sprintf("Your public IP address: %s", ip)
+}
+
+// networkErrorReason describes why a request did not reach the API.
+func networkErrorReason(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return fmt.Sprintf("could not resolve %s", dnsErr.Name)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "the request timed out"
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return "the connection was refused"
+	}
+
+	return "the connection failed"
+}
+
+// enableOrderingSteps lists the steps to enable ordering over the webservice.
+const enableOrderingSteps = `To enable ordering via the API:
+  1. Visit: https://robot.hetzner.com/preferences/index
+  2. Go to 'Webservice and app settings' -> 'Ordering'
+  3. Enable 'ordering over the webservice'
+  4. Click 'confirm' to save the setting`
+
 // enhanceOrderingAuthError checks if an error is an authentication error for ordering operations.
 // It verifies if credentials are valid but ordering permission is missing.
 func enhanceOrderingAuthError(ctx context.Context, client *hrobot.Client, err error) error {
@@ -71,28 +145,29 @@ func enhanceOrderingAuthError(ctx context.Context, client *hrobot.Client, err er
 		return nil
 	}
 
-	// Check if this is an unauthorized error
 	var hrobotErr *hrobot.Error
-	if errors.As(err, &hrobotErr) && hrobot.IsUnauthorizedError(hrobotErr) {
+	if !errors.As(err, &hrobotErr) {
+		return err
+	}
+
+	// Hetzner explicitly told us ordering is disabled
+	if hrobot.IsOrderingDisabledError(hrobotErr) {
+		return fmt.Errorf("%w\n\nOrdering via the API is not enabled for this account.\n\n%s", err, enableOrderingSteps)
+	}
+
+	// Check if this is an unauthorized error
+	if hrobot.IsUnauthorizedError(hrobotErr) {
 		// Verify if credentials work by testing with SSH keys endpoint
 		if verifyCredentials(ctx, client) {
 			// Credentials work, but ordering is not enabled
-			return fmt.Errorf(`%w
-
-Your credentials are valid, but ordering via the API is not enabled.
-
-To enable ordering via the API:
-  1. Visit: https://robot.hetzner.com/preferences/index
-  2. Go to 'Webservice and app settings' -> 'Ordering'
-  3. Enable 'ordering over the webservice'
-  4. Click 'confirm' to save the setting`, err)
+			return fmt.Errorf("%w\n\nYour credentials are valid, but ordering via the API is not enabled.\n\n%s", err, enableOrderingSteps)
 		}
 
 		// Credentials don't work at all, show full authentication error
 		return enhanceAuthError(err)
 	}
 
-	return err
+	return enhanceAuthError(err)
 }
 
 func printHelp() {
@@ -103,12 +178,16 @@ Usage:
 
 Available Commands:
   help                                       Show this help message
+  doctor                                     Check credentials, IP access and ordering permission
 
   Server Commands:
     server list                              List all servers
+    server list --with-power                 List all servers with their power state
+    server list --filter <expr>              List servers matching e.g. datacenter=FSN1,status=ready
     server describe <id>                     Describe server details by ID
     server reboot <id>                       Reboot server (hardware reset)
     server shutdown <id>                     Shutdown server
+    server reset-history <id>                Show resets executed with hrobot
     server poweron <id>                      Power on server
     server poweroff <id>                     Power off server
     server wake <id>                         Wake server using Wake-on-LAN
@@ -117,6 +196,7 @@ Available Commands:
     server traffic <id>                      Show traffic statistics
     server images <id>                       Show boot/image configuration
     server install <id>                      Install operating system on server
+    server reinstall <id> --linux=<dist>     Install Linux and reboot into the installer
 
   Firewall Commands:
     firewall allow-ssh <server-id>           Allow SSH access (see firewall --help)
@@ -124,11 +204,17 @@ Available Commands:
     firewall allow-mosh <server-id>          Allow MOSH access (SSH + UDP 60000-61000)
     firewall block-http <server-id>          Block insecure HTTP
     firewall harden <server-id>              Apply security hardening
+    firewall harden-all --block-http         Apply security hardening to all servers
     firewall add-rule <server-id>            Add firewall rule
     firewall delete-rule <server-id>         Delete firewall rule
+    firewall delete-group <id> <label>       Delete all rules in a group
     firewall list-rules <server-id>          List firewall rules
+    firewall export <server-id>              Print firewall as Terraform HCL
+    firewall replace <server-id>             Replace all rules from a rules file
     firewall template list                   List firewall templates
     firewall template apply <id> <tmpl-id>   Apply template to server
+    firewall template update <tmpl-id>       Update a template in place
+    firewall template set-default <tmpl-id>  Mark a template as the account default
     firewall enable <server-id>              Enable firewall (use --filter-ipv6=true|false)
     firewall disable <server-id>             Disable firewall
     firewall status <server-id>              Show firewall status
@@ -146,16 +232,22 @@ Available Commands:
   Auction Commands:
     auction list                             List available auction servers
     auction order <product-id>               Order a server from auction
+    auction order-best [filters]             Order the cheapest matching auction server
+    auction wait <transaction-id>            Wait for an auction order to complete
 
   Product Commands:
     product list                             List available product servers
     product order <product-id>               Order a product server
 
+  Addon Commands:
+    addon transactions                       List addon transactions and assigned IPs
+
   Reverse DNS Commands:
-    rdns list                                List all reverse DNS entries
+    rdns list [--concurrency N]              List all reverse DNS entries sorted by IP
     rdns describe <ip>                       Describe reverse DNS entry for an IP
     rdns set <ip> <ptr>                      Set reverse DNS entry for an IP
     rdns reset <ip>                          Use default Hetzner reverse DNS entry for an IP
+    rdns reset-bulk <subnet>                 Reset all reverse DNS entries in a subnet
 
   Failover IP Commands:
     failover list                            List all failover IPs
@@ -165,6 +257,7 @@ Available Commands:
 
   VSwitch Commands:
     vswitch list                             List all vSwitches
+    vswitch list --server <number>           List vSwitches a server is connected to
     vswitch describe <id>                    Describe vSwitch details
     vswitch create <name> <vlan>             Create a new vSwitch
     vswitch update <id> <name> <vlan>        Update vSwitch name and VLAN
@@ -174,11 +267,36 @@ Available Commands:
 
 Global Flags:
   --config string                            Config file path (default "~/.config/hrobot/cli.toml")
-  --context string                           Currently active context
+  --context string                           Use this context instead of the active one and the
+                                             environment variables
+  --base-url string                          Robot API base URL, e.g. for a mock or proxy
+  --fields strings                           With --output json, only print these comma-separated
+                                             fields, e.g. --fields server_number,ipv4
+  --columns strings                          Only print these list columns, in this order,
+                                             e.g. --columns id,cpu,memory,price-mo
+  --max-width int                            Truncate table cells longer than this, e.g. --max-width 30
+  --timeout duration                         Give up on the whole command after this long, e.g. 2m
+                                             (each API request also times out after 30s)
 
 Environment Variables:
-  HROBOT_USERNAME                            Your Hetzner Robot username (e.g., #ws+XXXXX)
-  HROBOT_PASSWORD                            Your Hetzner Robot password
+  HROBOT_USERNAME                            Your Hetzner Robot username (e.g., #ws+XXXXX),
+                                             overrides the username of the active context
+  HROBOT_PASSWORD                            Your Hetzner Robot password, overrides the
+                                             password of the active context
+  HROBOT_BASE_URL                            Robot API base URL (overridden by --base-url)
+
+Exit Codes:
+  0                                          Success
+  1                                          Any other error
+  2                                          Authentication failed or access denied
+  3                                          Resource not found
+  4                                          Invalid input rejected by the API
+  5                                          Rate limit exceeded
+  6                                          Robot API could not be reached
+  130                                        Cancelled with Ctrl-C (SIGINT) or SIGTERM
+
+  With --output json, errors are printed to stdout as
+  {"error": {"code": "...", "message": "...", "exit_code": N}}
 
 `)
 }
@@ -0,0 +1,178 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func writeUnauthorized(t *testing.T, w http.ResponseWriter, message string) {
+	w.WriteHeader(http.StatusUnauthorized)
+	response := map[string]interface{}{
+		"error": map[string]interface{}{
+			"status":  401,
+			"code":    "UNAUTHORIZED",
+			"message": message,
+		},
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		t.Fatalf("failed to encode response: %v", err)
+	}
+}
+
+func TestEnhanceOrderingAuthError_OrderingDisabled(t *testing.T) {
+	keyCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/key" {
+			keyCalls++
+		}
+		writeUnauthorized(t, w, "Ordering over the webservice is not enabled")
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	err := enhanceOrderingAuthError(ctx, client, orderProductServer(ctx, client, "EX44", "", hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}, orderImage{}, "", true, true, orderWait{}))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	errMsg := err.Error()
+	if !strings.Contains(errMsg, "Ordering via the API is not enabled") {
+		t.Errorf("error message should explain that ordering is disabled, got: %s", errMsg)
+	}
+	if !strings.Contains(errMsg, "Enable 'ordering over the webservice'") {
+		t.Errorf("error message should list the steps to enable ordering, got: %s", errMsg)
+	}
+	if keyCalls != 0 {
+		t.Errorf("expected no credential verification request, got %d", keyCalls)
+	}
+}
+
+func TestEnhanceOrderingAuthError_ValidCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/key" {
+			if err := json.NewEncoder(w).Encode([]map[string]interface{}{}); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+			return
+		}
+		writeUnauthorized(t, w, "Unable to authenticate")
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	err := enhanceOrderingAuthError(ctx, client, orderProductServer(ctx, client, "EX44", "", hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}, orderImage{}, "", true, true, orderWait{}))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	errMsg := err.Error()
+	if !strings.Contains(errMsg, "Your credentials are valid") {
+		t.Errorf("error message should confirm valid credentials, got: %s", errMsg)
+	}
+	if !strings.Contains(errMsg, "Enable 'ordering over the webservice'") {
+		t.Errorf("error message should list the steps to enable ordering, got: %s", errMsg)
+	}
+}
+
+func TestEnhanceAuthError_NetworkError(t *testing.T) {
+	dnsErr := &net.DNSError{Err: "no such host", Name: "robot-ws.your-server.de", IsNotFound: true}
+	err := enhanceAuthError(fmt.Errorf("failed to list servers: %w", hrobot.NewNetworkError("request failed", dnsErr)))
+
+	errMsg := err.Error()
+	for _, want := range []string{
+		"failed to list servers",
+		"Could not reach the Hetzner Robot API (could not resolve robot-ws.your-server.de)",
+		"network connectivity",
+		"Robot API is down",
+		"whitelisted for webservice access",
+	} {
+		if !strings.Contains(errMsg, want) {
+			t.Errorf("expected %q in error message, got: %s", want, errMsg)
+		}
+	}
+
+	var target *net.DNSError
+	if !errors.As(err, &target) {
+		t.Error("expected the enhanced error to still wrap the DNS error")
+	}
+}
+
+func TestEnhanceAuthError_ConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(url))
+	err := enhanceAuthError(listServers(context.Background(), client, "", nil, 0))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "Could not reach the Hetzner Robot API (the connection was refused)") {
+		t.Errorf("expected connection refused guidance, got: %s", err)
+	}
+}
+
+func TestEnhanceAuthError_OtherErrors(t *testing.T) {
+	apiErr := hrobot.NewAPIError(hrobot.ErrNotFound, "server not found")
+	if err := enhanceAuthError(apiErr); err != apiErr {
+		t.Errorf("expected non-network error to be returned unchanged, got: %v", err)
+	}
+}
+
+func TestEnhanceAuthError_IPNotWhitelisted(t *testing.T) {
+	origDetect := detectPublicIP
+	t.Cleanup(func() { detectPublicIP = origDetect })
+	detectPublicIP = func() (string, error) { return "203.0.113.7", nil }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<html><body>403 Forbidden</body></html>"))
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	err := enhanceAuthError(listServers(context.Background(), client, "", nil, 0))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	errMsg := err.Error()
+	for _, want := range []string{
+		"HTTP 403",
+		"your IP address is not one of them",
+		"Your public IP address: 203.0.113.7",
+		"'Webservice and app settings' -> 'Webservice/app access'",
+	} {
+		if !strings.Contains(errMsg, want) {
+			t.Errorf("expected %q in error message, got: %s", want, errMsg)
+		}
+	}
+}
+
+func TestEnhanceAuthError_IPNotWhitelisted_UnknownIP(t *testing.T) {
+	origDetect := detectPublicIP
+	t.Cleanup(func() { detectPublicIP = origDetect })
+	detectPublicIP = func() (string, error) { return "", errors.New("failed to get public IP") }
+
+	err := enhanceAuthError(fmt.Errorf("failed to list servers: %w", hrobot.NewAPIError(hrobot.ErrForbidden, "HTTP 403: Forbidden")))
+	if !strings.Contains(err.Error(), "Your public IP address could not be detected: failed to get public IP") {
+		t.Errorf("expected detection failure in error message, got: %s", err)
+	}
+}
@@ -6,29 +6,91 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// Ctrl-C or SIGTERM cancels the root context, so that waits and API
+	// requests stop promptly instead of polling until the process is killed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := runMain(ctx, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// runMain runs the command and reports its error, returning the exit code.
+// The error is reported as a cancellation only if ctx itself was cancelled by
+// a signal; ctx must therefore still be live when this is called.
+func runMain(ctx context.Context, stdout, stderr io.Writer) int {
+	err := run(ctx)
+	if err == nil {
+		return exitOK
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(stderr, "cancelled.")
+		return exitCancelled
+	}
+	// JSON consumers get a structured error on stdout instead
+	if parseFlagString(os.Args, "--output") == "json" && writeJSONError(stdout, err) == nil {
+		return exitCode(err)
 	}
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+	return exitCode(err)
 }
 
 // printGlobalFlags prints the global flags section for help output.
 func printGlobalFlags() {
 	fmt.Println("\nGlobal Flags:")
 	fmt.Println("      --config string              Config file path (default \"~/.config/hrobot/cli.toml\")")
-	fmt.Println("      --context string             Currently active context")
+	fmt.Println("      --context string             Use this context instead of the active one and the environment")
+	fmt.Println("      --base-url string            Robot API base URL (default \"" + hrobot.DefaultBaseURL + "\")")
+	fmt.Println("      --fields strings             With --output json, only print these fields (dot paths for nested)")
+	fmt.Println("      --columns strings            Only print these list columns, in this order")
+	fmt.Println("      --max-width int              Truncate table cells longer than this")
+	fmt.Println("      --timeout duration           Give up on the whole command after this long, e.g. 2m")
 }
 
-func run() error {
+// commandTimeout is the --timeout of the whole command; zero means no limit.
+// It bounds everything the command does, unlike the per-request HTTP timeout
+// and --wait-timeout.
+var commandTimeout time.Duration
+
+func run(ctx context.Context) (err error) {
+	timeout, err := parseDurationFlag(os.Args, "--timeout")
+	if err != nil {
+		return err
+	}
+
+	// Global flags can appear anywhere, even before the command, so they are
+	// removed before the arguments are routed
+	baseURL, args := removeFlagString(os.Args, "--base-url")
+	contextName, args := removeFlagString(args, "--context")
+	_, args = removeFlagString(args, "--timeout")
+	os.Args = args
+
+	if timeout > 0 {
+		commandTimeout = timeout
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		defer func() {
+			err = timeoutError(ctx, timeout, err)
+		}()
+	}
+
 	// Parse command line arguments
 	if len(os.Args) < 2 {
 		printHelp()
@@ -37,6 +99,13 @@ func run() error {
 
 	command := os.Args[1]
 
+	if parseFlagString(os.Args, "--fields") != "" && parseFlagString(os.Args, "--output") != "json" {
+		return fmt.Errorf("--fields requires --output json")
+	}
+	if maxWidth := parseFlagString(os.Args, "--max-width"); maxWidth != "" && parseFlagInt(os.Args, "--max-width") < 1 {
+		return fmt.Errorf("invalid --max-width value: %s (must be a positive number)", maxWidth)
+	}
+
 	// Handle help flag
 	if command == "--help" || command == "-h" || command == "help" {
 		printHelp()
@@ -45,16 +114,29 @@ func run() error {
 
 	// Handle context command (doesn't require credentials)
 	if command == "context" {
-		return handleContextCommand()
+		return handleContextCommand(ctx, baseURL)
 	}
 
-	// Get credentials from context first, then fall back to environment
-	username, password := getCredentialsFromContext()
+	// Get credentials from --context, the environment or the active context
+	username, password, err := getCredentialsFromContext(contextName)
+	if err != nil {
+		return err
+	}
 
-	// Fall back to environment variables if no context is active
-	if username == "" || password == "" {
-		username = os.Getenv("HROBOT_USERNAME")
-		password = os.Getenv("HROBOT_PASSWORD")
+	// The doctor command reports missing credentials itself
+	if command == "doctor" {
+		if isHelpRequested() {
+			fmt.Printf("Usage: %s doctor\n\n", os.Args[0])
+			fmt.Println("Check the credentials, webservice access from your IP and whether ordering")
+			fmt.Println("over the webservice is enabled.")
+			printGlobalFlags()
+			return nil
+		}
+		clientOpts, err := clientOptions(parseFlagBool(os.Args, "--verbose"), baseURL)
+		if err != nil {
+			return err
+		}
+		return runDoctor(ctx, username, password, clientOpts)
 	}
 
 	if username == "" || password == "" {
@@ -83,16 +165,19 @@ Or use context management:
   hrobot context use <name>`)
 	}
 
+	if err := hrobot.ValidateUsername(username); err != nil {
+		return fmt.Errorf("%w\n\nCheck HROBOT_USERNAME or the credentials of the active context ('hrobot context active')", err)
+	}
+
 	// Check for verbose flag
 	verbose := parseFlagBool(os.Args, "--verbose")
 
 	// Create client
-	var clientOpts []hrobot.ClientOption
-	if verbose {
-		clientOpts = append(clientOpts, hrobot.WithDebug(true))
+	clientOpts, err := clientOptions(verbose, baseURL)
+	if err != nil {
+		return err
 	}
 	client := hrobot.New(username, password, clientOpts...)
-	ctx := context.Background()
 
 	// Route commands to their handlers
 	switch command {
@@ -120,6 +205,9 @@ Or use context management:
 	case "product":
 		return handleProductCommand(ctx, client)
 
+	case "addon":
+		return handleAddonCommand(ctx, client)
+
 	default:
 		printHelp()
 		return fmt.Errorf("unknown command: %s", command)
@@ -129,61 +217,96 @@ Or use context management:
 // handleServerCommand handles all server-related subcommands.
 func handleServerCommand(ctx context.Context, client *hrobot.Client) error {
 	if len(os.Args) < 3 {
-		return fmt.Errorf("usage: %s server <subcommand>\nSubcommands:\n  list              - List all servers\n  describe <id>     - Describe server details by ID\n  reboot <id>       - Reboot server (hardware reset)\n  shutdown <id>     - Shutdown server\n  poweron <id>      - Power on server\n  poweroff <id>     - Power off server\n  wake <id>         - Wake server via WoL\n  enable-rescue <id> - Enable rescue system\n  disable-rescue <id> - Disable rescue system\n  traffic <id>      - Show traffic statistics\n  images <id>       - Show boot/image configuration\n  install <id>      - Install operating system on server\n  ssh <id>          - SSH into server with auto firewall config", os.Args[0])
+		return fmt.Errorf("usage: %s server <subcommand>\nSubcommands:\n  list              - List all servers\n  describe <id>     - Describe server details by ID\n  reboot <id>       - Reboot server (hardware reset)\n  shutdown <id>     - Shutdown server\n  reset-history <id> - Show resets executed with hrobot\n  poweron <id>      - Power on server\n  poweroff <id>     - Power off server\n  wake <id>         - Wake server via WoL\n  enable-rescue <id> - Enable rescue system\n  disable-rescue <id> - Disable rescue system\n  traffic <id>      - Show traffic statistics\n  images <id>       - Show boot/image configuration\n  install <id>      - Install operating system on server\n  reinstall <id>    - Install Linux and reboot into the installer\n  ssh <id>          - SSH into server with auto firewall config", os.Args[0])
 	}
 
 	subcommand := os.Args[2]
 	switch subcommand {
 	case "list":
-		return enhanceAuthError(listServers(ctx, client))
+		if isHelpRequested() {
+			fmt.Printf("Usage: %s server list [--filter <expr>] [--output=csv|json] [--with-power] [--concurrency N]\n\n", os.Args[0])
+			fmt.Println("List all servers.")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --filter <expr>    Only list servers matching all comma-separated predicates:")
+			fmt.Println("                     field=value, field!=value, field~value (contains) or a name substring")
+			fmt.Println("                     Fields: number, name, ip, product, datacenter (dc), status, cancelled, paid_until")
+			fmt.Println("  --output           Output format: csv or json instead of a table")
+			fmt.Println("  --with-power       Add the power state of every server (one request per server)")
+			fmt.Printf("  --concurrency N    With --with-power, fetch N power states in parallel (default: %d)\n", defaultPowerConcurrency)
+			printGlobalFlags()
+			return nil
+		}
+
+		outputFormat, err := parseListOutput(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		filter, err := parseServerFilter(parseFlagString(os.Args, "--filter"))
+		if err != nil {
+			return err
+		}
+		powerConcurrency := 0
+		if parseFlagBool(os.Args, "--with-power") {
+			powerConcurrency = defaultPowerConcurrency
+			if parseFlagString(os.Args, "--concurrency") != "" {
+				powerConcurrency = parseFlagInt(os.Args, "--concurrency")
+				if powerConcurrency < 1 {
+					return fmt.Errorf("--concurrency must be a positive number")
+				}
+			}
+		}
+		return enhanceAuthError(listServers(ctx, client, outputFormat, filter, powerConcurrency))
 
 	case "describe":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s server describe <server-id>\n\n", os.Args[0])
+			fmt.Printf("Usage: %s server describe <server-id> [--output json]\n\n", os.Args[0])
 			fmt.Println("Describe detailed information about a specific server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number to describe")
+			fmt.Println("  <server-id>    The server number, name or IP to describe")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --output       Output format: json")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(getServer(ctx, client, hrobot.ServerID(serverID)))
+		outputFormat := parseFlagString(os.Args, "--output")
+		return enhanceAuthError(getServer(ctx, client, serverID, outputFormat))
 
 	case "reboot":
 		if isHelpRequested() || len(os.Args) < 4 {
 			fmt.Printf("Usage: %s server reboot <server-id>\n\n", os.Args[0])
 			fmt.Println("Reboot a server using hardware reset.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number to reboot")
+			fmt.Println("  <server-id>    The server number, name or IP to reboot")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(executeReset(ctx, client, hrobot.ServerID(serverID), "hw"))
+		return enhanceAuthError(executeReset(ctx, client, serverID, "hw"))
 
 	case "shutdown":
 		if isHelpRequested() || len(os.Args) < 4 {
 			fmt.Printf("Usage: %s server shutdown <server-id> [--order-manual-power-cycle-from-technician]\n\n", os.Args[0])
 			fmt.Println("Shutdown a server using long power button press.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>                                  The server number to shutdown")
+			fmt.Println("  <server-id>                                  The server number, name or IP to shutdown")
 			fmt.Println("\nFlags:")
 			fmt.Println("  --order-manual-power-cycle-from-technician   Emails datacenter technician to manually turn the server off and on")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
 		manualPowerCycle := false
 		for _, arg := range os.Args[4:] {
@@ -196,73 +319,109 @@ func handleServerCommand(ctx context.Context, client *hrobot.Client) error {
 		if manualPowerCycle {
 			resetType = "man"
 		}
-		return enhanceAuthError(executeReset(ctx, client, hrobot.ServerID(serverID), resetType))
+		return enhanceAuthError(executeReset(ctx, client, serverID, resetType))
+
+	case "reset-history":
+		if isHelpRequested() || len(os.Args) < 4 {
+			fmt.Printf("Usage: %s server reset-history <server-id> [--output=csv|json]\n\n", os.Args[0])
+			fmt.Println("Show the resets of a server executed with hrobot, newest first.")
+			fmt.Println("\nThe Robot API keeps no reset history, so hrobot records the resets it")
+			fmt.Println("executes (reboot, shutdown, poweron, poweroff, reinstall) in a local log")
+			fmt.Println("in ~/.config/hrobot. Resets made elsewhere are not listed.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  <server-id>    The server number, name or IP")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --output       Output format: table (default), csv or json")
+			printGlobalFlags()
+			return nil
+		}
+		serverIDStr := os.Args[3]
+		serverID, err := parseServerID(ctx, client, serverIDStr)
+		if err != nil {
+			return err
+		}
+		return showResetHistory(serverID, os.Args[4:])
 
 	case "poweron":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s server poweron <server-id>\n\n", os.Args[0])
+			fmt.Printf("Usage: %s server poweron <server-id> [--wait] [--wait-timeout <duration>]\n\n", os.Args[0])
 			fmt.Println("Power on a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number to power on")
+			fmt.Println("  <server-id>    The server number, name or IP to power on")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --wait           Wait until the server is powered on")
+			fmt.Println("  --wait-timeout   Give up waiting after this long, e.g. 90s or 5m (default: 5m)")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(powerOnServer(ctx, client, hrobot.ServerID(serverID)))
+		timeout, err := parseWaitTimeout(os.Args)
+		if err != nil {
+			return err
+		}
+		return enhanceAuthError(powerOnServer(ctx, client, serverID, parseFlagBool(os.Args, "--wait"), timeout))
 
 	case "poweroff":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s server poweroff <server-id>\n\n", os.Args[0])
+			fmt.Printf("Usage: %s server poweroff <server-id> [--wait] [--wait-timeout <duration>]\n\n", os.Args[0])
 			fmt.Println("Power off a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number to power off")
+			fmt.Println("  <server-id>    The server number, name or IP to power off")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --wait           Wait until the server is powered off")
+			fmt.Println("  --wait-timeout   Give up waiting after this long, e.g. 90s or 5m (default: 5m)")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
+		}
+		timeout, err := parseWaitTimeout(os.Args)
+		if err != nil {
+			return err
 		}
-		return enhanceAuthError(powerOffServer(ctx, client, hrobot.ServerID(serverID)))
+		return enhanceAuthError(powerOffServer(ctx, client, serverID, parseFlagBool(os.Args, "--wait"), timeout))
 
 	case "wake":
 		if isHelpRequested() || len(os.Args) < 4 {
 			fmt.Printf("Usage: %s server wake <server-id>\n\n", os.Args[0])
 			fmt.Println("Send a Wake-on-LAN packet to wake the server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number to wake")
+			fmt.Println("  <server-id>    The server number, name or IP to wake")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(wakeServer(ctx, client, hrobot.ServerID(serverID)))
+		return enhanceAuthError(wakeServer(ctx, client, serverID))
 
 	case "enable-rescue":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s server enable-rescue <server-id> [--linux|--vkvm] [--password]\n\n", os.Args[0])
+			fmt.Printf("Usage: %s server enable-rescue <server-id> [--linux|--vkvm] [--password | --key <name>...]\n\n", os.Args[0])
 			fmt.Println("Enable rescue system for a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number")
+			fmt.Println("  <server-id>    The server number, name or IP")
 			fmt.Println("\nFlags:")
 			fmt.Println("  --linux        Use Linux rescue system (default)")
 			fmt.Println("  --vkvm         Use VNC/KVM rescue system")
 			fmt.Println("  --password     Use password-based authentication instead of SSH keys")
+			fmt.Println("  --key <name>   Authorize only this SSH key; repeat for several (default: all keys)")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
 		osType := "linux"
 		usePassword := false
@@ -276,72 +435,79 @@ func handleServerCommand(ctx context.Context, client *hrobot.Client) error {
 				osType = "vkvm"
 			}
 		}
-		return enhanceAuthError(activateRescue(ctx, client, hrobot.ServerID(serverID), osType, usePassword))
+		return enhanceAuthError(activateRescue(ctx, client, serverID, osType, usePassword, parseFlagStringSlice(os.Args, "--key")))
 
 	case "disable-rescue":
 		if isHelpRequested() || len(os.Args) < 4 {
 			fmt.Printf("Usage: %s server disable-rescue <server-id>\n\n", os.Args[0])
 			fmt.Println("Disable rescue system for a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number")
+			fmt.Println("  <server-id>    The server number, name or IP")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(deactivateRescue(ctx, client, hrobot.ServerID(serverID)))
+		return enhanceAuthError(deactivateRescue(ctx, client, serverID))
 
 	case "traffic":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s server traffic <server-id> [--days <n>] [--from <date>] [--to <date>]\n\n", os.Args[0])
+			fmt.Printf("Usage: %s server traffic <server-id> [--days <n>] [--from <date>] [--to <date>] [--month <YYYY-MM>] [--ip <address>] [--summary] [--output json]\n\n", os.Args[0])
 			fmt.Println("Show traffic statistics for a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number")
+			fmt.Println("  <server-id>      The server number, name or IP")
 			fmt.Println("\nFlags:")
-			fmt.Println("  --days <n>     Number of days to show (default: 14)")
-			fmt.Println("  --from <date>  Start date in YYYY-MM-DD format")
-			fmt.Println("  --to <date>    End date in YYYY-MM-DD format")
-			fmt.Println("\nNote: If --from and --to are specified, --days is ignored.")
+			fmt.Println("  --days <n>       Number of days to show (default: 14)")
+			fmt.Println("  --from <date>    Start date in YYYY-MM-DD format")
+			fmt.Println("  --to <date>      End date in YYYY-MM-DD format")
+			fmt.Println("  --month <month>  Show the totals of a single month (YYYY-MM)")
+			fmt.Println("  --ip <address>   Only show traffic of this IP (default: all server IPs)")
+			fmt.Println("  --summary        Only show the totals for the period")
+			fmt.Println("  --output json    Output per-day traffic and totals as JSON")
+			fmt.Println("\nNote: If --from and --to are specified, --days is ignored. With only --from")
+			fmt.Println("or only --to, the period spans --days days from or up to that date.")
+			fmt.Println("--month cannot be combined with --days, --from or --to.")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(showTraffic(ctx, client, hrobot.ServerID(serverID), os.Args[4:]))
+		return enhanceAuthError(showTraffic(ctx, client, serverID, os.Args[4:]))
 
 	case "images":
 		if isHelpRequested() || len(os.Args) < 4 {
 			fmt.Printf("Usage: %s server images <server-id>\n\n", os.Args[0])
 			fmt.Println("Show boot/image configuration for a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number")
+			fmt.Println("  <server-id>    The server number, name or IP")
 			printGlobalFlags()
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(getBootConfig(ctx, client, hrobot.ServerID(serverID)))
+		return enhanceAuthError(getBootConfig(ctx, client, serverID))
 
 	case "install":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s server install <server-id> --linux=<distribution> [--lang=<language>] [--yes]\n", os.Args[0])
+			fmt.Printf("Usage: %s server install <server-id> --linux=<distribution> [--lang=<language>] [--key <name>...] [--yes]\n", os.Args[0])
 			fmt.Printf("       %s server install <server-id> --vnc=<distribution> [--lang=<language>] [--yes]\n\n", os.Args[0])
 			fmt.Println("Install an operating system on a server.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>         The server number")
+			fmt.Println("  <server-id>         The server number, name or IP")
 			fmt.Println("\nFlags:")
 			fmt.Println("  --linux=<dist>      Install Linux distribution (e.g., --linux=ubuntu, --linux=debian)")
 			fmt.Println("  --vnc=<dist>        Install via VNC (e.g., --vnc=centos)")
 			fmt.Println("  --lang=<language>   Language code (default: en for Linux, en_US for VNC)")
+			fmt.Println("  --key <name>        Authorize only this SSH key with --linux; repeat for several (default: all keys)")
 			fmt.Println("  --yes               Skip confirmation prompt")
 			fmt.Println("\nNote: The distribution name will be matched to the newest available version.")
 			fmt.Println("      WARNING: This will format all drives on the server!")
@@ -349,18 +515,49 @@ func handleServerCommand(ctx context.Context, client *hrobot.Client) error {
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
-		return enhanceAuthError(installOS(ctx, client, hrobot.ServerID(serverID), os.Args[4:]))
+		return enhanceAuthError(installOS(ctx, client, serverID, os.Args[4:]))
+
+	case "reinstall":
+		if isHelpRequested() || len(os.Args) < 4 {
+			fmt.Printf("Usage: %s server reinstall <server-id> --linux=<distribution> [--lang=<language>] [--key <name>...] [--yes] [--wait] [--wait-timeout <duration>]\n\n", os.Args[0])
+			fmt.Println("Install Linux on a server and reboot it into the installer.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  <server-id>         The server number, name or IP")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --linux=<dist>      Linux distribution to install (e.g., --linux=ubuntu, --linux=debian)")
+			fmt.Println("  --lang=<language>   Language code (default: en)")
+			fmt.Println("  --key <name>        Authorize only this SSH key; repeat for several (default: all keys)")
+			fmt.Println("  --yes               Skip confirmation prompt")
+			fmt.Println("  --wait              Wait until the installation has finished")
+			fmt.Println("  --wait-timeout      Give up waiting after this long, e.g. 20m (default: 30m)")
+			fmt.Println("\nNote: The distribution name will be matched to the newest available version.")
+			fmt.Println("      WARNING: This will format all drives on the server!")
+			printGlobalFlags()
+			return nil
+		}
+		serverIDStr := os.Args[3]
+		serverID, err := parseServerID(ctx, client, serverIDStr)
+		if err != nil {
+			return err
+		}
+		timeout, err := parseWaitTimeout(os.Args)
+		if err != nil {
+			return err
+		}
+		return enhanceAuthError(reinstallServer(ctx, client, serverID,
+			parseFlagString(os.Args, "--linux"), parseFlagString(os.Args, "--lang"), parseFlagStringSlice(os.Args, "--key"),
+			parseFlagBool(os.Args, "--yes"), parseFlagBool(os.Args, "--wait"), timeout))
 
 	case "ssh":
 		if isHelpRequested() || len(os.Args) < 4 {
 			fmt.Printf("Usage: %s server ssh <server-id> [--user <username>]\n\n", os.Args[0])
 			fmt.Println("SSH into a server with automatic firewall configuration.")
 			fmt.Println("\nArguments:")
-			fmt.Println("  <server-id>    The server number to SSH into")
+			fmt.Println("  <server-id>    The server number, name or IP to SSH into")
 			fmt.Println("\nFlags:")
 			fmt.Println("  --user         Username to SSH as (default: root)")
 			fmt.Println("\nBehavior:")
@@ -373,18 +570,18 @@ func handleServerCommand(ctx context.Context, client *hrobot.Client) error {
 			return nil
 		}
 		serverIDStr := os.Args[3]
-		serverID, err := strconv.Atoi(serverIDStr)
+		serverID, err := parseServerID(ctx, client, serverIDStr)
 		if err != nil {
-			return fmt.Errorf("invalid server ID: %s", serverIDStr)
+			return err
 		}
 		user := parseFlagString(os.Args, "--user")
 		if user == "" {
 			user = "root"
 		}
-		return enhanceAuthError(sshToServer(ctx, client, hrobot.ServerID(serverID), user))
+		return enhanceAuthError(sshToServer(ctx, client, serverID, user))
 
 	default:
-		return fmt.Errorf("unknown server subcommand: %s\nSubcommands:\n  list              - List all servers\n  describe <id>     - Describe server details by ID\n  reboot <id>       - Reboot server (hardware reset)\n  shutdown <id>     - Shutdown server\n  poweron <id>      - Power on server\n  poweroff <id>     - Power off server\n  wake <id>         - Wake server via WoL\n  enable-rescue <id> - Enable rescue system\n  disable-rescue <id> - Disable rescue system\n  traffic <id>      - Show traffic statistics\n  images <id>       - Show boot/image configuration\n  install <id>      - Install operating system on server\n  ssh <id>          - SSH into server with auto firewall config", subcommand)
+		return fmt.Errorf("unknown server subcommand: %s\nSubcommands:\n  list              - List all servers\n  describe <id>     - Describe server details by ID\n  reboot <id>       - Reboot server (hardware reset)\n  shutdown <id>     - Shutdown server\n  reset-history <id> - Show resets executed with hrobot\n  poweron <id>      - Power on server\n  poweroff <id>     - Power off server\n  wake <id>         - Wake server via WoL\n  enable-rescue <id> - Enable rescue system\n  disable-rescue <id> - Disable rescue system\n  traffic <id>      - Show traffic statistics\n  images <id>       - Show boot/image configuration\n  install <id>      - Install operating system on server\n  reinstall <id>    - Install Linux and reboot into the installer\n  ssh <id>          - SSH into server with auto firewall config", subcommand)
 	}
 }
 
@@ -395,53 +592,75 @@ func handleFirewallCommand(ctx context.Context, client *hrobot.Client) error {
 		return nil
 	}
 
+	waitTimeout, err := parseWaitTimeout(os.Args)
+	if err != nil {
+		return err
+	}
+	opts := firewallOptions{
+		keepMailBlock:  parseFlagBool(os.Args, "--keep-mail-block"),
+		waitTimeout:    waitTimeout,
+		checkUnchanged: parseFlagBool(os.Args, "--check-unchanged"),
+	}
+
 	subcommand := os.Args[2]
 	switch subcommand {
 	// Phase 1: Convenience commands
 	case "allow-ssh":
-		return handleAllowSSH(ctx, client)
+		return handleAllowSSH(ctx, client, opts)
 
 	case "allow-https":
-		return handleAllowHTTPS(ctx, client)
+		return handleAllowHTTPS(ctx, client, opts)
 
 	case "allow-mosh":
-		return handleAllowMOSH(ctx, client)
+		return handleAllowMOSH(ctx, client, opts)
 
 	case "allow-all":
-		return handleAllowAll(ctx, client)
+		return handleAllowAll(ctx, client, opts)
 
 	case "block-http":
-		return handleBlockHTTP(ctx, client)
+		return handleBlockHTTP(ctx, client, opts)
 
 	case "harden":
-		return handleHarden(ctx, client)
+		return handleHarden(ctx, client, opts)
+
+	case "harden-all":
+		return handleHardenAll(ctx, client, opts)
 
 	// Phase 2: Granular rule management
 	case "add-rule":
-		return handleAddRule(ctx, client)
+		return handleAddRule(ctx, client, opts)
 
 	case "delete-rule":
-		return handleDeleteRule(ctx, client)
+		return handleDeleteRule(ctx, client, opts)
+
+	case "delete-group":
+		return handleDeleteGroup(ctx, client, opts)
 
 	case "list-rules":
 		return handleListRules(ctx, client)
 
+	case "export":
+		return handleExportFirewall(ctx, client)
+
+	case "replace":
+		return handleReplaceRules(ctx, client, opts)
+
 	// Phase 3: Template management
 	case "template":
 		return handleTemplateCommand(ctx, client)
 
 	// Phase 4: Status management
 	case "enable":
-		return handleEnableFirewall(ctx, client)
+		return handleEnableFirewall(ctx, client, opts)
 
 	case "disable":
-		return handleDisableFirewall(ctx, client)
+		return handleDisableFirewall(ctx, client, opts)
 
 	case "status":
 		return handleFirewallStatus(ctx, client)
 
 	case "wait":
-		return handleWaitFirewall(ctx, client)
+		return handleWaitFirewall(ctx, client, opts)
 
 	case "reset":
 		return handleResetFirewall(ctx, client)
@@ -468,13 +687,29 @@ func printFirewallHelp() {
 	fmt.Println("      block insecure HTTP (port 80)")
 	fmt.Println("  harden <server-id> --block-http")
 	fmt.Println("      apply common security hardening")
+	fmt.Println("  harden-all --block-http [--concurrency N]")
+	fmt.Println("      apply common security hardening to every server")
 	fmt.Println("\nRule Management:")
 	fmt.Println("  add-rule <server-id> --direction <in|out> --protocol <proto> [options]")
 	fmt.Println("      add a firewall rule")
-	fmt.Println("  delete-rule <server-id> --name <name> | --index <n> [--direction <in|out>]")
+	fmt.Println("  delete-rule <server-id> --name <name> | --index <n> | --protocol/--port/--source-ip/--action [--direction <in|out>]")
 	fmt.Println("      delete a firewall rule")
+	fmt.Println("  delete-group <server-id> <label>")
+	fmt.Println("      delete all rules added with --group <label>")
 	fmt.Println("  list-rules <server-id> [--direction <in|out>] [--output json]")
 	fmt.Println("      list firewall rules")
+	fmt.Println("  export <server-id> [--format hcl]")
+	fmt.Println("      print the firewall as an hrobot_firewall Terraform resource")
+	fmt.Println("  replace <server-id> --rules-file <file|->")
+	fmt.Println("      replace all rules with the rules from a file")
+	fmt.Println("\nConvenience and rule management commands accept --dry-run to preview")
+	fmt.Println("the resulting rules (including skipped duplicates) without applying them.")
+	fmt.Println("\nHetzner's auto-added \"Block mail ports\" rule is removed when rules are")
+	fmt.Println("updated; pass --keep-mail-block to keep it in the submitted configuration.")
+	fmt.Println("\nCommands that change rules wait while the firewall is \"in process\".")
+	fmt.Println("Pass --wait-timeout <duration> (e.g. 5m) to give up after that long.")
+	fmt.Println("\nPass --check-unchanged to re-read the firewall right before writing and")
+	fmt.Println("abort if it was changed in the meantime, e.g. by a concurrent hrobot run.")
 	fmt.Println("\nTemplate Management:")
 	fmt.Println("  template list [--output json]")
 	fmt.Println("      list firewall templates")
@@ -484,6 +719,10 @@ func printFirewallHelp() {
 	fmt.Println("      apply template to server")
 	fmt.Println("  template create --name <name> [--from-server <id> | --rules-file <file>]")
 	fmt.Println("      create a new template")
+	fmt.Println("  template update <template-id> [--name <name>] [--rules-file <file>] [--whitelist-hos] [--filter-ipv6]")
+	fmt.Println("      update a template in place")
+	fmt.Println("  template set-default <template-id>")
+	fmt.Println("      mark a template as the account default")
 	fmt.Println("  template delete <template-id> --confirm")
 	fmt.Println("      delete a template")
 	fmt.Println("\nStatus Management:")
@@ -493,18 +732,147 @@ func printFirewallHelp() {
 	fmt.Println("      disable firewall")
 	fmt.Println("  status <server-id>")
 	fmt.Println("      show firewall status")
-	fmt.Println("  wait <server-id>")
+	fmt.Println("  wait <server-id> [--wait-timeout <duration>]")
 	fmt.Println("      wait for firewall to be ready")
-	fmt.Println("  reset <server-id> --confirm")
+	fmt.Println("  wait --all [--timeout <duration>] [--concurrency N]")
+	fmt.Println("      wait for the firewalls of all servers to be ready")
+	fmt.Println("  reset <server-id> [--yes]")
 	fmt.Println("      reset firewall (delete all rules)")
 }
 
-func parseServerID(s string) (hrobot.ServerID, error) {
-	id, err := strconv.Atoi(s)
+// serverListCache, serverCache and keyListCache memoize Server.List,
+// Server.Get and Key.List per client so that multi-step commands, and
+// resolving several server names or IPs, fetch each of them only once.
+var (
+	cacheMu         sync.Mutex
+	serverListCache = map[*hrobot.Client][]hrobot.Server{}
+	serverCache     = map[*hrobot.Client]map[hrobot.ServerID]*hrobot.Server{}
+	keyListCache    = map[*hrobot.Client][]hrobot.SSHKey{}
+)
+
+// listServersCached returns the server list, fetching it at most once per client.
+func listServersCached(ctx context.Context, client *hrobot.Client) ([]hrobot.Server, error) {
+	cacheMu.Lock()
+	servers, ok := serverListCache[client]
+	cacheMu.Unlock()
+	if ok {
+		return servers, nil
+	}
+
+	servers, err := client.Server.List(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("invalid server ID: %s", s)
+		return nil, err
 	}
-	return hrobot.ServerID(id), nil
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	serverListCache[client] = servers
+	return servers, nil
+}
+
+// getServerCached returns the server, fetching it at most once per client.
+func getServerCached(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) (*hrobot.Server, error) {
+	cacheMu.Lock()
+	server, ok := serverCache[client][serverID]
+	cacheMu.Unlock()
+	if ok {
+		return server, nil
+	}
+
+	server, err := client.Server.Get(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if serverCache[client] == nil {
+		serverCache[client] = map[hrobot.ServerID]*hrobot.Server{}
+	}
+	serverCache[client][serverID] = server
+	return server, nil
+}
+
+// listKeysCached returns the SSH keys of the account, fetching them at most once per client.
+func listKeysCached(ctx context.Context, client *hrobot.Client) ([]hrobot.SSHKey, error) {
+	cacheMu.Lock()
+	keys, ok := keyListCache[client]
+	cacheMu.Unlock()
+	if ok {
+		return keys, nil
+	}
+
+	keys, err := client.Key.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	keyListCache[client] = keys
+	return keys, nil
+}
+
+// parseServerID resolves a <server-id> argument. It accepts a server number,
+// a server name or the primary IPv4 address of a server. Names and IPs are
+// resolved against the (cached) server list.
+func parseServerID(ctx context.Context, client *hrobot.Client, s string) (hrobot.ServerID, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		return hrobot.ServerID(id), nil
+	}
+
+	servers, err := listServersCached(ctx, client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve server %q: %w", s, err)
+	}
+
+	var matches []hrobot.Server
+	if ip := net.ParseIP(s); ip != nil {
+		for _, server := range servers {
+			if server.ServerIP.Equal(ip) {
+				matches = append(matches, server)
+			}
+		}
+	} else {
+		matches = hrobot.ServersNamed(servers, s)
+	}
+
+	switch len(matches) {
+	case 1:
+		return hrobot.ServerID(matches[0].ServerNumber), nil
+	case 0:
+		msg := fmt.Sprintf("invalid server ID: no server with number, name or IP %q", s)
+		if near := serverNearMatches(servers, s); len(near) > 0 {
+			msg += "\n\nDid you mean:\n" + formatServerCandidates(near)
+		} else {
+			msg += "\n\nRun 'hrobot server list' to see all servers"
+		}
+		return 0, errors.New(msg)
+	default:
+		return 0, fmt.Errorf("server %q is ambiguous, use the server number instead:\n%s", s, formatServerCandidates(matches))
+	}
+}
+
+// serverNearMatches returns servers whose name or IP contains s (case-insensitive).
+func serverNearMatches(servers []hrobot.Server, s string) []hrobot.Server {
+	needle := strings.ToLower(s)
+	var near []hrobot.Server
+	for _, server := range servers {
+		if strings.Contains(strings.ToLower(server.ServerName), needle) ||
+			(server.ServerIP != nil && strings.Contains(server.ServerIP.String(), needle)) {
+			near = append(near, server)
+		}
+	}
+	return near
+}
+
+// formatServerCandidates renders servers as an indented "number  name (ip)" list.
+func formatServerCandidates(servers []hrobot.Server) string {
+	lines := make([]string, len(servers))
+	for i, server := range servers {
+		lines[i] = fmt.Sprintf("  %d  %s (%s)", server.ServerNumber, server.ServerName, server.ServerIP)
+	}
+	return strings.Join(lines, "\n")
 }
 
 func parseFlagStringSlice(args []string, flag string) []string {
@@ -537,6 +905,49 @@ func parseFlagStringSlice(args []string, flag string) []string {
 	return results
 }
 
+// removeFlagString removes a string flag and its value from args and returns
+// the value and the remaining arguments.
+func removeFlagString(args []string, flag string) (string, []string) {
+	value := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, flag+"="):
+			value = strings.Trim(strings.TrimPrefix(arg, flag+"="), "'\"")
+		case arg == flag && i+1 < len(args):
+			value = args[i+1]
+			i++
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return value, remaining
+}
+
+// clientOptions returns the client options for the global flags. The base
+// URL comes from --base-url or HROBOT_BASE_URL and defaults to the
+// production API.
+func clientOptions(verbose bool, baseURL string) ([]hrobot.ClientOption, error) {
+	var opts []hrobot.ClientOption
+	if verbose {
+		opts = append(opts, hrobot.WithDebug(true))
+	}
+
+	if baseURL == "" {
+		baseURL = os.Getenv("HROBOT_BASE_URL")
+	}
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid base URL: %s (expected e.g. https://robot-ws.your-server.de)", baseURL)
+		}
+		opts = append(opts, hrobot.WithBaseURL(baseURL))
+	}
+
+	return opts, nil
+}
+
 func parseFlagString(args []string, flag string) string {
 	for i, arg := range args {
 		// Support both --flag=value and --flag value formats
@@ -582,42 +993,81 @@ func parseFlagBool(args []string, flag string) bool {
 	return false
 }
 
+// parseFlagOptionalBool parses a boolean flag that may be absent. It returns
+// nil when the flag is not given, so that --flag=false can be told apart from
+// leaving the setting unchanged.
+func parseFlagOptionalBool(args []string, flag string) *bool {
+	for _, arg := range args {
+		if arg == flag {
+			value := true
+			return &value
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			value := parseFlagBool([]string{arg}, flag)
+			return &value
+		}
+	}
+	return nil
+}
+
 // Phase 1 command handlers.
-func handleAllowSSH(ctx context.Context, client *hrobot.Client) error {
+func handleAllowSSH(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall allow-ssh <server-id> --source-ips <ips> | --my-ip\n\n", os.Args[0])
 		fmt.Println("allow SSH access from specific IPs")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --source-ips   Comma-separated list of IPs/CIDRs")
 		fmt.Println("  --my-ip        Use your current public IP")
+		fmt.Println("  --group        Label prefixed to the rule names, e.g. \"office VPN\"")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
 	sourceIPs := parseFlagStringSlice(os.Args, "--source-ips")
 	myIP := parseFlagBool(os.Args, "--my-ip")
+	group := parseFlagString(os.Args, "--group")
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
+	progress := firewallProgress(jsonOutput)
+
+	ips, err := resolveSourceIPs(progress, sourceIPs, myIP)
+	if err != nil {
+		return err
+	}
 
-	return enhanceAuthError(allowSSH(ctx, client, serverID, sourceIPs, myIP))
+	info, err := allowSSH(ctx, client, progress, serverID, ips, group, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printAllowSSHResult(w, info, ips) })
 }
 
-func handleAllowHTTPS(ctx context.Context, client *hrobot.Client) error {
+func handleAllowHTTPS(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall allow-https <server-id> --source-ips <ips>\n\n", os.Args[0])
 		fmt.Println("allow HTTPS access from specific IPs (supports IPv6)")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --source-ips   Comma-separated list of IPs/CIDRs (IPv4 or IPv6)")
+		fmt.Println("  --group        Label prefixed to the rule names, e.g. \"office VPN\"")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
@@ -626,19 +1076,33 @@ func handleAllowHTTPS(ctx context.Context, client *hrobot.Client) error {
 	if len(sourceIPs) == 0 {
 		return fmt.Errorf("--source-ips is required")
 	}
+	group := parseFlagString(os.Args, "--group")
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
 
-	return enhanceAuthError(allowHTTPS(ctx, client, serverID, sourceIPs))
+	info, err := allowHTTPS(ctx, client, firewallProgress(jsonOutput), serverID, sourceIPs, group, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printAllowHTTPSResult(w, info, sourceIPs) })
 }
 
-func handleAllowMOSH(ctx context.Context, client *hrobot.Client) error {
+func handleAllowMOSH(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall allow-mosh <server-id> --source-ips <ips> | --my-ip\n\n", os.Args[0])
 		fmt.Println("allow MOSH access from specific IPs")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --source-ips   Comma-separated list of IPs/CIDRs")
 		fmt.Println("  --my-ip        Use your current public IP")
+		fmt.Println("  --group        Label prefixed to the rule names, e.g. \"office VPN\"")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		fmt.Println("\nCreates 3 rules per IP:")
 		fmt.Println("  • SSH (TCP port 22)")
 		fmt.Println("  • MOSH (UDP ports 60000-61000)")
@@ -646,100 +1110,187 @@ func handleAllowMOSH(ctx context.Context, client *hrobot.Client) error {
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
 	sourceIPs := parseFlagStringSlice(os.Args, "--source-ips")
 	myIP := parseFlagBool(os.Args, "--my-ip")
+	group := parseFlagString(os.Args, "--group")
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
+	progress := firewallProgress(jsonOutput)
 
-	return enhanceAuthError(allowMOSH(ctx, client, serverID, sourceIPs, myIP))
+	ips, err := resolveSourceIPs(progress, sourceIPs, myIP)
+	if err != nil {
+		return err
+	}
+
+	info, err := allowMOSH(ctx, client, progress, serverID, ips, group, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printAllowMOSHResult(w, info, ips) })
 }
 
-func handleAllowAll(ctx context.Context, client *hrobot.Client) error {
+func handleAllowAll(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall allow-all <server-id> --source-ips <ips> | --my-ip\n\n", os.Args[0])
 		fmt.Println("allow access to all ports from specific IPs")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --source-ips   Comma-separated list of IPs/CIDRs")
 		fmt.Println("  --my-ip        Use your current public IP")
+		fmt.Println("  --group        Label prefixed to the rule names, e.g. \"office VPN\"")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		fmt.Println("\nWarning: This creates a rule allowing ALL traffic from the specified IP(s).")
 		fmt.Println("         Use only for fully trusted sources.")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
 	sourceIPs := parseFlagStringSlice(os.Args, "--source-ips")
 	myIP := parseFlagBool(os.Args, "--my-ip")
+	group := parseFlagString(os.Args, "--group")
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
+	progress := firewallProgress(jsonOutput)
+
+	ips, err := resolveSourceIPs(progress, sourceIPs, myIP)
+	if err != nil {
+		return err
+	}
 
-	return enhanceAuthError(allowAll(ctx, client, serverID, sourceIPs, myIP))
+	info, err := allowAll(ctx, client, progress, serverID, ips, group, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printAllowAllResult(w, info, ips) })
 }
 
-func handleBlockHTTP(ctx context.Context, client *hrobot.Client) error {
+func handleBlockHTTP(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
-		fmt.Printf("Usage: %s firewall block-http <server-id>\n\n", os.Args[0])
+		fmt.Printf("Usage: %s firewall block-http <server-id> [--dry-run]\n\n", os.Args[0])
 		fmt.Println("block insecure HTTP (port 80)")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
+		fmt.Println("\nFlags:")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
-	return enhanceAuthError(blockHTTP(ctx, client, serverID))
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
+
+	info, err := blockHTTP(ctx, client, firewallProgress(jsonOutput), serverID, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printBlockHTTPResult(w, info) })
 }
 
-func handleHarden(ctx context.Context, client *hrobot.Client) error {
+func handleHarden(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall harden <server-id> --block-http\n\n", os.Args[0])
 		fmt.Println("apply common security hardening")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --block-http   Block insecure HTTP")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
 	blockHTTPFlag := parseFlagBool(os.Args, "--block-http")
+	dryRun := parseFlagBool(os.Args, "--dry-run")
 
-	return enhanceAuthError(hardenFirewall(ctx, client, serverID, blockHTTPFlag))
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
+
+	info, err := hardenFirewall(ctx, client, firewallProgress(jsonOutput), serverID, blockHTTPFlag, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printHardenResult(w, info) })
+}
+
+func handleHardenAll(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
+	if isHelpRequested() {
+		fmt.Printf("Usage: %s firewall harden-all --block-http [--concurrency N]\n\n", os.Args[0])
+		fmt.Println("apply common security hardening to every server in the account")
+		fmt.Println("\nServers that are already hardened are skipped. Failures do not stop the")
+		fmt.Println("remaining servers; the command exits non-zero if any server failed.")
+		fmt.Println("\nFlags:")
+		fmt.Println("  --block-http       Block insecure HTTP")
+		fmt.Printf("  --concurrency N    Number of servers updated in parallel (default: %d)\n", defaultHardenConcurrency)
+		fmt.Println("  --dry-run          Show what would change without applying it")
+		printGlobalFlags()
+		return nil
+	}
+
+	blockHTTPFlag := parseFlagBool(os.Args, "--block-http")
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+	concurrency := defaultHardenConcurrency
+	if parseFlagString(os.Args, "--concurrency") != "" {
+		concurrency = parseFlagInt(os.Args, "--concurrency")
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency must be a positive number")
+		}
+	}
+
+	return enhanceAuthError(hardenAllServers(ctx, client, blockHTTPFlag, concurrency, dryRun, opts))
 }
 
 // Phase 2 command handlers.
-func handleAddRule(ctx context.Context, client *hrobot.Client) error {
+func handleAddRule(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall add-rule <server-id> --direction <in|out> --protocol <proto> [options]\n\n", os.Args[0])
 		fmt.Println("add a firewall rule")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>       The server number")
+		fmt.Println("  <server-id>       The server number, name or IP")
 		fmt.Println("\nRequired Flags:")
 		fmt.Println("  --direction       in or out")
 		fmt.Println("  --protocol        tcp, udp, icmp, esp, or gre")
 		fmt.Println("\nOptional Flags:")
 		fmt.Println("  --source-ips      Comma-separated source IPs (for direction=in)")
 		fmt.Println("  --destination-ips Comma-separated dest IPs (for direction=out)")
-		fmt.Println("  --port            Port or port range (required for tcp/udp)")
+		fmt.Println("  --port            Port or port range (tcp/udp only, required inbound)")
+		fmt.Println("  --source-port     Source port or port range (tcp/udp only)")
 		fmt.Println("  --action          accept or discard (default: accept)")
 		fmt.Println("  --name            Rule name")
+		fmt.Println("  --group           Label prefixed to the rule name, e.g. \"office VPN\"")
+		fmt.Println("  --dry-run         Show the resulting rules without applying them")
+		fmt.Println("  --output          Output format: json")
+		fmt.Println("\nNote: icmp rules match all ICMP types; the Robot API cannot filter by type.")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
@@ -749,8 +1300,10 @@ func handleAddRule(ctx context.Context, client *hrobot.Client) error {
 	action := parseFlagString(os.Args, "--action")
 	name := parseFlagString(os.Args, "--name")
 	port := parseFlagString(os.Args, "--port")
+	sourcePort := parseFlagString(os.Args, "--source-port")
 	sourceIPs := parseFlagStringSlice(os.Args, "--source-ips")
 	destIPs := parseFlagStringSlice(os.Args, "--destination-ips")
+	dryRun := parseFlagBool(os.Args, "--dry-run")
 
 	if direction == "" {
 		return fmt.Errorf("--direction is required")
@@ -758,27 +1311,48 @@ func handleAddRule(ctx context.Context, client *hrobot.Client) error {
 	if protocol == "" {
 		return fmt.Errorf("--protocol is required")
 	}
+	if err := validateICMPType(protocol, parseFlagString(os.Args, "--icmp-type")); err != nil {
+		return err
+	}
 	if name == "" {
 		name = fmt.Sprintf("custom %s rule", protocol)
 	}
+	group := parseFlagString(os.Args, "--group")
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	name = groupRuleName(group, name)
+
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
 
-	return enhanceAuthError(addRule(ctx, client, serverID, direction, protocol, action, name, sourceIPs, destIPs, port))
+	info, err := addRule(ctx, client, firewallProgress(jsonOutput), serverID, direction, protocol, action, name, sourceIPs, destIPs, sourcePort, port, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printAddRuleResult(w, info, direction) })
 }
 
-func handleDeleteRule(ctx context.Context, client *hrobot.Client) error {
+func handleDeleteRule(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
-		fmt.Printf("Usage: %s firewall delete-rule <server-id> --name <name> | --index <n> [--direction <in|out>]\n\n", os.Args[0])
+		fmt.Printf("Usage: %s firewall delete-rule <server-id> --name <name> | --index <n> | <matchers> [--direction <in|out>]\n\n", os.Args[0])
 		fmt.Println("delete a firewall rule")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --name         Rule name to delete")
 		fmt.Println("  --index        Rule index to delete")
 		fmt.Println("  --direction    in or out (default: in)")
+		fmt.Println("\nMatchers (delete all rules matching every given value):")
+		fmt.Println("  --protocol     tcp, udp, icmp, esp, or gre")
+		fmt.Println("  --port         Destination port or port range, e.g. 22 or 60000-61000")
+		fmt.Println("  --source-ip    Source IP or CIDR")
+		fmt.Println("  --action       accept or discard")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		fmt.Println("  --output       Output format: json")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
@@ -786,8 +1360,43 @@ func handleDeleteRule(ctx context.Context, client *hrobot.Client) error {
 	name := parseFlagString(os.Args, "--name")
 	index := parseFlagInt(os.Args, "--index")
 	direction := parseFlagString(os.Args, "--direction")
+	dryRun := parseFlagBool(os.Args, "--dry-run")
+	matcher := ruleMatcher{
+		Protocol: parseFlagString(os.Args, "--protocol"),
+		Port:     parseFlagString(os.Args, "--port"),
+		SourceIP: parseFlagString(os.Args, "--source-ip"),
+		Action:   parseFlagString(os.Args, "--action"),
+	}
+
+	jsonOutput := parseFlagString(os.Args, "--output") == "json"
+
+	info, err := deleteRule(ctx, client, firewallProgress(jsonOutput), serverID, name, index, matcher, direction, dryRun, opts)
+	if err != nil {
+		return enhanceAuthError(err)
+	}
+	return printRuleChange(info, jsonOutput, func(w io.Writer) { printDeleteRuleResult(w, info, direction) })
+}
+
+func handleDeleteGroup(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
+	if len(os.Args) < 5 {
+		fmt.Printf("Usage: %s firewall delete-group <server-id> <label> [--dry-run]\n\n", os.Args[0])
+		fmt.Println("delete all input and output rules in a group")
+		fmt.Println("\nArguments:")
+		fmt.Println("  <server-id>    The server number, name or IP")
+		fmt.Println("  <label>        Group label used with --group when adding the rules")
+		fmt.Println("\nFlags:")
+		fmt.Println("  --dry-run      Show the resulting rules without applying them")
+		return nil
+	}
+
+	serverID, err := parseServerID(ctx, client, os.Args[3])
+	if err != nil {
+		return err
+	}
+
+	dryRun := parseFlagBool(os.Args, "--dry-run")
 
-	return enhanceAuthError(deleteRule(ctx, client, serverID, name, index, direction))
+	return enhanceAuthError(deleteGroup(ctx, client, serverID, os.Args[4], dryRun, opts))
 }
 
 func handleListRules(ctx context.Context, client *hrobot.Client) error {
@@ -795,14 +1404,14 @@ func handleListRules(ctx context.Context, client *hrobot.Client) error {
 		fmt.Printf("Usage: %s firewall list-rules <server-id> [--direction <in|out>] [--output json]\n\n", os.Args[0])
 		fmt.Println("list firewall rules")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --direction    Filter by direction (in or out)")
 		fmt.Println("  --output       Output format (json)")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
@@ -813,6 +1422,27 @@ func handleListRules(ctx context.Context, client *hrobot.Client) error {
 	return enhanceAuthError(listRules(ctx, client, serverID, direction, outputFormat))
 }
 
+func handleExportFirewall(ctx context.Context, client *hrobot.Client) error {
+	if len(os.Args) < 4 {
+		fmt.Printf("Usage: %s firewall export <server-id> [--format hcl]\n\n", os.Args[0])
+		fmt.Println("print the live firewall as an hrobot_firewall Terraform resource")
+		fmt.Println("\nArguments:")
+		fmt.Println("  <server-id>    The server number, name or IP")
+		fmt.Println("\nFlags:")
+		fmt.Println("  --format       Output format (hcl, default)")
+		return nil
+	}
+
+	serverID, err := parseServerID(ctx, client, os.Args[3])
+	if err != nil {
+		return err
+	}
+
+	format := parseFlagString(os.Args, "--format")
+
+	return enhanceAuthError(exportFirewall(ctx, client, serverID, format))
+}
+
 // Phase 3 template command handlers.
 func handleTemplateCommand(ctx context.Context, client *hrobot.Client) error {
 	if len(os.Args) < 4 {
@@ -822,6 +1452,8 @@ func handleTemplateCommand(ctx context.Context, client *hrobot.Client) error {
 		fmt.Println("  describe <template-id> [--output json]")
 		fmt.Println("  apply <server-id> <template-id>")
 		fmt.Println("  create --name <name> [options]")
+		fmt.Println("  update <template-id> [options]")
+		fmt.Println("  set-default <template-id>")
 		fmt.Println("  delete <template-id> --confirm")
 		return nil
 	}
@@ -849,7 +1481,7 @@ func handleTemplateCommand(ctx context.Context, client *hrobot.Client) error {
 			fmt.Printf("Usage: %s firewall template apply <server-id> <template-id>\n", os.Args[0])
 			return nil
 		}
-		serverID, err := parseServerID(os.Args[4])
+		serverID, err := parseServerID(ctx, client, os.Args[4])
 		if err != nil {
 			return err
 		}
@@ -871,6 +1503,45 @@ func handleTemplateCommand(ctx context.Context, client *hrobot.Client) error {
 
 		return enhanceAuthError(createTemplate(ctx, client, name, hrobot.ServerID(fromServerID), rulesFile, whitelistHOS, filterIPv6))
 
+	case "update":
+		if isHelpRequested() || len(os.Args) < 5 {
+			fmt.Printf("Usage: %s firewall template update <template-id> [--name <name>] [--rules-file <file|->] [--whitelist-hos[=false]] [--filter-ipv6[=false]]\n\n", os.Args[0])
+			fmt.Println("Update a template in place, keeping its ID. Settings that are not given are kept.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  <template-id>    The template to update")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --name           New template name")
+			fmt.Println("  --rules-file     JSON file whose rules replace the template rules, or '-' for stdin")
+			fmt.Println("  --whitelist-hos  Whitelist Hetzner services (--whitelist-hos=false to disable)")
+			fmt.Println("  --filter-ipv6    Filter IPv6 traffic (--filter-ipv6=false to disable)")
+			printGlobalFlags()
+			return nil
+		}
+		templateID, err := strconv.Atoi(os.Args[4])
+		if err != nil {
+			return fmt.Errorf("invalid template ID: %s", os.Args[4])
+		}
+		name := parseFlagString(os.Args, "--name")
+		rulesFile := parseFlagString(os.Args, "--rules-file")
+		whitelistHOS := parseFlagOptionalBool(os.Args, "--whitelist-hos")
+		filterIPv6 := parseFlagOptionalBool(os.Args, "--filter-ipv6")
+		return enhanceAuthError(updateTemplate(ctx, client, templateID, name, rulesFile, whitelistHOS, filterIPv6))
+
+	case "set-default":
+		if isHelpRequested() || len(os.Args) < 5 {
+			fmt.Printf("Usage: %s firewall template set-default <template-id>\n\n", os.Args[0])
+			fmt.Println("Mark a template as the account default template.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  <template-id>    The template to mark as default")
+			printGlobalFlags()
+			return nil
+		}
+		templateID, err := strconv.Atoi(os.Args[4])
+		if err != nil {
+			return fmt.Errorf("invalid template ID: %s", os.Args[4])
+		}
+		return enhanceAuthError(setDefaultTemplate(ctx, client, templateID))
+
 	case "delete":
 		if len(os.Args) < 5 {
 			fmt.Printf("Usage: %s firewall template delete <template-id> --confirm\n", os.Args[0])
@@ -888,19 +1559,45 @@ func handleTemplateCommand(ctx context.Context, client *hrobot.Client) error {
 	}
 }
 
+func handleReplaceRules(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
+	if len(os.Args) < 4 {
+		fmt.Printf("Usage: %s firewall replace <server-id> --rules-file <file|->\n\n", os.Args[0])
+		fmt.Println("replace all firewall rules with the rules from a file")
+		fmt.Println("\nArguments:")
+		fmt.Println("  <server-id>    The server number, name or IP")
+		fmt.Println("\nFlags:")
+		fmt.Println("  --rules-file   JSON file with input and output rules, or '-' for stdin")
+		fmt.Println("\nThe file uses the same format as template create --rules-file, so the")
+		fmt.Println("output of 'list-rules --output json' can be edited and applied again.")
+		return nil
+	}
+
+	serverID, err := parseServerID(ctx, client, os.Args[3])
+	if err != nil {
+		return err
+	}
+
+	rulesFile := parseFlagString(os.Args, "--rules-file")
+	if rulesFile == "" {
+		return fmt.Errorf("--rules-file is required")
+	}
+
+	return enhanceAuthError(replaceFirewall(ctx, client, serverID, rulesFile, opts))
+}
+
 // Phase 4 status management command handlers.
-func handleEnableFirewall(ctx context.Context, client *hrobot.Client) error {
+func handleEnableFirewall(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall enable <server-id> [--filter-ipv6=true|false]\n\n", os.Args[0])
 		fmt.Println("enable firewall")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
 		fmt.Println("  --filter-ipv6=true|false    Enable or disable IPv6 filtering (optional)")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
@@ -916,24 +1613,24 @@ func handleEnableFirewall(ctx context.Context, client *hrobot.Client) error {
 		filterIPv6 = &val
 	}
 
-	return enhanceAuthError(enableFirewall(ctx, client, serverID, filterIPv6))
+	return enhanceAuthError(enableFirewall(ctx, client, serverID, filterIPv6, opts))
 }
 
-func handleDisableFirewall(ctx context.Context, client *hrobot.Client) error {
+func handleDisableFirewall(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
 	if len(os.Args) < 4 {
 		fmt.Printf("Usage: %s firewall disable <server-id>\n\n", os.Args[0])
 		fmt.Println("disable firewall")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
-	return enhanceAuthError(disableFirewall(ctx, client, serverID))
+	return enhanceAuthError(disableFirewall(ctx, client, serverID, opts))
 }
 
 func handleFirewallStatus(ctx context.Context, client *hrobot.Client) error {
@@ -941,11 +1638,11 @@ func handleFirewallStatus(ctx context.Context, client *hrobot.Client) error {
 		fmt.Printf("Usage: %s firewall status <server-id>\n\n", os.Args[0])
 		fmt.Println("show firewall status")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
@@ -953,42 +1650,64 @@ func handleFirewallStatus(ctx context.Context, client *hrobot.Client) error {
 	return enhanceAuthError(getFirewallStatus(ctx, client, serverID))
 }
 
-func handleWaitFirewall(ctx context.Context, client *hrobot.Client) error {
-	if len(os.Args) < 4 {
-		fmt.Printf("Usage: %s firewall wait <server-id>\n\n", os.Args[0])
+func handleWaitFirewall(ctx context.Context, client *hrobot.Client, opts firewallOptions) error {
+	if len(os.Args) < 4 || isHelpRequested() {
+		fmt.Printf("Usage: %s firewall wait <server-id> [--wait-timeout <duration>]\n", os.Args[0])
+		fmt.Printf("       %s firewall wait --all [--timeout <duration>] [--concurrency N]\n\n", os.Args[0])
 		fmt.Println("wait for firewall to be ready")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>      The server number, name or IP")
+		fmt.Println("\nFlags:")
+		fmt.Println("  --wait-timeout   Give up after this long, e.g. 90s or 5m (default: no limit)")
+		fmt.Println("  --all            Wait for the firewalls of all servers in the account")
+		fmt.Println("  --timeout        With --all: give up after this long and list the firewalls still in process")
+		fmt.Println("                   (the global --timeout; --wait-timeout is used if it is not set)")
+		fmt.Printf("  --concurrency N  With --all: number of firewalls polled in parallel (default: %d)\n", defaultWaitAllConcurrency)
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	if parseFlagBool(os.Args, "--all") {
+		timeout := commandTimeout
+		if timeout == 0 {
+			timeout = opts.waitTimeout
+		}
+		concurrency := defaultWaitAllConcurrency
+		if parseFlagString(os.Args, "--concurrency") != "" {
+			concurrency = parseFlagInt(os.Args, "--concurrency")
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be a positive number")
+			}
+		}
+		return enhanceAuthError(waitForAllFirewalls(ctx, client, timeout, concurrency))
+	}
+
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
-	return enhanceAuthError(waitForFirewall(ctx, client, serverID))
+	return enhanceAuthError(waitForFirewall(ctx, client, serverID, opts.waitTimeout))
 }
 
 func handleResetFirewall(ctx context.Context, client *hrobot.Client) error {
 	if len(os.Args) < 4 {
-		fmt.Printf("Usage: %s firewall reset <server-id> --confirm\n\n", os.Args[0])
+		fmt.Printf("Usage: %s firewall reset <server-id> [--yes]\n\n", os.Args[0])
 		fmt.Println("reset firewall (delete all rules)")
 		fmt.Println("\nArguments:")
-		fmt.Println("  <server-id>    The server number")
+		fmt.Println("  <server-id>    The server number, name or IP")
 		fmt.Println("\nFlags:")
-		fmt.Println("  --confirm      Required confirmation flag")
+		fmt.Println("  --yes          Skip confirmation prompt (alias: --confirm)")
 		return nil
 	}
 
-	serverID, err := parseServerID(os.Args[3])
+	serverID, err := parseServerID(ctx, client, os.Args[3])
 	if err != nil {
 		return err
 	}
 
-	confirm := parseFlagBool(os.Args, "--confirm")
+	skipConfirmation := parseFlagBool(os.Args, "--yes") || parseFlagBool(os.Args, "--confirm")
 
-	return enhanceAuthError(resetFirewall(ctx, client, serverID, confirm))
+	return enhanceAuthError(resetFirewall(ctx, client, serverID, skipConfirmation))
 }
 
 // handleSSHKeyCommand handles all ssh-key-related subcommands.
@@ -1062,17 +1781,34 @@ func handleSSHKeyCommand(ctx context.Context, client *hrobot.Client) error {
 // handleRDNSCommand handles all rdns-related subcommands.
 func handleRDNSCommand(ctx context.Context, client *hrobot.Client) error {
 	if len(os.Args) < 3 {
-		return fmt.Errorf("usage: %s rdns <subcommand>\nSubcommands:\n  list [server-ip]        - List all reverse DNS entries\n  describe <ip>           - Describe reverse DNS entry for an IP\n  set <ip> <ptr>          - Set reverse DNS entry for an IP\n  reset <ip>              - Reset reverse DNS entry to default", os.Args[0])
+		return fmt.Errorf("usage: %s rdns <subcommand>\nSubcommands:\n  list [server-ip]        - List all reverse DNS entries sorted by IP\n  describe <ip>           - Describe reverse DNS entry for an IP\n  set <ip> <ptr>          - Set reverse DNS entry for an IP\n  reset <ip>              - Reset reverse DNS entry to default\n  reset-bulk <subnet>     - Reset all reverse DNS entries in a subnet", os.Args[0])
 	}
 
 	subcommand := os.Args[2]
 	switch subcommand {
 	case "list":
+		if isHelpRequested() {
+			fmt.Printf("Usage: %s rdns list [server-ip] [--concurrency N]\n\n", os.Args[0])
+			fmt.Println("List reverse DNS entries sorted by IP address.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  [server-ip]        Only list the entries of this server")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --concurrency N    Fetch the entries per server with N parallel requests")
+			printGlobalFlags()
+			return nil
+		}
 		serverIP := ""
-		if len(os.Args) > 3 {
+		if len(os.Args) > 3 && !strings.HasPrefix(os.Args[3], "--") {
 			serverIP = os.Args[3]
 		}
-		return enhanceAuthError(listRDNS(ctx, client, serverIP))
+		concurrency := 0
+		if parseFlagString(os.Args, "--concurrency") != "" {
+			concurrency = parseFlagInt(os.Args, "--concurrency")
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be a positive number")
+			}
+		}
+		return enhanceAuthError(listRDNS(ctx, client, serverIP, concurrency))
 
 	case "describe":
 		if isHelpRequested() || len(os.Args) < 4 {
@@ -1088,17 +1824,20 @@ func handleRDNSCommand(ctx context.Context, client *hrobot.Client) error {
 
 	case "set":
 		if isHelpRequested() || len(os.Args) < 5 {
-			fmt.Printf("Usage: %s rdns set <ip> <ptr>\n\n", os.Args[0])
+			fmt.Printf("Usage: %s rdns set <ip> <ptr> [--force]\n\n", os.Args[0])
 			fmt.Println("Set reverse DNS entry for an IP address.")
 			fmt.Println("\nArguments:")
 			fmt.Println("  <ip>     The IP address to configure")
-			fmt.Println("  <ptr>    The PTR record value (hostname)")
+			fmt.Println("  <ptr>    The PTR record value (fully qualified hostname)")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --force  Skip the hostname validation and forward DNS check")
 			printGlobalFlags()
 			return nil
 		}
 		ip := os.Args[3]
 		ptr := os.Args[4]
-		return enhanceAuthError(setRDNS(ctx, client, ip, ptr))
+		force := parseFlagBool(os.Args, "--force")
+		return enhanceAuthError(setRDNS(ctx, client, ip, ptr, force))
 
 	case "reset":
 		if isHelpRequested() || len(os.Args) < 4 {
@@ -1112,8 +1851,22 @@ func handleRDNSCommand(ctx context.Context, client *hrobot.Client) error {
 		ip := os.Args[3]
 		return enhanceAuthError(deleteRDNS(ctx, client, ip))
 
+	case "reset-bulk":
+		if isHelpRequested() || len(os.Args) < 4 {
+			fmt.Printf("Usage: %s rdns reset-bulk <subnet> [--confirm]\n\n", os.Args[0])
+			fmt.Println("Reset all reverse DNS entries in a subnet to the default Hetzner value.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  <subnet>    The subnet in CIDR notation (e.g., 123.123.123.0/29)")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --confirm   Skip confirmation prompt (alias: --yes)")
+			printGlobalFlags()
+			return nil
+		}
+		skipConfirmation := parseFlagBool(os.Args, "--confirm") || parseFlagBool(os.Args, "--yes")
+		return enhanceAuthError(resetRDNSSubnet(ctx, client, os.Args[3], skipConfirmation))
+
 	default:
-		return fmt.Errorf("unknown rdns subcommand: %s\nSubcommands:\n  list [server-ip]        - List all reverse DNS entries\n  describe <ip>           - Describe reverse DNS entry for an IP\n  set <ip> <ptr>          - Set reverse DNS entry for an IP\n  reset <ip>              - Reset reverse DNS entry to default", subcommand)
+		return fmt.Errorf("unknown rdns subcommand: %s\nSubcommands:\n  list [server-ip]        - List all reverse DNS entries sorted by IP\n  describe <ip>           - Describe reverse DNS entry for an IP\n  set <ip> <ptr>          - Set reverse DNS entry for an IP\n  reset <ip>              - Reset reverse DNS entry to default\n  reset-bulk <subnet>     - Reset all reverse DNS entries in a subnet", subcommand)
 	}
 }
 
@@ -1174,20 +1927,38 @@ func handleFailoverCommand(ctx context.Context, client *hrobot.Client) error {
 // handleVSwitchCommand handles all vswitch-related subcommands.
 func handleVSwitchCommand(ctx context.Context, client *hrobot.Client) error {
 	if len(os.Args) < 3 {
-		return fmt.Errorf("usage: %s vswitch <subcommand>\nSubcommands:\n  list                          - List all vSwitches\n  describe <id>                 - Describe vSwitch details\n  create <name> <vlan>          - Create a new vSwitch\n  update <id> <name> <vlan>     - Update vSwitch name and VLAN\n  delete <id> [--immediate]     - Cancel a vSwitch\n  add-server <id> <ip> [...]    - Add server(s) to vSwitch\n  remove-server <id> <ip> [...] - Remove server(s) from vSwitch", os.Args[0])
+		return fmt.Errorf("usage: %s vswitch <subcommand>\nSubcommands:\n  list [--server <number>]      - List all vSwitches\n  describe <id>                 - Describe vSwitch details\n  create <name> <vlan>          - Create a new vSwitch\n  update <id> <name> <vlan>     - Update vSwitch name and VLAN\n  delete <id> [--immediate]     - Cancel a vSwitch\n  add-server <id> <ip> [...]    - Add server(s) to vSwitch\n  remove-server <id> <ip> [...] - Remove server(s) from vSwitch", os.Args[0])
 	}
 
 	subcommand := os.Args[2]
 	switch subcommand {
 	case "list":
+		if isHelpRequested() {
+			fmt.Printf("Usage: %s vswitch list [--server <number>]\n\n", os.Args[0])
+			fmt.Println("List all vSwitches, or the vSwitches a server is connected to.")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --server <number>   Only list vSwitches the server is connected to")
+			printGlobalFlags()
+			return nil
+		}
+
+		if serverFlag := parseFlagString(os.Args[3:], "--server"); serverFlag != "" {
+			serverNumber, err := strconv.Atoi(serverFlag)
+			if err != nil || serverNumber <= 0 {
+				return fmt.Errorf("invalid --server value: %s (expected a server number)", serverFlag)
+			}
+			return enhanceAuthError(listVSwitchesForServer(ctx, client, serverNumber))
+		}
 		return enhanceAuthError(listVSwitches(ctx, client))
 
 	case "describe":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s vswitch describe <id>\n\n", os.Args[0])
-			fmt.Println("Describe vSwitch details.")
+			fmt.Printf("Usage: %s vswitch describe <id> [--output json]\n\n", os.Args[0])
+			fmt.Println("Describe vSwitch details, including its servers and subnets.")
 			fmt.Println("\nArguments:")
 			fmt.Println("  <id>    The vSwitch ID")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --output json    Output in JSON format")
 			printGlobalFlags()
 			return nil
 		}
@@ -1195,7 +1966,8 @@ func handleVSwitchCommand(ctx context.Context, client *hrobot.Client) error {
 		if err != nil {
 			return fmt.Errorf("invalid vSwitch ID: %s", os.Args[3])
 		}
-		return enhanceAuthError(getVSwitch(ctx, client, id))
+		outputFormat := parseFlagString(os.Args, "--output")
+		return enhanceAuthError(getVSwitch(ctx, client, id, outputFormat))
 
 	case "create":
 		if isHelpRequested() || len(os.Args) < 5 {
@@ -1295,86 +2067,69 @@ func handleVSwitchCommand(ctx context.Context, client *hrobot.Client) error {
 		return enhanceAuthError(removeServersFromVSwitch(ctx, client, id, servers))
 
 	default:
-		return fmt.Errorf("unknown vswitch subcommand: %s\nSubcommands:\n  list                          - List all vSwitches\n  describe <id>                 - Describe vSwitch details\n  create <name> <vlan>          - Create a new vSwitch\n  update <id> <name> <vlan>     - Update vSwitch name and VLAN\n  delete <id> [--immediate]     - Cancel a vSwitch\n  add-server <id> <ip> [...]    - Add server(s) to vSwitch\n  remove-server <id> <ip> [...] - Remove server(s) from vSwitch", subcommand)
+		return fmt.Errorf("unknown vswitch subcommand: %s\nSubcommands:\n  list [--server <number>]      - List all vSwitches\n  describe <id>                 - Describe vSwitch details\n  create <name> <vlan>          - Create a new vSwitch\n  update <id> <name> <vlan>     - Update vSwitch name and VLAN\n  delete <id> [--immediate]     - Cancel a vSwitch\n  add-server <id> <ip> [...]    - Add server(s) to vSwitch\n  remove-server <id> <ip> [...] - Remove server(s) from vSwitch", subcommand)
 	}
 }
 
 // handleAuctionCommand handles all auction-related subcommands.
 func handleAuctionCommand(ctx context.Context, client *hrobot.Client) error {
 	if len(os.Args) < 3 {
-		return fmt.Errorf("usage: %s auction <subcommand>\nSubcommands:\n  list                 - List available auction servers\n  describe <server-id> - Show details about a specific auction server\n  order <product-id>   - Order a server from auction", os.Args[0])
+		return fmt.Errorf("usage: %s auction <subcommand>\nSubcommands:\n  list                 - List available auction servers\n  describe <server-id> - Show details about a specific auction server\n  order <product-id>   - Order a server from auction\n  order-best           - Order the cheapest server matching filters\n  wait <transaction-id> - Wait for an auction order to complete", os.Args[0])
 	}
 
 	subcommand := os.Args[2]
 	switch subcommand {
 	case "list":
 		if isHelpRequested() {
-			fmt.Printf("Usage: %s auction list [--location=<location>] [--memory-min=<gb>] [--cpu=<type>] [--cpu-benchmark-min=<score>] [--disk-space-min=<gb>] [--price-max=<euros>] [--gpu]\n\n", os.Args[0])
+			fmt.Printf("Usage: %s auction list [--location=<location>] [--datacenter=<dc>] [--memory-min=<gb>] [--cpu=<type>] [--cpu-benchmark-min=<score>] [--disk-space-min=<gb>] [--price-max=<euros>] [--gpu] [--ecc] [--memory-type=<type>] [--disk-type=<type>] [--sort=<key>] [--desc] [--prices=<net|gross|both>] [--output=csv|json]\n\n", os.Args[0])
 			fmt.Println("List available auction servers with optional filters.")
 			fmt.Println("\nFlags:")
-			fmt.Println("  --location=<loc>            Filter by location (e.g., HEL, FSN, NBG)")
+			fmt.Println("  --location=<loc>            Filter by location prefix (e.g., HEL, FSN1)")
+			fmt.Println("  --datacenter=<dc>           Filter by exact datacenter (e.g., FSN1-DC14)")
 			fmt.Println("  --memory-min=<gb>           Minimum memory in GB (e.g., 128)")
 			fmt.Println("  --cpu=<type>                Filter by CPU vendor (amd or intel)")
 			fmt.Println("  --cpu-benchmark-min=<score> Minimum CPU benchmark score (e.g., 10000)")
 			fmt.Println("  --disk-space-min=<gb>       Minimum disk space in GB (e.g., 7000)")
 			fmt.Println("  --price-max=<euros>         Maximum monthly price in euros (e.g., 200)")
 			fmt.Println("  --gpu                       Show only servers with GPU")
+			fmt.Println("  --ecc                       Show only servers with ECC memory")
+			fmt.Println("  --memory-type=<type>        Filter by memory type (ddr4 or ddr5)")
+			fmt.Println("  --disk-type=<type>          Filter by disk technology (nvme, sata or hdd)")
+			fmt.Println("  --sort=<key>                Sort by price, memory, benchmark or disk")
+			fmt.Println("  --desc                      Sort in descending order")
+			fmt.Println("  --prices=<net|gross|both>   Prices to show (default: net)")
+			fmt.Println("  --output=<csv|json>         Output as CSV or JSON instead of a table")
 			printGlobalFlags()
 			return nil
 		}
 
-		// Parse filter flags
-		var location string
-		var memoryMin float64
-		var cpu string
-		var cpuBenchmarkMin uint32
-		var diskSpaceMin float64
-		var priceMax float64
-		var gpuOnly bool
-
-		for i := 3; i < len(os.Args); i++ {
-			arg := os.Args[i]
-			if arg == "--gpu" {
-				gpuOnly = true
-			} else if len(arg) > 11 && arg[:11] == "--location=" {
-				location = arg[11:]
-			} else if len(arg) > 13 && arg[:13] == "--memory-min=" {
-				val, err := strconv.ParseFloat(arg[13:], 64)
-				if err != nil {
-					return fmt.Errorf("invalid memory-min value: %s", arg[13:])
-				}
-				memoryMin = val
-			} else if len(arg) > 6 && arg[:6] == "--cpu=" {
-				cpu = arg[6:]
-			} else if len(arg) > 20 && arg[:20] == "--cpu-benchmark-min=" {
-				val, err := strconv.ParseUint(arg[20:], 10, 32)
-				if err != nil {
-					return fmt.Errorf("invalid cpu-benchmark-min value: %s", arg[20:])
-				}
-				cpuBenchmarkMin = uint32(val)
-			} else if len(arg) > 17 && arg[:17] == "--disk-space-min=" {
-				val, err := strconv.ParseFloat(arg[17:], 64)
-				if err != nil {
-					return fmt.Errorf("invalid disk-space-min value: %s", arg[17:])
-				}
-				diskSpaceMin = val
-			} else if len(arg) > 12 && arg[:12] == "--price-max=" {
-				val, err := strconv.ParseFloat(arg[12:], 64)
-				if err != nil {
-					return fmt.Errorf("invalid price-max value: %s", arg[12:])
-				}
-				priceMax = val
-			}
+		filter, err := parseAuctionFilterFlags(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		order, err := parseListSort(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		prices, err := parsePriceMode(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		outputFormat, err := parseListOutput(os.Args[3:])
+		if err != nil {
+			return err
 		}
 
-		return enhanceOrderingAuthError(ctx, client, listAuctionServers(ctx, client, location, memoryMin, cpu, cpuBenchmarkMin, diskSpaceMin, priceMax, gpuOnly))
+		return enhanceOrderingAuthError(ctx, client, listAuctionServers(ctx, client, filter, order, prices, outputFormat))
 
 	case "describe":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s auction describe <server-id>\n\n", os.Args[0])
+			fmt.Printf("Usage: %s auction describe <server-id> [--prices=<net|gross|both>]\n\n", os.Args[0])
 			fmt.Println("Show detailed information about a specific auction server.")
 			fmt.Println("\nArguments:")
 			fmt.Println("  <server-id>   The auction server ID")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --prices=<net|gross|both>   Prices to show (default: net)")
 			printGlobalFlags()
 			return nil
 		}
@@ -1382,18 +2137,27 @@ func handleAuctionCommand(ctx context.Context, client *hrobot.Client) error {
 		if err != nil {
 			return fmt.Errorf("invalid server ID: %s", os.Args[3])
 		}
-		return describeAuctionServer(ctx, client, uint32(serverID))
+		prices, err := parsePriceMode(os.Args[4:])
+		if err != nil {
+			return err
+		}
+		return describeAuctionServer(ctx, client, uint32(serverID), prices)
 
 	case "order":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s auction order <product-id> [<ssh-key-name>] [--yes] [--test]\n\n", os.Args[0])
+			fmt.Printf("Usage: %s auction order <product-id> [<ssh-key-name> | --ssh-keys <names> | --password[=<password>] [--no-keys]] [--image <dist>] [--lang <code>] [--comment <text>] [--wait [--wait-timeout <duration>] [--quiet]] [--yes] [--test] [--max-price=<euros>]\n\n", os.Args[0])
 			fmt.Println("Order a server from the auction marketplace.")
 			fmt.Println("\nArguments:")
 			fmt.Println("  <product-id>      The auction server product ID")
 			fmt.Println("  <ssh-key-name>    Optional: specific SSH key to use (default: all keys)")
 			fmt.Println("\nFlags:")
+			fmt.Println(orderAuthFlagsHelp)
+			fmt.Println(orderImageFlagsHelp)
+			fmt.Println("  --comment <text>  Comment for Hetzner; the order is then processed manually")
+			fmt.Println(orderWaitFlagsHelp)
 			fmt.Println("  --yes             Skip confirmation prompt")
 			fmt.Println("  --test            Test mode - does not actually place the order")
+			fmt.Println("  --max-price=<eur> Abort if the current monthly price (excl. VAT) is higher")
 			printGlobalFlags()
 			return nil
 		}
@@ -1402,48 +2166,140 @@ func handleAuctionCommand(ctx context.Context, client *hrobot.Client) error {
 			return fmt.Errorf("invalid product ID: %s", os.Args[3])
 		}
 
-		var sshKeyFingerprints []string
-		var sshKeyName string
+		authFlags, args := removeOrderAuthFlags(os.Args)
+		image, args := removeOrderImageFlags(args)
+		var keyName string
+		comment, args := removeFlagString(args, "--comment")
+		wait, args, err := removeOrderWaitFlags(args)
+		if err != nil {
+			return err
+		}
 		testMode := false
 		skipConfirmation := false
+		var maxPrice float64
 
-		for i := 4; i < len(os.Args); i++ {
-			arg := os.Args[i]
-			switch arg {
-			case "--test":
+		for i := 4; i < len(args); i++ {
+			arg := args[i]
+			switch {
+			case arg == "--test":
 				testMode = true
-			case "--yes":
+			case arg == "--yes":
 				skipConfirmation = true
+			case arg == "--max-price" || strings.HasPrefix(arg, "--max-price="):
+				value := strings.TrimPrefix(arg, "--max-price=")
+				if arg == "--max-price" {
+					if i+1 >= len(args) {
+						return fmt.Errorf("--max-price requires a value")
+					}
+					i++
+					value = args[i]
+				}
+				val, err := strconv.ParseFloat(value, 64)
+				if err != nil || val <= 0 {
+					return fmt.Errorf("invalid max-price value: %s", value)
+				}
+				maxPrice = val
 			default:
-				if sshKeyName == "" {
-					sshKeyName = arg
+				if keyName == "" {
+					keyName = arg
 				}
 			}
 		}
 
-		if sshKeyName != "" {
-			fingerprint, err := findKeyFingerprintByName(ctx, client, sshKeyName)
-			if err != nil {
-				return err
-			}
-			sshKeyFingerprints = []string{fingerprint}
-		} else {
-			keys, err := client.Key.List(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to list SSH keys: %w", err)
-			}
-			if len(keys) == 0 {
-				return fmt.Errorf("no SSH keys found in your account. Please create at least one SSH key first")
-			}
-			for _, key := range keys {
-				sshKeyFingerprints = append(sshKeyFingerprints, key.Fingerprint)
+		if keyName != "" {
+			authFlags.KeyNames = append([]string{keyName}, authFlags.KeyNames...)
+		}
+
+		auth, err := orderAuth(ctx, client, authFlags)
+		if err != nil {
+			return err
+		}
+
+		return enhanceOrderingAuthError(ctx, client, orderMarketServer(ctx, client, uint32(productID), auth, image, comment, testMode, skipConfirmation, maxPrice, wait))
+
+	case "order-best":
+		if isHelpRequested() {
+			fmt.Printf("Usage: %s auction order-best [filters] [--max-price=<euros>] [--ssh-key=<name> | --ssh-keys <names> | --password[=<password>] [--no-keys]] [--image <dist>] [--lang <code>] [--wait [--wait-timeout <duration>] [--quiet]] [--yes] [--test]\n\n", os.Args[0])
+			fmt.Println("Order the cheapest auction server matching the given filters.")
+			fmt.Println("\nFilters:")
+			fmt.Println("  --location=<loc>            Filter by location prefix (e.g., HEL, FSN1)")
+			fmt.Println("  --datacenter=<dc>           Filter by exact datacenter (e.g., FSN1-DC14)")
+			fmt.Println("  --memory-min=<gb>           Minimum memory in GB (e.g., 128)")
+			fmt.Println("  --cpu=<type>                Filter by CPU vendor (amd or intel)")
+			fmt.Println("  --cpu-benchmark-min=<score> Minimum CPU benchmark score (e.g., 10000)")
+			fmt.Println("  --disk-space-min=<gb>       Minimum disk space in GB (e.g., 7000)")
+			fmt.Println("  --gpu                       Only servers with GPU")
+			fmt.Println("  --ecc                       Only servers with ECC memory")
+			fmt.Println("  --memory-type=<type>        Filter by memory type (ddr4 or ddr5)")
+			fmt.Println("  --disk-type=<type>          Filter by disk technology (nvme, sata or hdd)")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --max-price=<euros>         Maximum monthly price (excl. VAT), checked again before ordering")
+			fmt.Println("  --ssh-key=<name>            SSH key to use (default: all keys)")
+			fmt.Println("  --ssh-keys <names>          Comma-separated names of the SSH keys to use")
+			fmt.Println("  --password[=<password>]     Log in with a root password instead of SSH keys; prompts")
+			fmt.Println("                              without a value, which keeps it out of the shell history")
+			fmt.Println("  --no-keys                   Do not authorize any SSH key (requires --password)")
+			fmt.Println("  --image <dist>              Install this distribution (default: boot into the rescue system)")
+			fmt.Println("  --lang <code>               Language of the distribution (default: en)")
+			fmt.Println("  --wait                      Wait until the server is ready and show its number and IP")
+			fmt.Println("  --wait-timeout <duration>   Give up waiting after this long, e.g. 2h (default: no limit)")
+			fmt.Println("  --quiet                     Do not show progress while waiting")
+			fmt.Println("  --yes                       Skip confirmation prompt")
+			fmt.Println("  --test                      Test mode - does not actually place the order")
+			fmt.Println("\nExample:")
+			fmt.Printf("  %s auction order-best --cpu amd --memory-min 128 --location HEL --max-price 100\n", os.Args[0])
+			printGlobalFlags()
+			return nil
+		}
+
+		filter, err := parseAuctionFilterFlags(os.Args[3:])
+		if err != nil {
+			return err
+		}
+
+		var maxPrice float64
+		if v := parseFlagString(os.Args, "--max-price"); v != "" {
+			maxPrice, err = strconv.ParseFloat(v, 64)
+			if err != nil || maxPrice <= 0 {
+				return fmt.Errorf("invalid max-price value: %s", v)
 			}
 		}
 
-		return enhanceOrderingAuthError(ctx, client, orderMarketServer(ctx, client, uint32(productID), sshKeyFingerprints, testMode, skipConfirmation))
+		authFlags, _ := removeOrderAuthFlags(os.Args)
+		if name := parseFlagString(os.Args, "--ssh-key"); name != "" {
+			authFlags.KeyNames = append(authFlags.KeyNames, name)
+		}
+		auth, err := orderAuth(ctx, client, authFlags)
+		if err != nil {
+			return err
+		}
+
+		image, _ := removeOrderImageFlags(os.Args)
+		wait, _, err := removeOrderWaitFlags(os.Args)
+		if err != nil {
+			return err
+		}
+		testMode := parseFlagBool(os.Args, "--test")
+		skipConfirmation := parseFlagBool(os.Args, "--yes")
+
+		return enhanceOrderingAuthError(ctx, client, orderBestAuctionServer(ctx, client, filter, auth, image, testMode, skipConfirmation, maxPrice, wait))
+
+	case "wait":
+		if isHelpRequested() || len(os.Args) < 4 {
+			fmt.Printf("Usage: %s auction wait <transaction-id> [--quiet]\n\n", os.Args[0])
+			fmt.Println("Wait for an auction order to complete and show the ordered server.")
+			fmt.Println("\nArguments:")
+			fmt.Println("  <transaction-id>  The transaction ID printed by 'auction order'")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --quiet           Do not show progress while waiting")
+			printGlobalFlags()
+			return nil
+		}
+		quiet := parseFlagBool(os.Args, "--quiet")
+		return enhanceOrderingAuthError(ctx, client, waitAuctionTransaction(ctx, client, os.Args[3], quiet))
 
 	default:
-		return fmt.Errorf("unknown auction subcommand: %s\nSubcommands:\n  list                 - List available auction servers\n  describe <server-id> - Show details about a specific auction server\n  order <product-id>   - Order a server from auction", subcommand)
+		return fmt.Errorf("unknown auction subcommand: %s\nSubcommands:\n  list                 - List available auction servers\n  describe <server-id> - Show details about a specific auction server\n  order <product-id>   - Order a server from auction\n  order-best           - Order the cheapest server matching filters\n  wait <transaction-id> - Wait for an auction order to complete", subcommand)
 	}
 }
 
@@ -1457,7 +2313,7 @@ func handleProductCommand(ctx context.Context, client *hrobot.Client) error {
 	switch subcommand {
 	case "list":
 		if isHelpRequested() {
-			fmt.Printf("Usage: %s product list [--location=<location>] [--memory-min=<gb>] [--cpu=<type>] [--cpu-benchmark-min=<score>] [--disk-space-min=<gb>] [--price-max=<euros>] [--gpu]\n\n", os.Args[0])
+			fmt.Printf("Usage: %s product list [--location=<location>] [--memory-min=<gb>] [--cpu=<type>] [--cpu-benchmark-min=<score>] [--disk-space-min=<gb>] [--price-max=<euros>] [--gpu] [--ecc] [--memory-type=<type>] [--disk-type=<type>] [--sort=<key>] [--desc] [--prices=<net|gross|both>] [--output=csv|json]\n\n", os.Args[0])
 			fmt.Println("List available product servers with optional filters.")
 			fmt.Println("\nFlags:")
 			fmt.Println("  --location=<loc>            Filter by location (e.g., HEL, FSN, NBG)")
@@ -1467,76 +2323,69 @@ func handleProductCommand(ctx context.Context, client *hrobot.Client) error {
 			fmt.Println("  --disk-space-min=<gb>       Minimum disk space in GB (e.g., 7000)")
 			fmt.Println("  --price-max=<euros>         Maximum monthly price in euros (e.g., 200)")
 			fmt.Println("  --gpu                       Show only servers with GPU")
+			fmt.Println("  --ecc                       Show only servers with ECC memory")
+			fmt.Println("  --memory-type=<type>        Filter by memory type (ddr4 or ddr5)")
+			fmt.Println("  --disk-type=<type>          Filter by disk technology (nvme, sata or hdd)")
+			fmt.Println("  --sort=<key>                Sort by price, memory or disk")
+			fmt.Println("  --desc                      Sort in descending order")
+			fmt.Println("  --prices=<net|gross|both>   Prices to show (default: net)")
+			fmt.Println("  --output=<csv|json>         Output as CSV or JSON instead of a table")
 			printGlobalFlags()
 			return nil
 		}
 
-		// Parse filter flags
-		var location string
-		var memoryMin float64
-		var cpu string
-		var cpuBenchmarkMin uint32
-		var diskSpaceMin float64
-		var priceMax float64
-		var gpuOnly bool
-
-		for i := 3; i < len(os.Args); i++ {
-			arg := os.Args[i]
-			if arg == "--gpu" {
-				gpuOnly = true
-			} else if len(arg) > 11 && arg[:11] == "--location=" {
-				location = arg[11:]
-			} else if len(arg) > 13 && arg[:13] == "--memory-min=" {
-				val, err := strconv.ParseFloat(arg[13:], 64)
-				if err != nil {
-					return fmt.Errorf("invalid memory-min value: %s", arg[13:])
-				}
-				memoryMin = val
-			} else if len(arg) > 6 && arg[:6] == "--cpu=" {
-				cpu = arg[6:]
-			} else if len(arg) > 20 && arg[:20] == "--cpu-benchmark-min=" {
-				val, err := strconv.ParseUint(arg[20:], 10, 32)
-				if err != nil {
-					return fmt.Errorf("invalid cpu-benchmark-min value: %s", arg[20:])
-				}
-				cpuBenchmarkMin = uint32(val)
-			} else if len(arg) > 17 && arg[:17] == "--disk-space-min=" {
-				val, err := strconv.ParseFloat(arg[17:], 64)
-				if err != nil {
-					return fmt.Errorf("invalid disk-space-min value: %s", arg[17:])
-				}
-				diskSpaceMin = val
-			} else if len(arg) > 12 && arg[:12] == "--price-max=" {
-				val, err := strconv.ParseFloat(arg[12:], 64)
-				if err != nil {
-					return fmt.Errorf("invalid price-max value: %s", arg[12:])
-				}
-				priceMax = val
-			}
+		filter, err := parseAuctionFilterFlags(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		order, err := parseListSort(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		prices, err := parsePriceMode(os.Args[3:])
+		if err != nil {
+			return err
+		}
+		outputFormat, err := parseListOutput(os.Args[3:])
+		if err != nil {
+			return err
 		}
 
-		return enhanceOrderingAuthError(ctx, client, listProducts(ctx, client, location, memoryMin, cpu, cpuBenchmarkMin, diskSpaceMin, priceMax, gpuOnly))
+		return enhanceOrderingAuthError(ctx, client, listProducts(ctx, client, filter, order, prices, outputFormat))
 
 	case "describe":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s product describe <product-id>\n\n", os.Args[0])
-			fmt.Println("Show detailed information about a specific product.")
+			fmt.Printf("Usage: %s product describe <product-id> [--prices=<net|gross|both>] [--output json]\n\n", os.Args[0])
+			fmt.Println("Show detailed information about a specific product, including pricing")
+			fmt.Println("and availability per location.")
 			fmt.Println("\nArguments:")
 			fmt.Println("  <product-id>   The product ID (e.g., EX44, AX41-NVMe)")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --prices=<net|gross|both>   Prices to show (default: net)")
+			fmt.Println("  --output                    Output format: json")
 			printGlobalFlags()
 			return nil
 		}
 		productID := os.Args[3]
-		return describeProduct(ctx, client, productID)
+		prices, err := parsePriceMode(os.Args[4:])
+		if err != nil {
+			return err
+		}
+		outputFormat := parseFlagString(os.Args, "--output")
+		return describeProduct(ctx, client, productID, prices, outputFormat)
 
 	case "order":
 		if isHelpRequested() || len(os.Args) < 4 {
-			fmt.Printf("Usage: %s product order <product-id> [<ssh-key-name>] [--location=<dc>] [--yes] [--test]\n\n", os.Args[0])
+			fmt.Printf("Usage: %s product order <product-id> [<ssh-key-name> | --ssh-keys <names> | --password[=<password>] [--no-keys]] [--location=<dc>] [--image <dist>] [--lang <code>] [--comment <text>] [--wait [--wait-timeout <duration>] [--quiet]] [--yes] [--test]\n\n", os.Args[0])
 			fmt.Println("Order a product server.")
 			fmt.Println("\nArguments:")
 			fmt.Println("  <product-id>      The product ID (e.g., EX44, AX41)")
 			fmt.Println("  <ssh-key-name>    Optional: specific SSH key to use (default: all keys)")
 			fmt.Println("\nFlags:")
+			fmt.Println(orderAuthFlagsHelp)
+			fmt.Println(orderImageFlagsHelp)
+			fmt.Println("  --comment <text>  Comment for Hetzner; the order is then processed manually")
+			fmt.Println(orderWaitFlagsHelp)
 			fmt.Println("  --location=<dc>   Data center location (e.g., FSN1, NBG1, HEL1)")
 			fmt.Println("                    If not specified, automatically selects location with shortest availability")
 			fmt.Println("  --yes             Skip confirmation prompt")
@@ -1546,55 +2395,76 @@ func handleProductCommand(ctx context.Context, client *hrobot.Client) error {
 		}
 		productID := os.Args[3]
 
-		var sshKeyFingerprints []string
-		var sshKeyName string
+		authFlags, args := removeOrderAuthFlags(os.Args)
+		image, args := removeOrderImageFlags(args)
+		var keyName string
+		comment, args := removeFlagString(args, "--comment")
+		wait, args, err := removeOrderWaitFlags(args)
+		if err != nil {
+			return err
+		}
 		var location string
 		testMode := false
 		skipConfirmation := false
 
-		for i := 4; i < len(os.Args); i++ {
-			arg := os.Args[i]
+		for i := 4; i < len(args); i++ {
+			arg := args[i]
 			if arg == "--test" {
 				testMode = true
 			} else if arg == "--yes" {
 				skipConfirmation = true
 			} else if len(arg) > 11 && arg[:11] == "--location=" {
 				location = arg[11:]
-			} else if sshKeyName == "" {
-				sshKeyName = arg
+			} else if keyName == "" {
+				keyName = arg
 			}
 		}
 
-		if sshKeyName != "" {
-			fingerprint, err := findKeyFingerprintByName(ctx, client, sshKeyName)
-			if err != nil {
-				return err
-			}
-			sshKeyFingerprints = []string{fingerprint}
-		} else {
-			keys, err := client.Key.List(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to list SSH keys: %w", err)
-			}
-			if len(keys) == 0 {
-				return fmt.Errorf("no SSH keys found in your account. Please create at least one SSH key first")
-			}
-			for _, key := range keys {
-				sshKeyFingerprints = append(sshKeyFingerprints, key.Fingerprint)
-			}
+		if keyName != "" {
+			authFlags.KeyNames = append([]string{keyName}, authFlags.KeyNames...)
 		}
 
-		return enhanceOrderingAuthError(ctx, client, orderProductServer(ctx, client, productID, location, sshKeyFingerprints, testMode, skipConfirmation))
+		auth, err := orderAuth(ctx, client, authFlags)
+		if err != nil {
+			return err
+		}
+
+		return enhanceOrderingAuthError(ctx, client, orderProductServer(ctx, client, productID, location, auth, image, comment, testMode, skipConfirmation, wait))
 
 	default:
 		return fmt.Errorf("unknown product subcommand: %s\nSubcommands:\n  list                  - List available product servers\n  describe <product-id> - Show details about a specific product\n  order <product-id>    - Order a product server", subcommand)
 	}
 }
 
+// handleAddonCommand handles all addon-related subcommands.
+func handleAddonCommand(ctx context.Context, client *hrobot.Client) error {
+	if len(os.Args) < 3 {
+		return fmt.Errorf("usage: %s addon <subcommand>\nSubcommands:\n  transactions          - List addon transactions from the last 30 days", os.Args[0])
+	}
+
+	subcommand := os.Args[2]
+	switch subcommand {
+	case "transactions":
+		if isHelpRequested() {
+			fmt.Printf("Usage: %s addon transactions [--output json]\n\n", os.Args[0])
+			fmt.Println("List addon transactions from the last 30 days, including assigned IPs and subnets.")
+			fmt.Println("\nFlags:")
+			fmt.Println("  --output json    Output in JSON format")
+			printGlobalFlags()
+			return nil
+		}
+		outputFormat := parseFlagString(os.Args, "--output")
+		return enhanceOrderingAuthError(ctx, client, listAddonTransactions(ctx, client, outputFormat))
+
+	default:
+		return fmt.Errorf("unknown addon subcommand: %s\nSubcommands:\n  transactions          - List addon transactions from the last 30 days", subcommand)
+	}
+}
+
 // handleContextCommand handles all context-related subcommands.
-func handleContextCommand() error {
+func handleContextCommand(ctx context.Context, baseURL string) error {
 	if len(os.Args) < 3 {
-		return fmt.Errorf("usage: %s context <subcommand>\nSubcommands:\n  list           - List all contexts\n  create <name>  - Create a new context\n  use <name>     - Switch to a context\n  active         - Show active context\n  delete <name>  - Delete a context", os.Args[0])
+		return fmt.Errorf("usage: %s context <subcommand>\nSubcommands:\n  list           - List all contexts\n  create <name>  - Create a new context\n  use <name>     - Switch to a context\n  active         - Show active context\n  delete <name>  - Delete a context\n  test <name>    - Check the credentials of a context", os.Args[0])
 	}
 
 	subcommand := os.Args[2]
@@ -1604,13 +2474,21 @@ func handleContextCommand() error {
 
 	case "create":
 		if len(os.Args) < 4 {
-			return fmt.Errorf("usage: %s context create <name> [--username <username>] [--password <password>]", os.Args[0])
+			return fmt.Errorf("usage: %s context create <name> [--username <username>] [--password <password>] [--force]", os.Args[0])
 		}
 		name := os.Args[3]
 
 		// Use centralized flag parsing that handles both --flag=value and --flag value
 		username := parseFlagString(os.Args, "--username")
 		password := parseFlagString(os.Args, "--password")
+		force := parseFlagBool(os.Args, "--force")
+
+		// Refuse a taken name before prompting for credentials
+		if !force {
+			if config, err := loadConfig(); err == nil && config.getContext(name) != nil {
+				return contextExistsError(name)
+			}
+		}
 
 		// Prompt for missing credentials
 		reader := bufio.NewReader(os.Stdin)
@@ -1639,7 +2517,7 @@ func handleContextCommand() error {
 			}
 		}
 
-		return createContext(name, username, password)
+		return createContext(name, username, password, force)
 
 	case "use":
 		if len(os.Args) < 4 {
@@ -1656,7 +2534,17 @@ func handleContextCommand() error {
 		}
 		return deleteContextCmd(os.Args[3])
 
+	case "test":
+		if len(os.Args) < 4 {
+			return fmt.Errorf("usage: %s context test <name>", os.Args[0])
+		}
+		clientOpts, err := clientOptions(parseFlagBool(os.Args, "--verbose"), baseURL)
+		if err != nil {
+			return err
+		}
+		return testContext(ctx, os.Args[3], clientOpts)
+
 	default:
-		return fmt.Errorf("unknown context subcommand: %s\nSubcommands:\n  list           - List all contexts\n  create <name>  - Create a new context\n  use <name>     - Switch to a context\n  active         - Show active context\n  delete <name>  - Delete a context", subcommand)
+		return fmt.Errorf("unknown context subcommand: %s\nSubcommands:\n  list           - List all contexts\n  create <name>  - Create a new context\n  use <name>     - Switch to a context\n  active         - Show active context\n  delete <name>  - Delete a context\n  test <name>    - Check the credentials of a context", subcommand)
 	}
 }
@@ -0,0 +1,413 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// newServerListClient returns a client backed by a fake /server endpoint and
+// a pointer to the number of list requests it has served.
+func newServerListClient(t *testing.T) (*hrobot.Client, *int) {
+	listCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/server" {
+			t.Errorf("expected path '/server', got '%s'", r.URL.Path)
+		}
+		listCalls++
+
+		servers := []struct {
+			number int
+			name   string
+			ip     string
+		}{
+			{321, "web-1", "123.123.123.123"},
+			{322, "web-2", "123.123.123.124"},
+			{400, "db", "124.124.124.124"},
+			{401, "db", "124.124.124.125"},
+		}
+		response := make([]map[string]interface{}, len(servers))
+		for i, s := range servers {
+			response[i] = map[string]interface{}{
+				"server": map[string]interface{}{
+					"server_ip":     s.ip,
+					"server_number": s.number,
+					"server_name":   s.name,
+					"status":        "ready",
+				},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL)), &listCalls
+}
+
+func TestParseServerID(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected hrobot.ServerID
+	}{
+		{name: "numeric ID", input: "321", expected: 321},
+		{name: "server name", input: "web-2", expected: 322},
+		{name: "primary IPv4", input: "124.124.124.124", expected: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newServerListClient(t)
+			result, err := parseServerID(context.Background(), client, tt.input)
+			if err != nil {
+				t.Fatalf("parseServerID(%s) returned error: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("parseServerID(%s) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseServerID_NumericSkipsLookup(t *testing.T) {
+	client, listCalls := newServerListClient(t)
+
+	if _, err := parseServerID(context.Background(), client, "999"); err != nil {
+		t.Fatalf("parseServerID returned error: %v", err)
+	}
+	if *listCalls != 0 {
+		t.Errorf("expected no server list request for numeric ID, got %d", *listCalls)
+	}
+}
+
+func TestParseServerID_CachesServerList(t *testing.T) {
+	client, listCalls := newServerListClient(t)
+	ctx := context.Background()
+
+	for _, input := range []string{"web-1", "web-2", "123.123.123.123"} {
+		if _, err := parseServerID(ctx, client, input); err != nil {
+			t.Fatalf("parseServerID(%s) returned error: %v", input, err)
+		}
+	}
+	if *listCalls != 1 {
+		t.Errorf("expected 1 server list request, got %d", *listCalls)
+	}
+}
+
+func TestParseServerID_NearMatches(t *testing.T) {
+	client, _ := newServerListClient(t)
+
+	_, err := parseServerID(context.Background(), client, "web")
+	if err == nil {
+		t.Fatal("expected error for unknown server, got nil")
+	}
+	errMsg := err.Error()
+	if !strings.Contains(errMsg, "Did you mean") {
+		t.Errorf("error message should suggest near matches, got: %s", errMsg)
+	}
+	if !strings.Contains(errMsg, "web-1") || !strings.Contains(errMsg, "web-2") {
+		t.Errorf("error message should list both web servers, got: %s", errMsg)
+	}
+	if strings.Contains(errMsg, "124.124.124.124") {
+		t.Errorf("error message should not list unrelated servers, got: %s", errMsg)
+	}
+}
+
+func TestParseServerID_NoMatch(t *testing.T) {
+	client, _ := newServerListClient(t)
+
+	_, err := parseServerID(context.Background(), client, "10.0.0.1")
+	if err == nil {
+		t.Fatal("expected error for unknown IP, got nil")
+	}
+	if !strings.Contains(err.Error(), "hrobot server list") {
+		t.Errorf("error message should point to 'hrobot server list', got: %s", err.Error())
+	}
+}
+
+func TestParseServerID_Ambiguous(t *testing.T) {
+	client, _ := newServerListClient(t)
+
+	_, err := parseServerID(context.Background(), client, "db")
+	if err == nil {
+		t.Fatal("expected error for ambiguous name, got nil")
+	}
+	if !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error message should list both candidates, got: %s", err.Error())
+	}
+}
+
+func TestGetServerCached(t *testing.T) {
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		switch r.URL.Path {
+		case "/server/321":
+			_, _ = w.Write([]byte(`{"server":{"server_ip":"123.123.123.123","server_number":321,"server_name":"web-1"}}`))
+		case "/key":
+			_, _ = w.Write([]byte(`[{"key":{"name":"laptop","fingerprint":"15:28:b0:03:95:f0:77:b3:10:56:15:6b:77:22:a5:bb"}}]`))
+		default:
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		s, err := getServerCached(ctx, client, 321)
+		if err != nil {
+			t.Fatalf("getServerCached returned error: %v", err)
+		}
+		if s.ServerName != "web-1" {
+			t.Errorf("expected server name 'web-1', got '%s'", s.ServerName)
+		}
+
+		keys, err := listKeysCached(ctx, client)
+		if err != nil {
+			t.Fatalf("listKeysCached returned error: %v", err)
+		}
+		if len(keys) != 1 || keys[0].Name != "laptop" {
+			t.Errorf("expected key 'laptop', got %v", keys)
+		}
+	}
+
+	if calls["/server/321"] != 1 {
+		t.Errorf("expected 1 server request, got %d", calls["/server/321"])
+	}
+	if calls["/key"] != 1 {
+		t.Errorf("expected 1 key list request, got %d", calls["/key"])
+	}
+}
+
+func TestRemoveFlagString(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantValue string
+		wantArgs  []string
+	}{
+		{
+			args:      []string{"hrobot", "--base-url", "http://localhost:8080", "server", "list"},
+			wantValue: "http://localhost:8080",
+			wantArgs:  []string{"hrobot", "server", "list"},
+		},
+		{
+			args:      []string{"hrobot", "server", "describe", "321", "--base-url=http://localhost:8080"},
+			wantValue: "http://localhost:8080",
+			wantArgs:  []string{"hrobot", "server", "describe", "321"},
+		},
+		{
+			args:      []string{"hrobot", "server", "list"},
+			wantValue: "",
+			wantArgs:  []string{"hrobot", "server", "list"},
+		},
+	}
+
+	for _, tt := range tests {
+		value, args := removeFlagString(tt.args, "--base-url")
+		if value != tt.wantValue {
+			t.Errorf("removeFlagString(%v) value = %q, want %q", tt.args, value, tt.wantValue)
+		}
+		if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+			t.Errorf("removeFlagString(%v) args = %v, want %v", tt.args, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestClientOptions_BaseURL(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	t.Setenv("HROBOT_BASE_URL", "")
+
+	opts, err := clientOptions(false, server.URL)
+	if err != nil {
+		t.Fatalf("clientOptions returned error: %v", err)
+	}
+	client := hrobot.New("test-user", "test-pass", opts...)
+	if _, err := client.Server.List(context.Background()); err != nil {
+		t.Fatalf("Server.List returned error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the request to go to --base-url instead of %s, got %d requests", hrobot.DefaultBaseURL, requests)
+	}
+
+	// HROBOT_BASE_URL is used when --base-url is not set
+	t.Setenv("HROBOT_BASE_URL", server.URL)
+	opts, err = clientOptions(false, "")
+	if err != nil {
+		t.Fatalf("clientOptions returned error: %v", err)
+	}
+	client = hrobot.New("test-user", "test-pass", opts...)
+	if _, err := client.Server.List(context.Background()); err != nil {
+		t.Fatalf("Server.List returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the request to go to HROBOT_BASE_URL, got %d requests", requests)
+	}
+
+	for _, invalid := range []string{"localhost:8080", "ftp://example.com", "http://"} {
+		if _, err := clientOptions(false, invalid); err == nil {
+			t.Errorf("expected error for invalid base URL %q, got nil", invalid)
+		}
+	}
+}
+
+func TestRunMain_ReportsErrors(t *testing.T) {
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+
+	tests := []struct {
+		name       string
+		args       []string
+		cancel     bool
+		wantCode   int
+		wantStderr string
+		wantStdout string
+	}{
+		{
+			name:       "error is not reported as cancelled",
+			args:       []string{"hrobot"},
+			wantCode:   exitError,
+			wantStderr: "Error: no command specified\n",
+		},
+		{
+			name:       "json error envelope",
+			args:       []string{"hrobot", "--output", "json"},
+			wantCode:   exitError,
+			wantStdout: `"exit_code": 1`,
+		},
+		{
+			name:       "signal cancels",
+			args:       []string{"hrobot"},
+			cancel:     true,
+			wantCode:   exitCancelled,
+			wantStderr: "cancelled.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = tt.args
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			var stdout, stderr bytes.Buffer
+			var code int
+			captureStdout(t, func() {
+				code = runMain(ctx, &stdout, &stderr)
+			})
+
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantStderr != "" && stderr.String() != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+			if tt.wantStdout != "" && !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("expected stdout to contain %q, got %q", tt.wantStdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunMain_Timeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HROBOT_USERNAME", "#ws+AbCdEfGh")
+	t.Setenv("HROBOT_PASSWORD", "test-pass")
+	t.Setenv("HROBOT_BASE_URL", "")
+
+	// The API answers much later than the --timeout allows.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	origArgs := os.Args
+	t.Cleanup(func() {
+		os.Args = origArgs
+		commandTimeout = 0
+	})
+	os.Args = []string{"hrobot", "--timeout", "50ms", "--base-url", server.URL, "server", "list"}
+
+	// The deadline of --timeout must not be reported as a signal cancel.
+	start := time.Now()
+	var stdout, stderr bytes.Buffer
+	var code int
+	captureStdout(t, func() {
+		code = runMain(context.Background(), &stdout, &stderr)
+	})
+
+	if want := "Error: operation timed out after 50ms (--timeout)\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+	if code != exitError {
+		t.Errorf("expected exit code %d for a timeout, got %d", exitError, code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the request to be cancelled promptly, took %s", elapsed)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+
+	deadlineErr := fmt.Errorf("failed to list servers: %w", hrobot.NewNetworkError("request failed", context.DeadlineExceeded))
+	otherErr := errors.New("server not found")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want string
+	}{
+		{name: "no error", ctx: expired, err: nil},
+		{name: "command deadline", ctx: expired, err: deadlineErr, want: "operation timed out after 2s (--timeout)"},
+		{name: "http timeout before the deadline", ctx: context.Background(), err: deadlineErr, want: deadlineErr.Error()},
+		{name: "unrelated error after the deadline", ctx: expired, err: otherErr, want: otherErr.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timeoutError(tt.ctx, 2*time.Second, tt.err)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("expected no error, got %v", got)
+				}
+				return
+			}
+			if got == nil || got.Error() != tt.want {
+				t.Errorf("timeoutError() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/aquasecurity/table"
+)
+
+// parseListOutput validates the --output value of the list commands. An
+// empty value selects the table.
+func parseListOutput(args []string) (string, error) {
+	outputFormat := parseFlagString(args, "--output")
+	switch outputFormat {
+	case "", "table", "csv", "json":
+		return outputFormat, nil
+	default:
+		return "", fmt.Errorf("invalid --output value: %s (use table, csv or json)", outputFormat)
+	}
+}
+
+// renderRows writes a listing as a table, or as CSV with a header row when
+// outputFormat is "csv". Both use the same columns, which --columns can
+// select and reorder. In a table, --max-width truncates long cells.
+func renderRows(w io.Writer, outputFormat string, headers []string, rows [][]string) error {
+	headers, rows, err := selectColumns(headers, rows, parseFlagStringSlice(os.Args, "--columns"))
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "csv" {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(headers); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	}
+
+	maxWidth := parseFlagInt(os.Args, "--max-width")
+	t := table.New(w)
+	t.SetHeaders(headers...)
+	for _, row := range rows {
+		t.AddRow(truncateCells(row, maxWidth)...)
+	}
+	t.Render()
+	return nil
+}
+
+// columnKey is the name of a column for --columns: the header in lower case
+// with every run of other characters than letters and digits replaced by a
+// dash, e.g. "mem-type" for "Mem Type" and "price-mo-net" for
+// "Price/mo (net)".
+func columnKey(header string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(header) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// selectColumns reduces headers and rows to the given columns, in the given
+// order. A column is named by its header, ignoring case, or by its
+// columnKey. Without columns, headers and rows are returned unchanged.
+func selectColumns(headers []string, rows [][]string, columns []string) ([]string, [][]string, error) {
+	if len(columns) == 0 {
+		return headers, rows, nil
+	}
+
+	indexes := make([]int, 0, len(columns))
+	for _, column := range columns {
+		index := slices.IndexFunc(headers, func(header string) bool {
+			return strings.EqualFold(header, column) || columnKey(header) == columnKey(column)
+		})
+		if index < 0 {
+			keys := make([]string, len(headers))
+			for i, header := range headers {
+				keys[i] = columnKey(header)
+			}
+			return nil, nil, fmt.Errorf("unknown column %q (available: %s)", column, strings.Join(keys, ", "))
+		}
+		indexes = append(indexes, index)
+	}
+
+	selectedHeaders := make([]string, len(indexes))
+	for i, index := range indexes {
+		selectedHeaders[i] = headers[index]
+	}
+	selectedRows := make([][]string, len(rows))
+	for r, row := range rows {
+		selectedRows[r] = make([]string, len(indexes))
+		for i, index := range indexes {
+			if index < len(row) {
+				selectedRows[r][i] = row[index]
+			}
+		}
+	}
+	return selectedHeaders, selectedRows, nil
+}
+
+// truncateCells shortens cells longer than maxWidth characters, ending them
+// with an ellipsis. A maxWidth below 1 leaves the cells unchanged.
+func truncateCells(row []string, maxWidth int) []string {
+	if maxWidth < 1 {
+		return row
+	}
+	truncated := make([]string, len(row))
+	for i, cell := range row {
+		runes := []rune(cell)
+		if len(runes) > maxWidth {
+			cell = string(runes[:maxWidth-1]) + "…"
+		}
+		truncated[i] = cell
+	}
+	return truncated
+}
+
+// printJSON prints v as indented JSON on stdout, projected to the fields of
+// the --fields flag when it is given.
+func printJSON(v interface{}) error {
+	return writeJSON(os.Stdout, v, parseFlagStringSlice(os.Args, "--fields"))
+}
+
+// writeJSON writes v as indented JSON. With fields, every object is reduced
+// to the named fields; see projectFields.
+func writeJSON(w io.Writer, v interface{}, fields []string) error {
+	if len(fields) > 0 {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		v, _ = projectFields(generic, fields)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// projectFields reduces decoded JSON to the given fields. Fields are dot
+// paths such as "traffic.daily"; a list is projected element by element, so
+// "subnets.ip" selects the ip of every subnet. Fields missing from an object
+// are left out. The second result is false when nothing of v was selected.
+func projectFields(v interface{}, fields []string) (interface{}, bool) {
+	switch value := v.(type) {
+	case []interface{}:
+		projected := make([]interface{}, 0, len(value))
+		for _, elem := range value {
+			if p, ok := projectFields(elem, fields); ok {
+				projected = append(projected, p)
+			}
+		}
+		return projected, true
+
+	case map[string]interface{}:
+		// Group the remaining paths by their first segment; an empty
+		// remainder selects the whole value
+		nested := map[string][]string{}
+		whole := map[string]bool{}
+		for _, field := range fields {
+			key, rest, found := strings.Cut(field, ".")
+			if !found || rest == "" {
+				whole[key] = true
+				continue
+			}
+			nested[key] = append(nested[key], rest)
+		}
+
+		projected := map[string]interface{}{}
+		for key, val := range value {
+			switch {
+			case whole[key]:
+				projected[key] = val
+			case len(nested[key]) > 0:
+				if p, ok := projectFields(val, nested[key]); ok {
+					projected[key] = p
+				}
+			}
+		}
+		return projected, true
+
+	default:
+		// A path cannot continue into a scalar
+		return nil, false
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRenderRows_CSV(t *testing.T) {
+	storage := "2x 512 GB NVMe SSD, 2x 16 TB SATA HDD"
+	headers := []string{"ID", "Memory", "Storage", "Locations"}
+	rows := [][]string{{"2345678", "128 GB", storage, "FSN1, HEL1"}}
+
+	var buf bytes.Buffer
+	if err := renderRows(&buf, "csv", headers, rows); err != nil {
+		t.Fatalf("renderRows failed: %v", err)
+	}
+
+	want := "ID,Memory,Storage,Locations\n2345678,128 GB,\"" + storage + "\",\"FSN1, HEL1\"\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v", err)
+	}
+	if len(records) != 2 || records[1][2] != storage {
+		t.Errorf("expected storage %q to round-trip, got %v", storage, records)
+	}
+}
+
+func TestRenderRows_Table(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderRows(&buf, "", []string{"ID", "Storage"}, [][]string{{"1", "2x 512 GB, 1x 4 TB"}}); err != nil {
+		t.Fatalf("renderRows failed: %v", err)
+	}
+	if strings.Contains(buf.String(), `"`) || !strings.Contains(buf.String(), "2x 512 GB, 1x 4 TB") {
+		t.Errorf("expected unquoted table cell, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderRows_ColumnsAndMaxWidth(t *testing.T) {
+	headers := []string{"ID", "CPU", "Mem Type", "Storage", "Price/mo (net)"}
+	rows := [][]string{{"2345678", "AMD Ryzen 9 5950X", "DDR4", "2x 512 GB NVMe SSD, 2x 16 TB SATA HDD", "49.00 €"}}
+
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+	os.Args = []string{"hrobot", "auction", "list", "--columns", "storage,ID,price-mo-net", "--max-width", "12"}
+
+	var buf bytes.Buffer
+	if err := renderRows(&buf, "", headers, rows); err != nil {
+		t.Fatalf("renderRows failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "2x 512 GB N…") {
+		t.Errorf("expected storage truncated to 12 characters, got:\n%s", out)
+	}
+	if strings.Contains(out, "Ryzen") || strings.Contains(out, "DDR4") {
+		t.Errorf("expected unselected columns to be left out, got:\n%s", out)
+	}
+	if strings.Index(out, "Storage") > strings.Index(out, "ID") || !strings.Contains(out, "49.00 €") {
+		t.Errorf("expected the columns in the given order, got:\n%s", out)
+	}
+
+	// CSV selects the same columns but is never truncated
+	buf.Reset()
+	if err := renderRows(&buf, "csv", headers, rows); err != nil {
+		t.Fatalf("renderRows failed: %v", err)
+	}
+	want := "Storage,ID,Price/mo (net)\n\"" + rows[0][3] + "\",2345678,49.00 €\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV output:\ngot:  %q\nwant: %q", buf.String(), want)
+	}
+
+	os.Args = []string{"hrobot", "auction", "list", "--columns", "gpu"}
+	if err := renderRows(&buf, "", headers, rows); err == nil || !strings.Contains(err.Error(), "available: id, cpu, mem-type, storage, price-mo-net") {
+		t.Errorf("expected unknown column error listing the columns, got %v", err)
+	}
+}
+
+func TestParseListOutput(t *testing.T) {
+	for _, value := range []string{"", "table", "csv", "json"} {
+		args := []string{}
+		if value != "" {
+			args = []string{"--output", value}
+		}
+		if got, err := parseListOutput(args); err != nil || got != value {
+			t.Errorf("parseListOutput(%v) = %q, %v", args, got, err)
+		}
+	}
+	if _, err := parseListOutput([]string{"--output=yaml"}); err == nil {
+		t.Error("expected error for unsupported --output value, got nil")
+	}
+}
+
+func TestListServers_JSONFields(t *testing.T) {
+	client, _ := newServerListClient(t)
+
+	origArgs := os.Args
+	t.Cleanup(func() { os.Args = origArgs })
+	os.Args = []string{"hrobot", "server", "list", "--output", "json", "--fields", "server_number,ipv4"}
+
+	out := captureStdout(t, func() {
+		if err := listServers(context.Background(), client, "json", nil, 0); err != nil {
+			t.Fatalf("listServers returned error: %v", err)
+		}
+	})
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", out, err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 servers, got %d", len(got))
+	}
+	for _, server := range got {
+		if len(server) != 2 {
+			t.Errorf("expected only server_number and ipv4, got %v", server)
+		}
+	}
+	if got[0]["server_number"] != float64(321) || got[0]["ipv4"] != "123.123.123.123" {
+		t.Errorf("unexpected first server: %v", got[0])
+	}
+}
+
+func TestWriteJSON_NestedFields(t *testing.T) {
+	v := map[string]interface{}{
+		"id":      5,
+		"name":    "web",
+		"traffic": map[string]interface{}{"daily": 10, "monthly": 300},
+		"subnets": []map[string]interface{}{
+			{"ip": "2a01:4f8::", "mask": "64"},
+			{"ip": "123.123.124.0", "mask": "29"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v, []string{"id", "traffic.daily", "subnets.ip", "missing", "name.first"}); err != nil {
+		t.Fatalf("writeJSON returned error: %v", err)
+	}
+
+	want := `{
+  "id": 5,
+  "subnets": [
+    {
+      "ip": "2a01:4f8::"
+    },
+    {
+      "ip": "123.123.124.0"
+    }
+  ],
+  "traffic": {
+    "daily": 10
+  }
+}
+`
+	if buf.String() != want {
+		t.Errorf("unexpected projection:\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import "fmt"
+
+// priceMode selects which prices are shown: net, gross (incl. VAT) or both.
+type priceMode string
+
+const (
+	priceNet   priceMode = "net"
+	priceGross priceMode = "gross"
+	priceBoth  priceMode = "both"
+)
+
+// parsePriceMode reads the --prices flag. Net prices are shown by default.
+func parsePriceMode(args []string) (priceMode, error) {
+	value := parseFlagString(args, "--prices")
+	switch priceMode(value) {
+	case "":
+		return priceNet, nil
+	case priceNet, priceGross, priceBoth:
+		return priceMode(value), nil
+	}
+	return "", fmt.Errorf("invalid --prices value: %s (must be net, gross or both)", value)
+}
+
+// headers returns the table headers for a price column, e.g. "Price/mo".
+func (m priceMode) headers(label string) []string {
+	switch m {
+	case priceGross:
+		return []string{label + " (gross)"}
+	case priceBoth:
+		return []string{label + " (net)", label + " (gross)"}
+	}
+	return []string{label}
+}
+
+// cells returns the table cells matching headers.
+func (m priceMode) cells(net, gross float64) []string {
+	switch m {
+	case priceGross:
+		return []string{fmt.Sprintf("%.2f €", gross)}
+	case priceBoth:
+		return []string{fmt.Sprintf("%.2f €", net), fmt.Sprintf("%.2f €", gross)}
+	}
+	return []string{fmt.Sprintf("%.2f €", net)}
+}
+
+// format formats a price for detail output.
+func (m priceMode) format(net, gross float64) string {
+	switch m {
+	case priceGross:
+		return fmt.Sprintf("%.2f € incl. VAT", gross)
+	case priceBoth:
+		return fmt.Sprintf("%.2f € (%.2f € incl. VAT)", net, gross)
+	}
+	return fmt.Sprintf("%.2f €", net)
+}
@@ -6,12 +6,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
 
-	"github.com/aquasecurity/table"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
@@ -153,13 +153,43 @@ func parseProductDiskInfo(description []string) string {
 	return "-"
 }
 
-func listProducts(ctx context.Context, client *hrobot.Client, location string, memoryMin float64, cpu string, cpuBenchmarkMin uint32, diskSpaceMin float64, priceMax float64, gpuOnly bool) error {
-	products, err := client.Ordering.ListProducts(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to list products: %w", err)
+// lowestProductPrice returns the lowest monthly net price of a product across
+// its locations, or 0 if it has no prices.
+func lowestProductPrice(product hrobot.Product) float64 {
+	if len(product.Prices) == 0 {
+		return 0
 	}
+	lowestPrice := product.Prices[0].Price.Net.Float64()
+	for _, p := range product.Prices {
+		if p.Price.Net.Float64() < lowestPrice {
+			lowestPrice = p.Price.Net.Float64()
+		}
+	}
+	return lowestPrice
+}
 
-	// Apply filters
+// sortProducts sorts products in place by the selected key. Products have no
+// CPU benchmark, so sorting by benchmark is rejected.
+func sortProducts(products []hrobot.Product, order listSort) error {
+	var value func(hrobot.Product) float64
+	switch order.Key {
+	case "price":
+		value = lowestProductPrice
+	case "memory":
+		value = func(p hrobot.Product) float64 { return parseProductMemory(p.Description) }
+	case "disk":
+		value = func(p hrobot.Product) float64 { return parseProductDiskSpace(p.Description) }
+	case "benchmark":
+		return fmt.Errorf("products cannot be sorted by benchmark (no benchmark data available)")
+	default:
+		return nil
+	}
+	sortByValue(products, value, order.Desc)
+	return nil
+}
+
+// filterProducts returns the products matching the filter, in their original order.
+func filterProducts(products []hrobot.Product, filter auctionFilter) []hrobot.Product {
 	var filteredProducts []hrobot.Product
 	for _, product := range products {
 		// Parse product specs from description
@@ -168,10 +198,10 @@ func listProducts(ctx context.Context, client *hrobot.Client, location string, m
 		diskSpace := parseProductDiskSpace(product.Description)
 
 		// Filter by location
-		if location != "" {
+		if filter.Location != "" {
 			hasLocation := false
 			for _, loc := range product.Locations {
-				if strings.Contains(strings.ToUpper(loc), strings.ToUpper(location)) {
+				if strings.Contains(strings.ToUpper(loc), strings.ToUpper(filter.Location)) {
 					hasLocation = true
 					break
 				}
@@ -182,14 +212,14 @@ func listProducts(ctx context.Context, client *hrobot.Client, location string, m
 		}
 
 		// Filter by minimum memory
-		if memoryMin > 0 && memory < memoryMin {
+		if filter.MemoryMin > 0 && memory < filter.MemoryMin {
 			continue
 		}
 
 		// Filter by CPU vendor
-		if cpu != "" {
+		if filter.CPU != "" {
 			cpuLower := strings.ToLower(cpuName)
-			cpuFilter := strings.ToLower(cpu)
+			cpuFilter := strings.ToLower(filter.CPU)
 			if !strings.Contains(cpuLower, cpuFilter) {
 				continue
 			}
@@ -198,45 +228,96 @@ func listProducts(ctx context.Context, client *hrobot.Client, location string, m
 		// Note: CPU benchmark filtering not available for products (no benchmark data)
 
 		// Filter by minimum disk space
-		if diskSpaceMin > 0 && diskSpace < diskSpaceMin {
+		if filter.DiskSpaceMin > 0 && diskSpace < filter.DiskSpaceMin {
 			continue
 		}
 
 		// Filter by maximum price (use lowest price across locations)
-		if priceMax > 0 && len(product.Prices) > 0 {
-			lowestPrice := product.Prices[0].Price.Net.Float64()
-			for _, p := range product.Prices {
-				if p.Price.Net.Float64() < lowestPrice {
-					lowestPrice = p.Price.Net.Float64()
-				}
-			}
-			if lowestPrice > priceMax {
+		if filter.PriceMax > 0 && len(product.Prices) > 0 {
+			if lowestProductPrice(product) > filter.PriceMax {
 				continue
 			}
 		}
 
 		// Filter by GPU presence
-		if gpuOnly {
+		if filter.GPUOnly {
 			gpuInfo := parseProductGPU(product.Description)
 			if gpuInfo == "-" {
 				continue
 			}
 		}
 
+		// Filter by memory type and ECC
+		if !filter.matchesMemoryType(parseProductMemoryType(product.Description)) {
+			continue
+		}
+
+		// Filter by disk technology, looking at every disk line
+		if filter.DiskType != "" {
+			var disks []string
+			for _, line := range product.Description {
+				disks = append(disks, parseProductDiskInfo([]string{line}))
+			}
+			if !filter.matchesDiskType(strings.Join(disks, " | ")) {
+				continue
+			}
+		}
+
 		filteredProducts = append(filteredProducts, product)
 	}
+	return filteredProducts
+}
+
+func listProducts(ctx context.Context, client *hrobot.Client, filter auctionFilter, order listSort, prices priceMode, outputFormat string) error {
+	products, err := client.Ordering.ListProducts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list products: %w", err)
+	}
+
+	filteredProducts := filterProducts(products, filter)
+	if err := sortProducts(filteredProducts, order); err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		details := make([]productDetail, 0, len(filteredProducts))
+		for i := range filteredProducts {
+			details = append(details, newProductDetail(&filteredProducts[i]))
+		}
+		return printJSON(details)
+	}
+
+	if outputFormat == "csv" {
+		return renderProductTable(os.Stdout, filteredProducts, prices, outputFormat)
+	}
 
 	fmt.Printf("Found %d product server(s)", len(filteredProducts))
-	if location != "" || memoryMin > 0 || cpu != "" || cpuBenchmarkMin > 0 || diskSpaceMin > 0 || priceMax > 0 || gpuOnly {
+	if !filter.isEmpty() {
 		fmt.Printf(" (filtered from %d total)", len(products))
 	}
 	fmt.Println(":")
 
-	// Create table
-	t := table.New(os.Stdout)
-	t.SetHeaders("Product ID", "CPU", "GPU", "Memory", "Mem Type", "Storage", "Price/mo", "Setup", "Locations")
+	if err := renderProductTable(os.Stdout, filteredProducts, prices, outputFormat); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nNote: Prices shown are the lowest available across all locations\n")
+	fmt.Printf("      Use 'hrobot product describe <product-id>' for full details\n")
+	fmt.Printf("      Use 'hrobot product order <product-id>' to order a server\n")
+
+	return nil
+}
 
-	for _, product := range filteredProducts {
+// renderProductTable writes products as a table, or as CSV, with the lowest
+// prices across locations.
+func renderProductTable(w io.Writer, products []hrobot.Product, prices priceMode, outputFormat string) error {
+	headers := []string{"Product ID", "CPU", "GPU", "Memory", "Mem Type", "Storage"}
+	headers = append(headers, prices.headers("Price/mo")...)
+	headers = append(headers, prices.headers("Setup")...)
+	headers = append(headers, "Locations")
+
+	rows := make([][]string, 0, len(products))
+	for _, product := range products {
 		locations := strings.Join(product.Locations, ", ")
 		if locations == "" {
 			locations = "-"
@@ -256,47 +337,95 @@ func listProducts(ctx context.Context, client *hrobot.Client, location string, m
 		}
 
 		// Find lowest price
-		var lowestPrice float64
-		var lowestSetup float64
+		var lowestPrice, lowestSetup hrobot.ProductPriceInfo
 		if len(product.Prices) > 0 {
-			lowestPrice = product.Prices[0].Price.Net.Float64()
-			lowestSetup = product.Prices[0].PriceSetup.Net.Float64()
+			lowestPrice = product.Prices[0].Price
+			lowestSetup = product.Prices[0].PriceSetup
 			for _, p := range product.Prices {
-				if p.Price.Net.Float64() < lowestPrice {
-					lowestPrice = p.Price.Net.Float64()
+				if p.Price.Net.Float64() < lowestPrice.Net.Float64() {
+					lowestPrice = p.Price
 				}
-				if p.PriceSetup.Net.Float64() < lowestSetup {
-					lowestSetup = p.PriceSetup.Net.Float64()
+				if p.PriceSetup.Net.Float64() < lowestSetup.Net.Float64() {
+					lowestSetup = p.PriceSetup
 				}
 			}
 		}
 
-		priceStr := fmt.Sprintf("%.2f €", lowestPrice)
-		setupStr := fmt.Sprintf("%.2f €", lowestSetup)
-
-		t.AddRow(
+		row := []string{
 			product.ID,
 			cpuInfo,
 			gpuName,
 			memoryStr,
 			memType,
 			diskInfo,
-			priceStr,
-			setupStr,
-			locations,
-		)
+		}
+		row = append(row, prices.cells(lowestPrice.Net.Float64(), lowestPrice.Gross.Float64())...)
+		row = append(row, prices.cells(lowestSetup.Net.Float64(), lowestSetup.Gross.Float64())...)
+		row = append(row, locations)
+		rows = append(rows, row)
 	}
 
-	t.Render()
+	return renderRows(w, outputFormat, headers, rows)
+}
 
-	fmt.Printf("\nNote: Prices shown are the lowest available across all locations\n")
-	fmt.Printf("      Use 'hrobot product describe <product-id>' for full details\n")
-	fmt.Printf("      Use 'hrobot product order <product-id>' to order a server\n")
+// productLocation is the JSON representation of a product in one location.
+type productLocation struct {
+	Location     string  `json:"location"`
+	PriceNet     float64 `json:"price_net"`
+	PriceGross   float64 `json:"price_gross"`
+	SetupNet     float64 `json:"setup_net"`
+	SetupGross   float64 `json:"setup_gross"`
+	Availability string  `json:"availability"`
+}
 
-	return nil
+// productDetail is the JSON representation of a single product.
+type productDetail struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description []string          `json:"description"`
+	Traffic     string            `json:"traffic"`
+	Locations   []productLocation `json:"locations"`
 }
 
-func describeProduct(ctx context.Context, client *hrobot.Client, productID string) error {
+// newProductDetail builds the JSON representation of a product. Locations
+// without an availability reported by the API are marked "unknown".
+func newProductDetail(product *hrobot.Product) productDetail {
+	detail := productDetail{
+		ID:          product.ID,
+		Name:        stripProductNamePrefix(product.Name),
+		Description: product.Description,
+		Traffic:     product.Traffic,
+		Locations:   []productLocation{},
+	}
+
+	seen := make(map[string]bool)
+	for _, price := range product.Prices {
+		availability := price.Availability
+		if availability == "" {
+			availability = "unknown"
+		}
+		detail.Locations = append(detail.Locations, productLocation{
+			Location:     price.Location,
+			PriceNet:     price.Price.Net.Float64(),
+			PriceGross:   price.Price.Gross.Float64(),
+			SetupNet:     price.PriceSetup.Net.Float64(),
+			SetupGross:   price.PriceSetup.Gross.Float64(),
+			Availability: availability,
+		})
+		seen[price.Location] = true
+	}
+
+	// Locations without pricing are still listed
+	for _, location := range product.Locations {
+		if !seen[location] {
+			detail.Locations = append(detail.Locations, productLocation{Location: location, Availability: "unknown"})
+		}
+	}
+
+	return detail
+}
+
+func describeProduct(ctx context.Context, client *hrobot.Client, productID string, prices priceMode, outputFormat string) error {
 	// Fetch the product list to find the product details
 	products, err := client.Ordering.ListProducts(ctx)
 	if err != nil {
@@ -316,11 +445,17 @@ func describeProduct(ctx context.Context, client *hrobot.Client, productID strin
 		return fmt.Errorf("product with ID %s not found", productID)
 	}
 
+	detail := newProductDetail(product)
+
+	if outputFormat == "json" {
+		return printJSON(detail)
+	}
+
 	// Display server details
 	fmt.Printf("Product Server Details:\n")
 	fmt.Printf("  Product ID:  %s\n", product.ID)
 	if product.Name != "" {
-		fmt.Printf("  Name:        %s\n", stripProductNamePrefix(product.Name))
+		fmt.Printf("  Name:        %s\n", detail.Name)
 	}
 
 	// Show description array which contains the actual specs
@@ -340,22 +475,97 @@ func describeProduct(ctx context.Context, client *hrobot.Client, productID strin
 		fmt.Printf("  Locations:   %s\n", strings.Join(product.Locations, ", "))
 	}
 
-	// Show pricing per location
-	if len(product.Prices) > 0 {
+	// Show pricing and availability per location
+	if len(detail.Locations) > 0 {
 		fmt.Printf("  Pricing by location:\n")
-		for _, price := range product.Prices {
-			fmt.Printf("    %s: %.2f €/month", price.Location, price.Price.Net.Float64())
-			if price.PriceSetup.Net.Float64() > 0 {
-				fmt.Printf(" (%.2f € setup)", price.PriceSetup.Net.Float64())
+		for _, location := range detail.Locations {
+			fmt.Printf("    %s: %s/month", location.Location, prices.format(location.PriceNet, location.PriceGross))
+			if location.SetupNet > 0 {
+				fmt.Printf(", setup %s", prices.format(location.SetupNet, location.SetupGross))
 			}
-			fmt.Println()
+			fmt.Printf(" (availability: %s)\n", location.Availability)
 		}
 	}
 
 	return nil
 }
 
-func orderProductServer(ctx context.Context, client *hrobot.Client, productID string, location string, sshKeyFingerprints []string, testMode bool, skipConfirmation bool) error {
+// Without --image and --lang, an ordered server boots into the English
+// rescue system.
+const (
+	defaultOrderDistribution = "Rescue system"
+	defaultOrderLanguage     = "en"
+)
+
+// orderImage is the operating system installed with an order.
+type orderImage struct {
+	Distribution string // --image; empty for the rescue system
+	Language     string // --lang; empty for English
+}
+
+// resolve validates the image against the distributions and languages
+// offered for the product and fills in the defaults. Matching ignores case;
+// the order uses the product's spelling.
+func (img orderImage) resolve(dists, langs []string) (orderImage, error) {
+	resolved := orderImage{Distribution: defaultOrderDistribution, Language: defaultOrderLanguage}
+	if img.Distribution != "" {
+		dist, ok := matchOption(img.Distribution, dists)
+		if !ok {
+			return orderImage{}, fmt.Errorf("distribution '%s' is not available for this product\nAvailable distributions: %s", img.Distribution, formatOptions(dists))
+		}
+		resolved.Distribution = dist
+	}
+	if img.Language != "" {
+		lang, ok := matchOption(img.Language, langs)
+		if !ok {
+			return orderImage{}, fmt.Errorf("language '%s' is not available for this product\nAvailable languages: %s", img.Language, formatOptions(langs))
+		}
+		resolved.Language = lang
+	}
+	return resolved, nil
+}
+
+// printOrderCommentWarning warns that Hetzner processes orders with a
+// comment manually, so the server is not provisioned automatically.
+func printOrderCommentWarning(comment string) {
+	if comment == "" {
+		return
+	}
+	fmt.Printf("Warning: orders with a comment are processed manually by Hetzner, which can delay provisioning.\n\n")
+}
+
+// removeOrderImageFlags removes --image and --lang from args.
+func removeOrderImageFlags(args []string) (orderImage, []string) {
+	var image orderImage
+	image.Distribution, args = removeFlagString(args, "--image")
+	image.Language, args = removeFlagString(args, "--lang")
+	return image, args
+}
+
+// orderImageFlagsHelp describes --image and --lang in the help of the order
+// commands.
+const orderImageFlagsHelp = `  --image <dist>    Install this distribution (default: boot into the rescue system)
+  --lang <code>     Language of the distribution (default: en)`
+
+// matchOption returns the option equal to value, ignoring case.
+func matchOption(value string, options []string) (string, bool) {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// formatOptions joins options for an error message.
+func formatOptions(options []string) string {
+	if len(options) == 0 {
+		return "(none)"
+	}
+	return strings.Join(options, ", ")
+}
+
+func orderProductServer(ctx context.Context, client *hrobot.Client, productID string, location string, auth hrobot.AuthorizationMethod, image orderImage, comment string, testMode bool, skipConfirmation bool, wait orderWait) error {
 	// Fetch the product list to find the product details
 	fmt.Printf("Fetching product details...\n\n")
 	products, err := client.Ordering.ListProducts(ctx)
@@ -376,6 +586,16 @@ func orderProductServer(ctx context.Context, client *hrobot.Client, productID st
 		return fmt.Errorf("product with ID %s not found", productID)
 	}
 
+	image, err = image.resolve(product.Distributions, product.Languages)
+	if err != nil {
+		return err
+	}
+
+	image, err = image.resolve(product.Distributions, product.Languages)
+	if err != nil {
+		return err
+	}
+
 	// Display server details
 	fmt.Printf("Product Server Details:\n")
 	fmt.Printf("  Product ID:  %s\n", product.ID)
@@ -429,15 +649,16 @@ func orderProductServer(ctx context.Context, client *hrobot.Client, productID st
 	} else {
 		fmt.Printf("  Location:    (not specified - order may fail)\n")
 	}
-	if len(sshKeyFingerprints) == 1 {
-		fmt.Printf("  SSH Key:     %s\n", sshKeyFingerprints[0])
-	} else {
-		fmt.Printf("  SSH Keys:    %d keys\n", len(sshKeyFingerprints))
+	printOrderAuth(auth)
+	fmt.Printf("  Image:       %s (%s)\n", image.Distribution, image.Language)
+	if comment != "" {
+		fmt.Printf("  Comment:     %s\n", comment)
 	}
 	if testMode {
 		fmt.Printf("  Test Mode:   enabled (order will not be placed)\n")
 	}
 	fmt.Println()
+	printOrderCommentWarning(comment)
 
 	// Show info if location was auto-selected
 	if autoSelectedLocation {
@@ -445,30 +666,22 @@ func orderProductServer(ctx context.Context, client *hrobot.Client, productID st
 	}
 
 	// Ask for confirmation unless --yes flag was used
+	if !confirm("Do you want to proceed with this order?", skipConfirmation) {
+		fmt.Println("Order cancelled.")
+		return nil
+	}
 	if !skipConfirmation {
-		fmt.Printf("Do you want to proceed with this order? (y/N): ")
-		var response string
-		// Read response, treating any error (e.g., EOF) as empty input
-		if _, err := fmt.Scanln(&response); err != nil {
-			response = ""
-		}
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("Order cancelled.")
-			return nil
-		}
 		fmt.Println()
 	}
 
 	// Proceed with the order
 	order := hrobot.ProductOrder{
-		ProductID: productID,
-		Auth: hrobot.AuthorizationMethod{
-			Keys: sshKeyFingerprints,
-		},
+		ProductID:    productID,
+		Auth:         auth,
 		Location:     location,
-		Distribution: "Rescue system",
-		Language:     "en",
+		Distribution: image.Distribution,
+		Language:     image.Language,
+		Comment:      comment,
 		Test:         testMode,
 	}
 
@@ -478,17 +691,7 @@ func orderProductServer(ctx context.Context, client *hrobot.Client, productID st
 		return fmt.Errorf("failed to place order: %w", err)
 	}
 
-	fmt.Printf("\n✓ Order placed successfully!\n")
-	fmt.Printf("  Transaction ID: %s\n", tx.ID)
-	fmt.Printf("  Status:         %s\n", tx.Status)
-	fmt.Printf("  Date:           %s\n", tx.Date.Format("2006-01-02 15:04:05"))
-	fmt.Printf("  Product:        %s\n", tx.Product.Name)
-	if tx.ServerNumber != nil {
-		fmt.Printf("  Server Number:  %d\n", *tx.ServerNumber)
-	}
-	if tx.ServerIP != nil {
-		fmt.Printf("  Server IP:      %s\n", *tx.ServerIP)
-	}
+	printPlacedOrder(tx, testMode)
 
-	return nil
+	return waitForPlacedOrder(ctx, client.Ordering.WaitForProductTransactionCompletionWithProgress, tx, wait, testMode)
 }
@@ -0,0 +1,302 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestNewProductDetail_Availability(t *testing.T) {
+	var product hrobot.Product
+	input := `{
+		"id": "EX44",
+		"name": "Dedicated Server EX44",
+		"description": ["Intel Core i5-13500", "64 GB DDR4"],
+		"traffic": "unlimited",
+		"location": ["FSN1", "HEL1", "NBG1"],
+		"prices": [
+			{"location": "FSN1", "price": {"net": "44.00", "gross": "52.36"}, "price_setup": {"net": "0.00", "gross": "0.00"}, "availability": "in stock"},
+			{"location": "HEL1", "price": {"net": "44.00", "gross": "52.36"}, "price_setup": {"net": "0.00", "gross": "0.00"}, "availability": "2-3 weeks"},
+			{"location": "NBG1", "price": {"net": "44.00", "gross": "52.36"}, "price_setup": {"net": "0.00", "gross": "0.00"}}
+		]
+	}`
+	if err := json.Unmarshal([]byte(input), &product); err != nil {
+		t.Fatalf("failed to unmarshal product: %v", err)
+	}
+
+	detail := newProductDetail(&product)
+
+	if detail.Name != "EX44" {
+		t.Errorf("expected name 'EX44', got '%s'", detail.Name)
+	}
+
+	expected := map[string]string{
+		"FSN1": "in stock",
+		"HEL1": "2-3 weeks",
+		"NBG1": "unknown",
+	}
+	if len(detail.Locations) != len(expected) {
+		t.Fatalf("expected %d locations, got %d: %+v", len(expected), len(detail.Locations), detail.Locations)
+	}
+	for _, location := range detail.Locations {
+		if location.Availability != expected[location.Location] {
+			t.Errorf("expected availability '%s' for %s, got '%s'", expected[location.Location], location.Location, location.Availability)
+		}
+		if location.PriceGross != 52.36 {
+			t.Errorf("expected gross price 52.36 for %s, got %v", location.Location, location.PriceGross)
+		}
+	}
+
+	data, err := json.Marshal(detail)
+	if err != nil {
+		t.Fatalf("failed to marshal detail: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal detail: %v", err)
+	}
+	locations, ok := decoded["locations"].([]interface{})
+	if !ok || len(locations) != 3 {
+		t.Fatalf("expected 3 locations in JSON, got %v", decoded["locations"])
+	}
+	if first := locations[0].(map[string]interface{}); first["availability"] != "in stock" {
+		t.Errorf("expected first location availability 'in stock', got %v", first["availability"])
+	}
+}
+
+func TestNewProductDetail_LocationWithoutPrice(t *testing.T) {
+	product := hrobot.Product{
+		ID:        "AX41-NVMe",
+		Locations: []string{"FSN1", "HEL1"},
+		Prices: []hrobot.ProductPrice{
+			{Location: "FSN1"},
+		},
+	}
+
+	detail := newProductDetail(&product)
+	if len(detail.Locations) != 2 {
+		t.Fatalf("expected 2 locations, got %d", len(detail.Locations))
+	}
+	if detail.Locations[1].Location != "HEL1" || detail.Locations[1].Availability != "unknown" {
+		t.Errorf("expected HEL1 with unknown availability, got %+v", detail.Locations[1])
+	}
+}
+
+func TestFilterProducts_MemoryAndDisk(t *testing.T) {
+	products := []hrobot.Product{
+		{ID: "EX44", Locations: []string{"FSN1"}, Description: []string{"Intel Core i5-13500", "64 GB DDR4 RAM", "2 x 512 GB NVMe SSD"}},
+		{ID: "AX102", Locations: []string{"HEL1"}, Description: []string{"AMD Ryzen 9 7950X3D", "128 GB DDR5 ECC RAM", "2 x 1.92 TB NVMe SSD"}},
+		{ID: "SX65", Locations: []string{"FSN1"}, Description: []string{"AMD Ryzen 7 3700X", "64 GB DDR4 ECC RAM", "2 x 1 TB NVMe SSD", "4 x 22 TB SATA HDD"}},
+		{ID: "EX-SATA", Locations: []string{"NBG1"}, Description: []string{"Intel Core i7", "32 GB DDR5 UDIMM", "2 x 480 GB SATA SSD"}},
+	}
+
+	tests := []struct {
+		name    string
+		filter  auctionFilter
+		wantIDs []string
+	}{
+		{name: "ecc", filter: auctionFilter{ECCOnly: true}, wantIDs: []string{"AX102", "SX65"}},
+		{name: "ddr5", filter: auctionFilter{MemoryType: "ddr5"}, wantIDs: []string{"AX102", "EX-SATA"}},
+		{name: "nvme", filter: auctionFilter{DiskType: "nvme"}, wantIDs: []string{"EX44", "AX102", "SX65"}},
+		{name: "sata ssd", filter: auctionFilter{DiskType: "sata"}, wantIDs: []string{"EX-SATA"}},
+		{name: "hdd beyond the first disk line", filter: auctionFilter{DiskType: "hdd"}, wantIDs: []string{"SX65"}},
+		{name: "combined with location", filter: auctionFilter{ECCOnly: true, Location: "FSN"}, wantIDs: []string{"SX65"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []string
+			for _, product := range filterProducts(products, tt.filter) {
+				gotIDs = append(gotIDs, product.ID)
+			}
+			if fmt.Sprint(gotIDs) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected products %v, got %v", tt.wantIDs, gotIDs)
+			}
+		})
+	}
+}
+
+func TestSortProducts(t *testing.T) {
+	newProduct := func(id, price string, description ...string) hrobot.Product {
+		var product hrobot.Product
+		input := fmt.Sprintf(`{"id": %q, "description": [], "prices": [{"location": "FSN1", "price": {"net": %q, "gross": "0"}, "price_setup": {"net": "0", "gross": "0"}}]}`, id, price)
+		if err := json.Unmarshal([]byte(input), &product); err != nil {
+			t.Fatalf("failed to unmarshal product: %v", err)
+		}
+		product.Description = description
+		return product
+	}
+	products := []hrobot.Product{
+		newProduct("A", "60.00", "64 GB DDR4 RAM", "2 x 1 TB NVMe SSD"),
+		newProduct("B", "40.00", "128 GB DDR5 ECC RAM", "2 x 512 GB NVMe SSD"),
+		newProduct("C", "40.00", "32 GB DDR4 RAM", "2 x 4 TB SATA HDD"),
+	}
+
+	tests := []struct {
+		order   listSort
+		wantIDs []string
+		wantErr bool
+	}{
+		{order: listSort{Key: "price"}, wantIDs: []string{"B", "C", "A"}},
+		{order: listSort{Key: "price", Desc: true}, wantIDs: []string{"A", "B", "C"}},
+		{order: listSort{Key: "memory"}, wantIDs: []string{"C", "A", "B"}},
+		{order: listSort{Key: "disk", Desc: true}, wantIDs: []string{"C", "A", "B"}},
+		{order: listSort{Key: "benchmark"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s desc=%v", tt.order.Key, tt.order.Desc), func(t *testing.T) {
+			sorted := append([]hrobot.Product(nil), products...)
+			err := sortProducts(sorted, tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sortProducts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var gotIDs []string
+			for _, product := range sorted {
+				gotIDs = append(gotIDs, product.ID)
+			}
+			if fmt.Sprint(gotIDs) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected order %v, got %v", tt.wantIDs, gotIDs)
+			}
+		})
+	}
+}
+
+func TestOrderImageResolve(t *testing.T) {
+	dists := []string{"Rescue system", "Debian 12 base", "Ubuntu 24.04 LTS base"}
+	langs := []string{"en", "de"}
+
+	tests := []struct {
+		name    string
+		image   orderImage
+		want    orderImage
+		wantErr string
+	}{
+		{
+			name:  "defaults to the rescue system",
+			image: orderImage{},
+			want:  orderImage{Distribution: "Rescue system", Language: "en"},
+		},
+		{
+			name:  "matches ignoring case",
+			image: orderImage{Distribution: "ubuntu 24.04 lts base", Language: "DE"},
+			want:  orderImage{Distribution: "Ubuntu 24.04 LTS base", Language: "de"},
+		},
+		{
+			name:    "unavailable distribution",
+			image:   orderImage{Distribution: "Arch Linux"},
+			wantErr: "distribution 'Arch Linux' is not available",
+		},
+		{
+			name:    "unavailable language",
+			image:   orderImage{Distribution: "Debian 12 base", Language: "fr"},
+			wantErr: "language 'fr' is not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.image.resolve(dists, langs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if !strings.Contains(err.Error(), "Debian 12 base") && !strings.Contains(err.Error(), "en, de") {
+					t.Errorf("error should list the available options, got: %s", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderProductServer_Comment(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/order/server/product":
+			fmt.Fprint(w, `[{"product": {"id": "EX44", "name": "Dedicated Server EX44", "dist": ["Rescue system"], "lang": ["en"],
+				"prices": [{"location": "FSN1", "price": {"net": "44.00", "gross": "52.36"}, "price_setup": {"net": "0.00", "gross": "0.00"}}]}}]`)
+		case r.Method == "POST" && r.URL.Path == "/order/server/transaction":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			form = r.PostForm
+			fmt.Fprint(w, `{"transaction": {"id": "B20250101-1", "date": "2025-01-01T10:00:00+01:00", "status": "in process", "product": {"id": "EX44", "name": "EX44"}}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	auth := hrobot.AuthorizationMethod{Keys: []string{"aa:bb"}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = orderProductServer(context.Background(), client, "EX44", "", auth, orderImage{}, "rack next to 123456", true, true, orderWait{})
+	})
+	if err != nil {
+		t.Fatalf("orderProductServer returned error: %v", err)
+	}
+	if got := form.Get("comment"); got != "rack next to 123456" {
+		t.Errorf("expected comment to be sent with the order, got %q", got)
+	}
+	if !strings.Contains(out, "processed manually") {
+		t.Errorf("expected a manual processing warning, got:\n%s", out)
+	}
+}
+
+func TestPrintPlacedOrder_TestMode(t *testing.T) {
+	var tx hrobot.MarketTransaction
+	input := `{
+		"id": "B20250101-1",
+		"date": null,
+		"status": "in process",
+		"server_number": null,
+		"server_ip": null,
+		"product": {"id": 283693, "name": "SB110", "dist": "Debian 12 base", "lang": "en"}
+	}`
+	if err := json.Unmarshal([]byte(input), &tx); err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+
+	out := captureStdout(t, func() { printPlacedOrder(&tx, true) })
+
+	if !strings.Contains(out, "TEST MODE: no order placed") {
+		t.Errorf("expected test mode banner, got:\n%s", out)
+	}
+	if strings.Contains(out, "Order placed successfully") {
+		t.Errorf("test order must not be reported as placed, got:\n%s", out)
+	}
+	for _, want := range []string{"Transaction ID: B20250101-1", "Product:        SB110", "Image:          Debian 12 base (en)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Date:", "Server Number:", "Server IP:"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("expected no %q for a test order, got:\n%s", unwanted, out)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// plainProgressInterval is how often an unchanged status is repeated when
+// progress is written to a non-terminal (log files, CI output).
+const plainProgressInterval = time.Minute
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressReporter shows the state of a long-running wait.
+//
+// On a terminal it redraws a single spinner line with the elapsed time and
+// current status. Otherwise it prints a plain line whenever the status
+// changes, and repeats an unchanged status at most once per interval so that
+// the output stays bounded. A quiet reporter prints nothing.
+type progressReporter struct {
+	out      io.Writer
+	tty      bool
+	quiet    bool
+	interval time.Duration
+	label    string
+
+	mu        sync.Mutex
+	status    string
+	started   time.Time
+	lastPrint time.Duration
+	printed   bool
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// newProgressReporter creates a reporter writing to stdout.
+func newProgressReporter(label string, quiet bool) *progressReporter {
+	return &progressReporter{
+		out:      os.Stdout,
+		tty:      isTerminal(os.Stdout),
+		quiet:    quiet,
+		interval: plainProgressInterval,
+		label:    label,
+	}
+}
+
+// start begins reporting. On a terminal the spinner is animated in the background.
+func (p *progressReporter) start() {
+	p.started = time.Now()
+	if p.quiet || !p.tty {
+		return
+	}
+
+	p.stopCh = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		frame := 0
+		for {
+			select {
+			case <-p.stopCh:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				fmt.Fprintf(p.out, "\r\033[K%s %s: %s (%s)", spinnerFrames[frame%len(spinnerFrames)], p.label, p.statusText(), formatElapsed(time.Since(p.started)))
+				p.mu.Unlock()
+				frame++
+			}
+		}
+	}()
+}
+
+// update records the latest status. elapsed is the time spent waiting so far.
+func (p *progressReporter) update(status string, elapsed time.Duration) {
+	if p.quiet {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changed := status != p.status
+	p.status = status
+	if p.tty {
+		return
+	}
+
+	if changed || !p.printed || elapsed-p.lastPrint >= p.interval {
+		fmt.Fprintf(p.out, "%s: %s (%s)\n", p.label, p.statusText(), formatElapsed(elapsed))
+		p.lastPrint = elapsed
+		p.printed = true
+	}
+}
+
+// stop ends reporting and clears the spinner line.
+func (p *progressReporter) stop() {
+	if p.stopCh == nil {
+		return
+	}
+	close(p.stopCh)
+	p.wg.Wait()
+	p.stopCh = nil
+	fmt.Fprint(p.out, "\r\033[K")
+}
+
+func (p *progressReporter) statusText() string {
+	if p.status == "" {
+		return "waiting"
+	}
+	return p.status
+}
+
+// formatElapsed formats a duration as e.g. "1m05s".
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporter_NonTTY(t *testing.T) {
+	var out strings.Builder
+	p := &progressReporter{out: &out, interval: time.Minute, label: "transaction B1"}
+	p.start()
+
+	// Poll every 30 seconds for 10 minutes, with one status change
+	for i := 0; i <= 20; i++ {
+		status := "in process"
+		if i == 20 {
+			status = "ready"
+		}
+		p.update(status, time.Duration(i)*30*time.Second)
+	}
+	p.stop()
+
+	output := out.String()
+	if strings.ContainsAny(output, "\r\033") {
+		t.Errorf("expected plain output without control characters, got %q", output)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	// First status, one repeat per minute of unchanged status, and the final status
+	if len(lines) > 12 {
+		t.Errorf("expected at most 12 lines for a 10 minute wait, got %d:\n%s", len(lines), output)
+	}
+	if lines[0] != "transaction B1: in process (0s)" {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if lines[len(lines)-1] != "transaction B1: ready (10m00s)" {
+		t.Errorf("unexpected last line: %q", lines[len(lines)-1])
+	}
+}
+
+func TestProgressReporter_Quiet(t *testing.T) {
+	var out strings.Builder
+	p := &progressReporter{out: &out, quiet: true, tty: true, interval: time.Minute, label: "transaction B1"}
+	p.start()
+	p.update("in process", 0)
+	p.update("ready", time.Minute)
+	p.stop()
+
+	if out.Len() != 0 {
+		t.Errorf("expected no output in quiet mode, got %q", out.String())
+	}
+}
@@ -7,15 +7,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
-func listRDNS(ctx context.Context, client *hrobot.Client, serverIP string) error {
-	entries, err := client.RDNS.List(ctx, serverIP)
+// listRDNS prints the reverse DNS entries sorted by IP. With a concurrency
+// greater than zero and no server IP, the entries are fetched per server in
+// parallel instead of with a single request.
+func listRDNS(ctx context.Context, client *hrobot.Client, serverIP string, concurrency int) error {
+	var entries []hrobot.RDNS
+	var err error
+	if serverIP == "" && concurrency > 0 {
+		entries, err = listRDNSConcurrently(ctx, client, concurrency)
+	} else {
+		entries, err = client.RDNS.List(ctx, serverIP)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list reverse DNS entries: %w", err)
 	}
+	sortRDNSByIP(entries)
 
 	if serverIP != "" {
 		fmt.Printf("Reverse DNS entries for server %s:\n\n", serverIP)
@@ -31,6 +48,83 @@ func listRDNS(ctx context.Context, client *hrobot.Client, serverIP string) error
 	return nil
 }
 
+// listRDNSConcurrently fetches the reverse DNS entries of every server with
+// up to concurrency requests in flight and merges them. Entries returned for
+// more than one server are only kept once.
+func listRDNSConcurrently(ctx context.Context, client *hrobot.Client, concurrency int) ([]hrobot.RDNS, error) {
+	servers, err := listServersCached(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	results := make([][]hrobot.RDNS, len(servers))
+	errs := make([]error, len(servers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx], errs[idx] = listServerRDNS(ctx, client, servers[idx])
+			}
+		}()
+	}
+	for idx := range servers {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	var entries []hrobot.RDNS
+	for idx, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("server %d: %w", servers[idx].ServerNumber, err)
+		}
+		for _, entry := range results[idx] {
+			if seen[entry.IP] {
+				continue
+			}
+			seen[entry.IP] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// listServerRDNS returns the reverse DNS entries of one server. A server
+// without entries yields an empty list.
+func listServerRDNS(ctx context.Context, client *hrobot.Client, server hrobot.Server) ([]hrobot.RDNS, error) {
+	if server.ServerIP == nil {
+		return nil, nil
+	}
+	entries, err := client.RDNS.List(ctx, server.ServerIP.String())
+	if hrobot.IsAPIError(err, hrobot.ErrReverseDNSNotFound) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// sortRDNSByIP sorts entries by IP address, IPv4 before IPv6. Entries whose
+// IP does not parse are sorted last by their string value.
+func sortRDNSByIP(entries []hrobot.RDNS) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, errA := netip.ParseAddr(entries[i].IP)
+		b, errB := netip.ParseAddr(entries[j].IP)
+		switch {
+		case errA == nil && errB == nil:
+			return a.Less(b)
+		case errA == nil:
+			return true
+		case errB == nil:
+			return false
+		default:
+			return entries[i].IP < entries[j].IP
+		}
+	})
+}
+
 func getRDNS(ctx context.Context, client *hrobot.Client, ip string) error {
 	entry, err := client.RDNS.Get(ctx, ip)
 	if err != nil {
@@ -51,7 +145,64 @@ func getRDNS(ctx context.Context, client *hrobot.Client, ip string) error {
 	return nil
 }
 
-func setRDNS(ctx context.Context, client *hrobot.Client, ip, ptr string) error {
+// hostnameLabelPattern matches a single DNS label.
+var hostnameLabelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// validatePTR checks that ptr is a fully qualified hostname such as
+// "server1.example.com". A trailing dot is allowed.
+func validatePTR(ptr string) error {
+	name := strings.TrimSuffix(ptr, ".")
+	if name == "" {
+		return fmt.Errorf("PTR cannot be empty")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("invalid PTR %q: hostname is longer than 253 characters", ptr)
+	}
+
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("invalid PTR %q: not a fully qualified hostname (e.g. %s.example.com)", ptr, name)
+	}
+	for _, label := range labels {
+		if !hostnameLabelPattern.MatchString(label) {
+			return fmt.Errorf("invalid PTR %q: %q is not a valid hostname label", ptr, label)
+		}
+	}
+	if _, err := strconv.Atoi(labels[len(labels)-1]); err == nil {
+		return fmt.Errorf("invalid PTR %q: the top-level domain cannot be numeric", ptr)
+	}
+
+	return nil
+}
+
+// lookupHost resolves a hostname to its addresses. Tests replace it.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// checkForwardDNS warns when ptr does not resolve back to ip. Mail servers
+// and other services often reject hosts without matching forward DNS.
+func checkForwardDNS(ctx context.Context, ip, ptr string) {
+	addrs, err := lookupHost(ctx, strings.TrimSuffix(ptr, "."))
+	if err != nil {
+		fmt.Printf("warning: %s does not resolve (%v); forward DNS should point back to %s\n", ptr, err, ip)
+		return
+	}
+
+	want := net.ParseIP(ip)
+	for _, addr := range addrs {
+		if parsed := net.ParseIP(addr); parsed != nil && parsed.Equal(want) {
+			return
+		}
+	}
+	fmt.Printf("warning: %s resolves to %s, not to %s\n", ptr, strings.Join(addrs, ", "), ip)
+}
+
+func setRDNS(ctx context.Context, client *hrobot.Client, ip, ptr string, force bool) error {
+	if !force {
+		if err := validatePTR(ptr); err != nil {
+			return fmt.Errorf("%w (use --force to set it anyway)", err)
+		}
+	}
+
 	// Try to update first (works for both create and update)
 	entry, err := client.RDNS.Update(ctx, ip, ptr)
 	if err != nil {
@@ -62,6 +213,10 @@ func setRDNS(ctx context.Context, client *hrobot.Client, ip, ptr string) error {
 	fmt.Printf("  IP:  %s\n", entry.IP)
 	fmt.Printf("  PTR: %s\n", entry.PTR)
 
+	if !force {
+		checkForwardDNS(ctx, ip, ptr)
+	}
+
 	return nil
 }
 
@@ -75,3 +230,66 @@ func deleteRDNS(ctx context.Context, client *hrobot.Client, ip string) error {
 
 	return nil
 }
+
+// rdnsEntriesInSubnet returns the entries whose IP is in prefix.
+func rdnsEntriesInSubnet(entries []hrobot.RDNS, prefix netip.Prefix) []hrobot.RDNS {
+	var matches []hrobot.RDNS
+	for _, entry := range entries {
+		addr, err := netip.ParseAddr(entry.IP)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr.Unmap()) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// resetRDNSSubnet resets all reverse DNS entries in a subnet to the Hetzner
+// default. It keeps going when a reset fails and reports the failures at the end.
+func resetRDNSSubnet(ctx context.Context, client *hrobot.Client, subnet string, skipConfirmation bool) error {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return fmt.Errorf("invalid subnet: %s (expected CIDR notation, e.g. 123.123.123.0/29)", subnet)
+	}
+	prefix = prefix.Masked()
+
+	entries, err := client.RDNS.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list reverse DNS entries: %w", err)
+	}
+
+	matches := rdnsEntriesInSubnet(entries, prefix)
+	if len(matches) == 0 {
+		fmt.Printf("no reverse DNS entries set in %s\n", prefix)
+		return nil
+	}
+
+	fmt.Printf("Reverse DNS entries in %s:\n", prefix)
+	for _, entry := range matches {
+		fmt.Printf("  %s -> %s\n", entry.IP, entry.PTR)
+	}
+	fmt.Println()
+
+	if !confirm(fmt.Sprintf("Reset %d reverse DNS entry/entries?", len(matches)), skipConfirmation) {
+		return fmt.Errorf("reverse DNS reset cancelled")
+	}
+
+	reset := 0
+	var failed []string
+	for _, entry := range matches {
+		if err := client.RDNS.Delete(ctx, entry.IP); err != nil && !hrobot.IsAPIError(err, hrobot.ErrReverseDNSNotFound) {
+			fmt.Printf("✗ failed to reset %s: %v\n", entry.IP, err)
+			failed = append(failed, entry.IP)
+			continue
+		}
+		reset++
+	}
+
+	fmt.Printf("✓ reset %d of %d reverse DNS entry/entries in %s\n", reset, len(matches), prefix)
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to reset %d reverse DNS entry/entries: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// newRDNSTestServer serves the given reverse DNS entries and records the IPs
+// whose entry was deleted.
+func newRDNSTestServer(t *testing.T, entries map[string]string) (*hrobot.Client, *[]string) {
+	t.Helper()
+
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rdns":
+			var response []map[string]interface{}
+			for ip, ptr := range entries {
+				response = append(response, map[string]interface{}{
+					"rdns": map[string]string{"ip": ip, "ptr": ptr},
+				})
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/rdns/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/rdns/"))
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL)), &deleted
+}
+
+func TestResetRDNSSubnet(t *testing.T) {
+	client, deleted := newRDNSTestServer(t, map[string]string{
+		"123.123.123.8":  "mail.example.com",
+		"123.123.123.9":  "web1.example.com",
+		"123.123.123.15": "web2.example.com",
+		"123.123.123.16": "other.example.com",
+		"123.123.123.7":  "other.example.com",
+	})
+
+	out := captureStdout(t, func() {
+		if err := resetRDNSSubnet(context.Background(), client, "123.123.123.8/29", true); err != nil {
+			t.Fatalf("resetRDNSSubnet returned error: %v", err)
+		}
+	})
+
+	sort.Strings(*deleted)
+	want := []string{"123.123.123.15", "123.123.123.8", "123.123.123.9"}
+	if strings.Join(*deleted, ",") != strings.Join(want, ",") {
+		t.Errorf("expected entries %v to be reset, got %v", want, *deleted)
+	}
+	if !strings.Contains(out, "reset 3 of 3 reverse DNS entry/entries in 123.123.123.8/29") {
+		t.Errorf("expected reset count in output, got:\n%s", out)
+	}
+}
+
+func TestResetRDNSSubnet_RequiresConfirmation(t *testing.T) {
+	client, deleted := newRDNSTestServer(t, map[string]string{
+		"123.123.123.8": "mail.example.com",
+	})
+	stubConfirm(t, "", false)
+
+	var err error
+	captureStdout(t, func() {
+		err = resetRDNSSubnet(context.Background(), client, "123.123.123.8/29", false)
+	})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected cancellation without --confirm, got %v", err)
+	}
+	if len(*deleted) != 0 {
+		t.Errorf("expected no entries to be reset, got %v", *deleted)
+	}
+}
+
+func TestResetRDNSSubnet_InvalidSubnet(t *testing.T) {
+	client, _ := newRDNSTestServer(t, nil)
+
+	if err := resetRDNSSubnet(context.Background(), client, "123.123.123.8", true); err == nil {
+		t.Error("expected error for a subnet without prefix length, got nil")
+	}
+}
+
+func TestValidatePTR(t *testing.T) {
+	tests := []struct {
+		ptr     string
+		wantErr string
+	}{
+		{ptr: "", wantErr: "cannot be empty"},
+		{ptr: "server1", wantErr: "not a fully qualified hostname"},
+		{ptr: "server1.example.com", wantErr: ""},
+		{ptr: "server1.example.com.", wantErr: ""},
+		{ptr: "static.8.123.123.123.clients.your-server.de", wantErr: ""},
+		{ptr: "server_1.example.com", wantErr: "not a valid hostname label"},
+		{ptr: "-server.example.com", wantErr: "not a valid hostname label"},
+		{ptr: "server..example.com", wantErr: "not a valid hostname label"},
+		{ptr: "123.123.123.123", wantErr: "top-level domain cannot be numeric"},
+	}
+
+	for _, tt := range tests {
+		err := validatePTR(tt.ptr)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validatePTR(%q) = %v, want nil", tt.ptr, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("validatePTR(%q) = %v, want error containing %q", tt.ptr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetRDNS_Validation(t *testing.T) {
+	updates := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		updates++
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		response := map[string]interface{}{
+			"rdns": map[string]string{"ip": "123.123.123.123", "ptr": r.FormValue("ptr")},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	origLookup := lookupHost
+	t.Cleanup(func() { lookupHost = origLookup })
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"124.124.124.124"}, nil
+	}
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	err := setRDNS(ctx, client, "123.123.123.123", "server1", false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected validation error mentioning --force, got %v", err)
+	}
+	if updates != 0 {
+		t.Errorf("expected no API request for an invalid PTR, got %d", updates)
+	}
+
+	out := captureStdout(t, func() {
+		if err := setRDNS(ctx, client, "123.123.123.123", "server1.example.com", false); err != nil {
+			t.Fatalf("setRDNS returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "warning: server1.example.com resolves to 124.124.124.124, not to 123.123.123.123") {
+		t.Errorf("expected forward DNS warning, got:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := setRDNS(ctx, client, "123.123.123.123", "server1", true); err != nil {
+			t.Fatalf("setRDNS with --force returned error: %v", err)
+		}
+	})
+	if strings.Contains(out, "warning") {
+		t.Errorf("expected no warning with --force, got:\n%s", out)
+	}
+	if updates != 2 {
+		t.Errorf("expected 2 API requests, got %d", updates)
+	}
+}
+
+func TestListRDNS_Concurrent(t *testing.T) {
+	perServer := map[string][]map[string]string{
+		"123.123.123.1": {
+			{"ip": "123.123.123.10", "ptr": "web2.example.com"},
+			{"ip": "123.123.123.1", "ptr": "web1.example.com"},
+		},
+		"123.123.123.2": {
+			{"ip": "2a01:4f8:111:4221::2", "ptr": "db.example.com"},
+			{"ip": "123.123.123.2", "ptr": "db.example.com"},
+			// Also returned for the first server
+			{"ip": "123.123.123.10", "ptr": "web2.example.com"},
+		},
+	}
+
+	var mu sync.Mutex
+	var queried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/server":
+			response := []map[string]interface{}{
+				{"server": map[string]interface{}{"server_ip": "123.123.123.1", "server_number": 1}},
+				{"server": map[string]interface{}{"server_ip": "123.123.123.2", "server_number": 2}},
+				{"server": map[string]interface{}{"server_ip": "123.123.123.3", "server_number": 3}},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		case "/rdns":
+			serverIP := r.URL.Query().Get("server_ip")
+			mu.Lock()
+			queried = append(queried, serverIP)
+			mu.Unlock()
+
+			entries, ok := perServer[serverIP]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"status":404,"code":"RDNS_NOT_FOUND","message":"rdns entry not found"}}`))
+				return
+			}
+			var response []map[string]interface{}
+			for _, entry := range entries {
+				response = append(response, map[string]interface{}{"rdns": entry})
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	out := captureStdout(t, func() {
+		if err := listRDNS(context.Background(), client, "", 2); err != nil {
+			t.Fatalf("listRDNS returned error: %v", err)
+		}
+	})
+
+	sort.Strings(queried)
+	if strings.Join(queried, ",") != "123.123.123.1,123.123.123.2,123.123.123.3" {
+		t.Errorf("expected one request per server, got %v", queried)
+	}
+	if !strings.Contains(out, "Found 4 reverse DNS entry/entries") {
+		t.Errorf("expected duplicates to be merged, got:\n%s", out)
+	}
+
+	want := []string{"123.123.123.1", "123.123.123.2", "123.123.123.10", "2a01:4f8:111:4221::2"}
+	last := -1
+	for _, ip := range want {
+		idx := strings.Index(out, "] "+ip+"\n")
+		if idx < 0 {
+			t.Fatalf("expected %s in output, got:\n%s", ip, out)
+		}
+		if idx < last {
+			t.Errorf("expected %s to be listed after the previous IP, got:\n%s", ip, out)
+		}
+		last = idx
+	}
+}
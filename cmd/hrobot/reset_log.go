@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// The Robot API does not report past resets, so the CLI keeps its own log of
+// the resets it executed in the config directory.
+const (
+	resetLogFile       = "reset-history.json"
+	maxResetLogEntries = 500
+)
+
+// resetTypeDescriptions describes the reset types accepted by the Robot API.
+var resetTypeDescriptions = map[string]string{
+	"sw":         "software reset (CTRL+ALT+DEL)",
+	"hw":         "hardware reset (reset button)",
+	"power":      "power cycle",
+	"power_long": "shutdown (long power button press)",
+	"man":        "manual reset",
+}
+
+// resetLogEntry records one reset executed by the CLI.
+type resetLogEntry struct {
+	Time     time.Time `json:"time"`
+	ServerID int       `json:"server_id"`
+	Type     string    `json:"type"`
+}
+
+// getResetLogPath returns the path of the reset log next to the config file.
+func getResetLogPath() (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), resetLogFile), nil
+}
+
+// loadResetLog reads all logged resets, oldest first. A missing log is empty.
+func loadResetLog() ([]resetLogEntry, error) {
+	path, err := getResetLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reset history: %w", err)
+	}
+
+	var entries []resetLogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse reset history %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// recordReset appends a reset to the log, keeping only the newest
+// maxResetLogEntries entries.
+func recordReset(serverID hrobot.ServerID, resetType hrobot.ResetType, at time.Time) error {
+	entries, err := loadResetLog()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, resetLogEntry{Time: at.UTC(), ServerID: int(serverID), Type: string(resetType)})
+	if len(entries) > maxResetLogEntries {
+		entries = entries[len(entries)-maxResetLogEntries:]
+	}
+
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+	path, err := getResetLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write reset history: %w", err)
+	}
+	return nil
+}
+
+// executeAndRecordReset executes a reset and records it in the reset log. A
+// failure to write the log only prints a warning: the reset already happened.
+func executeAndRecordReset(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, resetType hrobot.ResetType) (*hrobot.Reset, error) {
+	reset, err := client.Reset.Execute(ctx, serverID, resetType)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordReset(serverID, resetType, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not record the reset: %v\n", err)
+	}
+	return reset, nil
+}
+
+// showResetHistory prints the resets of a server that were executed with this
+// CLI on this machine, newest first.
+func showResetHistory(serverID hrobot.ServerID, args []string) error {
+	outputFormat, err := parseListOutput(args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := loadResetLog()
+	if err != nil {
+		return err
+	}
+	var history []resetLogEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].ServerID == int(serverID) {
+			history = append(history, entries[i])
+		}
+	}
+
+	if outputFormat == "json" {
+		if history == nil {
+			history = []resetLogEntry{}
+		}
+		return printJSON(history)
+	}
+
+	if len(history) == 0 {
+		fmt.Printf("no resets of server #%d recorded\n", serverID)
+		fmt.Println("\nOnly resets executed with hrobot on this machine are recorded; the Robot API keeps no reset history.")
+		return nil
+	}
+
+	rows := make([][]string, len(history))
+	for i, entry := range history {
+		description := resetTypeDescriptions[entry.Type]
+		if description == "" {
+			description = entry.Type
+		}
+		rows[i] = []string{entry.Time.Local().Format("2006-01-02 15:04:05"), strconv.Itoa(entry.ServerID), entry.Type, description}
+	}
+	return renderRows(os.Stdout, outputFormat, []string{"Time", "Server", "Type", "Description"}, rows)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestExecuteReset_RecordsHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error": {"status": 409, "code": "RESET_MANUAL_ACTIVE", "message": "There is already a running manual reset"}}`)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		fmt.Fprintf(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": %q}}`, r.PostForm.Get("type"))
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	captureStdout(t, func() {
+		if err := executeReset(context.Background(), client, hrobot.ServerID(321), "hw"); err != nil {
+			t.Fatalf("executeReset returned error: %v", err)
+		}
+		if err := executeReset(context.Background(), client, hrobot.ServerID(321), "power_long"); err != nil {
+			t.Fatalf("executeReset returned error: %v", err)
+		}
+		if err := executeReset(context.Background(), client, hrobot.ServerID(999), "sw"); err != nil {
+			t.Fatalf("executeReset returned error: %v", err)
+		}
+		// Failed resets are not recorded.
+		fail = true
+		if err := executeReset(context.Background(), client, hrobot.ServerID(321), "sw"); err == nil {
+			t.Fatal("expected the reset to fail")
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := showResetHistory(hrobot.ServerID(321), []string{"--output", "json"}); err != nil {
+			t.Fatalf("showResetHistory returned error: %v", err)
+		}
+	})
+
+	var history []resetLogEntry
+	if err := json.Unmarshal([]byte(out), &history); err != nil {
+		t.Fatalf("failed to parse JSON output %q: %v", out, err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 resets of server 321, got %+v", history)
+	}
+	if history[0].Type != "power_long" || history[1].Type != "hw" {
+		t.Errorf("expected the newest reset first, got %+v", history)
+	}
+	if history[0].ServerID != 321 || history[0].Time.IsZero() {
+		t.Errorf("expected server and time to be recorded, got %+v", history[0])
+	}
+
+	out = captureStdout(t, func() {
+		if err := showResetHistory(hrobot.ServerID(321), nil); err != nil {
+			t.Fatalf("showResetHistory returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "shutdown (long power button press)") || !strings.Contains(out, "hardware reset (reset button)") {
+		t.Errorf("expected the reset types to be described, got:\n%s", out)
+	}
+}
+
+func TestShowResetHistory_Empty(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := captureStdout(t, func() {
+		if err := showResetHistory(hrobot.ServerID(321), nil); err != nil {
+			t.Fatalf("showResetHistory returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "no resets of server #321 recorded") {
+		t.Errorf("expected an empty history message, got %q", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := showResetHistory(hrobot.ServerID(321), []string{"--output=json"}); err != nil {
+			t.Fatalf("showResetHistory returned error: %v", err)
+		}
+	})
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty JSON list, got %q", out)
+	}
+}
+
+func TestRecordReset_KeepsNewestEntries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxResetLogEntries+5; i++ {
+		if err := recordReset(hrobot.ServerID(i), hrobot.ResetTypeHardware, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("recordReset returned error: %v", err)
+		}
+	}
+
+	entries, err := loadResetLog()
+	if err != nil {
+		t.Fatalf("loadResetLog returned error: %v", err)
+	}
+	if len(entries) != maxResetLogEntries {
+		t.Fatalf("expected %d entries, got %d", maxResetLogEntries, len(entries))
+	}
+	if entries[0].ServerID != 5 || entries[len(entries)-1].ServerID != maxResetLogEntries+4 {
+		t.Errorf("expected the oldest entries to be dropped, got first %d and last %d", entries[0].ServerID, entries[len(entries)-1].ServerID)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// rulesFileFormat is the structure of a rules file. It matches the output of
+// 'firewall list-rules --output json', so other fields of that output, such
+// as server_ip and status, are accepted and ignored. The fields of a rule are
+// checked strictly so that a misspelled field is not silently dropped.
+//
+//	{
+//	  "whitelist_hos": true,
+//	  "filter_ipv6": false,
+//	  "rules": {
+//	    "input": [
+//	      {"name": "Allow SSH", "ip_version": "ipv4", "action": "accept",
+//	       "protocol": "tcp", "src_ip": "1.2.3.4/32", "dst_port": "22"}
+//	    ],
+//	    "output": [{"name": "Allow all", "action": "accept"}]
+//	  }
+//	}
+type rulesFileFormat struct {
+	WhitelistHOS *bool `json:"whitelist_hos"`
+	FilterIPv6   *bool `json:"filter_ipv6"`
+	Rules        *struct {
+		Input  []json.RawMessage `json:"input"`
+		Output []json.RawMessage `json:"output"`
+	} `json:"rules"`
+}
+
+// rulesFile is a parsed and validated rules file. The settings are nil when
+// the file does not set them.
+type rulesFile struct {
+	WhitelistHOS *bool
+	FilterIPv6   *bool
+	Rules        hrobot.FirewallRules
+}
+
+// loadRulesFile reads, parses and validates a rules file, or stdin when path
+// is "-".
+func loadRulesFile(path string) (*rulesFile, error) {
+	data, err := readRulesFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	rf, err := parseRulesFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules file: %w", err)
+	}
+	return rf, nil
+}
+
+// parseRulesFile parses and validates the content of a rules file. Errors
+// name the line and column of malformed JSON, or the direction and index of
+// an invalid rule.
+func parseRulesFile(data []byte) (*rulesFile, error) {
+	var format rulesFileFormat
+	if err := json.Unmarshal(data, &format); err != nil {
+		return nil, jsonLocationError(data, err)
+	}
+	if format.Rules == nil {
+		return nil, fmt.Errorf(`missing "rules" object with "input" and "output" lists`)
+	}
+
+	input, err := decodeRules("input", format.Rules.Input)
+	if err != nil {
+		return nil, err
+	}
+	output, err := decodeRules("output", format.Rules.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	rf := &rulesFile{
+		WhitelistHOS: format.WhitelistHOS,
+		FilterIPv6:   format.FilterIPv6,
+		Rules:        hrobot.FirewallRules{Input: input, Output: output},
+	}
+	if err := validateFirewallRules(rf.Rules); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// decodeRules decodes the rules of one direction, rejecting unknown fields.
+func decodeRules(direction string, raw []json.RawMessage) ([]hrobot.FirewallRule, error) {
+	rules := make([]hrobot.FirewallRule, 0, len(raw))
+	for i, data := range raw {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+
+		var rule hrobot.FirewallRule
+		if err := dec.Decode(&rule); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return nil, fmt.Errorf("%s rule %d: field %q must be a %s, got %s", direction, i, typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value)
+			}
+			// The decoder reports unknown fields as `json: unknown field "x"`
+			return nil, fmt.Errorf("%s rule %d: %s", direction, i, strings.TrimPrefix(err.Error(), "json: "))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// jsonLocationError adds the line and column to a JSON syntax or type error.
+func jsonLocationError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := offsetPosition(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %s", line, col, syntaxErr.Error())
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		line, col := offsetPosition(data, typeErr.Offset)
+		return fmt.Errorf("line %d, column %d: field %q must be a %s, got %s", line, col, typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value)
+	}
+
+	return err
+}
+
+// jsonTypeName names the JSON type that decodes into t.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return "number"
+	}
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column.
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	// The offset points just past the offending character
+	col := int(offset) - bytes.LastIndexByte(before, '\n') - 1
+	return line, max(col, 1)
+}
+
+// validatePortSpec checks a rule port: a port, a range such as "1024-65535",
+// or a comma-separated list of both.
+func validatePortSpec(spec string) error {
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		low, err := parsePortNumber(from)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		high, err := parsePortNumber(to)
+		if err != nil {
+			return err
+		}
+		if low > high {
+			return fmt.Errorf("port range %q starts after it ends", part)
+		}
+	}
+	return nil
+}
+
+// parsePortNumber parses a port between 0 and 65535.
+func parsePortNumber(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q (must be 0-65535)", s)
+	}
+	return port, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRulesFile_Valid(t *testing.T) {
+	content := `{
+  "server_ip": "123.123.123.123",
+  "status": "active",
+  "whitelist_hos": true,
+  "rules": {
+    "input": [
+      {"name": "Allow SSH", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "src_ip": "1.2.3.4/32", "dst_port": "22"},
+      {"name": "Allow mail", "action": "accept", "protocol": "tcp", "dst_port": "25,465,1024-2048"}
+    ],
+    "output": [{"name": "Allow all", "action": "accept"}]
+  }
+}`
+
+	rf, err := parseRulesFile([]byte(content))
+	if err != nil {
+		t.Fatalf("parseRulesFile returned error: %v", err)
+	}
+	if len(rf.Rules.Input) != 2 || len(rf.Rules.Output) != 1 {
+		t.Errorf("expected 2 input and 1 output rule, got %d and %d", len(rf.Rules.Input), len(rf.Rules.Output))
+	}
+	if rf.WhitelistHOS == nil || !*rf.WhitelistHOS {
+		t.Error("expected whitelist_hos to be read from the file")
+	}
+	if rf.FilterIPv6 != nil {
+		t.Errorf("expected filter_ipv6 to be unset, got %v", *rf.FilterIPv6)
+	}
+}
+
+func TestParseRulesFile_Malformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "syntax error",
+			content: "{\n  \"rules\": {\n    \"input\": [\n      {\"action\": \"accept\",}\n    ]\n  }\n}",
+			wantErr: "line 4, column 27: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			name:    "wrong type",
+			content: "{\n  \"rules\": {\"input\": {}}\n}",
+			wantErr: `line 2, column 22: field "rules.input" must be a list, got object`,
+		},
+		{
+			name:    "missing rules",
+			content: `{"input": []}`,
+			wantErr: `missing "rules" object`,
+		},
+		{
+			name:    "unknown rule field",
+			content: `{"rules": {"input": [{"action": "accept"}, {"action": "accept", "source_ip": "1.2.3.4"}]}}`,
+			wantErr: `input rule 1: unknown field "source_ip"`,
+		},
+		{
+			name:    "rule field type",
+			content: `{"rules": {"output": [{"action": "accept", "dst_port": 22}]}}`,
+			wantErr: `output rule 0: field "dst_port" must be a string, got number`,
+		},
+		{
+			name:    "invalid action",
+			content: `{"rules": {"input": [{"action": "accept"}, {"action": "accept"}, {"name": "web", "action": "allow"}]}}`,
+			wantErr: `input rule 2 ("web"): action must be 'accept' or 'discard', got "allow"`,
+		},
+		{
+			name:    "invalid protocol",
+			content: `{"rules": {"input": [{"name": "sctp", "action": "accept", "protocol": "sctp"}]}}`,
+			wantErr: `input rule 0 ("sctp"): protocol must be one of`,
+		},
+		{
+			name:    "invalid cidr",
+			content: `{"rules": {"output": [{"action": "accept"}, {"name": "net", "action": "discard", "dst_ip": "10.0.0.0/33"}]}}`,
+			wantErr: `output rule 1 ("net"): invalid IP address or CIDR "10.0.0.0/33"`,
+		},
+		{
+			name:    "port out of range",
+			content: `{"rules": {"input": [{"name": "ssh", "action": "accept", "protocol": "tcp", "dst_port": "65536"}]}}`,
+			wantErr: `input rule 0 ("ssh"): invalid port "65536" (must be 0-65535)`,
+		},
+		{
+			name:    "reversed port range",
+			content: `{"rules": {"input": [{"name": "high", "action": "accept", "protocol": "udp", "src_port": "2000-1000"}]}}`,
+			wantErr: `input rule 0 ("high"): port range "2000-1000" starts after it ends`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRulesFile([]byte(tt.content))
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
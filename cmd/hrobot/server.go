@@ -7,21 +7,77 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/aquasecurity/table"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
-func getServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
-	server, err := client.Server.Get(ctx, serverID)
+// serverDetail is the JSON representation of a single server.
+// Absent addresses are encoded as null instead of an empty string.
+type serverDetail struct {
+	ServerNumber int      `json:"server_number"`
+	ServerName   string   `json:"server_name"`
+	Product      string   `json:"product"`
+	Datacenter   string   `json:"datacenter"`
+	Status       string   `json:"status"`
+	Traffic      string   `json:"traffic"`
+	Cancelled    bool     `json:"cancelled"`
+	PaidUntil    string   `json:"paid_until"`
+	IPv4         *string  `json:"ipv4"`
+	IPv6         *string  `json:"ipv6"`
+	IPs          []string `json:"ips"`
+	Subnets      []string `json:"subnets"`
+	Power        string   `json:"power,omitempty"`
+}
+
+// newServerDetail builds the JSON representation of a server. The primary
+// IPv6 is the first IPv6 subnet of the server.
+func newServerDetail(server *hrobot.Server) serverDetail {
+	detail := serverDetail{
+		ServerNumber: server.ServerNumber,
+		ServerName:   server.ServerName,
+		Product:      server.Product,
+		Datacenter:   server.DC,
+		Status:       string(server.Status),
+		Traffic:      server.Traffic.String(),
+		Cancelled:    server.Cancelled,
+		PaidUntil:    server.PaidUntil,
+		IPs:          []string{},
+		Subnets:      []string{},
+	}
+
+	if server.ServerIP != nil && server.ServerIP.To4() != nil {
+		ipv4 := server.ServerIP.String()
+		detail.IPv4 = &ipv4
+	}
+
+	for _, ip := range server.IP {
+		detail.IPs = append(detail.IPs, ip.String())
+	}
+
+	for _, subnet := range server.Subnet {
+		cidr := fmt.Sprintf("%s/%s", subnet.IP.String(), subnet.Mask)
+		detail.Subnets = append(detail.Subnets, cidr)
+		if detail.IPv6 == nil && subnet.IP.To4() == nil {
+			detail.IPv6 = &cidr
+		}
+	}
+
+	return detail
+}
+
+func getServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, outputFormat string) error {
+	server, err := getServerCached(ctx, client, serverID)
 	if err != nil {
 		return fmt.Errorf("failed to get server: %w", err)
 	}
 
+	if outputFormat == "json" {
+		return printJSON(newServerDetail(server))
+	}
+
 	// Get reset info to retrieve operating status
 	reset, err := client.Reset.Get(ctx, serverID)
 	var operatingStatus string
@@ -47,7 +103,11 @@ func getServer(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 	fmt.Printf("  Cancelled:         %v\n", server.Cancelled)
 	fmt.Printf("  Paid Until:        %s\n", server.PaidUntil)
 
-	if len(server.IP) > 0 {
+	// Single IPs and subnets carry more detail (separate MACs, gateways,
+	// failover) than the server object; fall back to the latter if unavailable
+	if ips, err := client.Server.ListIPs(ctx, serverID); err == nil {
+		printServerIPs(server, ips)
+	} else if len(server.IP) > 0 {
 		fmt.Printf("  IP Addresses:\n")
 		for i, ip := range server.IP {
 			fmt.Printf("    [%d] %s", i, ip.String())
@@ -58,7 +118,9 @@ func getServer(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 		}
 	}
 
-	if len(server.Subnet) > 0 {
+	if subnets, err := client.Server.ListSubnets(ctx, serverID); err == nil {
+		printServerSubnets(subnets)
+	} else if len(server.Subnet) > 0 {
 		fmt.Printf("  Subnets:\n")
 		for i, subnet := range server.Subnet {
 			fmt.Printf("    [%d] %s/%s\n", i, subnet.IP.String(), subnet.Mask)
@@ -68,50 +130,155 @@ func getServer(ctx context.Context, client *hrobot.Client, serverID hrobot.Serve
 	return nil
 }
 
-func listServers(ctx context.Context, client *hrobot.Client) error {
+// printServerIPs prints the single IP addresses of a server.
+func printServerIPs(server *hrobot.Server, ips []hrobot.IPAddress) {
+	if len(ips) == 0 {
+		return
+	}
+
+	fmt.Printf("  IP Addresses:\n")
+	for i, ip := range ips {
+		fmt.Printf("    [%d] %s", i, ip.IP.String())
+		var notes []string
+		if ip.IP.Equal(server.ServerIP) {
+			notes = append(notes, "primary IPv4")
+		}
+		if ip.SeparateMac != "" {
+			notes = append(notes, "separate MAC "+ip.SeparateMac)
+		}
+		if ip.Locked {
+			notes = append(notes, "locked")
+		}
+		if len(notes) > 0 {
+			fmt.Printf(" (%s)", strings.Join(notes, ", "))
+		}
+		fmt.Println()
+	}
+}
+
+// printServerSubnets prints the additional subnets of a server.
+func printServerSubnets(subnets []hrobot.ServerSubnet) {
+	if len(subnets) == 0 {
+		return
+	}
+
+	fmt.Printf("  Subnets:\n")
+	for i, subnet := range subnets {
+		fmt.Printf("    [%d] %s", i, subnet.String())
+		var notes []string
+		if subnet.Gateway != nil {
+			notes = append(notes, "gateway "+subnet.Gateway.String())
+		}
+		if subnet.Failover {
+			notes = append(notes, "failover")
+		}
+		if subnet.Locked {
+			notes = append(notes, "locked")
+		}
+		if len(notes) > 0 {
+			fmt.Printf(" (%s)", strings.Join(notes, ", "))
+		}
+		fmt.Println()
+	}
+}
+
+// defaultPowerConcurrency is how many operating statuses server list
+// --with-power fetches at once.
+const defaultPowerConcurrency = 4
+
+// listServers prints the servers matching filter. With powerConcurrency > 0,
+// the operating status of every listed server is fetched from the reset
+// endpoint with that many parallel requests and shown in a Power column.
+func listServers(ctx context.Context, client *hrobot.Client, outputFormat string, filter serverFilter, powerConcurrency int) error {
 	servers, err := client.Server.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list servers: %w", err)
 	}
+	servers = filterServers(servers, filter)
+
+	var power []string
+	if powerConcurrency > 0 {
+		power = fetchPowerStates(ctx, client, servers, powerConcurrency)
+	}
 
-	fmt.Printf("Found %d server(s):\n\n", len(servers))
+	if outputFormat == "json" {
+		details := make([]serverDetail, 0, len(servers))
+		for i := range servers {
+			detail := newServerDetail(&servers[i])
+			if power != nil {
+				detail.Power = power[i]
+			}
+			details = append(details, detail)
+		}
+		return printJSON(details)
+	}
 
-	t := table.New(os.Stdout)
-	t.SetHeaders("Server #", "Name", "IP", "Product", "DC", "Status")
+	if outputFormat != "csv" {
+		fmt.Printf("Found %d server(s):\n\n", len(servers))
+	}
 
-	for _, server := range servers {
-		t.AddRow(
+	headers := []string{"Server #", "Name", "IP", "Product", "DC", "Status"}
+	if power != nil {
+		headers = append(headers, "Power")
+	}
+	rows := make([][]string, 0, len(servers))
+	for i, server := range servers {
+		row := []string{
 			fmt.Sprintf("%d", server.ServerNumber),
 			server.ServerName,
 			server.ServerIP.String(),
 			server.Product,
 			server.DC,
 			string(server.Status),
-		)
+		}
+		if power != nil {
+			row = append(row, power[i])
+		}
+		rows = append(rows, row)
 	}
 
-	t.Render()
-	return nil
+	return renderRows(os.Stdout, outputFormat, headers, rows)
 }
 
-func executeReset(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, resetType string) error {
-	// Validate reset type
-	validTypes := map[string]string{
-		"sw":         "software reset (CTRL+ALT+DEL)",
-		"hw":         "hardware reset (reset button)",
-		"power":      "power cycle",
-		"power_long": "shutdown (long power button press)",
-		"man":        "manual reset",
+// fetchPowerStates returns the operating status of every server, in the
+// order of servers. As in server describe, a status that cannot be fetched,
+// e.g. for a server without reset support, is shown as "(unavailable)".
+func fetchPowerStates(ctx context.Context, client *hrobot.Client, servers []hrobot.Server, concurrency int) []string {
+	states := make([]string, len(servers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				reset, err := client.Reset.Get(ctx, hrobot.ServerID(servers[idx].ServerNumber))
+				if err != nil {
+					states[idx] = "(unavailable)"
+					continue
+				}
+				states[idx] = formatOperatingStatus(reset.OperatingStatus)
+			}
+		}()
 	}
+	for idx := range servers {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	return states
+}
 
-	description, valid := validTypes[resetType]
+func executeReset(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, resetType string) error {
+	// Validate reset type
+	description, valid := resetTypeDescriptions[resetType]
 	if !valid {
 		return fmt.Errorf("invalid reset type: %s\nValid types: sw, hw, power, power_long, man", resetType)
 	}
 
 	fmt.Printf("Executing %s on server #%d...\n", description, serverID)
 
-	reset, err := client.Reset.Execute(ctx, serverID, hrobot.ResetType(resetType))
+	reset, err := executeAndRecordReset(ctx, client, serverID, hrobot.ResetType(resetType))
 	if err != nil {
 		return fmt.Errorf("failed to execute reset: %w", err)
 	}
@@ -123,7 +290,116 @@ func executeReset(ctx context.Context, client *hrobot.Client, serverID hrobot.Se
 	return nil
 }
 
-func powerOnServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
+// defaultPowerWaitTimeout bounds server poweron/poweroff --wait when no
+// --wait-timeout is given.
+const defaultPowerWaitTimeout = 5 * time.Minute
+
+// powerPollInterval is how often the operating status is polled while waiting
+// for a power state change.
+var powerPollInterval = 5 * time.Second
+
+// isPoweredOn reports whether an operating status means the server is on. The
+// API omits the status for servers that are running.
+func isPoweredOn(status string) bool {
+	status = strings.ToLower(status)
+	return status == "ready" || status == "running" || status == ""
+}
+
+// isPoweredOff reports whether an operating status means the server is off.
+func isPoweredOff(status string) bool {
+	status = strings.ToLower(status)
+	return status == "off" || status == "powered off" || status == "shutdown"
+}
+
+// formatOperatingStatus returns the operating status for display.
+func formatOperatingStatus(status string) string {
+	if status == "" {
+		return "running"
+	}
+	return status
+}
+
+// waitForPowerState polls the operating status until the server is powered on
+// (or off) and returns the final status. It gives up after timeout.
+func waitForPowerState(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, on bool, timeout time.Duration) (string, error) {
+	reached := isPoweredOff
+	state := "off"
+	if on {
+		reached = isPoweredOn
+		state = "on"
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	progress := newProgressReporter(fmt.Sprintf("server #%d", serverID), false)
+	progress.start()
+	defer progress.stop()
+
+	started := time.Now()
+	ticker := time.NewTicker(powerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		reset, err := client.Reset.Get(waitCtx, serverID)
+		if err != nil {
+			if waitCtx.Err() != nil && ctx.Err() == nil {
+				return "", fmt.Errorf("timed out after %s waiting for server #%d to power %s", timeout, serverID, state)
+			}
+			return "", fmt.Errorf("failed to get server status: %w", err)
+		}
+		if reached(reset.OperatingStatus) {
+			return reset.OperatingStatus, nil
+		}
+		progress.update(formatOperatingStatus(reset.OperatingStatus), time.Since(started))
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", fmt.Errorf("timed out after %s waiting for server #%d to power %s (last status: %s)", timeout, serverID, state, formatOperatingStatus(reset.OperatingStatus))
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportPowerState prints the operating status after a power command. With
+// wait it polls until the requested state is reached, otherwise it shows the
+// status as reported right after the command, which may not have changed yet.
+func reportPowerState(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, on, wait bool, timeout time.Duration) error {
+	if wait {
+		if timeout <= 0 {
+			timeout = defaultPowerWaitTimeout
+		}
+		status, err := waitForPowerState(ctx, client, serverID, on, timeout)
+		if err != nil {
+			return err
+		}
+		state := "off"
+		if on {
+			state = "on"
+		}
+		fmt.Printf("✓ Server #%d is powered %s\n", serverID, state)
+		fmt.Printf("  Operating Status: %s\n", formatOperatingStatus(status))
+		return nil
+	}
+
+	// The power command already succeeded, so failing to read the new state
+	// is only worth a warning.
+	reset, err := client.Reset.Get(ctx, serverID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not read the power state: %v\n", err)
+		return nil
+	}
+	fmt.Printf("  Operating Status: %s\n", formatOperatingStatus(reset.OperatingStatus))
+	if (on && !isPoweredOn(reset.OperatingStatus)) || (!on && !isPoweredOff(reset.OperatingStatus)) {
+		fmt.Println("\nℹ the power state may take a moment to change; use --wait to wait for it")
+	}
+	return nil
+}
+
+func powerOnServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, wait bool, timeout time.Duration) error {
 	// Get reset options to check operating status
 	reset, err := client.Reset.Get(ctx, serverID)
 	if err != nil {
@@ -131,8 +407,7 @@ func powerOnServer(ctx context.Context, client *hrobot.Client, serverID hrobot.S
 	}
 
 	// Check if server is already powered on
-	status := strings.ToLower(reset.OperatingStatus)
-	if status == "ready" || status == "running" || status == "" {
+	if isPoweredOn(reset.OperatingStatus) {
 		fmt.Printf("Server #%d is already powered on\n", serverID)
 		fmt.Printf("  Server IP:        %s\n", reset.ServerIP.String())
 		fmt.Printf("  Operating Status: %s\n", reset.OperatingStatus)
@@ -143,7 +418,7 @@ func powerOnServer(ctx context.Context, client *hrobot.Client, serverID hrobot.S
 	fmt.Printf("Powering on server #%d...\n", serverID)
 	fmt.Printf("  Current status: %s\n\n", reset.OperatingStatus)
 
-	resetResult, err := client.Reset.Execute(ctx, serverID, hrobot.ResetTypePower)
+	resetResult, err := executeAndRecordReset(ctx, client, serverID, hrobot.ResetTypePower)
 	if err != nil {
 		return fmt.Errorf("failed to power on server: %w", err)
 	}
@@ -152,10 +427,10 @@ func powerOnServer(ctx context.Context, client *hrobot.Client, serverID hrobot.S
 	fmt.Printf("  Server IP: %s\n", resetResult.ServerIP.String())
 	fmt.Printf("  Type:      %s\n", resetResult.Type)
 
-	return nil
+	return reportPowerState(ctx, client, serverID, true, wait, timeout)
 }
 
-func powerOffServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
+func powerOffServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, wait bool, timeout time.Duration) error {
 	// Get reset options to check operating status
 	reset, err := client.Reset.Get(ctx, serverID)
 	if err != nil {
@@ -163,8 +438,7 @@ func powerOffServer(ctx context.Context, client *hrobot.Client, serverID hrobot.
 	}
 
 	// Check if server is already powered off
-	status := strings.ToLower(reset.OperatingStatus)
-	if status == "off" || status == "powered off" || status == "shutdown" {
+	if isPoweredOff(reset.OperatingStatus) {
 		fmt.Printf("Server #%d is already powered off\n", serverID)
 		fmt.Printf("  Server IP:        %s\n", reset.ServerIP.String())
 		fmt.Printf("  Operating Status: %s\n", reset.OperatingStatus)
@@ -175,7 +449,7 @@ func powerOffServer(ctx context.Context, client *hrobot.Client, serverID hrobot.
 	fmt.Printf("Powering off server #%d...\n", serverID)
 	fmt.Printf("  Current status: %s\n\n", reset.OperatingStatus)
 
-	resetResult, err := client.Reset.Execute(ctx, serverID, hrobot.ResetTypePower)
+	resetResult, err := executeAndRecordReset(ctx, client, serverID, hrobot.ResetTypePower)
 	if err != nil {
 		return fmt.Errorf("failed to power off server: %w", err)
 	}
@@ -184,15 +458,32 @@ func powerOffServer(ctx context.Context, client *hrobot.Client, serverID hrobot.
 	fmt.Printf("  Server IP: %s\n", resetResult.ServerIP.String())
 	fmt.Printf("  Type:      %s\n", resetResult.Type)
 
-	return nil
+	return reportPowerState(ctx, client, serverID, false, wait, timeout)
+}
+
+// wolError turns Wake-on-LAN API errors into a message that says what went wrong.
+func wolError(serverID hrobot.ServerID, err error) error {
+	switch {
+	case hrobot.IsWOLNotAvailableError(err):
+		return fmt.Errorf("server #%d does not support Wake-on-LAN; use 'server poweron' instead", serverID)
+	case hrobot.IsAPIError(err, hrobot.ErrWOLFailed):
+		return fmt.Errorf("the Wake-on-LAN packet could not be sent to server #%d, try again later: %w", serverID, err)
+	default:
+		return fmt.Errorf("failed to send Wake-on-LAN packet: %w", err)
+	}
 }
 
 func wakeServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID) error {
+	// Check that the server supports Wake-on-LAN before sending the packet
+	if _, err := client.WOL.Get(ctx, serverID); err != nil {
+		return wolError(serverID, err)
+	}
+
 	fmt.Printf("Sending Wake-on-LAN packet to server #%d...\n", serverID)
 
 	wol, err := client.WOL.Send(ctx, serverID)
 	if err != nil {
-		return fmt.Errorf("failed to send Wake-on-LAN packet: %w", err)
+		return wolError(serverID, err)
 	}
 
 	fmt.Printf("✓ Wake-on-LAN packet sent successfully!\n")
@@ -205,192 +496,3 @@ func wakeServer(ctx context.Context, client *hrobot.Client, serverID hrobot.Serv
 
 	return nil
 }
-
-func showTraffic(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, args []string) error {
-	// Parse flags
-	days := 14
-	var fromDate, toDate string
-
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--days":
-			if i+1 < len(args) {
-				d, err := strconv.Atoi(args[i+1])
-				if err != nil {
-					return fmt.Errorf("invalid --days value: %s", args[i+1])
-				}
-				days = d
-				i++
-			}
-		case "--from":
-			if i+1 < len(args) {
-				fromDate = args[i+1]
-				i++
-			}
-		case "--to":
-			if i+1 < len(args) {
-				toDate = args[i+1]
-				i++
-			}
-		}
-	}
-
-	// Get server details to find IP address
-	server, err := client.Server.Get(ctx, serverID)
-	if err != nil {
-		return fmt.Errorf("failed to get server: %w", err)
-	}
-
-	serverIP := server.ServerIP.String()
-
-	// Calculate date range if not provided
-	if fromDate == "" || toDate == "" {
-		now := time.Now()
-		toDate = now.Format("2006-01-02")
-		fromDate = now.AddDate(0, 0, -days+1).Format("2006-01-02")
-	}
-
-	// Fetch traffic data
-	fmt.Printf("Fetching traffic data for server #%d (%s)...\n", serverID, serverIP)
-	fmt.Printf("  Period: %s to %s\n", fromDate, toDate)
-
-	// Parse dates to check if they span multiple months
-	fromTime, err := time.Parse("2006-01-02", fromDate)
-	if err != nil {
-		return fmt.Errorf("invalid from date: %w", err)
-	}
-	toTime, err := time.Parse("2006-01-02", toDate)
-	if err != nil {
-		return fmt.Errorf("invalid to date: %w", err)
-	}
-
-	fmt.Println()
-
-	// If query spans multiple months, we need to make separate requests
-	// The API only supports single-month queries with type=month
-	allData := make(map[string]hrobot.TrafficStats)
-
-	// Generate list of month ranges to query
-	currentStart := fromTime
-	for currentStart.Before(toTime) || currentStart.Equal(toTime) {
-		// Calculate end of current month or toTime, whichever is earlier
-		endOfMonth := time.Date(currentStart.Year(), currentStart.Month()+1, 0, 0, 0, 0, 0, currentStart.Location())
-
-		rangeEnd := toTime
-		if endOfMonth.Before(toTime) {
-			rangeEnd = endOfMonth
-		}
-
-		// Query this month's data
-		params := hrobot.TrafficGetParams{
-			Type:         hrobot.TrafficTypeMonth,
-			From:         currentStart.Format("2006-01-02"),
-			To:           rangeEnd.Format("2006-01-02"),
-			IP:           serverIP,
-			SingleValues: true,
-		}
-
-		trafficData, err2 := client.Traffic.Get(ctx, params)
-		if err2 != nil {
-			return fmt.Errorf("failed to get traffic data for %s: %w", currentStart.Format("2006-01"), err2)
-		}
-
-		// Merge data - convert day numbers to full dates
-		if ipData, ok := trafficData.Data[serverIP]; ok {
-			yearMonth := currentStart.Format("2006-01")
-			for day, stats := range ipData {
-				// Construct full date from year-month and day number
-				fullDate := fmt.Sprintf("%s-%s", yearMonth, day)
-				allData[fullDate] = stats
-			}
-		}
-
-		// Move to next month
-		currentStart = endOfMonth.AddDate(0, 0, 1)
-	}
-
-	if len(allData) == 0 {
-		fmt.Println("No traffic data available for this period.")
-		return nil
-	}
-
-	// Filter data to only include dates within the requested range
-	ipData := make(map[string]hrobot.TrafficStats)
-	for date, stats := range allData {
-		// Simple string comparison works since dates are in YYYY-MM-DD format
-		if date >= fromDate && date <= toDate {
-			ipData[date] = stats
-		}
-	}
-
-	// Sort dates and find max traffic for scaling
-	var dates []string
-	maxTraffic := 0.0
-	for date := range ipData {
-		dates = append(dates, date)
-		if ipData[date].Sum > maxTraffic {
-			maxTraffic = ipData[date].Sum
-		}
-	}
-	sort.Strings(dates)
-
-	// Display traffic graph
-	fmt.Printf("Traffic Statistics (GB)\n\n")
-
-	// Determine scale for bar chart
-	barWidth := 50
-	scale := maxTraffic / float64(barWidth)
-	if scale == 0 {
-		scale = 1
-	}
-
-	totalIn := 0.0
-	totalOut := 0.0
-	totalSum := 0.0
-
-	// fromTime is already parsed above, no need to parse again
-
-	// Create table
-	t := table.New(os.Stdout)
-	t.SetHeaders("Date", "Download", "Upload", "Graph")
-
-	for _, date := range dates {
-		traffic := ipData[date]
-		totalIn += traffic.In
-		totalOut += traffic.Out
-		totalSum += traffic.Sum
-
-		// Create bar
-		barLength := int(traffic.Sum / scale)
-		if barLength > barWidth {
-			barLength = barWidth
-		}
-		bar := strings.Repeat("█", barLength)
-
-		// Format date properly
-		displayDate := date
-		if len(date) == 2 {
-			// Day of month only - construct full date
-			dayNum, err := strconv.Atoi(date)
-			if err == nil {
-				// Use the year and month from the request period
-				fullDate := time.Date(fromTime.Year(), fromTime.Month(), dayNum, 0, 0, 0, 0, time.UTC)
-				displayDate = fullDate.Format("2006-01-02")
-			}
-		}
-
-		// Add row
-		t.AddRow(
-			displayDate,
-			fmt.Sprintf("%.2f GB", traffic.In),
-			fmt.Sprintf("%.2f GB", traffic.Out),
-			bar,
-		)
-	}
-
-	t.Render()
-	fmt.Printf("\nTotal Traffic: %.2f GB (↓%.2f GB in, ↑%.2f GB out)\n", totalSum, totalIn, totalOut)
-	fmt.Printf("Average per day: %.2f GB\n", totalSum/float64(len(dates)))
-
-	return nil
-}
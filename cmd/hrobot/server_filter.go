@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// serverFilterFields maps the fields of a server list filter to the server
+// values they compare.
+var serverFilterFields = map[string]func(server *hrobot.Server) string{
+	"number":     func(s *hrobot.Server) string { return strconv.Itoa(s.ServerNumber) },
+	"name":       func(s *hrobot.Server) string { return s.ServerName },
+	"ip":         func(s *hrobot.Server) string { return s.ServerIP.String() },
+	"product":    func(s *hrobot.Server) string { return s.Product },
+	"datacenter": func(s *hrobot.Server) string { return s.DC },
+	"status":     func(s *hrobot.Server) string { return string(s.Status) },
+	"cancelled":  func(s *hrobot.Server) string { return strconv.FormatBool(s.Cancelled) },
+	"paid_until": func(s *hrobot.Server) string { return s.PaidUntil },
+}
+
+// serverFilterAliases are alternative names of filter fields.
+var serverFilterAliases = map[string]string{
+	"dc":            "datacenter",
+	"server_number": "number",
+	"server_name":   "name",
+	"server_ip":     "ip",
+}
+
+// serverPredicate is one condition of a server list filter.
+type serverPredicate struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// serverFilter selects servers matching all of its predicates.
+type serverFilter []serverPredicate
+
+// parseServerFilter parses a --filter expression: comma-separated predicates
+// that must all match. A predicate is one of
+//
+//	field=value   the field equals value, ignoring case
+//	field!=value  the field does not equal value
+//	field~value   the field contains value, ignoring case
+//	value         the server name contains value
+//
+// For the datacenter, "=" also matches a location, so datacenter=FSN1
+// matches FSN1-DC14.
+func parseServerFilter(expr string) (serverFilter, error) {
+	var filter serverFilter
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		predicate, err := parseServerPredicate(term)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, predicate)
+	}
+	return filter, nil
+}
+
+// parseServerPredicate parses one predicate of a filter expression.
+func parseServerPredicate(term string) (serverPredicate, error) {
+	index := strings.IndexAny(term, "=!~")
+	if index < 0 {
+		return serverPredicate{Field: "name", Op: "~", Value: term}, nil
+	}
+
+	field := strings.ToLower(strings.TrimSpace(term[:index]))
+	op := term[index : index+1]
+	value := term[index+1:]
+	if op == "!" {
+		if !strings.HasPrefix(value, "=") {
+			return serverPredicate{}, fmt.Errorf("invalid filter %q (use field=value, field!=value or field~value)", term)
+		}
+		op, value = "!=", value[1:]
+	}
+
+	if alias, ok := serverFilterAliases[field]; ok {
+		field = alias
+	}
+	if _, ok := serverFilterFields[field]; !ok {
+		fields := make([]string, 0, len(serverFilterFields))
+		for name := range serverFilterFields {
+			fields = append(fields, name)
+		}
+		slices.Sort(fields)
+		return serverPredicate{}, fmt.Errorf("invalid filter %q: unknown field %q (available: %s)", term, field, strings.Join(fields, ", "))
+	}
+
+	return serverPredicate{Field: field, Op: op, Value: strings.TrimSpace(value)}, nil
+}
+
+// matches reports whether the server satisfies the predicate.
+func (p serverPredicate) matches(server *hrobot.Server) bool {
+	actual := serverFilterFields[p.Field](server)
+	switch p.Op {
+	case "~":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(p.Value))
+	case "!=":
+		return !p.equals(server, actual)
+	default:
+		return p.equals(server, actual)
+	}
+}
+
+// equals compares a field value with the predicate value, ignoring case. A
+// datacenter is compared like the SDK does, so a location matches as well.
+func (p serverPredicate) equals(server *hrobot.Server, actual string) bool {
+	if p.Field == "datacenter" && p.Value != "" {
+		return hrobot.ServerListOptions{Datacenter: p.Value}.Matches(server)
+	}
+	return strings.EqualFold(actual, p.Value)
+}
+
+// matches reports whether the server satisfies all predicates. An empty
+// filter matches every server.
+func (f serverFilter) matches(server *hrobot.Server) bool {
+	for _, predicate := range f {
+		if !predicate.matches(server) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterServers returns the servers matching the filter, in their original order.
+func filterServers(servers []hrobot.Server, filter serverFilter) []hrobot.Server {
+	if len(filter) == 0 {
+		return servers
+	}
+	var filtered []hrobot.Server
+	for i := range servers {
+		if filter.matches(&servers[i]) {
+			filtered = append(filtered, servers[i])
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func testFilterServers() []hrobot.Server {
+	return []hrobot.Server{
+		{ServerNumber: 321, ServerName: "web-1", ServerIP: net.ParseIP("123.123.123.123"), Product: "AX41-NVMe", DC: "FSN1-DC14", Status: hrobot.ServerStatusReady},
+		{ServerNumber: 322, ServerName: "web-2", ServerIP: net.ParseIP("123.123.123.124"), Product: "AX41-NVMe", DC: "HEL1-DC2", Status: hrobot.ServerStatusReady, Cancelled: true},
+		{ServerNumber: 400, ServerName: "db", ServerIP: net.ParseIP("124.124.124.124"), Product: "AX102", DC: "FSN1-DC8", Status: hrobot.ServerStatusInProcess},
+	}
+}
+
+func TestFilterServers(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{expr: "", want: []int{321, 322, 400}},
+		{expr: "datacenter=FSN1", want: []int{321, 400}},
+		{expr: "dc=fsn1-dc14", want: []int{321}},
+		{expr: "datacenter=FSN", want: nil},
+		{expr: "status=ready", want: []int{321, 322}},
+		{expr: "status!=ready", want: []int{400}},
+		{expr: "product~ax41", want: []int{321, 322}},
+		{expr: "product=AX41", want: nil},
+		{expr: "web", want: []int{321, 322}},
+		{expr: "name~db", want: []int{400}},
+		{expr: "number=322", want: []int{322}},
+		{expr: "ip~124.124", want: []int{400}},
+		{expr: "cancelled=true", want: []int{322}},
+		{expr: "datacenter=FSN1, status=ready", want: []int{321}},
+		{expr: "product~AX41,status=ready,web-2", want: []int{322}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := parseServerFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("parseServerFilter(%q) returned error: %v", tt.expr, err)
+			}
+
+			var got []int
+			for _, server := range filterServers(testFilterServers(), filter) {
+				got = append(got, server.ServerNumber)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("filter %q matched %v, want %v", tt.expr, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("filter %q matched %v, want %v", tt.expr, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseServerFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "location=FSN1", wantErr: `unknown field "location"`},
+		{expr: "status!ready", wantErr: "use field=value, field!=value or field~value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseServerFilter(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseServerFilter(%q) error = %v, want error containing %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -20,7 +20,7 @@ import (
 func sshToServer(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, user string) error {
 	// Step 1: Get server details to obtain IP address
 	fmt.Printf("fetching server details for #%d...\n", serverID)
-	server, err := client.Server.Get(ctx, serverID)
+	server, err := getServerCached(ctx, client, serverID)
 	if err != nil {
 		return fmt.Errorf("failed to get server details: %w", err)
 	}
@@ -67,14 +67,16 @@ func sshToServer(ctx context.Context, client *hrobot.Client, serverID hrobot.Ser
 		} else {
 			// Step 5: Add SSH rule for current IP
 			fmt.Printf("adding SSH access rule for %s...\n", myIP)
-			err = allowSSH(ctx, client, serverID, []string{}, true)
+			ips := []string{myIPWithCIDR}
+			info, err := allowSSH(ctx, client, os.Stdout, serverID, ips, "", false, firewallOptions{})
 			if err != nil {
 				return fmt.Errorf("failed to add SSH firewall rule: %w", err)
 			}
+			printAllowSSHResult(os.Stdout, info, ips)
 
 			// Step 6: Wait for firewall to be ready
 			fmt.Println("waiting for firewall changes to be applied...")
-			err = client.Firewall.WaitForFirewallReady(ctx, serverID)
+			err = waitForFirewallReady(ctx, client, serverID, 0)
 			if err != nil {
 				return fmt.Errorf("failed while waiting for firewall: %w", err)
 			}
@@ -82,7 +84,11 @@ func sshToServer(ctx context.Context, client *hrobot.Client, serverID hrobot.Ser
 
 			// Give a bit more time for the rule to take effect
 			fmt.Println("waiting 5 seconds for rules to propagate...")
-			time.Sleep(5 * time.Second)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
 		}
 	}
 
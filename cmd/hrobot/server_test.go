@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestNewServerDetail_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   hrobot.Server
+		wantIPv4 interface{}
+		wantIPv6 interface{}
+	}{
+		{
+			name: "with IPv4",
+			server: hrobot.Server{
+				ServerIP:     net.ParseIP("123.123.123.123"),
+				ServerNumber: 321,
+				ServerName:   "web-1",
+				Product:      "EX44",
+				DC:           "FSN1-DC14",
+				Status:       hrobot.ServerStatusReady,
+				PaidUntil:    "2025-12-31",
+				IP:           []net.IP{net.ParseIP("123.123.123.123")},
+				Subnet: []hrobot.Subnet{
+					{IP: net.ParseIP("2a01:4f8:111:4221::"), Mask: "64"},
+				},
+			},
+			wantIPv4: "123.123.123.123",
+			wantIPv6: "2a01:4f8:111:4221::/64",
+		},
+		{
+			name: "IPv6 only",
+			server: hrobot.Server{
+				ServerNumber: 322,
+				ServerName:   "web-2",
+				Product:      "EX44",
+				DC:           "HEL1-DC2",
+				Status:       hrobot.ServerStatusReady,
+				Cancelled:    true,
+				Subnet: []hrobot.Subnet{
+					{IP: net.ParseIP("2a01:4f9:3a:1234::"), Mask: "64"},
+				},
+			},
+			wantIPv4: nil,
+			wantIPv6: "2a01:4f9:3a:1234::/64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(newServerDetail(&tt.server))
+			if err != nil {
+				t.Fatalf("failed to marshal server detail: %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to unmarshal server detail: %v", err)
+			}
+
+			for _, key := range []string{"server_number", "server_name", "product", "datacenter", "status", "cancelled", "paid_until", "ipv4", "ipv6", "ips", "subnets"} {
+				if _, ok := got[key]; !ok {
+					t.Errorf("expected key %q in JSON output: %s", key, data)
+				}
+			}
+
+			if got["ipv4"] != tt.wantIPv4 {
+				t.Errorf("expected ipv4 %v, got %v", tt.wantIPv4, got["ipv4"])
+			}
+			if got["ipv6"] != tt.wantIPv6 {
+				t.Errorf("expected ipv6 %v, got %v", tt.wantIPv6, got["ipv6"])
+			}
+			if got["datacenter"] != tt.server.DC {
+				t.Errorf("expected datacenter %q, got %v", tt.server.DC, got["datacenter"])
+			}
+			if got["cancelled"] != tt.server.Cancelled {
+				t.Errorf("expected cancelled %v, got %v", tt.server.Cancelled, got["cancelled"])
+			}
+			if ips, ok := got["ips"].([]interface{}); !ok || len(ips) != len(tt.server.IP) {
+				t.Errorf("expected ips to be a list of %d entries, got %v", len(tt.server.IP), got["ips"])
+			}
+		})
+	}
+}
+
+func TestPowerOnServer_Wait(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	origInterval := powerPollInterval
+	powerPollInterval = 10 * time.Millisecond
+	defer func() { powerPollInterval = origInterval }()
+
+	polls := 0
+	powered := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reset/321" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+
+		if r.Method == http.MethodPost {
+			powered = true
+			fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": "power"}}`)
+			return
+		}
+
+		status := "off"
+		if powered {
+			// The state only changes after the first poll following the command.
+			polls++
+			if polls > 1 {
+				status = "ready"
+			}
+		}
+		fmt.Fprintf(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": ["sw", "hw", "power"], "operating_status": %q}}`, status)
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = powerOnServer(context.Background(), client, hrobot.ServerID(321), true, time.Second)
+	})
+	if err != nil {
+		t.Fatalf("powerOnServer returned error: %v", err)
+	}
+	if polls != 2 {
+		t.Errorf("expected 2 status polls after the power command, got %d", polls)
+	}
+	if !strings.Contains(out, "Server #321 is powered on") || !strings.Contains(out, "Operating Status: ready") {
+		t.Errorf("expected final power state in output, got:\n%s", out)
+	}
+}
+
+func TestPowerOnServer_StatusReadFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	powered := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			powered = true
+			fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": "power"}}`)
+			return
+		}
+		if powered {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error": {"status": 500, "code": "INTERNAL_ERROR", "message": "internal error"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": ["power"], "operating_status": "off"}}`)
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = powerOnServer(context.Background(), client, hrobot.ServerID(321), false, 0)
+	})
+	if err != nil {
+		t.Fatalf("expected the executed power command to succeed despite the failed status read, got %v", err)
+	}
+	if !strings.Contains(out, "Power command sent successfully") {
+		t.Errorf("expected the power command to be reported, got:\n%s", out)
+	}
+}
+
+func TestWaitForPowerState_Timeout(t *testing.T) {
+	origInterval := powerPollInterval
+	powerPollInterval = 10 * time.Millisecond
+	defer func() { powerPollInterval = origInterval }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": ["power"], "operating_status": "ready"}}`)
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	captureStdout(t, func() {
+		_, err = waitForPowerState(context.Background(), client, hrobot.ServerID(321), false, 50*time.Millisecond)
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestWakeServer_NotSupported(t *testing.T) {
+	sent := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wol/321" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+		if r.Method == http.MethodPost {
+			sent = true
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": {"status": 404, "code": "WOL_NOT_AVAILABLE", "message": "The server has no wake on lan feature"}}`)
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	captureStdout(t, func() {
+		err = wakeServer(context.Background(), client, hrobot.ServerID(321))
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not support Wake-on-LAN") {
+		t.Errorf("expected Wake-on-LAN not supported error, got %v", err)
+	}
+	if sent {
+		t.Error("expected no Wake-on-LAN packet to be sent to an unsupported server")
+	}
+}
+
+func TestListServers_StatusAndPower(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/server":
+			fmt.Fprint(w, `[
+				{"server": {"server_ip": "123.123.123.123", "server_number": 321, "server_name": "web-1", "product": "EX44", "dc": "FSN1-DC14", "status": "ready"}},
+				{"server": {"server_ip": "123.123.123.124", "server_number": 322, "server_name": "web-2", "product": "EX44", "dc": "FSN1-DC14", "status": "in process"}},
+				{"server": {"server_ip": "124.124.124.124", "server_number": 400, "server_name": "db", "product": "AX102", "dc": "HEL1-DC2", "status": "ready"}}
+			]`)
+		case "/reset/321":
+			fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.123", "server_number": 321, "type": ["sw", "hw"]}}`)
+		case "/reset/322":
+			fmt.Fprint(w, `{"reset": {"server_ip": "123.123.123.124", "server_number": 322, "type": ["sw", "hw"], "operating_status": "off"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"status": 404, "code": "RESET_NOT_AVAILABLE", "message": "The server has no reset option"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = listServers(context.Background(), client, "csv", nil, 2)
+	})
+	if err != nil {
+		t.Fatalf("listServers returned error: %v", err)
+	}
+
+	want := "Server #,Name,IP,Product,DC,Status,Power\n" +
+		"321,web-1,123.123.123.123,EX44,FSN1-DC14,ready,running\n" +
+		"322,web-2,123.123.123.124,EX44,FSN1-DC14,in process,off\n" +
+		"400,db,124.124.124.124,AX102,HEL1-DC2,ready,(unavailable)\n"
+	if out != want {
+		t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+
+	// Without --with-power, the status column is shown without extra requests
+	out = captureStdout(t, func() {
+		err = listServers(context.Background(), client, "csv", nil, 0)
+	})
+	if err != nil {
+		t.Fatalf("listServers returned error: %v", err)
+	}
+	if !strings.HasPrefix(out, "Server #,Name,IP,Product,DC,Status\n321,web-1,123.123.123.123,EX44,FSN1-DC14,ready\n") {
+		t.Errorf("expected status column without power, got:\n%s", out)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/aquasecurity/table"
@@ -44,7 +45,7 @@ func listKeys(ctx context.Context, client *hrobot.Client) error {
 
 // findKeyFingerprintByName looks up a key by name and returns its fingerprint.
 func findKeyFingerprintByName(ctx context.Context, client *hrobot.Client, name string) (string, error) {
-	keys, err := client.Key.List(ctx)
+	keys, err := listKeysCached(ctx, client)
 	if err != nil {
 		return "", fmt.Errorf("failed to list SSH keys: %w", err)
 	}
@@ -58,6 +59,151 @@ func findKeyFingerprintByName(ctx context.Context, client *hrobot.Client, name s
 	return "", fmt.Errorf("SSH key with name '%s' not found", name)
 }
 
+// orderAuthFlagsHelp describes the authorization flags in the help of the
+// order commands.
+const orderAuthFlagsHelp = `  --ssh-keys <names> Comma-separated names of the SSH keys to authorize
+  --password[=<pw>] Log in with a root password instead of SSH keys; prompts
+                    without a value, which keeps it out of the shell history
+  --no-keys         Do not authorize any SSH key (requires --password)`
+
+// orderAuthFlags are the authorization flags of the order commands.
+type orderAuthFlags struct {
+	KeyNames    []string // SSH keys to authorize; empty for all keys
+	Password    string   // Root password given with --password
+	PasswordSet bool     // --password was given, possibly without a value
+	NoKeys      bool     // --no-keys was given
+}
+
+// removeOrderAuthFlags removes --ssh-keys, --password and --no-keys from
+// args. A bare --password takes the next argument as its value unless that
+// is a flag; without a value, orderAuth prompts for the password.
+func removeOrderAuthFlags(args []string) (orderAuthFlags, []string) {
+	var flags orderAuthFlags
+	flags.KeyNames = parseFlagStringSlice(args, "--ssh-keys")
+	_, args = removeFlagString(args, "--ssh-keys")
+
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--no-keys":
+			flags.NoKeys = true
+		case strings.HasPrefix(arg, "--password="):
+			flags.PasswordSet = true
+			flags.Password = strings.TrimPrefix(arg, "--password=")
+		case arg == "--password":
+			flags.PasswordSet = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				flags.Password = args[i+1]
+				i++
+			}
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return flags, remaining
+}
+
+// orderAuth returns the authorization of an order, which is either a root
+// password or SSH keys, never both. Without --password, the keys named by
+// flags.KeyNames or all keys of the account are used.
+func orderAuth(ctx context.Context, client *hrobot.Client, flags orderAuthFlags) (hrobot.AuthorizationMethod, error) {
+	keyNames := strings.Join(flags.KeyNames, ", ")
+	switch {
+	case flags.PasswordSet && len(flags.KeyNames) > 0:
+		return hrobot.AuthorizationMethod{}, fmt.Errorf("use either SSH keys (%s) or --password, not both", keyNames)
+	case flags.NoKeys && len(flags.KeyNames) > 0:
+		return hrobot.AuthorizationMethod{}, fmt.Errorf("--no-keys cannot be combined with SSH keys (%s)", keyNames)
+	case flags.NoKeys && !flags.PasswordSet:
+		return hrobot.AuthorizationMethod{}, fmt.Errorf("--no-keys requires --password, since the server needs a way to log in")
+	}
+
+	if flags.PasswordSet {
+		password := flags.Password
+		if password == "" {
+			var err error
+			password, err = readPassword("Root password: ")
+			if err != nil {
+				return hrobot.AuthorizationMethod{}, err
+			}
+		}
+		if password == "" {
+			return hrobot.AuthorizationMethod{}, fmt.Errorf("password cannot be empty")
+		}
+		return hrobot.AuthorizationMethod{Password: password}, nil
+	}
+
+	fingerprints, err := orderKeyFingerprints(ctx, client, flags.KeyNames)
+	if err != nil {
+		return hrobot.AuthorizationMethod{}, err
+	}
+	return hrobot.AuthorizationMethod{Keys: fingerprints}, nil
+}
+
+// printOrderAuth prints the authorization of an order without the password.
+func printOrderAuth(auth hrobot.AuthorizationMethod) {
+	switch {
+	case auth.Password != "":
+		fmt.Printf("  Login:       root password (no SSH keys)\n")
+	case len(auth.Keys) == 1:
+		fmt.Printf("  SSH Key:     %s\n", auth.Keys[0])
+	default:
+		fmt.Printf("  SSH Keys:    %d keys\n", len(auth.Keys))
+	}
+}
+
+// orderKeyFingerprints returns the SSH key fingerprints to use for an order:
+// the keys with the given names, or all keys of the account if names is
+// empty. Every name must match a key.
+func orderKeyFingerprints(ctx context.Context, client *hrobot.Client, names []string) ([]string, error) {
+	keys, err := listKeysCached(ctx, client)
+	if err != nil && !hrobot.IsAPIError(err, hrobot.ErrNotFound) {
+		return nil, fmt.Errorf("failed to list SSH keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no SSH keys found in your account. Please create at least one SSH key first, or order with --password")
+	}
+
+	if len(names) == 0 {
+		var fingerprints []string
+		for _, key := range keys {
+			fingerprints = append(fingerprints, key.Fingerprint)
+		}
+		return fingerprints, nil
+	}
+
+	return resolveKeyFingerprints(keys, names)
+}
+
+// resolveKeyFingerprints returns the fingerprints of the keys with the given
+// names, without duplicates. Unknown names are reported together.
+func resolveKeyFingerprints(keys []hrobot.SSHKey, names []string) ([]string, error) {
+	byName := make(map[string]string, len(keys))
+	for _, key := range keys {
+		byName[key.Name] = key.Fingerprint
+	}
+
+	var fingerprints, missing []string
+	for _, name := range names {
+		fingerprint, ok := byName[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		if !slices.Contains(fingerprints, fingerprint) {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	if len(missing) > 0 {
+		available := make([]string, 0, len(keys))
+		for _, key := range keys {
+			available = append(available, key.Name)
+		}
+		return nil, fmt.Errorf("SSH key(s) not found: %s\nAvailable keys: %s", strings.Join(missing, ", "), strings.Join(available, ", "))
+	}
+	return fingerprints, nil
+}
+
 func getKey(ctx context.Context, client *hrobot.Client, name string) error {
 	// Look up the fingerprint by name
 	fingerprint, err := findKeyFingerprintByName(ctx, client, name)
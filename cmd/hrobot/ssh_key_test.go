@@ -0,0 +1,165 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestRemoveOrderAuthFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantFlags orderAuthFlags
+		wantArgs  []string
+	}{
+		{
+			name:      "no auth flags",
+			args:      []string{"hrobot", "product", "order", "EX44", "mykey", "--yes"},
+			wantFlags: orderAuthFlags{},
+			wantArgs:  []string{"hrobot", "product", "order", "EX44", "mykey", "--yes"},
+		},
+		{
+			name:      "password with equals",
+			args:      []string{"hrobot", "product", "order", "EX44", "--password=s3cret", "--no-keys"},
+			wantFlags: orderAuthFlags{Password: "s3cret", PasswordSet: true, NoKeys: true},
+			wantArgs:  []string{"hrobot", "product", "order", "EX44"},
+		},
+		{
+			name:      "password as next argument",
+			args:      []string{"hrobot", "product", "order", "EX44", "--password", "s3cret", "--yes"},
+			wantFlags: orderAuthFlags{Password: "s3cret", PasswordSet: true},
+			wantArgs:  []string{"hrobot", "product", "order", "EX44", "--yes"},
+		},
+		{
+			name:      "ssh keys",
+			args:      []string{"hrobot", "product", "order", "EX44", "--ssh-keys", "laptop, desktop", "--yes"},
+			wantFlags: orderAuthFlags{KeyNames: []string{"laptop", "desktop"}},
+			wantArgs:  []string{"hrobot", "product", "order", "EX44", "--yes"},
+		},
+		{
+			name:      "password without value",
+			args:      []string{"hrobot", "product", "order", "EX44", "--password", "--yes"},
+			wantFlags: orderAuthFlags{PasswordSet: true},
+			wantArgs:  []string{"hrobot", "product", "order", "EX44", "--yes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, args := removeOrderAuthFlags(tt.args)
+			if !reflect.DeepEqual(flags, tt.wantFlags) {
+				t.Errorf("flags = %+v, want %+v", flags, tt.wantFlags)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOrderAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/key" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"key": {"name": "laptop", "fingerprint": "aa:bb", "type": "ED25519", "size": 256, "data": "ssh-ed25519 AAAA"}},
+			{"key": {"name": "desktop", "fingerprint": "cc:dd", "type": "ED25519", "size": 256, "data": "ssh-ed25519 BBBB"}}
+		]`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		flags    orderAuthFlags
+		prompted string
+		want     hrobot.AuthorizationMethod
+		wantErr  string
+	}{
+		{
+			name:  "all keys by default",
+			flags: orderAuthFlags{},
+			want:  hrobot.AuthorizationMethod{Keys: []string{"aa:bb", "cc:dd"}},
+		},
+		{
+			name:  "named key",
+			flags: orderAuthFlags{KeyNames: []string{"desktop"}},
+			want:  hrobot.AuthorizationMethod{Keys: []string{"cc:dd"}},
+		},
+		{
+			name:  "several named keys",
+			flags: orderAuthFlags{KeyNames: []string{"desktop", "laptop", "desktop"}},
+			want:  hrobot.AuthorizationMethod{Keys: []string{"cc:dd", "aa:bb"}},
+		},
+		{
+			name:    "unknown key name",
+			flags:   orderAuthFlags{KeyNames: []string{"laptop", "tablet", "phone"}},
+			wantErr: "SSH key(s) not found: tablet, phone",
+		},
+		{
+			name:  "password with no keys",
+			flags: orderAuthFlags{Password: "s3cret", PasswordSet: true, NoKeys: true},
+			want:  hrobot.AuthorizationMethod{Password: "s3cret"},
+		},
+		{
+			name:     "prompted password",
+			flags:    orderAuthFlags{PasswordSet: true},
+			prompted: "typed",
+			want:     hrobot.AuthorizationMethod{Password: "typed"},
+		},
+		{
+			name:    "empty prompted password",
+			flags:   orderAuthFlags{PasswordSet: true},
+			wantErr: "password cannot be empty",
+		},
+		{
+			name:    "password and key",
+			flags:   orderAuthFlags{KeyNames: []string{"laptop"}, Password: "s3cret", PasswordSet: true},
+			wantErr: "not both",
+		},
+		{
+			name:    "no keys and key",
+			flags:   orderAuthFlags{KeyNames: []string{"laptop"}, NoKeys: true},
+			wantErr: "--no-keys cannot be combined",
+		},
+		{
+			name:    "no keys without password",
+			flags:   orderAuthFlags{NoKeys: true},
+			wantErr: "--no-keys requires --password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origReadPassword := readPassword
+			t.Cleanup(func() { readPassword = origReadPassword })
+			readPassword = func(string) (string, error) { return tt.prompted, nil }
+
+			client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+			got, err := orderAuth(context.Background(), client, tt.flags)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderAuth() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/table"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// defaultTrafficDays is the period shown by server traffic without flags.
+const defaultTrafficDays = 14
+
+// trafficDay is the traffic of a single day in GB.
+type trafficDay struct {
+	Date string  `json:"date"`
+	In   float64 `json:"in"`
+	Out  float64 `json:"out"`
+	Sum  float64 `json:"sum"`
+}
+
+// trafficIPTotal is the traffic of a single IP over the whole period in GB.
+type trafficIPTotal struct {
+	IP  string  `json:"ip"`
+	In  float64 `json:"in"`
+	Out float64 `json:"out"`
+	Sum float64 `json:"sum"`
+}
+
+// trafficReport is the JSON representation of server traffic for a period.
+// Days and the totals are aggregated across all queried IPs; IPs holds the
+// per-IP breakdown.
+type trafficReport struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	IPs      []trafficIPTotal `json:"ips,omitempty"`
+	Days     []trafficDay     `json:"days,omitempty"`
+	TotalIn  float64          `json:"total_in"`
+	TotalOut float64          `json:"total_out"`
+	TotalSum float64          `json:"total_sum"`
+}
+
+// parseTrafficRange returns the period selected by --days, --from and --to.
+//
+// With both --from and --to the range is used as given and --days is ignored.
+// With only one of them the period spans --days days from --from or up to --to.
+// Without either, the period is the last --days days ending at now.
+func parseTrafficRange(args []string, now time.Time) (string, string, error) {
+	days := defaultTrafficDays
+	if value := parseFlagString(args, "--days"); value != "" {
+		d, err := strconv.Atoi(value)
+		if err != nil || d < 1 {
+			return "", "", fmt.Errorf("invalid --days value: %s (must be a positive number)", value)
+		}
+		days = d
+	}
+
+	var from, to time.Time
+	fromValue := parseFlagString(args, "--from")
+	toValue := parseFlagString(args, "--to")
+	if fromValue != "" {
+		t, err := time.Parse("2006-01-02", fromValue)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid --from date: %s (use YYYY-MM-DD)", fromValue)
+		}
+		from = t
+	}
+	if toValue != "" {
+		t, err := time.Parse("2006-01-02", toValue)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid --to date: %s (use YYYY-MM-DD)", toValue)
+		}
+		to = t
+	}
+
+	switch {
+	case fromValue != "" && toValue != "":
+		if from.After(to) {
+			return "", "", fmt.Errorf("--from (%s) must not be after --to (%s)", fromValue, toValue)
+		}
+	case fromValue != "":
+		to = from.AddDate(0, 0, days-1)
+	case toValue != "":
+		from = to.AddDate(0, 0, -days+1)
+	default:
+		to = now
+		from = now.AddDate(0, 0, -days+1)
+	}
+
+	return from.Format("2006-01-02"), to.Format("2006-01-02"), nil
+}
+
+// fetchTraffic returns the daily traffic of an IP keyed by date (YYYY-MM-DD).
+// The API only supports single-month queries with type=month, so periods that
+// span several months are fetched month by month.
+func fetchTraffic(ctx context.Context, client *hrobot.Client, ip, fromDate, toDate string) (map[string]hrobot.TrafficStats, error) {
+	fromTime, err := time.Parse("2006-01-02", fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date: %w", err)
+	}
+	toTime, err := time.Parse("2006-01-02", toDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date: %w", err)
+	}
+
+	allData := make(map[string]hrobot.TrafficStats)
+
+	currentStart := fromTime
+	for !currentStart.After(toTime) {
+		// Calculate end of current month or toTime, whichever is earlier
+		endOfMonth := time.Date(currentStart.Year(), currentStart.Month()+1, 0, 0, 0, 0, 0, currentStart.Location())
+
+		rangeEnd := toTime
+		if endOfMonth.Before(toTime) {
+			rangeEnd = endOfMonth
+		}
+
+		params := hrobot.TrafficGetParams{
+			Type:         hrobot.TrafficTypeMonth,
+			From:         currentStart.Format("2006-01-02"),
+			To:           rangeEnd.Format("2006-01-02"),
+			IP:           ip,
+			SingleValues: true,
+		}
+
+		trafficData, err := client.Traffic.Get(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get traffic data for %s: %w", currentStart.Format("2006-01"), err)
+		}
+
+		// Merge data - convert day numbers to full dates
+		if ipData, ok := trafficData.Data[ip]; ok {
+			yearMonth := currentStart.Format("2006-01")
+			for day, stats := range ipData {
+				date := fmt.Sprintf("%s-%s", yearMonth, day)
+				// Only keep dates within the requested range
+				if date >= fromDate && date <= toDate {
+					allData[date] = stats
+				}
+			}
+		}
+
+		currentStart = endOfMonth.AddDate(0, 0, 1)
+	}
+
+	return allData, nil
+}
+
+// trafficIPs returns the IPs whose traffic server traffic reports: the --ip
+// value if given, otherwise all single IPs of the server. Servers without
+// listed IPs fall back to their main IP.
+func trafficIPs(ctx context.Context, client *hrobot.Client, server *hrobot.Server, ipFlag string) ([]string, error) {
+	if ipFlag != "" {
+		if net.ParseIP(ipFlag) == nil {
+			return nil, fmt.Errorf("invalid --ip value: %s", ipFlag)
+		}
+		return []string{ipFlag}, nil
+	}
+
+	addresses, err := client.Server.ListIPs(ctx, hrobot.ServerID(server.ServerNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server IPs: %w", err)
+	}
+
+	var ips []string
+	for _, address := range addresses {
+		ips = append(ips, address.IP.String())
+	}
+	if len(ips) == 0 && server.ServerIP != nil {
+		ips = append(ips, server.ServerIP.String())
+	}
+	sort.Strings(ips)
+	return ips, nil
+}
+
+// collectTraffic fetches the daily traffic of each IP and returns it summed
+// per date, together with the per-IP totals in the order of ips.
+func collectTraffic(ctx context.Context, client *hrobot.Client, ips []string, fromDate, toDate string) (map[string]hrobot.TrafficStats, []trafficIPTotal, error) {
+	combined := make(map[string]hrobot.TrafficStats)
+	totals := make([]trafficIPTotal, 0, len(ips))
+
+	for _, ip := range ips {
+		data, err := fetchTraffic(ctx, client, ip, fromDate, toDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", ip, err)
+		}
+
+		total := trafficIPTotal{IP: ip}
+		for date, stats := range data {
+			total.In += stats.In
+			total.Out += stats.Out
+			total.Sum += stats.Sum
+
+			day := combined[date]
+			day.In += stats.In
+			day.Out += stats.Out
+			day.Sum += stats.Sum
+			combined[date] = day
+		}
+		totals = append(totals, total)
+	}
+
+	return combined, totals, nil
+}
+
+// summarizeTraffic sorts the daily traffic by date and computes the totals.
+func summarizeTraffic(fromDate, toDate string, data map[string]hrobot.TrafficStats) trafficReport {
+	report := trafficReport{From: fromDate, To: toDate}
+
+	for date, stats := range data {
+		report.Days = append(report.Days, trafficDay{Date: date, In: stats.In, Out: stats.Out, Sum: stats.Sum})
+		report.TotalIn += stats.In
+		report.TotalOut += stats.Out
+		report.TotalSum += stats.Sum
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date < report.Days[j].Date
+	})
+
+	return report
+}
+
+// renderTrafficTable prints the daily traffic with a bar chart and the totals.
+func renderTrafficTable(w io.Writer, report trafficReport) {
+	maxTraffic := 0.0
+	for _, day := range report.Days {
+		if day.Sum > maxTraffic {
+			maxTraffic = day.Sum
+		}
+	}
+
+	// Determine scale for bar chart
+	barWidth := 50
+	scale := maxTraffic / float64(barWidth)
+	if scale == 0 {
+		scale = 1
+	}
+
+	fmt.Fprintf(w, "Traffic Statistics (GB)\n\n")
+
+	t := table.New(w)
+	t.SetHeaders("Date", "Download", "Upload", "Graph")
+	for _, day := range report.Days {
+		barLength := int(day.Sum / scale)
+		if barLength > barWidth {
+			barLength = barWidth
+		}
+
+		t.AddRow(
+			day.Date,
+			fmt.Sprintf("%.2f GB", day.In),
+			fmt.Sprintf("%.2f GB", day.Out),
+			strings.Repeat("█", barLength),
+		)
+	}
+	t.Render()
+
+	fmt.Fprintln(w)
+	renderTrafficIPs(w, report)
+	renderTrafficTotals(w, report)
+}
+
+// renderTrafficIPs prints the per-IP breakdown of a report that covers more
+// than one IP.
+func renderTrafficIPs(w io.Writer, report trafficReport) {
+	if len(report.IPs) < 2 {
+		return
+	}
+
+	t := table.New(w)
+	t.SetHeaders("IP", "Download", "Upload", "Total")
+	for _, ip := range report.IPs {
+		t.AddRow(
+			ip.IP,
+			fmt.Sprintf("%.2f GB", ip.In),
+			fmt.Sprintf("%.2f GB", ip.Out),
+			fmt.Sprintf("%.2f GB", ip.Sum),
+		)
+	}
+	t.Render()
+	fmt.Fprintln(w)
+}
+
+// renderTrafficTotals prints the totals and the daily average of a report.
+func renderTrafficTotals(w io.Writer, report trafficReport) {
+	fmt.Fprintf(w, "Total Traffic: %.2f GB (↓%.2f GB in, ↑%.2f GB out)\n", report.TotalSum, report.TotalIn, report.TotalOut)
+	if len(report.Days) > 0 {
+		fmt.Fprintf(w, "Average per day: %.2f GB\n", report.TotalSum/float64(len(report.Days)))
+	}
+}
+
+// parseTrafficMonth returns the month selected by --month (YYYY-MM), or the
+// zero time if the flag is not set. --month cannot be combined with --days,
+// --from or --to.
+func parseTrafficMonth(args []string) (time.Time, error) {
+	value := parseFlagString(args, "--month")
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, flag := range []string{"--days", "--from", "--to"} {
+		if parseFlagString(args, flag) != "" {
+			return time.Time{}, fmt.Errorf("--month cannot be combined with %s", flag)
+		}
+	}
+	month, err := time.Parse("2006-01", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --month value: %s (use YYYY-MM)", value)
+	}
+	return month, nil
+}
+
+// monthlyTrafficReport is the JSON representation of the traffic of a single
+// month, summed across all queried IPs. IPs holds the per-IP breakdown.
+type monthlyTrafficReport struct {
+	Month string           `json:"month"`
+	Days  int              `json:"days"` // most days with data of any IP
+	IPs   []trafficIPTotal `json:"ips,omitempty"`
+	In    float64          `json:"in"`
+	Out   float64          `json:"out"`
+	Sum   float64          `json:"sum"`
+}
+
+// collectMonthlyTraffic fetches the traffic of each IP for a month and sums it.
+func collectMonthlyTraffic(ctx context.Context, client *hrobot.Client, ips []string, month time.Time) (monthlyTrafficReport, error) {
+	report := monthlyTrafficReport{Month: month.Format("2006-01")}
+	for _, ip := range ips {
+		traffic, err := client.Traffic.GetMonthly(ctx, ip, month.Year(), month.Month())
+		if err != nil {
+			return report, fmt.Errorf("%s: failed to get traffic data for %s: %w", ip, month.Format("2006-01"), err)
+		}
+		report.IPs = append(report.IPs, trafficIPTotal{IP: ip, In: traffic.In, Out: traffic.Out, Sum: traffic.Sum})
+		report.In += traffic.In
+		report.Out += traffic.Out
+		report.Sum += traffic.Sum
+		if traffic.Days > report.Days {
+			report.Days = traffic.Days
+		}
+	}
+	return report, nil
+}
+
+// showMonthlyTraffic prints the aggregated traffic of a single month. Without
+// --ip the traffic of all of the server's IPs is summed.
+func showMonthlyTraffic(ctx context.Context, client *hrobot.Client, server *hrobot.Server, month time.Time, ipFlag, outputFormat string) error {
+	ips, err := trafficIPs(ctx, client, server, ipFlag)
+	if err != nil {
+		return err
+	}
+
+	report, err := collectMonthlyTraffic(ctx, client, ips, month)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		return printJSON(report)
+	}
+
+	fmt.Printf("Traffic for server #%d (%s) in %s\n\n", server.ServerNumber, strings.Join(ips, ", "), report.Month)
+	if report.Days == 0 {
+		fmt.Println("No traffic data available for this month.")
+		return nil
+	}
+	renderTrafficIPs(os.Stdout, trafficReport{IPs: report.IPs})
+	fmt.Printf("Total Traffic: %.2f GB (↓%.2f GB in, ↑%.2f GB out)\n", report.Sum, report.In, report.Out)
+	fmt.Printf("Days with data: %d\n", report.Days)
+	fmt.Printf("Average per day: %.2f GB\n", report.Sum/float64(report.Days))
+	return nil
+}
+
+func showTraffic(ctx context.Context, client *hrobot.Client, serverID hrobot.ServerID, args []string) error {
+	month, err := parseTrafficMonth(args)
+	if err != nil {
+		return err
+	}
+
+	server, err := getServerCached(ctx, client, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get server: %w", err)
+	}
+	if !month.IsZero() {
+		return showMonthlyTraffic(ctx, client, server, month, parseFlagString(args, "--ip"), parseFlagString(args, "--output"))
+	}
+
+	fromDate, toDate, err := parseTrafficRange(args, time.Now())
+	if err != nil {
+		return err
+	}
+	outputFormat := parseFlagString(args, "--output")
+	summary := parseFlagBool(args, "--summary")
+	ips, err := trafficIPs(ctx, client, server, parseFlagString(args, "--ip"))
+	if err != nil {
+		return err
+	}
+
+	if outputFormat != "json" {
+		fmt.Printf("Fetching traffic data for server #%d (%s)...\n", serverID, strings.Join(ips, ", "))
+		fmt.Printf("  Period: %s to %s\n\n", fromDate, toDate)
+	}
+
+	data, ipTotals, err := collectTraffic(ctx, client, ips, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+	report := summarizeTraffic(fromDate, toDate, data)
+	report.IPs = ipTotals
+
+	if outputFormat == "json" {
+		if summary {
+			report.Days = nil
+		}
+		return printJSON(report)
+	}
+
+	if len(report.Days) == 0 {
+		fmt.Println("No traffic data available for this period.")
+		return nil
+	}
+
+	if summary {
+		renderTrafficIPs(os.Stdout, report)
+		renderTrafficTotals(os.Stdout, report)
+		return nil
+	}
+	renderTrafficTable(os.Stdout, report)
+
+	return nil
+}
@@ -0,0 +1,296 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestParseTrafficRange(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{name: "default", args: nil, wantFrom: "2024-02-26", wantTo: "2024-03-10"},
+		{name: "days", args: []string{"--days", "7"}, wantFrom: "2024-03-04", wantTo: "2024-03-10"},
+		{name: "from and to override days", args: []string{"--days", "7", "--from", "2024-01-01", "--to", "2024-01-31"}, wantFrom: "2024-01-01", wantTo: "2024-01-31"},
+		{name: "from only", args: []string{"--from=2024-02-27", "--days", "5"}, wantFrom: "2024-02-27", wantTo: "2024-03-02"},
+		{name: "to only", args: []string{"--to", "2024-03-02", "--days", "3"}, wantFrom: "2024-02-29", wantTo: "2024-03-02"},
+		{name: "from after to", args: []string{"--from", "2024-02-01", "--to", "2024-01-01"}, wantErr: true},
+		{name: "invalid from", args: []string{"--from", "01.02.2024", "--to", "2024-03-01"}, wantErr: true},
+		{name: "invalid days", args: []string{"--days", "0"}, wantErr: true},
+		{name: "non-numeric days", args: []string{"--days", "week"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseTrafficRange(tt.args, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTrafficRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("expected %s to %s, got %s to %s", tt.wantFrom, tt.wantTo, from, to)
+			}
+		})
+	}
+}
+
+func TestSummarizeTraffic(t *testing.T) {
+	data := map[string]hrobot.TrafficStats{
+		"2024-03-02": {In: 1.5, Out: 0.25, Sum: 1.75},
+		"2024-02-29": {In: 10, Out: 2, Sum: 12},
+		"2024-03-01": {In: 0.5, Out: 0.5, Sum: 1},
+	}
+
+	report := summarizeTraffic("2024-02-29", "2024-03-02", data)
+
+	expectedDates := []string{"2024-02-29", "2024-03-01", "2024-03-02"}
+	if len(report.Days) != len(expectedDates) {
+		t.Fatalf("expected %d days, got %d", len(expectedDates), len(report.Days))
+	}
+	for i, date := range expectedDates {
+		if report.Days[i].Date != date {
+			t.Errorf("expected day %d to be %s, got %s", i, date, report.Days[i].Date)
+		}
+	}
+
+	totals := map[string][2]float64{
+		"total_in":  {report.TotalIn, 12},
+		"total_out": {report.TotalOut, 2.75},
+		"total_sum": {report.TotalSum, 14.75},
+	}
+	for name, v := range totals {
+		if math.Abs(v[0]-v[1]) > 1e-9 {
+			t.Errorf("expected %s %v, got %v", name, v[1], v[0])
+		}
+	}
+
+	out, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if decoded["total_sum"] != 14.75 || decoded["from"] != "2024-02-29" {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+	if days, ok := decoded["days"].([]interface{}); !ok || len(days) != 3 {
+		t.Errorf("expected 3 days in JSON, got %v", decoded["days"])
+	}
+}
+
+func TestParseTrafficMonth(t *testing.T) {
+	month, err := parseTrafficMonth([]string{"--month", "2024-02"})
+	if err != nil {
+		t.Fatalf("parseTrafficMonth returned error: %v", err)
+	}
+	if month.Year() != 2024 || month.Month() != time.February {
+		t.Errorf("expected 2024-02, got %s", month.Format("2006-01"))
+	}
+
+	if month, err := parseTrafficMonth(nil); err != nil || !month.IsZero() {
+		t.Errorf("expected zero month without --month, got %v, %v", month, err)
+	}
+	if _, err := parseTrafficMonth([]string{"--month", "2024-02-01"}); err == nil {
+		t.Error("expected error for invalid --month")
+	}
+	if _, err := parseTrafficMonth([]string{"--month", "2024-02", "--days", "7"}); err == nil {
+		t.Error("expected error when combining --month with --days")
+	}
+}
+
+// newTrafficTestServer serves /ip with two IPs of server 321 and /traffic
+// with per-IP daily values for February 2024.
+func newTrafficTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	daily := map[string]map[string]hrobot.TrafficStats{
+		"1.1.1.1": {
+			"01": {In: 1, Out: 0.5, Sum: 1.5},
+			"02": {In: 2, Out: 1, Sum: 3},
+		},
+		"2.2.2.2": {
+			"02": {In: 0.5, Out: 0.5, Sum: 1},
+		},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ip":
+			fmt.Fprint(w, `[
+				{"ip": {"ip": "2.2.2.2", "server_ip": "1.1.1.1", "server_number": 321}},
+				{"ip": {"ip": "1.1.1.1", "server_ip": "1.1.1.1", "server_number": 321}},
+				{"ip": {"ip": "3.3.3.3", "server_ip": "3.3.3.3", "server_number": 999}}
+			]`)
+		case "/traffic":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			ip := r.PostForm.Get("ip")
+			response := map[string]interface{}{
+				"traffic": map[string]interface{}{
+					"type": "month",
+					"from": r.PostForm.Get("from"),
+					"to":   r.PostForm.Get("to"),
+					"data": map[string]interface{}{ip: daily[ip]},
+				},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Errorf("failed to encode response: %v", err)
+			}
+		default:
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCollectTraffic_SingleIP(t *testing.T) {
+	server := newTrafficTestServer(t)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+	srv := &hrobot.Server{ServerNumber: 321, ServerIP: net.ParseIP("1.1.1.1")}
+
+	ips, err := trafficIPs(ctx, client, srv, "2.2.2.2")
+	if err != nil {
+		t.Fatalf("trafficIPs returned error: %v", err)
+	}
+	if len(ips) != 1 || ips[0] != "2.2.2.2" {
+		t.Fatalf("expected only 2.2.2.2, got %v", ips)
+	}
+
+	data, totals, err := collectTraffic(ctx, client, ips, "2024-02-01", "2024-02-29")
+	if err != nil {
+		t.Fatalf("collectTraffic returned error: %v", err)
+	}
+	if len(data) != 1 || data["2024-02-02"].Sum != 1 {
+		t.Errorf("expected a single day with 1 GB, got %v", data)
+	}
+	if len(totals) != 1 || totals[0].IP != "2.2.2.2" || totals[0].Sum != 1 {
+		t.Errorf("unexpected per-IP totals: %+v", totals)
+	}
+
+	if _, err := trafficIPs(ctx, client, srv, "not-an-ip"); err == nil {
+		t.Error("expected error for invalid --ip")
+	}
+}
+
+func TestCollectTraffic_AllIPs(t *testing.T) {
+	server := newTrafficTestServer(t)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+	srv := &hrobot.Server{ServerNumber: 321, ServerIP: net.ParseIP("1.1.1.1")}
+
+	ips, err := trafficIPs(ctx, client, srv, "")
+	if err != nil {
+		t.Fatalf("trafficIPs returned error: %v", err)
+	}
+	if len(ips) != 2 || ips[0] != "1.1.1.1" || ips[1] != "2.2.2.2" {
+		t.Fatalf("expected the server's two IPs, got %v", ips)
+	}
+
+	data, totals, err := collectTraffic(ctx, client, ips, "2024-02-01", "2024-02-29")
+	if err != nil {
+		t.Fatalf("collectTraffic returned error: %v", err)
+	}
+
+	report := summarizeTraffic("2024-02-01", "2024-02-29", data)
+	report.IPs = totals
+
+	if len(report.Days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(report.Days))
+	}
+	if report.Days[1].Date != "2024-02-02" || report.Days[1].Sum != 4 {
+		t.Errorf("expected 4 GB on 2024-02-02 across both IPs, got %+v", report.Days[1])
+	}
+	if report.TotalSum != 5.5 {
+		t.Errorf("expected total 5.5 GB, got %v", report.TotalSum)
+	}
+	if len(report.IPs) != 2 || report.IPs[0].Sum != 4.5 || report.IPs[1].Sum != 1 {
+		t.Errorf("unexpected per-IP breakdown: %+v", report.IPs)
+	}
+
+	out := captureStdout(t, func() {
+		renderTrafficIPs(os.Stdout, report)
+	})
+	if !strings.Contains(out, "1.1.1.1") || !strings.Contains(out, "4.50 GB") || !strings.Contains(out, "2.2.2.2") {
+		t.Errorf("expected per-IP breakdown in output, got:\n%s", out)
+	}
+}
+
+func TestShowMonthlyTraffic_AllIPs(t *testing.T) {
+	server := newTrafficTestServer(t)
+	defer server.Close()
+
+	client := hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+	ctx := context.Background()
+	srv := &hrobot.Server{ServerNumber: 321, ServerIP: net.ParseIP("1.1.1.1")}
+	month := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	out := captureStdout(t, func() {
+		if err := showMonthlyTraffic(ctx, client, srv, month, "", "json"); err != nil {
+			t.Fatalf("showMonthlyTraffic returned error: %v", err)
+		}
+	})
+
+	var report monthlyTrafficReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("failed to parse JSON output %q: %v", out, err)
+	}
+	if report.Month != "2024-02" || report.Sum != 5.5 || report.In != 3.5 || report.Out != 2 {
+		t.Errorf("expected 5.5 GB across both IPs in 2024-02, got %+v", report)
+	}
+	if report.Days != 2 {
+		t.Errorf("expected 2 days with data, got %d", report.Days)
+	}
+	if len(report.IPs) != 2 || report.IPs[0].IP != "1.1.1.1" || report.IPs[0].Sum != 4.5 || report.IPs[1].Sum != 1 {
+		t.Errorf("unexpected per-IP breakdown: %+v", report.IPs)
+	}
+
+	out = captureStdout(t, func() {
+		if err := showMonthlyTraffic(ctx, client, srv, month, "", ""); err != nil {
+			t.Fatalf("showMonthlyTraffic returned error: %v", err)
+		}
+	})
+	for _, want := range []string{"1.1.1.1, 2.2.2.2", "4.50 GB", "Total Traffic: 5.50 GB", "Days with data: 2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		if err := showMonthlyTraffic(ctx, client, srv, month, "2.2.2.2", ""); err != nil {
+			t.Fatalf("showMonthlyTraffic returned error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Total Traffic: 1.00 GB") || strings.Contains(out, "1.1.1.1") {
+		t.Errorf("expected only the traffic of 2.2.2.2, got:\n%s", out)
+	}
+}
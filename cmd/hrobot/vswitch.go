@@ -6,7 +6,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/aquasecurity/table"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
@@ -27,46 +30,92 @@ func listVSwitches(ctx context.Context, client *hrobot.Client) error {
 	return nil
 }
 
-func getVSwitch(ctx context.Context, client *hrobot.Client, id int) error {
+// listVSwitchesForServer lists the vSwitches a server is connected to.
+func listVSwitchesForServer(ctx context.Context, client *hrobot.Client, serverNumber int) error {
+	vswitches, err := client.VSwitch.ListForServer(ctx, serverNumber)
+	if err != nil {
+		return fmt.Errorf("failed to list vSwitches: %w", err)
+	}
+
+	if len(vswitches) == 0 {
+		fmt.Printf("Server #%d is not connected to any vSwitch\n", serverNumber)
+		return nil
+	}
+
+	fmt.Printf("Server #%d is connected to %d vSwitch(es):\n\n", serverNumber, len(vswitches))
+	for i, vs := range vswitches {
+		fmt.Printf("[%d] %s (ID: %d)\n", i+1, vs.Name, vs.ID)
+		fmt.Printf("    VLAN:      %d\n", vs.VLAN)
+		fmt.Printf("    Cancelled: %v\n", vs.Cancelled)
+		for _, server := range vs.Servers {
+			if server.ServerNumber == serverNumber {
+				fmt.Printf("    Status:    %s\n", server.Status)
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func getVSwitch(ctx context.Context, client *hrobot.Client, id int, outputFormat string) error {
 	vs, err := client.VSwitch.Get(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get vSwitch: %w", err)
 	}
 
-	fmt.Printf("VSwitch Details:\n")
-	fmt.Printf("  ID:        %d\n", vs.ID)
-	fmt.Printf("  Name:      %s\n", vs.Name)
-	fmt.Printf("  VLAN:      %d\n", vs.VLAN)
-	fmt.Printf("  Cancelled: %v\n", vs.Cancelled)
+	if outputFormat == "json" {
+		return printJSON(vs)
+	}
+
+	renderVSwitch(os.Stdout, vs)
+	return nil
+}
+
+// renderVSwitch writes the details of a vSwitch, including its subnets and
+// hints for configuring the private network on the servers.
+func renderVSwitch(w io.Writer, vs *hrobot.VSwitch) {
+	fmt.Fprintf(w, "VSwitch Details:\n")
+	fmt.Fprintf(w, "  ID:        %d\n", vs.ID)
+	fmt.Fprintf(w, "  Name:      %s\n", vs.Name)
+	fmt.Fprintf(w, "  VLAN:      %d\n", vs.VLAN)
+	fmt.Fprintf(w, "  Cancelled: %v\n", vs.Cancelled)
 
 	if len(vs.Servers) > 0 {
-		fmt.Printf("\n  Servers (%d):\n", len(vs.Servers))
+		fmt.Fprintf(w, "\n  Servers (%d):\n", len(vs.Servers))
 		for i, server := range vs.Servers {
-			fmt.Printf("    [%d] #%d - %s\n", i+1, server.ServerNumber, server.ServerIP)
+			fmt.Fprintf(w, "    [%d] #%d - %s\n", i+1, server.ServerNumber, server.ServerIP)
 			if server.ServerIPv6Net != "" {
-				fmt.Printf("        IPv6: %s\n", server.ServerIPv6Net)
+				fmt.Fprintf(w, "        IPv6: %s\n", server.ServerIPv6Net)
 			}
-			fmt.Printf("        Status: %s\n", server.Status)
+			fmt.Fprintf(w, "        Status: %s\n", server.Status)
 		}
 	}
 
+	fmt.Fprintf(w, "\n  Subnets (%d):\n", len(vs.Subnets))
 	if len(vs.Subnets) > 0 {
-		fmt.Printf("\n  Subnets (%d):\n", len(vs.Subnets))
-		for i, subnet := range vs.Subnets {
-			fmt.Printf("    [%d] %s/%d\n", i+1, subnet.IP, subnet.Mask)
-			fmt.Printf("        Gateway: %s\n", subnet.Gateway)
+		t := table.New(w)
+		t.SetHeaders("IP", "Mask", "Gateway")
+		for _, subnet := range vs.Subnets {
+			t.AddRow(subnet.IP, fmt.Sprintf("/%d", subnet.Mask), subnet.Gateway)
 		}
+		t.Render()
+	} else {
+		fmt.Fprintln(w, "    none (use private addresses such as 10.0.0.0/24, or order a public subnet for the vSwitch in Robot)")
 	}
 
 	if len(vs.CloudNetwork) > 0 {
-		fmt.Printf("\n  Cloud Networks (%d):\n", len(vs.CloudNetwork))
+		fmt.Fprintf(w, "\n  Cloud Networks (%d):\n", len(vs.CloudNetwork))
 		for i, cn := range vs.CloudNetwork {
-			fmt.Printf("    [%d] ID %d - %s/%d\n", i+1, cn.ID, cn.IP, cn.Mask)
-			fmt.Printf("        Gateway: %s\n", cn.Gateway)
+			fmt.Fprintf(w, "    [%d] ID %d - %s/%d\n", i+1, cn.ID, cn.IP, cn.Mask)
+			fmt.Fprintf(w, "        Gateway: %s\n", cn.Gateway)
 		}
 	}
 
-	return nil
+	fmt.Fprintf(w, "\nTo use the vSwitch, add a VLAN interface with ID %d and MTU 1400 on each server.\n", vs.VLAN)
+	if len(vs.Subnets) > 0 {
+		fmt.Fprintln(w, "Assign it an address from a subnet above and route the subnet via its gateway.")
+	}
 }
 
 func createVSwitch(ctx context.Context, client *hrobot.Client, name string, vlan int) error {
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// vswitchFixture is the sample GET /vswitch/{vswitch-id} response from the
+// Robot API documentation.
+const vswitchFixture = `{
+  "vswitch": {
+    "id": 4321,
+    "name": "vswitch 1234",
+    "vlan": 4000,
+    "cancelled": false,
+    "server": [
+      {
+        "server_ip": "123.123.123.123",
+        "server_ipv6_net": "2a01:4f8:111:4221::",
+        "server_number": 321,
+        "status": "ready"
+      },
+      {
+        "server_ip": "123.123.123.124",
+        "server_ipv6_net": "2a01:4f8:111:4221::",
+        "server_number": 421,
+        "status": "ready"
+      }
+    ],
+    "subnet": [
+      {
+        "ip": "213.239.252.48",
+        "mask": 29,
+        "gateway": "213.239.252.49"
+      }
+    ],
+    "cloud_network": [
+      {
+        "id": 123,
+        "ip": "10.0.2.0",
+        "mask": 24,
+        "gateway": "10.0.2.1"
+      }
+    ]
+  }
+}`
+
+func newVSwitchTestServer(t *testing.T) *hrobot.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vswitch/4321" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(vswitchFixture))
+	}))
+	t.Cleanup(server.Close)
+
+	return hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))
+}
+
+func TestGetVSwitch_Subnets(t *testing.T) {
+	client := newVSwitchTestServer(t)
+
+	out := captureStdout(t, func() {
+		if err := getVSwitch(context.Background(), client, 4321, ""); err != nil {
+			t.Fatalf("getVSwitch returned error: %v", err)
+		}
+	})
+
+	for _, want := range []string{
+		"Subnets (1):",
+		"Gateway",
+		"213.239.252.48",
+		"/29",
+		"213.239.252.49",
+		"Cloud Networks (1):",
+		"VLAN interface with ID 4000 and MTU 1400",
+		"route the subnet via its gateway",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderVSwitch_NoSubnets(t *testing.T) {
+	var b strings.Builder
+	renderVSwitch(&b, &hrobot.VSwitch{ID: 1, Name: "private", VLAN: 4001})
+
+	out := b.String()
+	if !strings.Contains(out, "Subnets (0):") || !strings.Contains(out, "use private addresses") {
+		t.Errorf("expected subnet hint, got:\n%s", out)
+	}
+	if strings.Contains(out, "route the subnet via its gateway") {
+		t.Errorf("expected no gateway hint without subnets, got:\n%s", out)
+	}
+}
+
+func TestGetVSwitch_JSON(t *testing.T) {
+	client := newVSwitchTestServer(t)
+
+	out := captureStdout(t, func() {
+		if err := getVSwitch(context.Background(), client, 4321, "json"); err != nil {
+			t.Fatalf("getVSwitch returned error: %v", err)
+		}
+	})
+
+	var vs hrobot.VSwitch
+	if err := json.Unmarshal([]byte(out), &vs); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	if len(vs.Subnets) != 1 || vs.Subnets[0].Gateway != "213.239.252.49" || vs.Subnets[0].Mask != 29 {
+		t.Errorf("unexpected subnets in JSON output: %+v", vs.Subnets)
+	}
+}
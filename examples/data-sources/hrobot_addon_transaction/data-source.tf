@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    hrobot = {
+      source = "midwork-finds-jobs/hrobot"
+    }
+  }
+}
+
+provider "hrobot" {}
+
+# lookup the ip or subnet assigned by an addon order
+data "hrobot_addon_transaction" "example" {
+  id = "B20150121-344957-251478"
+}
+
+output "assigned_ips" {
+  value = data.hrobot_addon_transaction.example.assigned_ips
+}
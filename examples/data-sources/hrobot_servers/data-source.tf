@@ -0,0 +1,36 @@
+terraform {
+  required_providers {
+    hrobot = {
+      source = "midwork-finds-jobs/hrobot"
+    }
+  }
+}
+
+provider "hrobot" {}
+
+# list all servers
+data "hrobot_servers" "all" {}
+
+# list the ready web servers in Falkenstein
+data "hrobot_servers" "web" {
+  datacenter = "FSN1"
+  status     = "ready"
+  name_regex = "^web-"
+}
+
+# manage the firewall of every web server
+resource "hrobot_firewall" "web" {
+  for_each = { for server in data.hrobot_servers.web.servers : server.server_name => server }
+
+  server_id = each.value.server_id
+
+  input_rules = [
+    {
+      name             = "allow https"
+      ip_version       = "ipv4"
+      action           = "accept"
+      protocol         = "tcp"
+      destination_port = "443"
+    },
+  ]
+}
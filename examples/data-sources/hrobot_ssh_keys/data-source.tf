@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    hrobot = {
+      source = "midwork-finds-jobs/hrobot"
+    }
+  }
+}
+
+provider "hrobot" {}
+
+# list all SSH keys of the account
+data "hrobot_ssh_keys" "all" {}
+
+# authorize all keys on a new server
+resource "hrobot_server" "example" {
+  server_type = "AX41-NVMe"
+  server_name = "my-product-server"
+  datacenter  = "HEL1"
+
+  authorized_keys = data.hrobot_ssh_keys.all.keys[*].fingerprint
+}
@@ -42,3 +42,7 @@ resource "hrobot_firewall_template" "example" {
     },
   ]
 }
+
+# Import an existing firewall template by ID or by name
+# terraform import hrobot_firewall_template.example 12345
+# terraform import hrobot_firewall_template.example name:web-server-template
@@ -0,0 +1,7 @@
+resource "hrobot_ip" "example" {
+  ip               = "123.123.123.123"
+  traffic_warnings = true
+  traffic_hourly   = 200  # MB
+  traffic_daily    = 2000 # MB
+  traffic_monthly  = 20   # GB
+}
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// Ensure the implementation satisfies the datasource.DataSource interface.
+var _ datasource.DataSource = &AddonTransactionDataSource{}
+
+// NewAddonTransactionDataSource is a helper function to simplify the provider implementation.
+func NewAddonTransactionDataSource() datasource.DataSource {
+	return &AddonTransactionDataSource{}
+}
+
+// AddonTransactionDataSource is the data source implementation.
+type AddonTransactionDataSource struct {
+	client *hrobot.Client
+}
+
+// AddonTransactionDataSourceModel describes the data source data model.
+type AddonTransactionDataSourceModel struct {
+	ID          types.String   `tfsdk:"id"`
+	Date        types.String   `tfsdk:"date"`
+	Status      types.String   `tfsdk:"status"`
+	ServerID    types.Int64    `tfsdk:"server_id"`
+	ServerIP    types.String   `tfsdk:"server_ip"`
+	ProductID   types.String   `tfsdk:"product_id"`
+	ProductName types.String   `tfsdk:"product_name"`
+	AssignedIPs []types.String `tfsdk:"assigned_ips"`
+}
+
+// Metadata returns the data source type name.
+func (d *AddonTransactionDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_addon_transaction"
+}
+
+// Schema defines the schema for the data source.
+func (d *AddonTransactionDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetches an addon order transaction (e.g. an additional IP or subnet) and the resources it assigned.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Transaction ID",
+				Required:            true,
+			},
+			"date": schema.StringAttribute{
+				MarkdownDescription: "Transaction date",
+				Computed:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Transaction status (ready, in process, cancelled)",
+				Computed:            true,
+			},
+			"server_id": schema.Int64Attribute{
+				MarkdownDescription: "Server ID the addon was ordered for",
+				Computed:            true,
+			},
+			"server_ip": schema.StringAttribute{
+				MarkdownDescription: "Primary IP address of the server",
+				Computed:            true,
+			},
+			"product_id": schema.StringAttribute{
+				MarkdownDescription: "Addon product ID",
+				Computed:            true,
+			},
+			"product_name": schema.StringAttribute{
+				MarkdownDescription: "Addon product name",
+				Computed:            true,
+			},
+			"assigned_ips": schema.ListAttribute{
+				MarkdownDescription: "IP addresses and subnets assigned by the transaction (empty while the transaction is in process)",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *AddonTransactionDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*hrobot.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *hrobot.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *AddonTransactionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config AddonTransactionDataSourceModel
+
+	// Read configuration
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get transaction from API
+	transactionID := config.ID.ValueString()
+	transaction, err := d.client.Ordering.GetAddonTransaction(ctx, transactionID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading addon transaction",
+			fmt.Sprintf("Could not read addon transaction %s: %s", transactionID, err.Error()),
+		)
+		return
+	}
+
+	// Map API response to data source model
+	config.Date = types.StringValue(transaction.Date.Format("2006-01-02 15:04:05"))
+	config.Status = types.StringValue(transaction.Status)
+	config.ServerID = types.Int64Null()
+	if transaction.ServerNumber != nil {
+		config.ServerID = types.Int64Value(int64(*transaction.ServerNumber))
+	}
+	config.ServerIP = types.StringNull()
+	if transaction.ServerIP != nil {
+		config.ServerIP = types.StringValue(*transaction.ServerIP)
+	}
+	config.ProductID = types.StringValue(transaction.Product.ID)
+	config.ProductName = types.StringValue(transaction.Product.Name)
+
+	config.AssignedIPs = []types.String{}
+	for _, ip := range transaction.AssignedIPs() {
+		config.AssignedIPs = append(config.AssignedIPs, types.StringValue(ip))
+	}
+
+	// Save state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
@@ -6,14 +6,19 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net"
 	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
@@ -22,6 +27,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &FirewallResource{}
 var _ resource.ResourceWithImportState = &FirewallResource{}
+var _ resource.ResourceWithValidateConfig = &FirewallResource{}
 
 func NewFirewallResource() resource.Resource {
 	return &FirewallResource{}
@@ -39,6 +45,8 @@ type FirewallResourceModel struct {
 	WhitelistHetznerServices types.Bool          `tfsdk:"whitelist_hetzner_services"`
 	FilterIPv6               types.Bool          `tfsdk:"filter_ipv6"`
 	TemplateID               types.String        `tfsdk:"template_id"`
+	KeepMailBlock            types.Bool          `tfsdk:"keep_mail_block"`
+	WaitForReady             types.Bool          `tfsdk:"wait_for_ready"`
 	InputRules               []FirewallRuleModel `tfsdk:"input_rules"`
 	OutputRules              []FirewallRuleModel `tfsdk:"output_rules"`
 	ID                       types.String        `tfsdk:"id"`
@@ -93,9 +101,24 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 				MarkdownDescription: "firewall template id to apply. when set, this will apply the template rules to the server. cannot be used together with input_rules/output_rules. the whitelist_hetzner_services setting comes from the template.",
 				Optional:            true,
 			},
+			"keep_mail_block": schema.BoolAttribute{
+				MarkdownDescription: "keep hetzner's auto-added \"Block mail ports\" output rule (tcp ports 25,465). when enabled, the rule is submitted with the output rules even if it is not configured, and it is not reported as drift. (default: false)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"wait_for_ready": schema.BoolAttribute{
+				MarkdownDescription: "wait until the firewall has left the 'in process' state before applying changes. set to false to apply rules to many servers in bulk without waiting; the robot api rejects changes while a previous change is still in process, so an apply may then fail and have to be retried. (default: true)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
 			"input_rules": schema.ListNestedAttribute{
 				MarkdownDescription: "Input firewall rules",
 				Optional:            true,
+				Validators: []validator.List{
+					FirewallRulePortsValidator(),
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
@@ -109,10 +132,16 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 						"action": schema.StringAttribute{
 							MarkdownDescription: "Action: 'accept' or 'discard'",
 							Required:            true,
+							Validators: []validator.String{
+								FirewallRuleActionValidator(),
+							},
 						},
 						"protocol": schema.StringAttribute{
 							MarkdownDescription: "Protocol: 'tcp', 'udp', 'icmp', 'esp', 'gre'",
 							Optional:            true,
+							Validators: []validator.String{
+								FirewallRuleProtocolValueValidator(),
+							},
 						},
 						"source_ips": schema.ListAttribute{
 							MarkdownDescription: "List of source IP addresses or CIDRs. If CIDR notation is not specified, /32 will be automatically added for IPv4 addresses.",
@@ -125,11 +154,11 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 							Optional:            true,
 						},
 						"source_port": schema.StringAttribute{
-							MarkdownDescription: "Source port or port range",
+							MarkdownDescription: "Source port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"destination_port": schema.StringAttribute{
-							MarkdownDescription: "Destination port or port range",
+							MarkdownDescription: "Destination port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"tcp_flags": schema.StringAttribute{
@@ -142,6 +171,9 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 			"output_rules": schema.ListNestedAttribute{
 				MarkdownDescription: "Output firewall rules",
 				Optional:            true,
+				Validators: []validator.List{
+					FirewallRulePortsValidator(),
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
@@ -155,10 +187,16 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 						"action": schema.StringAttribute{
 							MarkdownDescription: "Action: 'accept' or 'discard'",
 							Required:            true,
+							Validators: []validator.String{
+								FirewallRuleActionValidator(),
+							},
 						},
 						"protocol": schema.StringAttribute{
 							MarkdownDescription: "Protocol: 'tcp', 'udp', 'icmp', 'esp', 'gre'",
 							Optional:            true,
+							Validators: []validator.String{
+								FirewallRuleProtocolValueValidator(),
+							},
 						},
 						"source_ips": schema.ListAttribute{
 							MarkdownDescription: "List of source IP addresses or CIDRs. If CIDR notation is not specified, /32 will be automatically added for IPv4 addresses.",
@@ -171,11 +209,11 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 							Optional:            true,
 						},
 						"source_port": schema.StringAttribute{
-							MarkdownDescription: "Source port or port range",
+							MarkdownDescription: "Source port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"destination_port": schema.StringAttribute{
-							MarkdownDescription: "Destination port or port range",
+							MarkdownDescription: "Destination port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"tcp_flags": schema.StringAttribute{
@@ -196,6 +234,34 @@ func (r *FirewallResource) Schema(ctx context.Context, req resource.SchemaReques
 	}
 }
 
+// ValidateConfig rejects configurations that set template_id together with rules.
+func (r *FirewallResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var templateID types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("template_id"), &templateID)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if templateID.IsNull() {
+		return
+	}
+
+	for _, attr := range []string{"input_rules", "output_rules"} {
+		var rules types.List
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attr), &rules)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if rules.IsNull() {
+			continue
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root(attr),
+			"Conflicting firewall configuration",
+			fmt.Sprintf("template_id cannot be combined with %s: the rules of the firewall come from the template. Remove either template_id or %s.", attr, attr),
+		)
+	}
+}
+
 func (r *FirewallResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -230,7 +296,7 @@ func (r *FirewallResource) Create(ctx context.Context, req resource.CreateReques
 	serverID := hrobot.ServerID(int(data.ServerID.ValueInt64()))
 
 	// Wait for firewall to be ready if needed
-	if err := r.client.Firewall.WaitForFirewallReady(ctx, serverID); err != nil {
+	if err := r.waitForReady(ctx, &data, serverID); err != nil {
 		resp.Diagnostics.AddError("firewall not ready", fmt.Sprintf("firewall is busy, could not wait for it to be ready: %s", err))
 		return
 	}
@@ -256,7 +322,9 @@ func (r *FirewallResource) Create(ctx context.Context, req resource.CreateReques
 
 		// Convert input rules (with array expansion)
 		if len(data.InputRules) > 0 {
-			updateConfig.Rules.Input = convertToAPIRules(data.InputRules)
+			var diags diag.Diagnostics
+			updateConfig.Rules.Input, diags = convertToAPIRules(data.InputRules)
+			resp.Diagnostics.Append(diags...)
 			if len(updateConfig.Rules.Input) > 10 {
 				resp.Diagnostics.AddError(
 					"Too many input firewall rules after expansion",
@@ -268,7 +336,9 @@ func (r *FirewallResource) Create(ctx context.Context, req resource.CreateReques
 
 		// Convert output rules (with array expansion)
 		if len(data.OutputRules) > 0 {
-			updateConfig.Rules.Output = convertToAPIRules(data.OutputRules)
+			var diags diag.Diagnostics
+			updateConfig.Rules.Output, diags = convertToAPIRules(data.OutputRules)
+			resp.Diagnostics.Append(diags...)
 			if len(updateConfig.Rules.Output) > 10 {
 				resp.Diagnostics.AddError(
 					"Too many output firewall rules after expansion",
@@ -278,6 +348,10 @@ func (r *FirewallResource) Create(ctx context.Context, req resource.CreateReques
 			}
 		}
 
+		if data.KeepMailBlock.ValueBool() {
+			updateConfig.Rules.Output = withMailBlockRule(updateConfig.Rules.Output)
+		}
+
 		// Update the firewall configuration
 		firewallConfig, err = r.client.Firewall.Update(ctx, serverID, updateConfig)
 		if err != nil {
@@ -349,10 +423,16 @@ func (r *FirewallResource) Read(ctx context.Context, req resource.ReadRequest, r
 			data.InputRules = nil
 		}
 
-		// Convert output rules from API response
-		if len(firewallConfig.Rules.Output) > 0 {
-			data.OutputRules = make([]FirewallRuleModel, len(firewallConfig.Rules.Output))
-			for i, rule := range firewallConfig.Rules.Output {
+		// Convert output rules from API response. A kept mail rule that is not
+		// part of the configuration is hidden so that it does not show up as drift.
+		outputRules := firewallConfig.Rules.Output
+		configuredOutput, _ := convertToAPIRules(data.OutputRules)
+		if data.KeepMailBlock.ValueBool() && !hasMailBlockRule(configuredOutput) {
+			outputRules = withoutMailBlockRule(outputRules)
+		}
+		if len(outputRules) > 0 {
+			data.OutputRules = make([]FirewallRuleModel, len(outputRules))
+			for i, rule := range outputRules {
 				data.OutputRules[i] = convertFromHRobotRule(rule)
 			}
 		} else {
@@ -362,6 +442,9 @@ func (r *FirewallResource) Read(ctx context.Context, req resource.ReadRequest, r
 		// When using template_id, explicitly set rules to nil to avoid drift
 		data.InputRules = nil
 		data.OutputRules = nil
+
+		// Warn when the live rules no longer match the template
+		resp.Diagnostics.Append(r.checkTemplateDrift(ctx, firewallConfig, data.TemplateID.ValueString())...)
 	}
 
 	// Save updated data into Terraform state
@@ -382,7 +465,7 @@ func (r *FirewallResource) Update(ctx context.Context, req resource.UpdateReques
 	serverID := hrobot.ServerID(int(data.ServerID.ValueInt64()))
 
 	// Wait for firewall to be ready if needed
-	if err := r.client.Firewall.WaitForFirewallReady(ctx, serverID); err != nil {
+	if err := r.waitForReady(ctx, &data, serverID); err != nil {
 		resp.Diagnostics.AddError("firewall not ready", fmt.Sprintf("firewall is busy, could not wait for it to be ready: %s", err))
 		return
 	}
@@ -408,7 +491,9 @@ func (r *FirewallResource) Update(ctx context.Context, req resource.UpdateReques
 
 		// Convert input rules (with array expansion)
 		if len(data.InputRules) > 0 {
-			updateConfig.Rules.Input = convertToAPIRules(data.InputRules)
+			var diags diag.Diagnostics
+			updateConfig.Rules.Input, diags = convertToAPIRules(data.InputRules)
+			resp.Diagnostics.Append(diags...)
 			if len(updateConfig.Rules.Input) > 10 {
 				resp.Diagnostics.AddError(
 					"Too many input firewall rules after expansion",
@@ -420,7 +505,9 @@ func (r *FirewallResource) Update(ctx context.Context, req resource.UpdateReques
 
 		// Convert output rules (with array expansion)
 		if len(data.OutputRules) > 0 {
-			updateConfig.Rules.Output = convertToAPIRules(data.OutputRules)
+			var diags diag.Diagnostics
+			updateConfig.Rules.Output, diags = convertToAPIRules(data.OutputRules)
+			resp.Diagnostics.Append(diags...)
 			if len(updateConfig.Rules.Output) > 10 {
 				resp.Diagnostics.AddError(
 					"Too many output firewall rules after expansion",
@@ -430,6 +517,10 @@ func (r *FirewallResource) Update(ctx context.Context, req resource.UpdateReques
 			}
 		}
 
+		if data.KeepMailBlock.ValueBool() {
+			updateConfig.Rules.Output = withMailBlockRule(updateConfig.Rules.Output)
+		}
+
 		// Update the firewall configuration
 		firewallConfig, err = r.client.Firewall.Update(ctx, serverID, updateConfig)
 		if err != nil {
@@ -471,6 +562,106 @@ func (r *FirewallResource) Delete(ctx context.Context, req resource.DeleteReques
 	)
 }
 
+// checkTemplateDrift compares the live firewall configuration with the template
+// it was created from and returns a warning when they diverge, e.g. because the
+// rules were changed in the Robot interface.
+func (r *FirewallResource) checkTemplateDrift(ctx context.Context, config *hrobot.FirewallConfig, templateID string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	template, err := getTemplateCached(ctx, r.client, templateID)
+	if err != nil {
+		diags.AddWarning(
+			"Could not check firewall template drift",
+			fmt.Sprintf("Unable to read firewall template %s: %s", templateID, err),
+		)
+		return diags
+	}
+
+	var differences []string
+	if !firewallRulesEqual(config.Rules.Input, template.Rules.Input) {
+		differences = append(differences, fmt.Sprintf("input rules: %d live, %d in template", len(config.Rules.Input), len(template.Rules.Input)))
+	}
+	liveOutput, templateOutput := withoutMailBlockRule(config.Rules.Output), withoutMailBlockRule(template.Rules.Output)
+	if !firewallRulesEqual(liveOutput, templateOutput) {
+		differences = append(differences, fmt.Sprintf("output rules: %d live, %d in template", len(liveOutput), len(templateOutput)))
+	}
+	if config.WhitelistHOS != template.WhitelistHOS {
+		differences = append(differences, fmt.Sprintf("whitelist_hos: %t live, %t in template", config.WhitelistHOS, template.WhitelistHOS))
+	}
+	if config.FilterIPv6 != template.FilterIPv6 {
+		differences = append(differences, fmt.Sprintf("filter_ipv6: %t live, %t in template", config.FilterIPv6, template.FilterIPv6))
+	}
+
+	if len(differences) > 0 {
+		diags.AddWarning(
+			"Firewall differs from template",
+			fmt.Sprintf("The firewall of server %d no longer matches template %s (%s):\n  - %s\n\nRe-apply the template with: terraform apply -replace=<address of this resource>",
+				config.ServerNumber, templateID, template.Name, strings.Join(differences, "\n  - ")),
+		)
+	}
+	return diags
+}
+
+// templateCache memoizes firewall templates per client for checkTemplateDrift,
+// so that refreshing several firewalls created from the same template reads it
+// from the rate-limited API only once per Terraform run.
+var (
+	templateCacheMu sync.Mutex
+	templateCache   = map[*hrobot.Client]map[string]*hrobot.FirewallTemplate{}
+)
+
+// getTemplateCached returns the firewall template from templateCache, fetching
+// it on first use. The lock is held while fetching, so that parallel refreshes
+// wait for the first request instead of each sending their own.
+func getTemplateCached(ctx context.Context, client *hrobot.Client, templateID string) (*hrobot.FirewallTemplate, error) {
+	templateCacheMu.Lock()
+	defer templateCacheMu.Unlock()
+
+	if template, ok := templateCache[client][templateID]; ok {
+		return template, nil
+	}
+	template, err := client.Firewall.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if templateCache[client] == nil {
+		templateCache[client] = map[string]*hrobot.FirewallTemplate{}
+	}
+	templateCache[client][templateID] = template
+	return template, nil
+}
+
+// forgetTemplate drops a template from templateCache after it was changed.
+func forgetTemplate(client *hrobot.Client, templateID string) {
+	templateCacheMu.Lock()
+	defer templateCacheMu.Unlock()
+	delete(templateCache[client], templateID)
+}
+
+// firewallRulesEqual reports whether two rule lists contain the same rules,
+// including their names and order.
+func firewallRulesEqual(a, b []hrobot.FirewallRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// waitForReady waits for the firewall to leave the "in process" state unless
+// wait_for_ready is disabled.
+func (r *FirewallResource) waitForReady(ctx context.Context, data *FirewallResourceModel, serverID hrobot.ServerID) error {
+	if !data.WaitForReady.IsNull() && !data.WaitForReady.ValueBool() {
+		tflog.Debug(ctx, "skipping firewall ready check", map[string]interface{}{"server_id": int(serverID)})
+		return nil
+	}
+	return r.client.Firewall.WaitForFirewallReady(ctx, serverID)
+}
+
 func (r *FirewallResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Import using server number as ID
 	serverNum, err := strconv.Atoi(req.ID)
@@ -479,50 +670,82 @@ func (r *FirewallResource) ImportState(ctx context.Context, req resource.ImportS
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("server_id"), int64(serverNum))...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	// Fetch the current configuration so that the first plan after import is clean
+	firewallConfig, err := r.client.Firewall.Get(ctx, hrobot.ServerID(serverNum))
+	if err != nil {
+		resp.Diagnostics.AddError("client error", fmt.Sprintf("unable to read firewall configuration, got error: %s", err))
+		return
+	}
+
+	data := FirewallResourceModel{
+		ServerID:                 types.Int64Value(int64(serverNum)),
+		ID:                       types.StringValue(req.ID),
+		Status:                   types.StringValue(string(firewallConfig.Status)),
+		WhitelistHetznerServices: types.BoolValue(firewallConfig.WhitelistHOS),
+		FilterIPv6:               types.BoolValue(firewallConfig.FilterIPv6),
+		TemplateID:               types.StringNull(),
+		WaitForReady:             types.BoolValue(true),
+	}
+	if len(firewallConfig.Rules.Input) > 0 {
+		data.InputRules = convertFromAPIRules(firewallConfig.Rules.Input)
+	}
+
+	// Hetzner's auto-added mail rule is imported as keep_mail_block rather than
+	// as an output rule, the same way Read hides a kept mail rule.
+	outputRules := firewallConfig.Rules.Output
+	data.KeepMailBlock = types.BoolValue(hasMailBlockRule(outputRules))
+	if data.KeepMailBlock.ValueBool() {
+		outputRules = withoutMailBlockRule(outputRules)
+	}
+	if len(outputRules) > 0 {
+		data.OutputRules = convertFromAPIRules(outputRules)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 // Helper function to convert Terraform model rule to hrobot rule with a specific source/dest IP.
 func convertToHRobotRuleWithIPs(rule FirewallRuleModel, sourceIP, destIP string) hrobot.FirewallRule {
 	return hrobot.FirewallRule{
 		Name:       rule.Name.ValueString(),
-		IPVersion:  hrobot.IPVersion(rule.IPVersion.ValueString()),
+		IPVersion:  ipVersionForAddresses(hrobot.IPVersion(rule.IPVersion.ValueString()), sourceIP, destIP),
 		Action:     hrobot.Action(rule.Action.ValueString()),
 		Protocol:   hrobot.Protocol(rule.Protocol.ValueString()),
-		SourceIP:   normalizeCIDR(sourceIP),
-		DestIP:     normalizeCIDR(destIP),
+		SourceIP:   hrobot.NormalizeCIDR(sourceIP),
+		DestIP:     hrobot.NormalizeCIDR(destIP),
 		SourcePort: rule.SourcePort.ValueString(),
 		DestPort:   rule.DestinationPort.ValueString(),
 		TCPFlags:   rule.TCPFlags.ValueString(),
 	}
 }
 
-// Helper function to normalize IP addresses by adding /32 or /128 if CIDR is missing.
-func normalizeCIDR(ip string) string {
-	if ip == "" {
-		return ""
-	}
-	// If already has CIDR notation, return as-is
-	if containsChar(ip, '/') {
-		return ip
+// hasMailBlockRule reports whether rules contain the auto-added mail rule.
+func hasMailBlockRule(rules []hrobot.FirewallRule) bool {
+	for _, rule := range rules {
+		if rule.IsMailBlock() {
+			return true
+		}
 	}
-	// Check if it's IPv6 (contains :)
-	if containsChar(ip, ':') {
-		return ip + "/128"
+	return false
+}
+
+// withMailBlockRule appends the auto-added mail rule unless it is already present.
+func withMailBlockRule(rules []hrobot.FirewallRule) []hrobot.FirewallRule {
+	if hasMailBlockRule(rules) {
+		return rules
 	}
-	// Assume IPv4
-	return ip + "/32"
+	return append(rules, hrobot.MailBlockRule)
 }
 
-// Helper function to check if string contains a character.
-func containsChar(s string, c rune) bool {
-	for _, ch := range s {
-		if ch == c {
-			return true
+// withoutMailBlockRule removes the auto-added mail rule.
+func withoutMailBlockRule(rules []hrobot.FirewallRule) []hrobot.FirewallRule {
+	var filtered []hrobot.FirewallRule
+	for _, rule := range rules {
+		if !rule.IsMailBlock() {
+			filtered = append(filtered, rule)
 		}
 	}
-	return false
+	return filtered
 }
 
 // Helper function to convert hrobot rule to Terraform model rule.
@@ -565,8 +788,11 @@ func stringOrNull(s string) types.String {
 
 // Helper function to convert slice of Terraform rules to API rules.
 // This function expands rules with multiple source_ips or destination_ips values into separate rules.
-func convertToAPIRules(rules []FirewallRuleModel) []hrobot.FirewallRule {
+// The ip_version of each expanded rule follows the family of its addresses; a warning is
+// returned when that overrides the declared ip_version.
+func convertToAPIRules(rules []FirewallRuleModel) ([]hrobot.FirewallRule, diag.Diagnostics) {
 	var apiRules []hrobot.FirewallRule
+	var diags diag.Diagnostics
 
 	for _, rule := range rules {
 		// Extract source IPs from the list
@@ -590,12 +816,42 @@ func convertToAPIRules(rules []FirewallRuleModel) []hrobot.FirewallRule {
 		// Create a rule for each combination of source and destination IPs
 		for _, sourceIP := range sourceIPs {
 			for _, destinationIP := range destinationIPs {
-				apiRules = append(apiRules, convertToHRobotRuleWithIPs(rule, sourceIP, destinationIP))
+				apiRule := convertToHRobotRuleWithIPs(rule, sourceIP, destinationIP)
+				declared := hrobot.IPVersion(rule.IPVersion.ValueString())
+				if declared != "" && apiRule.IPVersion != declared {
+					address := sourceIP
+					if address == "" {
+						address = destinationIP
+					}
+					diags.AddWarning(
+						"Firewall rule ip_version overridden",
+						fmt.Sprintf("Rule '%s' declares ip_version '%s' but lists the %s address '%s'. The expanded rule for this address uses ip_version '%s'.",
+							rule.Name.ValueString(), declared, apiRule.IPVersion, address, apiRule.IPVersion),
+					)
+				}
+				apiRules = append(apiRules, apiRule)
 			}
 		}
 	}
 
-	return apiRules
+	return apiRules, diags
+}
+
+// ipVersionForAddresses returns the IP version matching the family of the
+// rule's source or destination address, or the declared version when the rule
+// has no address.
+func ipVersionForAddresses(declared hrobot.IPVersion, sourceIP, destIP string) hrobot.IPVersion {
+	for _, address := range []string{sourceIP, destIP} {
+		ip := net.ParseIP(strings.SplitN(address, "/", 2)[0])
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			return hrobot.IPv4
+		}
+		return hrobot.IPv6
+	}
+	return declared
 }
 
 // Helper function to convert slice of API rules to Terraform rules.
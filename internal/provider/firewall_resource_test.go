@@ -0,0 +1,614 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestFirewallResource_WaitForReady(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		status := "in process"
+		if requests > 1 {
+			status = "active"
+		}
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        status,
+				"rules": map[string]interface{}{
+					"input":  []map[string]interface{}{},
+					"output": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	r := &FirewallResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+	ctx := context.Background()
+
+	data := FirewallResourceModel{WaitForReady: types.BoolValue(false)}
+	if err := r.waitForReady(ctx, &data, hrobot.ServerID(321)); err != nil {
+		t.Fatalf("waitForReady returned error: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no firewall requests with wait_for_ready = false, got %d", requests)
+	}
+
+	data.WaitForReady = types.BoolValue(true)
+	if err := r.waitForReady(ctx, &data, hrobot.ServerID(321)); err != nil {
+		t.Fatalf("waitForReady returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 firewall requests with wait_for_ready = true, got %d", requests)
+	}
+}
+
+func TestConvertToAPIRules_MixedIPFamilies(t *testing.T) {
+	sourceIPs, diags := types.ListValueFrom(context.Background(), types.StringType, []string{"1.2.3.4", "2001:db8::/64", "5.6.7.0/24"})
+	if diags.HasError() {
+		t.Fatalf("failed to build source_ips: %v", diags)
+	}
+
+	rules := []FirewallRuleModel{
+		{
+			Name:            types.StringValue("allow ssh"),
+			IPVersion:       types.StringValue("ipv4"),
+			Action:          types.StringValue("accept"),
+			Protocol:        types.StringValue("tcp"),
+			SourceIPs:       sourceIPs,
+			DestinationIPs:  types.ListNull(types.StringType),
+			DestinationPort: types.StringValue("22"),
+		},
+	}
+
+	apiRules, diags := convertToAPIRules(rules)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+
+	expected := []struct {
+		sourceIP  string
+		ipVersion hrobot.IPVersion
+	}{
+		{"1.2.3.4/32", hrobot.IPv4},
+		{"2001:db8::/64", hrobot.IPv6},
+		{"5.6.7.0/24", hrobot.IPv4},
+	}
+	if len(apiRules) != len(expected) {
+		t.Fatalf("expected %d rules, got %d", len(expected), len(apiRules))
+	}
+	for i, e := range expected {
+		if apiRules[i].SourceIP != e.sourceIP || apiRules[i].IPVersion != e.ipVersion {
+			t.Errorf("rule %d: expected %s (%s), got %s (%s)", i, e.sourceIP, e.ipVersion, apiRules[i].SourceIP, apiRules[i].IPVersion)
+		}
+	}
+
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("expected 1 warning for the overridden ip_version, got %d: %v", diags.WarningsCount(), diags)
+	}
+	if detail := diags.Warnings()[0].Detail(); !strings.Contains(detail, "2001:db8::/64") {
+		t.Errorf("expected warning to mention the IPv6 address, got %q", detail)
+	}
+}
+
+func TestConvertToAPIRules_NoAddressKeepsIPVersion(t *testing.T) {
+	rules := []FirewallRuleModel{
+		{
+			Name:            types.StringValue("block http"),
+			IPVersion:       types.StringValue("ipv6"),
+			Action:          types.StringValue("discard"),
+			Protocol:        types.StringValue("tcp"),
+			SourceIPs:       types.ListNull(types.StringType),
+			DestinationIPs:  types.ListNull(types.StringType),
+			DestinationPort: types.StringValue("80"),
+		},
+	}
+
+	apiRules, diags := convertToAPIRules(rules)
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+	if len(apiRules) != 1 || apiRules[0].IPVersion != hrobot.IPv6 {
+		t.Errorf("expected a single ipv6 rule, got %+v", apiRules)
+	}
+}
+
+func TestConvertRules_RoundTripPorts(t *testing.T) {
+	rules := []FirewallRuleModel{
+		{
+			Name:            types.StringValue("dns replies"),
+			IPVersion:       types.StringValue("ipv4"),
+			Action:          types.StringValue("accept"),
+			Protocol:        types.StringValue("udp"),
+			SourceIPs:       types.ListNull(types.StringType),
+			DestinationIPs:  types.ListNull(types.StringType),
+			SourcePort:      types.StringValue("53"),
+			DestinationPort: types.StringValue("32768-65535"),
+			TCPFlags:        types.StringNull(),
+		},
+	}
+
+	apiRules, diags := convertToAPIRules(rules)
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diags)
+	}
+	if len(apiRules) != 1 || apiRules[0].SourcePort != "53" || apiRules[0].DestPort != "32768-65535" {
+		t.Fatalf("expected both ports in the API rule, got %+v", apiRules)
+	}
+
+	back := convertFromAPIRules(apiRules)
+	if len(back) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(back))
+	}
+	if !back[0].SourcePort.Equal(rules[0].SourcePort) || !back[0].DestinationPort.Equal(rules[0].DestinationPort) {
+		t.Errorf("expected ports to round-trip, got source_port=%s destination_port=%s", back[0].SourcePort, back[0].DestinationPort)
+	}
+}
+
+func TestFirewallResource_ValidateConfig_TemplateAndRules(t *testing.T) {
+	ctx := context.Background()
+	r := &FirewallResource{}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	newConfig := func(model FirewallResourceModel) tfsdk.Config {
+		plan := tfsdk.Plan{Schema: schemaResp.Schema}
+		if diags := plan.Set(ctx, &model); diags.HasError() {
+			t.Fatalf("failed to build config: %v", diags)
+		}
+		return tfsdk.Config{Schema: plan.Schema, Raw: plan.Raw}
+	}
+
+	rule := FirewallRuleModel{
+		Name:            types.StringValue("allow ssh"),
+		IPVersion:       types.StringValue("ipv4"),
+		Action:          types.StringValue("accept"),
+		Protocol:        types.StringValue("tcp"),
+		SourceIPs:       types.ListNull(types.StringType),
+		DestinationIPs:  types.ListNull(types.StringType),
+		DestinationPort: types.StringValue("22"),
+	}
+	base := FirewallResourceModel{
+		ServerID:                 types.Int64Value(321),
+		Status:                   types.StringNull(),
+		WhitelistHetznerServices: types.BoolNull(),
+		FilterIPv6:               types.BoolNull(),
+		TemplateID:               types.StringNull(),
+		KeepMailBlock:            types.BoolNull(),
+		WaitForReady:             types.BoolNull(),
+		ID:                       types.StringNull(),
+	}
+
+	tests := []struct {
+		name      string
+		configure func(m *FirewallResourceModel)
+		wantError bool
+	}{
+		{
+			name: "template only",
+			configure: func(m *FirewallResourceModel) {
+				m.TemplateID = types.StringValue("1234")
+			},
+		},
+		{
+			name: "rules only",
+			configure: func(m *FirewallResourceModel) {
+				m.InputRules = []FirewallRuleModel{rule}
+			},
+		},
+		{
+			name: "template and input rules",
+			configure: func(m *FirewallResourceModel) {
+				m.TemplateID = types.StringValue("1234")
+				m.InputRules = []FirewallRuleModel{rule}
+			},
+			wantError: true,
+		},
+		{
+			name: "template and output rules",
+			configure: func(m *FirewallResourceModel) {
+				m.TemplateID = types.StringValue("1234")
+				m.OutputRules = []FirewallRuleModel{rule}
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := base
+			tt.configure(&model)
+
+			resp := &resource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: newConfig(model)}, resp)
+
+			if resp.Diagnostics.HasError() != tt.wantError {
+				t.Errorf("expected error = %v, got diagnostics: %v", tt.wantError, resp.Diagnostics)
+			}
+			if tt.wantError && resp.Diagnostics.Errors()[0].Summary() != "Conflicting firewall configuration" {
+				t.Errorf("unexpected diagnostic: %v", resp.Diagnostics.Errors()[0])
+			}
+		})
+	}
+}
+
+func TestFirewallResource_CheckTemplateDrift(t *testing.T) {
+	templateGets := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/firewall/template/1234" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+		templateGets++
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":            1234,
+				"name":          "webserver",
+				"filter_ipv6":   false,
+				"whitelist_hos": true,
+				"is_default":    false,
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{"name": "allow ssh", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "dst_port": "22"},
+					},
+					"output": []map[string]interface{}{
+						{"name": "allow all", "action": "accept"},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	r := &FirewallResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+	ctx := context.Background()
+
+	live := &hrobot.FirewallConfig{
+		ServerNumber: 321,
+		WhitelistHOS: true,
+		Rules: hrobot.FirewallRules{
+			Input: []hrobot.FirewallRule{
+				{Name: "allow ssh", IPVersion: hrobot.IPv4, Action: hrobot.ActionAccept, Protocol: hrobot.ProtocolTCP, DestPort: "22"},
+			},
+			Output: []hrobot.FirewallRule{
+				{Name: "allow all", Action: hrobot.ActionAccept},
+				hrobot.MailBlockRule,
+			},
+		},
+	}
+
+	if diags := r.checkTemplateDrift(ctx, live, "1234"); len(diags) != 0 {
+		t.Errorf("expected no drift for matching rules, got %v", diags)
+	}
+
+	live.Rules.Input = append(live.Rules.Input, hrobot.FirewallRule{Name: "allow http", IPVersion: hrobot.IPv4, Action: hrobot.ActionAccept, Protocol: hrobot.ProtocolTCP, DestPort: "80"})
+
+	diags := r.checkTemplateDrift(ctx, live, "1234")
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("expected a drift warning, got %v", diags)
+	}
+	warning := diags.Warnings()[0]
+	if warning.Summary() != "Firewall differs from template" {
+		t.Errorf("unexpected warning summary '%s'", warning.Summary())
+	}
+	if !strings.Contains(warning.Detail(), "input rules: 2 live, 1 in template") {
+		t.Errorf("expected input rule difference in warning, got %q", warning.Detail())
+	}
+
+	// The template is read once, not on every refresh of a firewall using it.
+	if templateGets != 1 {
+		t.Errorf("expected the template to be fetched once, got %d requests", templateGets)
+	}
+
+	forgetTemplate(r.client, "1234")
+	r.checkTemplateDrift(ctx, live, "1234")
+	if templateGets != 2 {
+		t.Errorf("expected the template to be fetched again after forgetTemplate, got %d requests", templateGets)
+	}
+}
+
+func TestFirewallResource_ImportStatePopulatesRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/firewall/321" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"filter_ipv6":   true,
+				"whitelist_hos": false,
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{
+						{"name": "allow ssh", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "src_ip": "1.2.3.4/32", "dst_port": "22"},
+						{"name": "allow https", "ip_version": "ipv6", "action": "accept", "protocol": "tcp", "dst_port": "443"},
+					},
+					"output": []map[string]interface{}{
+						{"name": "allow all", "action": "accept"},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r := &FirewallResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	resp := &resource.ImportStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: "321"}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState returned errors: %v", resp.Diagnostics)
+	}
+
+	var data FirewallResourceModel
+	if diags := resp.State.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read imported state: %v", diags)
+	}
+
+	if data.ServerID.ValueInt64() != 321 || data.ID.ValueString() != "321" {
+		t.Errorf("expected server_id 321 and id '321', got %v and %v", data.ServerID, data.ID)
+	}
+	if data.Status.ValueString() != "active" {
+		t.Errorf("expected status 'active', got %v", data.Status)
+	}
+	if !data.FilterIPv6.ValueBool() || data.WhitelistHetznerServices.ValueBool() {
+		t.Errorf("expected filter_ipv6 true and whitelist_hetzner_services false, got %v and %v", data.FilterIPv6, data.WhitelistHetznerServices)
+	}
+	if len(data.InputRules) != 2 || len(data.OutputRules) != 1 {
+		t.Fatalf("expected 2 input and 1 output rules, got %d and %d", len(data.InputRules), len(data.OutputRules))
+	}
+
+	var sourceIPs []string
+	data.InputRules[0].SourceIPs.ElementsAs(ctx, &sourceIPs, false)
+	if data.InputRules[0].Name.ValueString() != "allow ssh" || len(sourceIPs) != 1 || sourceIPs[0] != "1.2.3.4/32" {
+		t.Errorf("unexpected first input rule: %+v", data.InputRules[0])
+	}
+	if data.InputRules[1].DestinationPort.ValueString() != "443" {
+		t.Errorf("expected second input rule port '443', got %v", data.InputRules[1].DestinationPort)
+	}
+}
+
+func TestFirewallResource_ImportStateKeepsMailBlock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{
+			"firewall": map[string]interface{}{
+				"server_ip":     "123.123.123.123",
+				"server_number": 321,
+				"status":        "active",
+				"rules": map[string]interface{}{
+					"input": []map[string]interface{}{},
+					"output": []map[string]interface{}{
+						{"name": "Block mail ports", "ip_version": "ipv4", "action": "discard", "protocol": "tcp", "dst_port": "25,465"},
+						{"name": "allow all", "action": "accept"},
+					},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r := &FirewallResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	importResp := &resource.ImportStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: "321"}, importResp)
+	if importResp.Diagnostics.HasError() {
+		t.Fatalf("ImportState returned errors: %v", importResp.Diagnostics)
+	}
+
+	// The refresh that follows the import must keep the same state.
+	readResp := &resource.ReadResponse{State: importResp.State}
+	r.Read(ctx, resource.ReadRequest{State: importResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("Read returned errors: %v", readResp.Diagnostics)
+	}
+
+	for name, state := range map[string]tfsdk.State{"import": importResp.State, "read": readResp.State} {
+		var data FirewallResourceModel
+		if diags := state.Get(ctx, &data); diags.HasError() {
+			t.Fatalf("%s: failed to read state: %v", name, diags)
+		}
+		if !data.KeepMailBlock.ValueBool() {
+			t.Errorf("%s: expected keep_mail_block true for a firewall with the auto-added mail rule", name)
+		}
+		if len(data.OutputRules) != 1 || data.OutputRules[0].Name.ValueString() != "allow all" {
+			t.Errorf("%s: expected only the 'allow all' output rule, got %+v", name, data.OutputRules)
+		}
+	}
+}
+
+func TestFirewallRuleValueValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator validator.String
+		value     types.String
+		wantErr   string
+	}{
+		{name: "valid action", validator: FirewallRuleActionValidator(), value: types.StringValue("discard")},
+		{name: "invalid action", validator: FirewallRuleActionValidator(), value: types.StringValue("allow"), wantErr: "action must be 'accept' or 'discard'"},
+		{name: "uppercase action", validator: FirewallRuleActionValidator(), value: types.StringValue("ACCEPT"), wantErr: `Use "accept"`},
+		{name: "valid protocol", validator: FirewallRuleProtocolValueValidator(), value: types.StringValue("gre")},
+		{name: "invalid protocol", validator: FirewallRuleProtocolValueValidator(), value: types.StringValue("sctp"), wantErr: "protocol must be one of"},
+		{name: "null protocol", validator: FirewallRuleProtocolValueValidator(), value: types.StringNull()},
+		{name: "unknown protocol", validator: FirewallRuleProtocolValueValidator(), value: types.StringUnknown()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("protocol"), ConfigValue: tt.value}
+			resp := &validator.StringResponse{}
+			tt.validator.ValidateString(context.Background(), req, resp)
+
+			if tt.wantErr == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() {
+				t.Fatalf("expected error containing %q, got none", tt.wantErr)
+			}
+			if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %q", tt.wantErr, detail)
+			}
+		})
+	}
+}
+
+func TestConvertRules_PortlessProtocols(t *testing.T) {
+	rules := []FirewallRuleModel{
+		{
+			Name:            types.StringValue("ipsec"),
+			IPVersion:       types.StringValue("ipv4"),
+			Action:          types.StringValue("accept"),
+			Protocol:        types.StringValue("esp"),
+			SourceIPs:       types.ListNull(types.StringType),
+			DestinationIPs:  types.ListNull(types.StringType),
+			SourcePort:      types.StringNull(),
+			DestinationPort: types.StringNull(),
+			TCPFlags:        types.StringNull(),
+		},
+		{
+			Name:            types.StringValue("tunnel"),
+			IPVersion:       types.StringValue("ipv4"),
+			Action:          types.StringValue("accept"),
+			Protocol:        types.StringValue("gre"),
+			SourceIPs:       types.ListNull(types.StringType),
+			DestinationIPs:  types.ListNull(types.StringType),
+			SourcePort:      types.StringNull(),
+			DestinationPort: types.StringNull(),
+			TCPFlags:        types.StringNull(),
+		},
+	}
+
+	apiRules, diags := convertToAPIRules(rules)
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diags)
+	}
+	want := []hrobot.Protocol{hrobot.ProtocolESP, hrobot.ProtocolGRE}
+	if len(apiRules) != len(want) {
+		t.Fatalf("expected %d rules, got %d", len(want), len(apiRules))
+	}
+	for i, rule := range apiRules {
+		if rule.Protocol != want[i] || rule.SourcePort != "" || rule.DestPort != "" {
+			t.Errorf("rule %d: expected a portless %s rule, got %+v", i, want[i], rule)
+		}
+	}
+
+	back := convertFromAPIRules(apiRules)
+	for i, rule := range back {
+		if !rule.SourcePort.IsNull() || !rule.DestinationPort.IsNull() {
+			t.Errorf("rule %d: expected null ports after the round trip, got source_port=%s destination_port=%s", i, rule.SourcePort, rule.DestinationPort)
+		}
+	}
+}
+
+func TestFirewallRulePortsValidator(t *testing.T) {
+	attrTypes := map[string]attr.Type{
+		"name":             types.StringType,
+		"protocol":         types.StringType,
+		"source_port":      types.StringType,
+		"destination_port": types.StringType,
+	}
+	rule := func(protocol, sourcePort, destPort string) attr.Value {
+		value := func(s string) types.String {
+			if s == "" {
+				return types.StringNull()
+			}
+			return types.StringValue(s)
+		}
+		return types.ObjectValueMust(attrTypes, map[string]attr.Value{
+			"name":             types.StringValue("rule"),
+			"protocol":         value(protocol),
+			"source_port":      value(sourcePort),
+			"destination_port": value(destPort),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		rules   []attr.Value
+		wantErr string
+	}{
+		{name: "esp and gre without ports", rules: []attr.Value{rule("esp", "", ""), rule("gre", "", "")}},
+		{name: "tcp with ports", rules: []attr.Value{rule("tcp", "1024-65535", "22")}},
+		{name: "any protocol with port", rules: []attr.Value{rule("", "", "53")}},
+		{name: "esp with destination port", rules: []attr.Value{rule("esp", "", "500")}, wantErr: "sets destination_port, but esp rules have no ports"},
+		{name: "gre with source port", rules: []attr.Value{rule("tcp", "", "22"), rule("gre", "1024", "")}, wantErr: "Rule 2 ('rule') sets source_port"},
+		{name: "icmp with destination port", rules: []attr.Value{rule("icmp", "", "8")}, wantErr: "icmp rules have no ports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.ListRequest{
+				Path:        path.Root("input_rules"),
+				ConfigValue: types.ListValueMust(types.ObjectType{AttrTypes: attrTypes}, tt.rules),
+			}
+			resp := &validator.ListResponse{}
+			FirewallRulePortsValidator().ValidateList(context.Background(), req, resp)
+
+			if tt.wantErr == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() {
+				t.Fatalf("expected error containing %q, got none", tt.wantErr)
+			}
+			if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %q", tt.wantErr, detail)
+			}
+		})
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
 )
 
 // firewallRuleValidator validates that if protocol is set, ip_version must also be set.
@@ -129,3 +131,126 @@ func createICMPv6Error(index int, ruleName string, attrPath path.Path) diag.Diag
 func FirewallRuleProtocolValidator() validator.List {
 	return firewallRuleValidator{}
 }
+
+// firewallRulePortsValidator validates that ports are only set for protocols
+// that have them. ESP, GRE and ICMP rules match whole packets.
+type firewallRulePortsValidator struct{}
+
+// Description returns a plain text description of the validator's behavior.
+func (v firewallRulePortsValidator) Description(ctx context.Context) string {
+	return "ensures that source_port and destination_port are only set for tcp and udp rules"
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior.
+func (v firewallRulePortsValidator) MarkdownDescription(ctx context.Context) string {
+	return "ensures that `source_port` and `destination_port` are only set for `tcp` and `udp` rules"
+}
+
+// ValidateList validates the list of firewall rules.
+func (v firewallRulePortsValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for i, ruleAttr := range req.ConfigValue.Elements() {
+		ruleObj, ok := ruleAttr.(types.Object)
+		if !ok || ruleObj.IsNull() || ruleObj.IsUnknown() {
+			continue
+		}
+		attrs := ruleObj.Attributes()
+
+		protocol := hrobot.Protocol(knownString(attrs["protocol"]))
+		if protocol.HasPorts() {
+			continue
+		}
+		for _, attr := range []string{"source_port", "destination_port"} {
+			if knownString(attrs[attr]) == "" {
+				continue
+			}
+			ruleDesc := fmt.Sprintf("Rule %d", i+1)
+			if name := knownString(attrs["name"]); name != "" {
+				ruleDesc = fmt.Sprintf("Rule %d ('%s')", i+1, name)
+			}
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"Port not supported for protocol",
+				fmt.Sprintf("%s sets %s, but %s rules have no ports. Only tcp and udp rules can filter on ports; remove %s from this rule.",
+					ruleDesc, attr, protocol, attr),
+			)
+			return
+		}
+	}
+}
+
+// knownString returns the value of a known string attribute, or "" if the
+// attribute is missing, null or unknown.
+func knownString(v attr.Value) string {
+	str, ok := v.(types.String)
+	if !ok || str.IsNull() || str.IsUnknown() {
+		return ""
+	}
+	return str.ValueString()
+}
+
+// FirewallRulePortsValidator returns a validator that rejects ports on rules for protocols without ports.
+func FirewallRulePortsValidator() validator.List {
+	return firewallRulePortsValidator{}
+}
+
+// ruleValueValidator checks a rule attribute with one of the SDK parsers. The
+// value must also use the canonical lowercase spelling, which is what the
+// Robot API returns, so that the state matches the configuration.
+type ruleValueValidator struct {
+	description string
+	parse       func(string) (string, error)
+}
+
+// Description returns a plain text description of the validator's behavior.
+func (v ruleValueValidator) Description(ctx context.Context) string {
+	return v.description
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior.
+func (v ruleValueValidator) MarkdownDescription(ctx context.Context) string {
+	return v.description
+}
+
+// ValidateString validates a single rule attribute.
+func (v ruleValueValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	canonical, err := v.parse(value)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid firewall rule value", err.Error())
+		return
+	}
+	if canonical != value {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid firewall rule value",
+			fmt.Sprintf("Use %q instead of %q.", canonical, value))
+	}
+}
+
+// FirewallRuleActionValidator returns a validator that accepts the actions of hrobot.ParseAction.
+func FirewallRuleActionValidator() validator.String {
+	return ruleValueValidator{
+		description: "value must be 'accept' or 'discard'",
+		parse: func(s string) (string, error) {
+			action, err := hrobot.ParseAction(s)
+			return string(action), err
+		},
+	}
+}
+
+// FirewallRuleProtocolValueValidator returns a validator that accepts the protocols of hrobot.ParseProtocol.
+func FirewallRuleProtocolValueValidator() validator.String {
+	return ruleValueValidator{
+		description: "value must be one of 'tcp', 'udp', 'icmp', 'esp' or 'gre'",
+		parse: func(s string) (string, error) {
+			protocol, err := hrobot.ParseProtocol(s)
+			return string(protocol), err
+		},
+	}
+}
@@ -6,6 +6,8 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -78,7 +80,7 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 				Default:             booldefault.StaticBool(true),
 			},
 			"is_default": schema.BoolAttribute{
-				MarkdownDescription: "whether this is a default template (default: false)",
+				MarkdownDescription: "whether this is the account default template; setting it to true makes this template the default (default: false)",
 				Optional:            true,
 				Computed:            true,
 				Default:             booldefault.StaticBool(false),
@@ -89,6 +91,7 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 				Validators: []validator.List{
 					listvalidator.SizeAtMost(10),
 					FirewallRuleProtocolValidator(),
+					FirewallRulePortsValidator(),
 				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
@@ -103,10 +106,16 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 						"action": schema.StringAttribute{
 							MarkdownDescription: "Action (accept or discard)",
 							Required:            true,
+							Validators: []validator.String{
+								FirewallRuleActionValidator(),
+							},
 						},
 						"protocol": schema.StringAttribute{
 							MarkdownDescription: "Protocol (tcp, udp, icmp, esp, gre)",
 							Optional:            true,
+							Validators: []validator.String{
+								FirewallRuleProtocolValueValidator(),
+							},
 						},
 						"source_ips": schema.ListAttribute{
 							MarkdownDescription: "List of source IP addresses or CIDRs. If CIDR notation is not specified, /32 will be automatically added for IPv4 addresses.",
@@ -119,11 +128,11 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 							Optional:            true,
 						},
 						"source_port": schema.StringAttribute{
-							MarkdownDescription: "Source port or port range",
+							MarkdownDescription: "Source port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"destination_port": schema.StringAttribute{
-							MarkdownDescription: "Destination port or port range",
+							MarkdownDescription: "Destination port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"tcp_flags": schema.StringAttribute{
@@ -139,6 +148,7 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 				Validators: []validator.List{
 					listvalidator.SizeAtMost(10),
 					FirewallRuleProtocolValidator(),
+					FirewallRulePortsValidator(),
 				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
@@ -153,10 +163,16 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 						"action": schema.StringAttribute{
 							MarkdownDescription: "Action (accept or discard)",
 							Required:            true,
+							Validators: []validator.String{
+								FirewallRuleActionValidator(),
+							},
 						},
 						"protocol": schema.StringAttribute{
 							MarkdownDescription: "Protocol (tcp, udp, icmp, esp, gre)",
 							Optional:            true,
+							Validators: []validator.String{
+								FirewallRuleProtocolValueValidator(),
+							},
 						},
 						"source_ips": schema.ListAttribute{
 							MarkdownDescription: "List of source IP addresses or CIDRs. If CIDR notation is not specified, /32 will be automatically added for IPv4 addresses.",
@@ -169,11 +185,11 @@ func (r *FirewallTemplateResource) Schema(_ context.Context, _ resource.SchemaRe
 							Optional:            true,
 						},
 						"source_port": schema.StringAttribute{
-							MarkdownDescription: "Source port or port range",
+							MarkdownDescription: "Source port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"destination_port": schema.StringAttribute{
-							MarkdownDescription: "Destination port or port range",
+							MarkdownDescription: "Destination port or port range (tcp and udp only)",
 							Optional:            true,
 						},
 						"tcp_flags": schema.StringAttribute{
@@ -214,7 +230,8 @@ func (r *FirewallTemplateResource) Create(ctx context.Context, req resource.Crea
 	}
 
 	// Validate rule count after expansion - Hetzner enforces a maximum of 10 firewall rules
-	expandedInputRules := convertToAPIRules(data.InputRules)
+	expandedInputRules, diags := convertToAPIRules(data.InputRules)
+	resp.Diagnostics.Append(diags...)
 	if len(expandedInputRules) > 10 {
 		resp.Diagnostics.AddError(
 			"Too many input firewall rules after expansion",
@@ -222,7 +239,8 @@ func (r *FirewallTemplateResource) Create(ctx context.Context, req resource.Crea
 		)
 		return
 	}
-	expandedOutputRules := convertToAPIRules(data.OutputRules)
+	expandedOutputRules, diags := convertToAPIRules(data.OutputRules)
+	resp.Diagnostics.Append(diags...)
 	if len(expandedOutputRules) > 10 {
 		resp.Diagnostics.AddError(
 			"Too many output firewall rules after expansion",
@@ -281,6 +299,15 @@ func (r *FirewallTemplateResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
+	template, err = r.setDefault(ctx, template, strconv.Itoa(template.ID), data.IsDefault.ValueBool(), false)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting default firewall template",
+			fmt.Sprintf("Could not make firewall template %d the default: %s", template.ID, err),
+		)
+		return
+	}
+
 	// Map response to model
 	data.ID = types.StringValue(fmt.Sprintf("%d", template.ID))
 	data.Name = types.StringValue(template.Name)
@@ -339,8 +366,15 @@ func (r *FirewallTemplateResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
+	var wasDefault types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("is_default"), &wasDefault)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Validate rule count after expansion - Hetzner enforces a maximum of 10 firewall rules
-	expandedInputRules := convertToAPIRules(data.InputRules)
+	expandedInputRules, diags := convertToAPIRules(data.InputRules)
+	resp.Diagnostics.Append(diags...)
 	if len(expandedInputRules) > 10 {
 		resp.Diagnostics.AddError(
 			"Too many input firewall rules after expansion",
@@ -348,7 +382,8 @@ func (r *FirewallTemplateResource) Update(ctx context.Context, req resource.Upda
 		)
 		return
 	}
-	expandedOutputRules := convertToAPIRules(data.OutputRules)
+	expandedOutputRules, diags := convertToAPIRules(data.OutputRules)
+	resp.Diagnostics.Append(diags...)
 	if len(expandedOutputRules) > 10 {
 		resp.Diagnostics.AddError(
 			"Too many output firewall rules after expansion",
@@ -406,6 +441,16 @@ func (r *FirewallTemplateResource) Update(ctx context.Context, req resource.Upda
 		)
 		return
 	}
+	forgetTemplate(r.client, data.ID.ValueString())
+
+	template, err = r.setDefault(ctx, template, data.ID.ValueString(), data.IsDefault.ValueBool(), wasDefault.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting default firewall template",
+			fmt.Sprintf("Could not make firewall template %s the default: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
 
 	// Map response to model
 	data.Name = types.StringValue(template.Name)
@@ -420,6 +465,16 @@ func (r *FirewallTemplateResource) Update(ctx context.Context, req resource.Upda
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// setDefault makes the template the account default when is_default is
+// planned as true and either just changed to true or the API does not report
+// the template as the default. Otherwise the template is returned unchanged.
+func (r *FirewallTemplateResource) setDefault(ctx context.Context, template *hrobot.FirewallTemplate, id string, wantDefault, wasDefault bool) (*hrobot.FirewallTemplate, error) {
+	if !wantDefault || (wasDefault && template.IsDefault) {
+		return template, nil
+	}
+	return r.client.Firewall.SetDefaultTemplate(ctx, id)
+}
+
 // Delete deletes the resource and removes the Terraform state on success.
 func (r *FirewallTemplateResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data FirewallTemplateResourceModel
@@ -437,9 +492,49 @@ func (r *FirewallTemplateResource) Delete(ctx context.Context, req resource.Dele
 		)
 		return
 	}
+	forgetTemplate(r.client, data.ID.ValueString())
 }
 
-// ImportState imports the resource state.
+// ImportState imports the resource state. The import ID is either the
+// template ID or "name:<template-name>".
 func (r *FirewallTemplateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id := req.ID
+	if name, ok := strings.CutPrefix(req.ID, "name:"); ok {
+		resolved, err := r.templateIDByName(ctx, name)
+		if err != nil {
+			resp.Diagnostics.AddError("invalid import id", err.Error())
+			return
+		}
+		id = resolved
+	} else if _, err := strconv.Atoi(req.ID); err != nil {
+		resp.Diagnostics.AddError("invalid import id", fmt.Sprintf("import ID must be a template ID or name:<template-name>, got: %s", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
+
+// templateIDByName resolves a template name to its ID. Template names are not
+// unique, so a name shared by several templates is an error.
+func (r *FirewallTemplateResource) templateIDByName(ctx context.Context, name string) (string, error) {
+	templates, err := r.client.Firewall.ListTemplates(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not list firewall templates: %w", err)
+	}
+
+	var ids []string
+	for _, template := range templates {
+		if template.Name == name {
+			ids = append(ids, strconv.Itoa(template.ID))
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no firewall template named %q", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d firewall templates are named %q (IDs: %s), import by ID instead", len(ids), name, strings.Join(ids, ", "))
+	}
 }
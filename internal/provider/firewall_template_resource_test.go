@@ -0,0 +1,263 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// testTemplateModel returns a template with one input rule.
+func testTemplateModel(destinationPort string, isDefault bool) FirewallTemplateResourceModel {
+	return FirewallTemplateResourceModel{
+		ID:                       types.StringValue("5"),
+		Name:                     types.StringValue("web"),
+		FilterIPv6:               types.BoolValue(false),
+		WhitelistHetznerServices: types.BoolValue(true),
+		IsDefault:                types.BoolValue(isDefault),
+		InputRules: []FirewallRuleModel{
+			{
+				Name:            types.StringValue("allow ssh"),
+				IPVersion:       types.StringValue("ipv4"),
+				Action:          types.StringValue("accept"),
+				Protocol:        types.StringValue("tcp"),
+				SourceIPs:       types.ListNull(types.StringType),
+				DestinationIPs:  types.ListNull(types.StringType),
+				DestinationPort: types.StringValue(destinationPort),
+			},
+		},
+	}
+}
+
+// newTemplateUpdateRequest builds an update request from the prior state and
+// the plan.
+func newTemplateUpdateRequest(t *testing.T, schemaResp *resource.SchemaResponse, state, plan FirewallTemplateResourceModel) resource.UpdateRequest {
+	t.Helper()
+	ctx := context.Background()
+
+	req := resource.UpdateRequest{
+		Plan:  tfsdk.Plan{Schema: schemaResp.Schema},
+		State: tfsdk.State{Schema: schemaResp.Schema},
+	}
+	if diags := req.Plan.Set(ctx, &plan); diags.HasError() {
+		t.Fatalf("failed to build plan: %v", diags)
+	}
+	if diags := req.State.Set(ctx, &state); diags.HasError() {
+		t.Fatalf("failed to build state: %v", diags)
+	}
+	return req
+}
+
+func TestFirewallTemplateResource_UpdateKeepsID(t *testing.T) {
+	var requests []string
+	var posted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		posted = r.PostForm
+
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":            5,
+				"name":          "web",
+				"filter_ipv6":   false,
+				"whitelist_hos": true,
+				"is_default":    false,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r := &FirewallTemplateResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	// The destination port of the rule changes from 22 to 2222
+	req := newTemplateUpdateRequest(t, schemaResp, testTemplateModel("22", false), testTemplateModel("2222", false))
+
+	resp := &resource.UpdateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	r.Update(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update returned errors: %v", resp.Diagnostics)
+	}
+
+	if len(requests) != 1 || requests[0] != "POST /firewall/template/5" {
+		t.Errorf("expected a single in-place update of template 5, got %v", requests)
+	}
+	if got := posted.Get("rules[input][0][dst_port]"); got != "2222" {
+		t.Errorf("expected dst_port '2222' to be sent, got %q", got)
+	}
+
+	var data FirewallTemplateResourceModel
+	if diags := resp.State.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read state: %v", diags)
+	}
+	if data.ID.ValueString() != "5" {
+		t.Errorf("expected template id '5' to be kept, got %v", data.ID)
+	}
+	if len(data.InputRules) != 1 || data.InputRules[0].DestinationPort.ValueString() != "2222" {
+		t.Errorf("expected the planned rules in state, got %+v", data.InputRules)
+	}
+}
+
+func TestFirewallTemplateResource_UpdateSetsDefault(t *testing.T) {
+	var setDefaultCalls int
+	isDefault := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		// The set-default call only sends is_default
+		if r.PostForm.Get("name") == "" {
+			setDefaultCalls++
+			isDefault = true
+		}
+
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":         5,
+				"name":       "web",
+				"is_default": isDefault,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r := &FirewallTemplateResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	update := func(state, plan FirewallTemplateResourceModel) FirewallTemplateResourceModel {
+		t.Helper()
+		resp := &resource.UpdateResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+		r.Update(ctx, newTemplateUpdateRequest(t, schemaResp, state, plan), resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Update returned errors: %v", resp.Diagnostics)
+		}
+		var data FirewallTemplateResourceModel
+		if diags := resp.State.Get(ctx, &data); diags.HasError() {
+			t.Fatalf("failed to read state: %v", diags)
+		}
+		return data
+	}
+
+	// Changing only a rule does not touch the default
+	update(testTemplateModel("22", false), testTemplateModel("2222", false))
+	if setDefaultCalls != 0 {
+		t.Fatalf("expected no set-default call, got %d", setDefaultCalls)
+	}
+
+	// Flipping is_default to true makes the template the default
+	data := update(testTemplateModel("22", false), testTemplateModel("22", true))
+	if setDefaultCalls != 1 {
+		t.Fatalf("expected one set-default call, got %d", setDefaultCalls)
+	}
+	if !data.IsDefault.ValueBool() {
+		t.Error("expected is_default to be true in state")
+	}
+
+	// Once it is the default, further updates do not repeat the call
+	update(testTemplateModel("22", true), testTemplateModel("2222", true))
+	if setDefaultCalls != 1 {
+		t.Errorf("expected no further set-default call, got %d calls", setDefaultCalls)
+	}
+}
+
+func TestFirewallTemplateResource_ImportState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/firewall/template" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		response := []map[string]interface{}{
+			{"firewall_template": map[string]interface{}{"id": 3, "name": "web"}},
+			{"firewall_template": map[string]interface{}{"id": 7, "name": "db"}},
+			{"firewall_template": map[string]interface{}{"id": 9, "name": "db"}},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r := &FirewallTemplateResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	tests := []struct {
+		name    string
+		id      string
+		wantID  string
+		wantErr string
+	}{
+		{name: "numeric id", id: "12", wantID: "12"},
+		{name: "name", id: "name:web", wantID: "3"},
+		{name: "unknown name", id: "name:mail", wantErr: `no firewall template named "mail"`},
+		{name: "ambiguous name", id: "name:db", wantErr: `2 firewall templates are named "db" (IDs: 7, 9)`},
+		{name: "invalid id", id: "web", wantErr: "import ID must be a template ID or name:<template-name>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &resource.ImportStateResponse{
+				State: tfsdk.State{
+					Schema: schemaResp.Schema,
+					Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+				},
+			}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: tt.id}, resp)
+
+			if tt.wantErr != "" {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected error containing %q, got none", tt.wantErr)
+				}
+				if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, tt.wantErr) {
+					t.Errorf("expected error containing %q, got %q", tt.wantErr, detail)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("ImportState returned errors: %v", resp.Diagnostics)
+			}
+
+			var id types.String
+			if diags := resp.State.GetAttribute(ctx, path.Root("id"), &id); diags.HasError() {
+				t.Fatalf("failed to read id: %v", diags)
+			}
+			if id.ValueString() != tt.wantID {
+				t.Errorf("expected id %q, got %q", tt.wantID, id.ValueString())
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// Ensure the implementation satisfies the resource.Resource interface.
+var _ resource.Resource = &IPResource{}
+var _ resource.ResourceWithImportState = &IPResource{}
+
+// NewIPResource is a helper function to simplify the provider implementation.
+func NewIPResource() resource.Resource {
+	return &IPResource{}
+}
+
+// IPResource is the resource implementation.
+type IPResource struct {
+	client *hrobot.Client
+}
+
+// IPResourceModel describes the resource data model.
+type IPResourceModel struct {
+	IP              types.String `tfsdk:"ip"`
+	TrafficWarnings types.Bool   `tfsdk:"traffic_warnings"`
+	TrafficHourly   types.Int64  `tfsdk:"traffic_hourly"`
+	TrafficDaily    types.Int64  `tfsdk:"traffic_daily"`
+	TrafficMonthly  types.Int64  `tfsdk:"traffic_monthly"`
+}
+
+// Metadata returns the resource type name.
+func (r *IPResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ip"
+}
+
+// Schema defines the schema for the resource.
+func (r *IPResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	thresholdModifiers := []planmodifier.Int64{
+		int64planmodifier.UseStateForUnknown(),
+	}
+	thresholdValidators := []validator.Int64{
+		int64validator.AtLeast(1),
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the traffic warnings of an IP address in Hetzner Robot. Destroying the resource disables the traffic warnings; the IP address itself is not cancelled.",
+		Attributes: map[string]schema.Attribute{
+			"ip": schema.StringAttribute{
+				MarkdownDescription: "IP address",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"traffic_warnings": schema.BoolAttribute{
+				MarkdownDescription: "Whether traffic warnings are enabled (default: `true`)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"traffic_hourly": schema.Int64Attribute{
+				MarkdownDescription: "Hourly traffic limit in MB. Defaults to the current limit",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       thresholdModifiers,
+				Validators:          thresholdValidators,
+			},
+			"traffic_daily": schema.Int64Attribute{
+				MarkdownDescription: "Daily traffic limit in MB. Defaults to the current limit",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       thresholdModifiers,
+				Validators:          thresholdValidators,
+			},
+			"traffic_monthly": schema.Int64Attribute{
+				MarkdownDescription: "Monthly traffic limit in GB. Defaults to the current limit",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       thresholdModifiers,
+				Validators:          thresholdValidators,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *IPResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*hrobot.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"unexpected resource configure type",
+			fmt.Sprintf("expected *hrobot.Client, got: %T. please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// parseIP parses the ip attribute.
+func (m *IPResourceModel) parseIP() (net.IP, error) {
+	ip := net.ParseIP(m.IP.ValueString())
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address: %s", m.IP.ValueString())
+	}
+	return ip, nil
+}
+
+// trafficWarningSettings maps the planned attributes to the API settings.
+// Unknown thresholds are sent as 0, which keeps the current limits.
+func (m *IPResourceModel) trafficWarningSettings() hrobot.TrafficWarningSettings {
+	return hrobot.TrafficWarningSettings{
+		Enabled: m.TrafficWarnings.ValueBool(),
+		Hourly:  int(m.TrafficHourly.ValueInt64()),
+		Daily:   int(m.TrafficDaily.ValueInt64()),
+		Monthly: int(m.TrafficMonthly.ValueInt64()),
+	}
+}
+
+// setTrafficWarnings copies the traffic warning settings of an IP address
+// into the model.
+func (m *IPResourceModel) setTrafficWarnings(ip *hrobot.IPAddress) {
+	m.TrafficWarnings = types.BoolValue(ip.TrafficWarnings)
+	m.TrafficHourly = types.Int64Value(int64(ip.TrafficHourly))
+	m.TrafficDaily = types.Int64Value(int64(ip.TrafficDaily))
+	m.TrafficMonthly = types.Int64Value(int64(ip.TrafficMonthly))
+}
+
+// update applies the planned traffic warning settings.
+func (r *IPResource) update(ctx context.Context, plan *IPResourceModel) error {
+	ip, err := plan.parseIP()
+	if err != nil {
+		return err
+	}
+
+	updated, err := r.client.IP.UpdateTrafficWarnings(ctx, ip, plan.trafficWarningSettings())
+	if err != nil {
+		return err
+	}
+
+	plan.setTrafficWarnings(updated)
+	return nil
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *IPResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan IPResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.update(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(
+			"error setting traffic warnings",
+			fmt.Sprintf("could not set traffic warnings for %s: %s", plan.IP.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *IPResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state IPResourceModel
+
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ip, err := state.parseIP()
+	if err != nil {
+		resp.Diagnostics.AddError("invalid ip address", err.Error())
+		return
+	}
+
+	// Get current state from API
+	ipAddr, err := r.client.IP.Get(ctx, ip)
+	if err != nil {
+		if hrobot.IsNotFoundError(err) {
+			// IP address was cancelled outside of Terraform
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"error reading ip address",
+			fmt.Sprintf("could not read ip address %s: %s", state.IP.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Update state with latest values from API
+	state.setTrafficWarnings(ipAddr)
+
+	// Save updated state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update updates the resource and sets the updated Terraform state.
+func (r *IPResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan IPResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.update(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(
+			"error updating traffic warnings",
+			fmt.Sprintf("could not update traffic warnings for %s: %s", plan.IP.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	// Save updated state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete disables the traffic warnings and removes the Terraform state.
+func (r *IPResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state IPResourceModel
+
+	// Read Terraform state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ip, err := state.parseIP()
+	if err != nil {
+		resp.Diagnostics.AddError("invalid ip address", err.Error())
+		return
+	}
+
+	err = r.client.IP.SetTrafficWarnings(ctx, ip, false)
+	if err != nil {
+		if !hrobot.IsNotFoundError(err) {
+			resp.Diagnostics.AddError(
+				"error disabling traffic warnings",
+				fmt.Sprintf("could not disable traffic warnings for %s: %s", state.IP.ValueString(), err.Error()),
+			)
+			return
+		}
+	}
+}
+
+// ImportState imports an existing resource into Terraform.
+func (r *IPResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The import ID is the IP address
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ip"), req.ID)...)
+}
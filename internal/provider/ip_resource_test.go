@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestIPResourceModel_TrafficWarningSettings(t *testing.T) {
+	tests := []struct {
+		name  string
+		model IPResourceModel
+		want  hrobot.TrafficWarningSettings
+	}{
+		{
+			name: "all thresholds set",
+			model: IPResourceModel{
+				TrafficWarnings: types.BoolValue(true),
+				TrafficHourly:   types.Int64Value(200),
+				TrafficDaily:    types.Int64Value(2000),
+				TrafficMonthly:  types.Int64Value(20),
+			},
+			want: hrobot.TrafficWarningSettings{Enabled: true, Hourly: 200, Daily: 2000, Monthly: 20},
+		},
+		{
+			name: "unknown thresholds keep the current limits",
+			model: IPResourceModel{
+				TrafficWarnings: types.BoolValue(true),
+				TrafficHourly:   types.Int64Unknown(),
+				TrafficDaily:    types.Int64Value(5000),
+				TrafficMonthly:  types.Int64Unknown(),
+			},
+			want: hrobot.TrafficWarningSettings{Enabled: true, Daily: 5000},
+		},
+		{
+			name: "warnings disabled",
+			model: IPResourceModel{
+				TrafficWarnings: types.BoolValue(false),
+				TrafficHourly:   types.Int64Null(),
+				TrafficDaily:    types.Int64Null(),
+				TrafficMonthly:  types.Int64Null(),
+			},
+			want: hrobot.TrafficWarningSettings{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.trafficWarningSettings(); got != tt.want {
+				t.Errorf("trafficWarningSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPResourceModel_SetTrafficWarnings(t *testing.T) {
+	model := IPResourceModel{IP: types.StringValue("123.123.123.123")}
+	model.setTrafficWarnings(&hrobot.IPAddress{
+		TrafficWarnings: true,
+		TrafficHourly:   200,
+		TrafficDaily:    2000,
+		TrafficMonthly:  20,
+	})
+
+	if !model.TrafficWarnings.ValueBool() {
+		t.Error("expected traffic_warnings to be true")
+	}
+	if model.TrafficHourly.ValueInt64() != 200 || model.TrafficDaily.ValueInt64() != 2000 || model.TrafficMonthly.ValueInt64() != 20 {
+		t.Errorf("unexpected thresholds: hourly=%s daily=%s monthly=%s", model.TrafficHourly, model.TrafficDaily, model.TrafficMonthly)
+	}
+}
+
+func TestIPResource_Update(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/ip/123.123.123.123" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if r.FormValue("traffic_hourly") != "" {
+			t.Errorf("expected unknown traffic_hourly to be omitted, got %q", r.FormValue("traffic_hourly"))
+		}
+		if r.FormValue("traffic_daily") != "5000" {
+			t.Errorf("expected traffic_daily 5000, got %q", r.FormValue("traffic_daily"))
+		}
+
+		response := map[string]interface{}{
+			"ip": map[string]interface{}{
+				"ip":               "123.123.123.123",
+				"server_number":    321,
+				"traffic_warnings": true,
+				"traffic_hourly":   200,
+				"traffic_daily":    5000,
+				"traffic_monthly":  20,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	r := &IPResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	plan := IPResourceModel{
+		IP:              types.StringValue("123.123.123.123"),
+		TrafficWarnings: types.BoolValue(true),
+		TrafficHourly:   types.Int64Unknown(),
+		TrafficDaily:    types.Int64Value(5000),
+		TrafficMonthly:  types.Int64Unknown(),
+	}
+	if err := r.update(context.Background(), &plan); err != nil {
+		t.Fatalf("update returned error: %v", err)
+	}
+	if plan.TrafficHourly.ValueInt64() != 200 || plan.TrafficMonthly.ValueInt64() != 20 {
+		t.Errorf("expected unknown thresholds to be filled from the response, got hourly=%s monthly=%s", plan.TrafficHourly, plan.TrafficMonthly)
+	}
+
+	plan.IP = types.StringValue("not-an-ip")
+	if err := r.update(context.Background(), &plan); err == nil {
+		t.Error("expected error for invalid ip address, got nil")
+	}
+}
@@ -81,6 +81,11 @@ func (p *HetznerRobotProvider) Configure(ctx context.Context, req provider.Confi
 			"missing username configuration",
 			"username must be set in provider configuration or via HROBOT_USERNAME environment variable",
 		)
+	} else if err := hrobot.ValidateUsername(username); err != nil {
+		resp.Diagnostics.AddError(
+			"invalid username configuration",
+			err.Error(),
+		)
 	}
 
 	if password == "" {
@@ -117,6 +122,7 @@ func (p *HetznerRobotProvider) Resources(ctx context.Context) []func() resource.
 		NewVSwitchResource,
 		NewRDNSResource,
 		NewFailoverResource,
+		NewIPResource,
 	}
 }
 
@@ -126,6 +132,9 @@ func (p *HetznerRobotProvider) DataSources(ctx context.Context) []func() datasou
 		NewFirewallTemplateDataSource,
 		NewAuctionServersDataSource,
 		NewServerDataSource,
+		NewServersDataSource,
+		NewSSHKeysDataSource,
+		NewAddonTransactionDataSource,
 	}
 }
 
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -23,6 +25,7 @@ import (
 // Ensure the implementation satisfies the resource.Resource interface.
 var _ resource.Resource = &ServerResource{}
 var _ resource.ResourceWithImportState = &ServerResource{}
+var _ resource.ResourceWithModifyPlan = &ServerResource{}
 
 // NewServerResource is a helper function to simplify the provider implementation.
 func NewServerResource() resource.Resource {
@@ -32,6 +35,9 @@ func NewServerResource() resource.Resource {
 // ServerResource is the resource implementation.
 type ServerResource struct {
 	client *hrobot.Client
+
+	// pollInterval overrides how often order transactions are polled (tests only).
+	pollInterval time.Duration
 }
 
 // ServerResourceModel describes the resource data model.
@@ -55,6 +61,29 @@ type PublicNetModel struct {
 	IPv4Enabled types.Bool   `tfsdk:"ipv4_enabled"`
 	IPv4        types.String `tfsdk:"ipv4"`
 	IPv6        types.String `tfsdk:"ipv6"`
+	IPv6Subnets types.List   `tfsdk:"ipv6_subnets"`
+}
+
+// setIPv6Subnets records all IPv6 subnets of the server. The first one is kept
+// in ipv6 as the primary address for compatibility.
+func (m *PublicNetModel) setIPv6Subnets(server *hrobot.Server) {
+	subnets := ipv6Subnets(server)
+	if len(subnets) > 0 {
+		m.IPv6 = types.StringValue(subnets[0])
+	}
+	m.IPv6Subnets, _ = types.ListValueFrom(context.Background(), types.StringType, subnets)
+}
+
+// ipv6Subnets returns all IPv6 subnets of a server in CIDR notation.
+func ipv6Subnets(server *hrobot.Server) []string {
+	subnets := []string{}
+	for _, subnet := range server.Subnet {
+		// IPv6 addresses don't have a To4() representation
+		if subnet.IP != nil && subnet.IP.To4() == nil {
+			subnets = append(subnets, subnet.IP.String()+"/"+subnet.Mask)
+		}
+	}
+	return subnets
 }
 
 // Metadata returns the resource type name.
@@ -140,6 +169,11 @@ func (r *ServerResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 						MarkdownDescription: "Primary IPv6 address (computed, always enabled)",
 						Computed:            true,
 					},
+					"ipv6_subnets": schema.ListAttribute{
+						MarkdownDescription: "All IPv6 subnets of the server (computed). The first entry equals `ipv6`.",
+						ElementType:         types.StringType,
+						Computed:            true,
+					},
 				},
 			},
 		},
@@ -174,6 +208,20 @@ func (r *ServerResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	// Adopt a server that already exists instead of ordering it again
+	if !plan.ServerID.IsNull() && !plan.ServerID.IsUnknown() {
+		adopted, diags := r.adoptExistingServer(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if adopted {
+			plan.nullUnknownComputed()
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+	}
+
 	serverType := plan.ServerType.ValueString()
 
 	// Validate server_type and server_id combination
@@ -205,6 +253,160 @@ func (r *ServerResource) Create(ctx context.Context, req resource.CreateRequest,
 		}
 	}
 
+	resp.Diagnostics.Append(r.provision(ctx, &plan)...)
+
+	// Save data into Terraform state. The transaction ID is stored even when
+	// waiting failed so that the next apply resumes the order.
+	if !plan.TransactionID.IsUnknown() {
+		plan.nullUnknownComputed()
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+// provision places the order described by plan, or resumes the order already
+// recorded in plan.TransactionID if it has not completed yet, and waits for
+// the server to be provisioned when wait_for_complete is set.
+func (r *ServerResource) provision(ctx context.Context, plan *ServerResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !plan.orderPending() {
+		transaction, placeDiags := r.placeOrder(ctx, plan)
+		diags.Append(placeDiags...)
+		if diags.HasError() {
+			return diags
+		}
+
+		// Map response to resource model
+		plan.TransactionID = types.StringValue(transaction.ID)
+		plan.Status = types.StringValue(transaction.Status)
+
+		if transaction.ServerNumber != nil {
+			plan.ServerID = types.Int64Value(int64(*transaction.ServerNumber))
+		}
+	}
+
+	// Wait for completion if requested
+	if plan.WaitForComplete.ValueBool() && plan.orderPending() {
+		txID := plan.TransactionID.ValueString()
+		finalTx, err := r.client.Ordering.WaitForMarketTransactionCompletion(ctx, txID, r.transactionPollInterval())
+		if err != nil {
+			if finalTx != nil && finalTx.Status == "cancelled" {
+				plan.Status = types.StringValue(finalTx.Status)
+				diags.AddError(
+					"Server order cancelled",
+					fmt.Sprintf("Order %s was cancelled by Hetzner: %s", txID, err.Error()),
+				)
+				return diags
+			}
+
+			// The order exists, so keep it in state instead of failing the
+			// apply. A failed apply would taint the resource and the next
+			// apply would place a second order.
+			diags.AddWarning(
+				"Server order not complete yet",
+				fmt.Sprintf("Order %s was placed but did not complete: %s\n\nThe transaction ID is stored in state. Run apply again to resume waiting; no new order will be placed.", txID, err.Error()),
+			)
+			return diags
+		}
+
+		// Update with final state
+		plan.Status = types.StringValue(finalTx.Status)
+		if finalTx.ServerNumber != nil {
+			plan.ServerID = types.Int64Value(int64(*finalTx.ServerNumber))
+		}
+	}
+
+	if plan.orderPending() {
+		return diags
+	}
+
+	diags.Append(r.populateServer(ctx, plan)...)
+	return diags
+}
+
+// adoptExistingServer checks whether the server identified by plan.ServerID
+// already exists in the account, e.g. because it was imported and the resource
+// was tainted afterwards. An existing server is adopted like an import instead
+// of placing a new order. It reports whether the server was adopted.
+func (r *ServerResource) adoptExistingServer(ctx context.Context, plan *ServerResourceModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	serverID := hrobot.ServerID(plan.ServerID.ValueInt64())
+	server, err := r.client.Server.Get(ctx, serverID)
+	if err != nil {
+		if hrobot.IsNotFoundError(err) {
+			return false, diags
+		}
+		diags.AddError(
+			"Error checking for existing server",
+			fmt.Sprintf("Could not check whether server %d already exists: %s", plan.ServerID.ValueInt64(), err.Error()),
+		)
+		return false, diags
+	}
+
+	plan.ServerID = types.Int64Value(int64(server.ServerNumber))
+	plan.TransactionID = types.StringValue(fmt.Sprintf("server-%d", server.ServerNumber))
+	plan.Status = types.StringValue(string(server.Status))
+
+	diags.AddWarning(
+		"Adopted existing server",
+		fmt.Sprintf("Server %d already exists in your account, so no new order was placed. It is now managed as if it had been imported.", server.ServerNumber),
+	)
+	diags.Append(r.populateServer(ctx, plan)...)
+	return true, diags
+}
+
+// populateServer sets the server name and fills in the public network
+// details once the server has been provisioned.
+func (r *ServerResource) populateServer(ctx context.Context, plan *ServerResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// Set server name and fetch server details if server is provisioned
+	if !plan.ServerID.IsNull() && !plan.ServerID.IsUnknown() {
+		serverID := hrobot.ServerID(plan.ServerID.ValueInt64())
+
+		// Set the server name (required field)
+		server, err := r.client.Server.SetName(ctx, serverID, plan.ServerName.ValueString())
+		if err != nil {
+			diags.AddWarning(
+				"Failed to set server name",
+				fmt.Sprintf("Server was provisioned but failed to set name: %s", err.Error()),
+			)
+		} else if server != nil {
+			// Update server name from API response
+			plan.ServerName = types.StringValue(server.ServerName)
+		}
+
+		// Fetch server details to populate public_net IPs
+		server, err = r.client.Server.Get(ctx, serverID)
+		if err == nil && server != nil {
+			// Initialize public_net if not already set
+			if plan.PublicNet == nil {
+				plan.PublicNet = &PublicNetModel{
+					IPv4Enabled: types.BoolValue(true),
+				}
+			}
+
+			// Set IPv4 if enabled and available
+			if plan.PublicNet.IPv4Enabled.ValueBool() && server.ServerIP != nil {
+				plan.PublicNet.IPv4 = types.StringValue(server.ServerIP.String())
+			} else {
+				plan.PublicNet.IPv4 = types.StringNull()
+			}
+
+			// IPv6 is always enabled - fetch from subnets
+			plan.PublicNet.setIPv6Subnets(server)
+		}
+	}
+
+	return diags
+}
+
+// placeOrder places the auction or product order described by plan.
+func (r *ServerResource) placeOrder(ctx context.Context, plan *ServerResourceModel) (*hrobot.MarketTransaction, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	serverType := plan.ServerType.ValueString()
+
 	// Authorization method
 	auth := hrobot.AuthorizationMethod{}
 	if len(plan.AuthorizedKeys) > 0 {
@@ -216,11 +418,11 @@ func (r *ServerResource) Create(ctx context.Context, req resource.CreateRequest,
 	} else if !plan.Password.IsNull() {
 		auth.Password = plan.Password.ValueString()
 	} else {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Missing authorization method",
 			"Either authorized_keys or password must be provided",
 		)
-		return
+		return nil, diags
 	}
 
 	// Addons
@@ -229,9 +431,6 @@ func (r *ServerResource) Create(ctx context.Context, req resource.CreateRequest,
 		addons = []string{"primary_ipv4"}
 	}
 
-	var transaction *hrobot.MarketTransaction
-	var err error
-
 	// Build and place order based on server type
 	if serverType == "auction" {
 		// Auction server order
@@ -248,133 +447,125 @@ func (r *ServerResource) Create(ctx context.Context, req resource.CreateRequest,
 			order.Comment = plan.Comment.ValueString()
 		}
 
-		transaction, err = r.client.Ordering.PlaceMarketOrder(ctx, order)
+		transaction, err := r.client.Ordering.PlaceMarketOrder(ctx, order)
 		if err != nil {
 			// Check if server already exists when we get INVALID_INPUT error
 			errMsg := err.Error()
-			if serverType == "auction" && (strings.Contains(errMsg, "INVALID_INPUT") || strings.Contains(errMsg, "invalid input")) {
+			if strings.Contains(errMsg, "INVALID_INPUT") || strings.Contains(errMsg, "invalid input") {
 				// Try to fetch the server to see if it already exists
 				serverID := hrobot.ServerID(plan.ServerID.ValueInt64())
 				if existingServer, getErr := r.client.Server.Get(ctx, serverID); getErr == nil && existingServer != nil {
-					resp.Diagnostics.AddError(
+					diags.AddError(
 						"Server already exists",
 						fmt.Sprintf("Server %d already exists. You can import it into Terraform state by running:\n\n  tofu import hrobot_server.auction %d",
 							plan.ServerID.ValueInt64(),
 							plan.ServerID.ValueInt64(),
 						),
 					)
-					return
+					return nil, diags
 				}
 			}
 
-			resp.Diagnostics.AddError(
+			diags.AddError(
 				"Error placing auction server order",
 				fmt.Sprintf("Could not place auction server order: %s", err.Error()),
 			)
-			return
-		}
-	} else {
-		// Product server order
-		order := hrobot.ProductOrder{
-			ProductID:    serverType,
-			Auth:         auth,
-			Distribution: plan.Image.ValueString(),
-			Language:     "en",
-			Location:     plan.Datacenter.ValueString(),
-			ServerName:   plan.ServerName.ValueString(),
-			Addons:       addons,
-			Test:         false,
-		}
-		if !plan.Comment.IsNull() {
-			order.Comment = plan.Comment.ValueString()
-		}
-
-		transaction, err = r.client.Ordering.PlaceProductOrder(ctx, order)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error placing product server order",
-				fmt.Sprintf("Could not place product server order: %s", err.Error()),
-			)
-			return
+			return nil, diags
 		}
+		return transaction, diags
 	}
 
-	// Map response to resource model
-	plan.TransactionID = types.StringValue(transaction.ID)
-	plan.Status = types.StringValue(transaction.Status)
-
-	if transaction.ServerNumber != nil {
-		plan.ServerID = types.Int64Value(int64(*transaction.ServerNumber))
+	// Product server order
+	order := hrobot.ProductOrder{
+		ProductID:    serverType,
+		Auth:         auth,
+		Distribution: plan.Image.ValueString(),
+		Language:     "en",
+		Location:     plan.Datacenter.ValueString(),
+		ServerName:   plan.ServerName.ValueString(),
+		Addons:       addons,
+		Test:         false,
+	}
+	if !plan.Comment.IsNull() {
+		order.Comment = plan.Comment.ValueString()
 	}
 
-	// Wait for completion if requested
-	if plan.WaitForComplete.ValueBool() && transaction.Status != "ready" && transaction.Status != "cancelled" {
-		finalTx, err := r.client.Ordering.WaitForMarketTransactionCompletion(ctx, transaction.ID, 30*time.Second)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error waiting for order completion",
-				fmt.Sprintf("Order was placed but failed to complete: %s", err.Error()),
-			)
-			// Still save the state with what we have
-			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
-			return
-		}
+	transaction, err := r.client.Ordering.PlaceProductOrder(ctx, order)
+	if err != nil {
+		diags.AddError(
+			"Error placing product server order",
+			fmt.Sprintf("Could not place product server order: %s", err.Error()),
+		)
+		return nil, diags
+	}
+	return transaction, diags
+}
 
-		// Update with final state
-		plan.Status = types.StringValue(finalTx.Status)
-		if finalTx.ServerNumber != nil {
-			plan.ServerID = types.Int64Value(int64(*finalTx.ServerNumber))
-		}
+// transactionPollInterval returns how often an order transaction is polled.
+func (r *ServerResource) transactionPollInterval() time.Duration {
+	if r.pollInterval > 0 {
+		return r.pollInterval
 	}
+	return 30 * time.Second
+}
 
-	// Set server name and fetch server details if server is provisioned
-	if !plan.ServerID.IsNull() {
-		serverID := hrobot.ServerID(plan.ServerID.ValueInt64())
+// orderPending reports whether the model records an order transaction that
+// was placed but has not completed yet. Servers imported without a
+// transaction ("server-XXXXX") are never pending.
+func (m *ServerResourceModel) orderPending() bool {
+	txID := m.TransactionID.ValueString()
+	if m.TransactionID.IsUnknown() || txID == "" || strings.HasPrefix(txID, "server-") {
+		return false
+	}
+	status := m.Status.ValueString()
+	return status != "ready" && status != "cancelled"
+}
 
-		// Set the server name (required field)
-		server, err := r.client.Server.SetName(ctx, serverID, plan.ServerName.ValueString())
-		if err != nil {
-			resp.Diagnostics.AddWarning(
-				"Failed to set server name",
-				fmt.Sprintf("Server was provisioned but failed to set name: %s", err.Error()),
-			)
-		} else if server != nil {
-			// Update server name from API response
-			plan.ServerName = types.StringValue(server.ServerName)
+// nullUnknownComputed replaces computed values that are still unknown (e.g.
+// because the order has not completed) with null, as Terraform does not
+// accept unknown values after apply.
+func (m *ServerResourceModel) nullUnknownComputed() {
+	if m.ServerID.IsUnknown() {
+		m.ServerID = types.Int64Null()
+	}
+	if m.Status.IsUnknown() {
+		m.Status = types.StringNull()
+	}
+	if m.PublicNet != nil {
+		if m.PublicNet.IPv4.IsUnknown() {
+			m.PublicNet.IPv4 = types.StringNull()
 		}
+		if m.PublicNet.IPv6.IsUnknown() {
+			m.PublicNet.IPv6 = types.StringNull()
+		}
+		if m.PublicNet.IPv6Subnets.IsUnknown() {
+			m.PublicNet.IPv6Subnets = types.ListNull(types.StringType)
+		}
+	}
+}
 
-		// Fetch server details to populate public_net IPs
-		server, err = r.client.Server.Get(ctx, serverID)
-		if err == nil && server != nil {
-			// Initialize public_net if not already set
-			if plan.PublicNet == nil {
-				plan.PublicNet = &PublicNetModel{
-					IPv4Enabled: types.BoolValue(true),
-				}
-			}
+// ModifyPlan plans an update for servers whose order has not completed yet,
+// so that apply resumes waiting for the stored transaction.
+func (r *ServerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
 
-			// Set IPv4 if enabled and available
-			if plan.PublicNet.IPv4Enabled.ValueBool() && server.ServerIP != nil {
-				plan.PublicNet.IPv4 = types.StringValue(server.ServerIP.String())
-			} else {
-				plan.PublicNet.IPv4 = types.StringNull()
-			}
+	var state, plan ServerResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-			// IPv6 is always enabled - fetch from subnets
-			if len(server.Subnet) > 0 {
-				for _, subnet := range server.Subnet {
-					// Look for IPv6 subnet
-					if subnet.IP.To4() == nil {
-						plan.PublicNet.IPv6 = types.StringValue(subnet.IP.String() + "/" + subnet.Mask)
-						break
-					}
-				}
-			}
-		}
+	if !state.orderPending() || !plan.WaitForComplete.ValueBool() {
+		return
 	}
 
-	// Save data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("status"), types.StringUnknown())...)
+	if state.ServerID.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("server_id"), types.Int64Unknown())...)
+	}
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -413,6 +604,13 @@ func (r *ServerResource) Read(ctx context.Context, req resource.ReadRequest, res
 		if transaction.ServerNumber != nil {
 			state.ServerID = types.Int64Value(int64(*transaction.ServerNumber))
 		}
+
+		// The server is not ours until the order completes. An auction
+		// server_id would not be found yet, so don't look it up.
+		if state.orderPending() {
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			return
+		}
 	}
 
 	// For both imported and real orders, fetch server details if we have a server number
@@ -441,15 +639,7 @@ func (r *ServerResource) Read(ctx context.Context, req resource.ReadRequest, res
 				}
 
 				// IPv6 is always enabled - fetch from subnets
-				if len(server.Subnet) > 0 {
-					for _, subnet := range server.Subnet {
-						// Look for IPv6 subnet
-						if subnet.IP.To4() == nil {
-							state.PublicNet.IPv6 = types.StringValue(subnet.IP.String() + "/" + subnet.Mask)
-							break
-						}
-					}
-				}
+				state.PublicNet.setIPv6Subnets(server)
 			}
 		}
 	}
@@ -469,8 +659,9 @@ func (r *ServerResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	// Only server_name can be updated
-	if !plan.ServerName.Equal(state.ServerName) && !plan.ServerName.IsNull() && !plan.ServerName.IsUnknown() {
+	// Only server_name can be updated. A pending order sets the name once it
+	// completes, see below.
+	if !state.orderPending() && !plan.ServerName.Equal(state.ServerName) && !plan.ServerName.IsNull() && !plan.ServerName.IsUnknown() {
 		// Use state.ServerID since it contains the actual server number
 		// plan.ServerID might be unknown if other fields are being added
 		if state.ServerID.IsNull() {
@@ -510,6 +701,16 @@ func (r *ServerResource) Update(ctx context.Context, req resource.UpdateRequest,
 	plan.TransactionID = state.TransactionID
 	plan.Status = state.Status
 
+	// Resume waiting for an order that did not complete during create
+	if plan.orderPending() && plan.WaitForComplete.ValueBool() {
+		resp.Diagnostics.Append(r.provision(ctx, &plan)...)
+		if resp.Diagnostics.HasError() || plan.orderPending() {
+			plan.nullUnknownComputed()
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+	}
+
 	// Note: server_id cannot be changed as it identifies the server itself
 	// The plan.ServerID should equal state.ServerID by the time we get here
 
@@ -528,8 +729,8 @@ func (r *ServerResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Refresh server details to get latest values
-	if !state.ServerID.IsNull() {
-		serverID := hrobot.ServerID(state.ServerID.ValueInt64())
+	if !plan.ServerID.IsNull() {
+		serverID := hrobot.ServerID(plan.ServerID.ValueInt64())
 		server, err := r.client.Server.Get(ctx, serverID)
 		if err == nil && server != nil {
 			plan.ServerName = types.StringValue(server.ServerName)
@@ -544,20 +745,13 @@ func (r *ServerResource) Update(ctx context.Context, req resource.UpdateRequest,
 				}
 
 				// IPv6 is always enabled - fetch from subnets
-				if len(server.Subnet) > 0 {
-					for _, subnet := range server.Subnet {
-						// Look for IPv6 subnet
-						if subnet.IP.To4() == nil {
-							plan.PublicNet.IPv6 = types.StringValue(subnet.IP.String() + "/" + subnet.Mask)
-							break
-						}
-					}
-				}
+				plan.PublicNet.setIPv6Subnets(server)
 			}
 		}
 	}
 
 	// Save updated state
+	plan.nullUnknownComputed()
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -637,16 +831,8 @@ func (r *ServerResource) ImportState(ctx context.Context, req resource.ImportSta
 				state.PublicNet.IPv4 = types.StringNull()
 			}
 
-			// IPv6 - fetch from subnets
-			if len(server.Subnet) > 0 {
-				for _, subnet := range server.Subnet {
-					// Look for IPv6 subnet (IPv6 addresses don't have To4() representation)
-					if subnet.IP.To4() == nil {
-						state.PublicNet.IPv6 = types.StringValue(subnet.IP.String() + "/" + subnet.Mask)
-						break
-					}
-				}
-			}
+			// IPv6 is always enabled - fetch from subnets
+			state.PublicNet.setIPv6Subnets(server)
 
 			// Save to state
 			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
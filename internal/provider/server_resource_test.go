@@ -0,0 +1,249 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestPublicNetModel_SetIPv6Subnets(t *testing.T) {
+	server := &hrobot.Server{
+		ServerIP: net.ParseIP("123.123.123.123"),
+		Subnet: []hrobot.Subnet{
+			{IP: net.ParseIP("2a01:4f8:111:4221::"), Mask: "64"},
+			{IP: net.ParseIP("123.123.124.0"), Mask: "29"},
+			{IP: net.ParseIP("2a01:4f8:222:1234::"), Mask: "64"},
+		},
+	}
+
+	model := &PublicNetModel{IPv4Enabled: types.BoolValue(true)}
+	model.setIPv6Subnets(server)
+
+	if model.IPv6.ValueString() != "2a01:4f8:111:4221::/64" {
+		t.Errorf("expected primary ipv6 '2a01:4f8:111:4221::/64', got '%s'", model.IPv6.ValueString())
+	}
+
+	var subnets []string
+	if diags := model.IPv6Subnets.ElementsAs(context.Background(), &subnets, false); diags.HasError() {
+		t.Fatalf("failed to read ipv6_subnets: %v", diags)
+	}
+
+	expected := []string{"2a01:4f8:111:4221::/64", "2a01:4f8:222:1234::/64"}
+	if len(subnets) != len(expected) {
+		t.Fatalf("expected %d ipv6 subnets, got %d: %v", len(expected), len(subnets), subnets)
+	}
+	for i := range expected {
+		if subnets[i] != expected[i] {
+			t.Errorf("expected ipv6_subnets[%d] = '%s', got '%s'", i, expected[i], subnets[i])
+		}
+	}
+}
+
+func TestPublicNetModel_SetIPv6Subnets_NoIPv6(t *testing.T) {
+	server := &hrobot.Server{
+		Subnet: []hrobot.Subnet{
+			{IP: net.ParseIP("123.123.124.0"), Mask: "29"},
+		},
+	}
+
+	model := &PublicNetModel{IPv6: types.StringNull()}
+	model.setIPv6Subnets(server)
+
+	if !model.IPv6.IsNull() {
+		t.Errorf("expected ipv6 to stay null, got '%s'", model.IPv6.ValueString())
+	}
+	if model.IPv6Subnets.IsNull() || len(model.IPv6Subnets.Elements()) != 0 {
+		t.Errorf("expected empty ipv6_subnets list, got %v", model.IPv6Subnets)
+	}
+}
+
+func TestServerResource_ProvisionResumesPendingOrder(t *testing.T) {
+	var mu sync.Mutex
+	orders := 0
+	ready := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		transaction := map[string]interface{}{
+			"id":     "B20150121-344958-251479",
+			"date":   "2015-01-21T12:30:43+01:00",
+			"status": "in process",
+		}
+		if ready {
+			transaction["status"] = "ready"
+			transaction["server_number"] = 321
+		}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/order/server_market/transaction":
+			orders++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"transaction": transaction})
+		case r.Method == "GET" && r.URL.Path == "/order/server_market/transaction/B20150121-344958-251479":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"transaction": transaction})
+		case r.URL.Path == "/server/321":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"server": map[string]interface{}{
+					"server_ip":     "123.123.123.123",
+					"server_number": 321,
+					"server_name":   "web-1",
+					"status":        "ready",
+				},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := &ServerResource{
+		client:       hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL)),
+		pollInterval: 10 * time.Millisecond,
+	}
+
+	plan := ServerResourceModel{
+		TransactionID:   types.StringUnknown(),
+		ServerType:      types.StringValue("auction"),
+		AuthorizedKeys:  []types.String{types.StringValue("15:28:b0:03:95:f0:77:b3:10:56:15:6b:77:22:a5:bb")},
+		Password:        types.StringNull(),
+		Image:           types.StringValue("Rescue system"),
+		Comment:         types.StringNull(),
+		Status:          types.StringUnknown(),
+		ServerID:        types.Int64Value(321),
+		ServerName:      types.StringValue("web-1"),
+		WaitForComplete: types.BoolValue(true),
+	}
+
+	// First apply: the order is placed but waiting times out.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	diags := r.provision(ctx, &plan)
+	cancel()
+
+	if diags.HasError() {
+		t.Fatalf("expected only warnings after timeout, got %v", diags)
+	}
+	if diags.WarningsCount() != 1 {
+		t.Errorf("expected one warning after timeout, got %v", diags)
+	}
+	if plan.TransactionID.ValueString() != "B20150121-344958-251479" {
+		t.Fatalf("expected transaction ID to be recorded, got '%s'", plan.TransactionID.ValueString())
+	}
+	if !plan.orderPending() {
+		t.Fatalf("expected order to be pending, got status '%s'", plan.Status.ValueString())
+	}
+
+	// Second apply: the transaction completes and no new order is placed.
+	mu.Lock()
+	ready = true
+	mu.Unlock()
+
+	diags = r.provision(context.Background(), &plan)
+	if diags.HasError() {
+		t.Fatalf("expected resumed order to succeed, got %v", diags)
+	}
+
+	if orders != 1 {
+		t.Errorf("expected exactly one order to be placed, got %d", orders)
+	}
+	if plan.Status.ValueString() != "ready" {
+		t.Errorf("expected status 'ready', got '%s'", plan.Status.ValueString())
+	}
+	if plan.ServerID.ValueInt64() != 321 {
+		t.Errorf("expected server ID 321, got %d", plan.ServerID.ValueInt64())
+	}
+	if plan.PublicNet == nil || plan.PublicNet.IPv4.ValueString() != "123.123.123.123" {
+		t.Errorf("expected public_net.ipv4 '123.123.123.123', got %v", plan.PublicNet)
+	}
+}
+
+func TestServerResource_AdoptExistingServer(t *testing.T) {
+	var renamed string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/server/321":
+			if r.Method == "POST" {
+				if err := r.ParseForm(); err != nil {
+					t.Fatalf("failed to parse form: %v", err)
+				}
+				renamed = r.PostForm.Get("server_name")
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"server": map[string]interface{}{
+					"server_ip":     "123.123.123.123",
+					"server_number": 321,
+					"server_name":   "web-1",
+					"product":       "Server Auction",
+					"status":        "ready",
+				},
+			})
+		case "/server/999":
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{
+					"status":  404,
+					"code":    "SERVER_NOT_FOUND",
+					"message": "Server not found",
+				},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := &ServerResource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	// Plan for an imported server that is being re-created (e.g. after taint)
+	plan := ServerResourceModel{
+		TransactionID:   types.StringUnknown(),
+		ServerType:      types.StringValue("auction"),
+		Image:           types.StringValue("Rescue system"),
+		Status:          types.StringUnknown(),
+		ServerID:        types.Int64Value(321),
+		ServerName:      types.StringValue("web-1"),
+		WaitForComplete: types.BoolValue(true),
+	}
+
+	adopted, diags := r.adoptExistingServer(context.Background(), &plan)
+	if diags.HasError() {
+		t.Fatalf("adoptExistingServer returned errors: %v", diags)
+	}
+	if !adopted {
+		t.Fatal("expected existing server to be adopted")
+	}
+	if plan.TransactionID.ValueString() != "server-321" {
+		t.Errorf("expected transaction ID 'server-321', got '%s'", plan.TransactionID.ValueString())
+	}
+	if plan.Status.ValueString() != "ready" {
+		t.Errorf("expected status 'ready', got '%s'", plan.Status.ValueString())
+	}
+	if renamed != "web-1" {
+		t.Errorf("expected server name to be set to 'web-1', got '%s'", renamed)
+	}
+	if plan.PublicNet == nil || plan.PublicNet.IPv4.ValueString() != "123.123.123.123" {
+		t.Errorf("expected public_net.ipv4 '123.123.123.123', got %v", plan.PublicNet)
+	}
+
+	missing := ServerResourceModel{ServerID: types.Int64Value(999)}
+	adopted, diags = r.adoptExistingServer(context.Background(), &missing)
+	if diags.HasError() {
+		t.Fatalf("expected no errors for unknown server, got %v", diags)
+	}
+	if adopted {
+		t.Error("expected unknown server not to be adopted")
+	}
+}
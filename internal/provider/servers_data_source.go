@@ -0,0 +1,257 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// Ensure the implementation satisfies the datasource.DataSource interface.
+var _ datasource.DataSource = &ServersDataSource{}
+
+// NewServersDataSource is a helper function to simplify the provider implementation.
+func NewServersDataSource() datasource.DataSource {
+	return &ServersDataSource{}
+}
+
+// ServersDataSource is the data source implementation.
+type ServersDataSource struct {
+	client *hrobot.Client
+}
+
+// ServersDataSourceModel describes the data source data model.
+type ServersDataSourceModel struct {
+	ID         types.String       `tfsdk:"id"`
+	Datacenter types.String       `tfsdk:"datacenter"`
+	Status     types.String       `tfsdk:"status"`
+	NameRegex  types.String       `tfsdk:"name_regex"`
+	Servers    []ServersItemModel `tfsdk:"servers"`
+}
+
+// ServersItemModel describes a single server of the list.
+type ServersItemModel struct {
+	ServerID   types.Int64  `tfsdk:"server_id"`
+	ServerIP   types.String `tfsdk:"server_ip"`
+	ServerName types.String `tfsdk:"server_name"`
+	Product    types.String `tfsdk:"product"`
+	Datacenter types.String `tfsdk:"datacenter"`
+	Traffic    types.String `tfsdk:"traffic"`
+	Status     types.String `tfsdk:"status"`
+	Cancelled  types.Bool   `tfsdk:"cancelled"`
+	PaidUntil  types.String `tfsdk:"paid_until"`
+}
+
+// Metadata returns the data source type name.
+func (d *ServersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_servers"
+}
+
+// Schema defines the schema for the data source.
+func (d *ServersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the servers of the account, optionally filtered. Combine it with `for_each` to manage resources such as firewalls across all servers.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Placeholder identifier (always set to 'servers')",
+				Computed:            true,
+			},
+			"datacenter": schema.StringAttribute{
+				MarkdownDescription: "Only list servers in this datacenter (e.g. `FSN1-DC14`) or location (e.g. `FSN1`), ignoring case",
+				Optional:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Only list servers with this status (`ready`, `in process` or `cancelled`)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(hrobot.ServerStatusReady),
+						string(hrobot.ServerStatusInProcess),
+						string(hrobot.ServerStatusCancelled),
+					),
+				},
+			},
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "Only list servers whose name matches this regular expression (RE2 syntax), e.g. `^web-`",
+				Optional:            true,
+				Validators: []validator.String{
+					regexValidator{},
+				},
+			},
+			"servers": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching servers, in the order of the Robot API",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"server_id": schema.Int64Attribute{
+							MarkdownDescription: "Server ID",
+							Computed:            true,
+						},
+						"server_ip": schema.StringAttribute{
+							MarkdownDescription: "Primary server IP address",
+							Computed:            true,
+						},
+						"server_name": schema.StringAttribute{
+							MarkdownDescription: "Server name",
+							Computed:            true,
+						},
+						"product": schema.StringAttribute{
+							MarkdownDescription: "Server product model",
+							Computed:            true,
+						},
+						"datacenter": schema.StringAttribute{
+							MarkdownDescription: "Datacenter location",
+							Computed:            true,
+						},
+						"traffic": schema.StringAttribute{
+							MarkdownDescription: "Traffic limit",
+							Computed:            true,
+						},
+						"status": schema.StringAttribute{
+							MarkdownDescription: "Server status (ready, in process, etc.)",
+							Computed:            true,
+						},
+						"cancelled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the server is cancelled",
+							Computed:            true,
+						},
+						"paid_until": schema.StringAttribute{
+							MarkdownDescription: "Paid until date",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *ServersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*hrobot.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *hrobot.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+// listOptions maps the datacenter and status filters to the SDK options.
+func (m *ServersDataSourceModel) listOptions() hrobot.ServerListOptions {
+	return hrobot.ServerListOptions{
+		Status:     hrobot.ServerStatus(m.Status.ValueString()),
+		Datacenter: m.Datacenter.ValueString(),
+	}
+}
+
+// filterServersByName returns the servers whose name matches pattern. An
+// empty pattern keeps all servers.
+func filterServersByName(servers []hrobot.Server, pattern string) ([]hrobot.Server, error) {
+	if pattern == "" {
+		return servers, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]hrobot.Server, 0, len(servers))
+	for _, server := range servers {
+		if re.MatchString(server.ServerName) {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered, nil
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *ServersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config ServersDataSourceModel
+
+	// Read configuration
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get servers from API
+	servers, err := d.client.Server.ListFiltered(ctx, config.listOptions())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading servers",
+			fmt.Sprintf("Could not read servers: %s", err.Error()),
+		)
+		return
+	}
+
+	servers, err = filterServersByName(servers, config.NameRegex.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid name_regex", err.Error())
+		return
+	}
+
+	// Map API response to data source model
+	config.ID = types.StringValue("servers")
+	config.Servers = make([]ServersItemModel, len(servers))
+	for i, server := range servers {
+		serverIP := types.StringNull()
+		if server.ServerIP != nil {
+			serverIP = types.StringValue(server.ServerIP.String())
+		}
+
+		config.Servers[i] = ServersItemModel{
+			ServerID:   types.Int64Value(int64(server.ServerNumber)),
+			ServerIP:   serverIP,
+			ServerName: types.StringValue(server.ServerName),
+			Product:    types.StringValue(server.Product),
+			Datacenter: types.StringValue(server.DC),
+			Traffic:    types.StringValue(server.Traffic.String()),
+			Status:     types.StringValue(string(server.Status)),
+			Cancelled:  types.BoolValue(server.Cancelled),
+			PaidUntil:  types.StringValue(server.PaidUntil),
+		}
+	}
+
+	// Save state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// regexValidator checks that a string is a valid regular expression.
+type regexValidator struct{}
+
+// Description returns a plain text description of the validator.
+func (v regexValidator) Description(_ context.Context) string {
+	return "value must be a valid regular expression"
+}
+
+// MarkdownDescription returns a markdown description of the validator.
+func (v regexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString checks that the value compiles as a regular expression.
+func (v regexValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := regexp.Compile(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid regular expression", err.Error())
+	}
+}
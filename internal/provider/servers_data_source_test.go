@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+func TestServersDataSource_Read(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/server" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		servers := []struct {
+			name   string
+			dc     string
+			status string
+		}{
+			{"web-1", "FSN1-DC14", "ready"},
+			{"web-2", "HEL1-DC2", "ready"},
+			{"db-1", "FSN1-DC8", "ready"},
+			{"web-3", "FSN1-DC8", "in process"},
+		}
+		response := make([]map[string]interface{}, len(servers))
+		for i, s := range servers {
+			response[i] = map[string]interface{}{
+				"server": map[string]interface{}{
+					"server_ip":     fmt.Sprintf("123.123.123.%d", i+1),
+					"server_number": 100 + i,
+					"server_name":   s.name,
+					"dc":            s.dc,
+					"status":        s.status,
+					"traffic":       "unlimited",
+				},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	d := &ServersDataSource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	optional := func(s string) types.String {
+		if s == "" {
+			return types.StringNull()
+		}
+		return types.StringValue(s)
+	}
+
+	tests := []struct {
+		name       string
+		datacenter string
+		status     string
+		nameRegex  string
+		want       []int64
+	}{
+		{name: "no filters", want: []int64{100, 101, 102, 103}},
+		{name: "location", datacenter: "FSN1", want: []int64{100, 102, 103}},
+		{name: "datacenter", datacenter: "fsn1-dc8", want: []int64{102, 103}},
+		{name: "status", status: "in process", want: []int64{103}},
+		{name: "name regex", nameRegex: "^web-[12]$", want: []int64{100, 101}},
+		{name: "all filters", datacenter: "FSN1", status: "ready", nameRegex: "^web-", want: []int64{100}},
+		{name: "no match", datacenter: "NBG1", want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The config is built through a state, which shares its schema
+			model := ServersDataSourceModel{
+				ID:         types.StringNull(),
+				Datacenter: optional(tt.datacenter),
+				Status:     optional(tt.status),
+				NameRegex:  optional(tt.nameRegex),
+			}
+			state := tfsdk.State{Schema: schemaResp.Schema}
+			if diags := state.Set(ctx, &model); diags.HasError() {
+				t.Fatalf("failed to build config: %v", diags)
+			}
+
+			resp := &datasource.ReadResponse{
+				State: tfsdk.State{
+					Schema: schemaResp.Schema,
+					Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+				},
+			}
+			d.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: state.Raw}}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Read returned errors: %v", resp.Diagnostics)
+			}
+
+			var data ServersDataSourceModel
+			if diags := resp.State.Get(ctx, &data); diags.HasError() {
+				t.Fatalf("failed to read state: %v", diags)
+			}
+			got := make([]int64, len(data.Servers))
+			for i, s := range data.Servers {
+				got[i] = s.ServerID.ValueInt64()
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected servers %v, got %v", tt.want, got)
+			}
+			if data.ID.ValueString() != "servers" {
+				t.Errorf("expected id 'servers', got %v", data.ID)
+			}
+		})
+	}
+}
+
+func TestFilterServersByName_InvalidRegex(t *testing.T) {
+	if _, err := filterServersByName([]hrobot.Server{{ServerName: "web-1"}}, "web-("); err == nil {
+		t.Error("expected error for invalid regular expression, got nil")
+	}
+}
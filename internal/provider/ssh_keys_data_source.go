@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// Ensure the implementation satisfies the datasource.DataSource interface.
+var _ datasource.DataSource = &SSHKeysDataSource{}
+
+// NewSSHKeysDataSource is a helper function to simplify the provider implementation.
+func NewSSHKeysDataSource() datasource.DataSource {
+	return &SSHKeysDataSource{}
+}
+
+// SSHKeysDataSource is the data source implementation.
+type SSHKeysDataSource struct {
+	client *hrobot.Client
+}
+
+// SSHKeysDataSourceModel describes the data source data model.
+type SSHKeysDataSourceModel struct {
+	ID   types.String       `tfsdk:"id"`
+	Keys []SSHKeysItemModel `tfsdk:"keys"`
+}
+
+// SSHKeysItemModel describes a single SSH key of the list.
+type SSHKeysItemModel struct {
+	Name        types.String `tfsdk:"name"`
+	Fingerprint types.String `tfsdk:"fingerprint"`
+	Type        types.String `tfsdk:"type"`
+	Size        types.Int64  `tfsdk:"size"`
+	PublicKey   types.String `tfsdk:"public_key"`
+}
+
+// Metadata returns the data source type name.
+func (d *SSHKeysDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ssh_keys"
+}
+
+// Schema defines the schema for the data source.
+func (d *SSHKeysDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists all SSH keys stored in Hetzner Robot. Use `keys[*].fingerprint` to authorize all of them, e.g. in `authorized_keys` of `hrobot_server`.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Placeholder identifier (always set to 'ssh_keys')",
+				Computed:            true,
+			},
+			"keys": schema.ListNestedAttribute{
+				MarkdownDescription: "SSH keys of the account; empty when the account has none",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name of the SSH key",
+							Computed:            true,
+						},
+						"fingerprint": schema.StringAttribute{
+							MarkdownDescription: "SSH key fingerprint",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "SSH key type (e.g., RSA, ED25519)",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "SSH key size in bits",
+							Computed:            true,
+						},
+						"public_key": schema.StringAttribute{
+							MarkdownDescription: "SSH public key data in OpenSSH format",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *SSHKeysDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*hrobot.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *hrobot.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *SSHKeysDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state SSHKeysDataSourceModel
+
+	// Get SSH keys from API. An account without keys answers NOT_FOUND.
+	keys, err := d.client.Key.List(ctx)
+	if err != nil && !hrobot.IsAPIError(err, hrobot.ErrNotFound) {
+		resp.Diagnostics.AddError(
+			"Error reading SSH keys",
+			fmt.Sprintf("Could not read SSH keys: %s", err.Error()),
+		)
+		return
+	}
+
+	// Map API response to Terraform state
+	state.ID = types.StringValue("ssh_keys")
+	state.Keys = make([]SSHKeysItemModel, len(keys))
+	for i, key := range keys {
+		state.Keys[i] = SSHKeysItemModel{
+			Name:        types.StringValue(key.Name),
+			Fingerprint: types.StringValue(key.Fingerprint),
+			Type:        types.StringValue(key.Type),
+			Size:        types.Int64Value(int64(key.Size)),
+			PublicKey:   types.StringValue(key.Data),
+		}
+	}
+
+	// Save state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot"
+)
+
+// readSSHKeys runs the data source against a server answering GET /key with
+// the given status and body.
+func readSSHKeys(t *testing.T, status int, body string) SSHKeysDataSourceModel {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/key" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	d := &SSHKeysDataSource{client: hrobot.NewClient("test-user", "test-pass", hrobot.WithBaseURL(server.URL))}
+
+	schemaResp := &datasource.SchemaResponse{}
+	d.Schema(ctx, datasource.SchemaRequest{}, schemaResp)
+
+	resp := &datasource.ReadResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	d.Read(ctx, datasource.ReadRequest{}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read returned errors: %v", resp.Diagnostics)
+	}
+
+	var data SSHKeysDataSourceModel
+	if diags := resp.State.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read state: %v", diags)
+	}
+	return data
+}
+
+func TestSSHKeysDataSource_Read(t *testing.T) {
+	data := readSSHKeys(t, http.StatusOK, `[
+		{"key": {"name": "laptop", "fingerprint": "56:29:99:a4:5d:ed:ac:95:c1:f5:88:82:90:5d:dd:10", "type": "ED25519", "size": 256, "data": "ssh-ed25519 AAAAC3Nz", "created_at": "2024-01-15 10:30:00"}},
+		{"key": {"name": "ci", "fingerprint": "15:28:b0:03:95:f0:77:b3:10:56:15:6b:77:22:a5:bb", "type": "RSA", "size": 4096, "data": "ssh-rsa AAAAB3Nz", "created_at": "2024-02-01 08:00:00"}}
+	]`)
+
+	if data.ID.ValueString() != "ssh_keys" {
+		t.Errorf("expected id 'ssh_keys', got %v", data.ID)
+	}
+	if len(data.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(data.Keys))
+	}
+	key := data.Keys[1]
+	if key.Name.ValueString() != "ci" ||
+		key.Fingerprint.ValueString() != "15:28:b0:03:95:f0:77:b3:10:56:15:6b:77:22:a5:bb" ||
+		key.Type.ValueString() != "RSA" ||
+		key.Size.ValueInt64() != 4096 ||
+		key.PublicKey.ValueString() != "ssh-rsa AAAAB3Nz" {
+		t.Errorf("unexpected key mapping: %+v", key)
+	}
+}
+
+func TestSSHKeysDataSource_Read_Empty(t *testing.T) {
+	// Hetzner answers NOT_FOUND for an account without keys
+	data := readSSHKeys(t, http.StatusNotFound, `{"error": {"status": 404, "code": "NOT_FOUND", "message": "Not found"}}`)
+
+	if data.Keys == nil || len(data.Keys) != 0 {
+		t.Errorf("expected an empty key list, got %+v", data.Keys)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hrobot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuctionService_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/order/server_market/product/1234567" {
+			t.Errorf("expected path '/order/server_market/product/1234567', got '%s'", r.URL.Path)
+		}
+		if r.Method != "GET" {
+			t.Errorf("expected GET request, got '%s'", r.Method)
+		}
+
+		_, _ = w.Write([]byte(`{
+			"product": {
+				"id": 1234567,
+				"name": "SB",
+				"cpu": "Intel Core i7-6700",
+				"cpu_benchmark": 10000,
+				"memory_size": 64,
+				"hdd_size": 512,
+				"datacenter": "FSN1-DC8",
+				"price": "39.00",
+				"price_vat": "46.41"
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	product, err := client.Auction.Get(context.Background(), 1234567)
+	if err != nil {
+		t.Fatalf("Auction.Get returned error: %v", err)
+	}
+
+	if product.ID != 1234567 {
+		t.Errorf("expected ID 1234567, got %d", product.ID)
+	}
+	if product.CPU != "Intel Core i7-6700" {
+		t.Errorf("expected CPU 'Intel Core i7-6700', got '%s'", product.CPU)
+	}
+	if product.MemorySize != 64 {
+		t.Errorf("expected memory size 64, got %v", product.MemorySize)
+	}
+	if product.Datacenter == nil || *product.Datacenter != "FSN1-DC8" {
+		t.Errorf("expected datacenter 'FSN1-DC8', got %v", product.Datacenter)
+	}
+	if product.Price.Float64() != 39 {
+		t.Errorf("expected price 39, got %v", product.Price.Float64())
+	}
+}
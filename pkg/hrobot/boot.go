@@ -96,7 +96,7 @@ type CPanelConfig struct {
 func (b *BootService) Get(ctx context.Context, serverID ServerID) (*BootConfig, error) {
 	var config BootConfig
 	path := fmt.Sprintf("/boot/%s", serverID.String())
-	err := b.client.Get(ctx, path, &config)
+	err := b.client.GetWrapped(ctx, path, "boot", &config)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +116,7 @@ func (b *BootService) ActivateRescue(ctx context.Context, serverID ServerID, os
 	}
 
 	var rescue RescueConfig
-	err := b.client.Post(ctx, path, data, &rescue)
+	err := b.client.PostWrapped(ctx, path, data, "rescue", &rescue)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +134,7 @@ func (b *BootService) DeactivateRescue(ctx context.Context, serverID ServerID) e
 func (b *BootService) GetLastRescue(ctx context.Context, serverID ServerID) (*RescueConfig, error) {
 	var rescue RescueConfig
 	path := fmt.Sprintf("/boot/%s/rescue/last", serverID.String())
-	err := b.client.Get(ctx, path, &rescue)
+	err := b.client.GetWrapped(ctx, path, "rescue", &rescue)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +155,7 @@ func (b *BootService) ActivateLinux(ctx context.Context, serverID ServerID, dist
 	}
 
 	var linux LinuxConfig
-	err := b.client.Post(ctx, path, data, &linux)
+	err := b.client.PostWrapped(ctx, path, data, "linux", &linux)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +179,7 @@ func (b *BootService) ActivateVNC(ctx context.Context, serverID ServerID, dist s
 	data.Set("lang", lang)
 
 	var vnc VNCConfig
-	err := b.client.Post(ctx, path, data, &vnc)
+	err := b.client.PostWrapped(ctx, path, data, "vnc", &vnc)
 	if err != nil {
 		return nil, err
 	}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -30,6 +31,12 @@ type Client struct {
 	userAgent  string
 	debug      bool
 
+	transport        http.RoundTripper
+	customHTTPClient bool
+	maxConnsPerHost  int
+	requestHooks     []RequestHook
+	responseHooks    []ResponseHook
+
 	// API Services
 	Server   *ServerService
 	Firewall *FirewallService
@@ -49,6 +56,13 @@ type Client struct {
 // ClientOption configures the Client.
 type ClientOption func(*Client)
 
+// RequestHook is called before every request is sent.
+type RequestHook func(req *http.Request)
+
+// ResponseHook is called after every request with the response, the transport
+// error (if any) and the time the round trip took. resp is nil when err is set.
+type ResponseHook func(resp *http.Response, err error, duration time.Duration)
+
 // WithBaseURL sets a custom base URL.
 func WithBaseURL(url string) ClientOption {
 	return func(c *Client) {
@@ -60,6 +74,44 @@ func WithBaseURL(url string) ClientOption {
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(c *Client) {
 		c.httpClient = httpClient
+		c.customHTTPClient = true
+	}
+}
+
+// WithMaxConnsPerHost limits the number of connections to the API to n and
+// keeps up to n idle connections open for reuse. This helps when many
+// requests run concurrently (e.g. querying all servers at once).
+//
+// By default http.DefaultTransport is used, which does not limit connections
+// per host but keeps only 2 idle connections (http.DefaultMaxIdleConnsPerHost).
+// The option has no effect when WithHTTPClient or WithTransport is used.
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(c *Client) {
+		c.maxConnsPerHost = n
+	}
+}
+
+// WithTransport sets the transport used for requests while keeping the
+// client's other settings (like the timeout). It can be used to wrap
+// http.DefaultTransport with logging or metrics middleware.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithRequestHook adds a hook that is called before every request.
+func WithRequestHook(hook RequestHook) ClientOption {
+	return func(c *Client) {
+		c.requestHooks = append(c.requestHooks, hook)
+	}
+}
+
+// WithResponseHook adds a hook that is called after every request,
+// including requests that failed.
+func WithResponseHook(hook ResponseHook) ClientOption {
+	return func(c *Client) {
+		c.responseHooks = append(c.responseHooks, hook)
 	}
 }
 
@@ -93,6 +145,20 @@ func NewClient(username, password string, opts ...ClientOption) *Client {
 		opt(c)
 	}
 
+	if c.transport == nil && !c.customHTTPClient && c.maxConnsPerHost > 0 {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxConnsPerHost = c.maxConnsPerHost
+		transport.MaxIdleConnsPerHost = c.maxConnsPerHost
+		c.httpClient.Transport = transport
+	}
+
+	if c.transport != nil {
+		// Copy the HTTP client so that a client passed via WithHTTPClient is not modified
+		httpClient := *c.httpClient
+		httpClient.Transport = c.transport
+		c.httpClient = &httpClient
+	}
+
 	// Initialize services
 	c.Server = NewServerService(c)
 	c.Firewall = NewFirewallService(c)
@@ -116,6 +182,39 @@ func New(username, password string, opts ...ClientOption) *Client {
 	return NewClient(username, password, opts...)
 }
 
+// webserviceUsernamePattern matches webservice usernames like "#ws+XXXXXXX".
+var webserviceUsernamePattern = regexp.MustCompile(`(?i)^#ws\+\S+$`)
+
+// ValidateUsername checks a username for obvious mistakes before it is used
+// to authenticate. Robot webservice usernames look like "#ws+XXXXXXX".
+//
+// Only clearly malformed values are rejected: empty usernames, usernames
+// containing whitespace or quotes, and usernames with a broken "#ws+" prefix.
+// Other forms are accepted, so unusual but valid logins are not blocked.
+func ValidateUsername(username string) error {
+	if username == "" {
+		return fmt.Errorf("username is empty (expected #ws+XXXXXXX)")
+	}
+	if strings.ContainsAny(username, `"'`) {
+		return fmt.Errorf("invalid username %s: remove the quotes around it", username)
+	}
+
+	lower := strings.ToLower(username)
+	switch {
+	case strings.HasPrefix(lower, "ws+") || strings.HasPrefix(lower, "ws "):
+		return fmt.Errorf("invalid username %q: missing the leading '#' (expected #ws+XXXXXXX)", username)
+	case strings.HasPrefix(lower, "#ws ") && !strings.ContainsAny(username[4:], " \t\r\n"):
+		return fmt.Errorf("invalid username %q: the '+' was replaced by a space (expected #ws+XXXXXXX)", username)
+	case strings.HasPrefix(lower, "#ws") && !webserviceUsernamePattern.MatchString(strings.TrimSpace(username)):
+		return fmt.Errorf("invalid username %q: expected #ws+XXXXXXX", username)
+	}
+
+	if strings.ContainsAny(username, " \t\r\n") {
+		return fmt.Errorf("invalid username %q: usernames cannot contain whitespace", username)
+	}
+	return nil
+}
+
 // Response wrapper types to handle Hetzner's response structure.
 type responseWrapper struct {
 	Data json.RawMessage `json:"data,omitempty"`
@@ -140,24 +239,73 @@ type responseWrapper struct {
 }
 
 // unwrapArrayResponse handles arrays where each item is wrapped in an object
-// e.g. [{"server": {...}}, {"server": {...}}].
+// e.g. [{"server": {...}}, {"server": {...}}]. Items without the wrapper key
+// (flat arrays like [{...}, {...}]) are returned as-is.
 func unwrapArrayResponse(data []byte, wrapperKey string) (json.RawMessage, error) {
-	var wrappers []map[string]json.RawMessage
-	if err := json.Unmarshal(data, &wrappers); err != nil {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
 		return nil, err
 	}
 
-	result := make([]json.RawMessage, 0, len(wrappers))
-	for _, wrapper := range wrappers {
-		if item, ok := wrapper[wrapperKey]; ok {
-			result = append(result, item)
+	result := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		if inner, ok := unwrapObject(item, wrapperKey); ok {
+			result = append(result, inner)
+			continue
 		}
+		result = append(result, item)
 	}
 
 	return json.Marshal(result)
 }
 
+// unwrapObject returns the object wrapped in wrapperKey, e.g. {"ip": {...}}.
+// A resource that merely has a field named like the wrapper key, e.g.
+// {"ip": "1.2.3.4", ...}, is not a wrapper.
+func unwrapObject(data json.RawMessage, wrapperKey string) (json.RawMessage, bool) {
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, false
+	}
+
+	inner, ok := wrapper[wrapperKey]
+	if !ok {
+		return nil, false
+	}
+	inner = bytes.TrimSpace(inner)
+	if len(inner) == 0 || (inner[0] != '{' && inner[0] != '[') {
+		return nil, false
+	}
+	return inner, true
+}
+
+// unwrapKey extracts the data wrapped in wrapperKey, e.g. {"server": {...}}.
+// Arrays are unwrapped item by item as in unwrapArrayResponse. A response
+// without the wrapper key is returned as-is, and so is every response when
+// wrapperKey is empty.
+func unwrapKey(data []byte, wrapperKey string) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if wrapperKey == "" || len(trimmed) == 0 {
+		return data, nil
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return unwrapArrayResponse(trimmed, wrapperKey)
+	case '{':
+		if item, ok := unwrapObject(trimmed, wrapperKey); ok {
+			return item, nil
+		}
+	}
+
+	return data, nil
+}
+
 // unwrapResponse extracts the actual data from Hetzner's wrapped response.
+//
+// Deprecated: unwrapResponse guesses the wrapper key, which goes wrong for
+// resources that contain keys named like a wrapper (e.g. "ip"). Use unwrapKey
+// with the wrapper key the endpoint is documented to return.
 func unwrapResponse(data []byte) (json.RawMessage, error) {
 	// First, check if the response is an array
 	if len(data) > 0 && data[0] == '[' {
@@ -308,7 +456,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 		fmt.Printf("===================\n\n")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, NewNetworkError("request failed", err)
 	}
@@ -316,8 +464,57 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 	return resp, nil
 }
 
-// handleResponse processes the HTTP response and handles errors.
+// send executes a request and runs the configured request and response hooks.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	for _, hook := range c.requestHooks {
+		hook(req)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	duration := time.Since(start)
+
+	for _, hook := range c.responseHooks {
+		hook(resp, err, duration)
+	}
+
+	return resp, err
+}
+
+// handleResponse processes the HTTP response and handles errors, guessing the
+// wrapper key of the response.
 func (c *Client) handleResponse(resp *http.Response, v interface{}) error {
+	return c.decodeResponse(resp, unwrapResponse, v)
+}
+
+// newResponseError converts an error response into an API error. Responses
+// without an API error body get ErrUnknown, except for HTTP 403, which Hetzner
+// returns (e.g. as an HTML page) when the caller's IP is not allowed to access
+// the webservice.
+func newResponseError(statusCode int, body []byte) *Error {
+	var apiErr APIErrorResponse
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error.Code != "" {
+		return NewAPIError(apiErr.Error.Code, apiErr.Error.Message)
+	}
+
+	code := ErrUnknown
+	if statusCode == http.StatusForbidden {
+		code = ErrForbidden
+	}
+	return NewAPIError(code, fmt.Sprintf("HTTP %d: %s", statusCode, string(body)))
+}
+
+// handleWrappedResponse processes the HTTP response and handles errors,
+// unwrapping the response data from wrapperKey.
+func (c *Client) handleWrappedResponse(resp *http.Response, wrapperKey string, v interface{}) error {
+	return c.decodeResponse(resp, func(data []byte) (json.RawMessage, error) {
+		return unwrapKey(data, wrapperKey)
+	}, v)
+}
+
+// decodeResponse reads the HTTP response, converts API errors and decodes the
+// data returned by unwrap into v.
+func (c *Client) decodeResponse(resp *http.Response, unwrap func([]byte) (json.RawMessage, error), v interface{}) error {
 	defer func() { _ = resp.Body.Close() }()
 
 	body, err := io.ReadAll(resp.Body)
@@ -338,11 +535,7 @@ func (c *Client) handleResponse(resp *http.Response, v interface{}) error {
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
-		var apiErr APIErrorResponse
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return NewAPIError(ErrUnknown, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body)))
-		}
-		return NewAPIError(apiErr.Error.Code, apiErr.Error.Message)
+		return newResponseError(resp.StatusCode, body)
 	}
 
 	// Handle empty responses (204 No Content)
@@ -351,7 +544,7 @@ func (c *Client) handleResponse(resp *http.Response, v interface{}) error {
 	}
 
 	// Unwrap the response
-	unwrapped, err := unwrapResponse(body)
+	unwrapped, err := unwrap(body)
 	if err != nil {
 		return NewParseError("failed to unwrap response", err)
 	}
@@ -367,6 +560,8 @@ func (c *Client) handleResponse(resp *http.Response, v interface{}) error {
 }
 
 // Get performs a GET request.
+//
+// Deprecated: Get guesses the wrapper key of the response. Use GetWrapped.
 func (c *Client) Get(ctx context.Context, path string, v interface{}) error {
 	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
@@ -375,47 +570,95 @@ func (c *Client) Get(ctx context.Context, path string, v interface{}) error {
 	return c.handleResponse(resp, v)
 }
 
+// GetWrapped performs a GET request and decodes the data wrapped in wrapperKey
+// into v, e.g. {"server": {...}} for wrapperKey "server". An empty wrapperKey
+// decodes the response as-is.
+func (c *Client) GetWrapped(ctx context.Context, path string, wrapperKey string, v interface{}) error {
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return c.handleWrappedResponse(resp, wrapperKey, v)
+}
+
 // Post performs a POST request with form data.
+//
+// Deprecated: Post guesses the wrapper key of the response. Use PostWrapped.
 func (c *Client) Post(ctx context.Context, path string, data url.Values, v interface{}) error {
-	var body io.Reader
-	if data != nil {
-		body = strings.NewReader(data.Encode())
+	resp, err := c.doRequest(ctx, http.MethodPost, path, formBody(data))
+	if err != nil {
+		return err
 	}
+	return c.handleResponse(resp, v)
+}
 
-	resp, err := c.doRequest(ctx, http.MethodPost, path, body)
+// PostWrapped performs a POST request with form data and decodes the data
+// wrapped in wrapperKey into v.
+func (c *Client) PostWrapped(ctx context.Context, path string, data url.Values, wrapperKey string, v interface{}) error {
+	resp, err := c.doRequest(ctx, http.MethodPost, path, formBody(data))
 	if err != nil {
 		return err
 	}
-	return c.handleResponse(resp, v)
+	return c.handleWrappedResponse(resp, wrapperKey, v)
 }
 
 // PostRaw performs a POST request with pre-encoded form data string.
 // This is useful when the API expects literal brackets in form keys (not URL-encoded).
+//
+// Deprecated: PostRaw guesses the wrapper key of the response. Use PostRawWrapped.
 func (c *Client) PostRaw(ctx context.Context, path string, data string, v interface{}) error {
-	var body io.Reader
-	if data != "" {
-		body = strings.NewReader(data)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, rawBody(data))
+	if err != nil {
+		return err
 	}
+	return c.handleResponse(resp, v)
+}
 
-	resp, err := c.doRequest(ctx, http.MethodPost, path, body)
+// PostRawWrapped performs a POST request with pre-encoded form data string and
+// decodes the data wrapped in wrapperKey into v.
+func (c *Client) PostRawWrapped(ctx context.Context, path string, data string, wrapperKey string, v interface{}) error {
+	resp, err := c.doRequest(ctx, http.MethodPost, path, rawBody(data))
 	if err != nil {
 		return err
 	}
-	return c.handleResponse(resp, v)
+	return c.handleWrappedResponse(resp, wrapperKey, v)
 }
 
 // Put performs a PUT request with form data.
+//
+// Deprecated: Put guesses the wrapper key of the response. Use PutWrapped.
 func (c *Client) Put(ctx context.Context, path string, data url.Values, v interface{}) error {
-	var body io.Reader
-	if data != nil {
-		body = strings.NewReader(data.Encode())
+	resp, err := c.doRequest(ctx, http.MethodPut, path, formBody(data))
+	if err != nil {
+		return err
 	}
+	return c.handleResponse(resp, v)
+}
 
-	resp, err := c.doRequest(ctx, http.MethodPut, path, body)
+// PutWrapped performs a PUT request with form data and decodes the data
+// wrapped in wrapperKey into v.
+func (c *Client) PutWrapped(ctx context.Context, path string, data url.Values, wrapperKey string, v interface{}) error {
+	resp, err := c.doRequest(ctx, http.MethodPut, path, formBody(data))
 	if err != nil {
 		return err
 	}
-	return c.handleResponse(resp, v)
+	return c.handleWrappedResponse(resp, wrapperKey, v)
+}
+
+// formBody returns the encoded form data as a request body, or nil if there is no data.
+func formBody(data url.Values) io.Reader {
+	if data == nil {
+		return nil
+	}
+	return strings.NewReader(data.Encode())
+}
+
+// rawBody returns pre-encoded form data as a request body, or nil if it is empty.
+func rawBody(data string) io.Reader {
+	if data == "" {
+		return nil
+	}
+	return strings.NewReader(data)
 }
 
 // Delete performs a DELETE request.
@@ -424,7 +667,7 @@ func (c *Client) Delete(ctx context.Context, path string) error {
 	if err != nil {
 		return err
 	}
-	return c.handleResponse(resp, nil)
+	return c.handleWrappedResponse(resp, "", nil)
 }
 
 // DeleteWithBody performs a DELETE request with form data.
@@ -443,7 +686,7 @@ func (c *Client) DeleteWithBody(ctx context.Context, path string, data url.Value
 }
 
 // GetWrappedList performs a GET request for array responses where each item is wrapped
-// e.g. [{"server": {...}}, {"server": {...}}].
+// e.g. [{"server": {...}}, {"server": {...}}]. Flat arrays are accepted as well.
 func (c *Client) GetWrappedList(ctx context.Context, path string, wrapperKey string, v interface{}) error {
 	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
@@ -458,11 +701,7 @@ func (c *Client) GetWrappedList(ctx context.Context, path string, wrapperKey str
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
-		var apiErr APIErrorResponse
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return NewAPIError(ErrUnknown, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body)))
-		}
-		return NewAPIError(apiErr.Error.Code, apiErr.Error.Message)
+		return newResponseError(resp.StatusCode, body)
 	}
 
 	// Unwrap the array
@@ -498,7 +737,7 @@ func (c *Client) PostJSON(ctx context.Context, path string, body interface{}, v
 	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return NewNetworkError("request failed", err)
 	}
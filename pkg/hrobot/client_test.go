@@ -4,8 +4,14 @@
 package hrobot
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestUnwrapResponse(t *testing.T) {
@@ -72,6 +78,88 @@ func TestUnwrapResponse(t *testing.T) {
 	}
 }
 
+func TestUnwrapKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wrapperKey string
+		expected   string
+	}{
+		{
+			name:       "wrapped resource with id",
+			input:      `{"transaction":{"id":"B20150121-344958-251479","status":"ready"}}`,
+			wrapperKey: "transaction",
+			expected:   `{"id":"B20150121-344958-251479","status":"ready"}`,
+		},
+		{
+			name:       "wrapped resource without id",
+			input:      `{"rdns":{"ip":"1.2.3.4","ptr":"host.example.com"}}`,
+			wrapperKey: "rdns",
+			expected:   `{"ip":"1.2.3.4","ptr":"host.example.com"}`,
+		},
+		{
+			name:       "wrapped resource with nested wrapper-named key",
+			input:      `{"firewall":{"server_ip":"1.2.3.4","rules":{"input":[{"ip_version":"ipv4"}]},"ip":{"id":1}}}`,
+			wrapperKey: "firewall",
+			expected:   `{"server_ip":"1.2.3.4","rules":{"input":[{"ip_version":"ipv4"}]},"ip":{"id":1}}`,
+		},
+		{
+			name:       "unwrapped resource with id",
+			input:      `{"id":42,"server":{"id":7}}`,
+			wrapperKey: "vswitch",
+			expected:   `{"id":42,"server":{"id":7}}`,
+		},
+		{
+			name:       "unwrapped resource with field named like the wrapper",
+			input:      `{"ip":"1.2.3.4","server_number":321}`,
+			wrapperKey: "ip",
+			expected:   `{"ip":"1.2.3.4","server_number":321}`,
+		},
+		{
+			name:       "array of wrapped items",
+			input:      `[{"ip":{"ip":"1.2.3.4"}},{"ip":{"ip":"5.6.7.8"}}]`,
+			wrapperKey: "ip",
+			expected:   `[{"ip":"1.2.3.4"},{"ip":"5.6.7.8"}]`,
+		},
+		{
+			name:       "flat array",
+			input:      `[{"ip":"1.2.3.4"},{"ip":"5.6.7.8"}]`,
+			wrapperKey: "ip",
+			expected:   `[{"ip":"1.2.3.4"},{"ip":"5.6.7.8"}]`,
+		},
+		{
+			name:       "no wrapper key",
+			input:      `{"wol":{"server_ip":"1.2.3.4"}}`,
+			wrapperKey: "",
+			expected:   `{"wol":{"server_ip":"1.2.3.4"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := unwrapKey([]byte(tt.input), tt.wrapperKey)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var resultJSON, expectedJSON interface{}
+			if err := json.Unmarshal(result, &resultJSON); err != nil {
+				t.Fatalf("failed to unmarshal result: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &expectedJSON); err != nil {
+				t.Fatalf("failed to unmarshal expected: %v", err)
+			}
+
+			resultBytes, _ := json.Marshal(resultJSON)
+			expectedBytes, _ := json.Marshal(expectedJSON)
+
+			if string(resultBytes) != string(expectedBytes) {
+				t.Errorf("unwrapKey() = %s, want %s", string(resultBytes), string(expectedBytes))
+			}
+		})
+	}
+}
+
 func TestUnwrapArrayResponse(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -108,6 +196,26 @@ func TestUnwrapArrayResponse(t *testing.T) {
 			expected:   `[{"id":1}]`,
 			wantErr:    false,
 		},
+		{
+			name:       "flat array",
+			input:      `[{"server_number":1,"server_name":"s1"},{"server_number":2,"server_name":"s2"}]`,
+			wrapperKey: "server",
+			expected:   `[{"server_number":1,"server_name":"s1"},{"server_number":2,"server_name":"s2"}]`,
+			wantErr:    false,
+		},
+		{
+			name:       "mixed wrapped and flat items",
+			input:      `[{"server":{"id":1}},{"id":2},"plain"]`,
+			wrapperKey: "server",
+			expected:   `[{"id":1},{"id":2},"plain"]`,
+			wantErr:    false,
+		},
+		{
+			name:       "not an array",
+			input:      `{"server":{"id":1}}`,
+			wrapperKey: "server",
+			wantErr:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -201,3 +309,235 @@ func TestClientOptions(t *testing.T) {
 		}
 	})
 }
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientHooks(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"server":{"server_number":321}}`))
+		}))
+		defer server.Close()
+
+		var requests []string
+		var statuses []int
+		client := NewClient("test-user", "test-pass",
+			WithBaseURL(server.URL),
+			WithRequestHook(func(req *http.Request) {
+				requests = append(requests, req.Method+" "+req.URL.Path)
+			}),
+			WithResponseHook(func(resp *http.Response, err error, duration time.Duration) {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				if duration <= 0 {
+					t.Errorf("expected positive duration, got %v", duration)
+				}
+				statuses = append(statuses, resp.StatusCode)
+			}),
+		)
+
+		if _, err := client.Server.Get(context.Background(), ServerID(321)); err != nil {
+			t.Fatalf("Server.Get returned error: %v", err)
+		}
+
+		if len(requests) != 1 || requests[0] != "GET /server/321" {
+			t.Errorf("expected request hook for 'GET /server/321', got %v", requests)
+		}
+		if len(statuses) != 1 || statuses[0] != http.StatusOK {
+			t.Errorf("expected response hook with status 200, got %v", statuses)
+		}
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"status":404,"code":"SERVER_NOT_FOUND","message":"server not found"}}`))
+		}))
+		defer server.Close()
+
+		var statuses []int
+		client := NewClient("test-user", "test-pass",
+			WithBaseURL(server.URL),
+			WithResponseHook(func(resp *http.Response, err error, duration time.Duration) {
+				statuses = append(statuses, resp.StatusCode)
+			}),
+		)
+
+		if _, err := client.Server.Get(context.Background(), ServerID(321)); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if len(statuses) != 1 || statuses[0] != http.StatusNotFound {
+			t.Errorf("expected response hook with status 404, got %v", statuses)
+		}
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		transportErr := errors.New("connection refused")
+		requestCount := 0
+		var hookErr error
+		client := NewClient("test-user", "test-pass",
+			WithBaseURL("http://robot.invalid"),
+			WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, transportErr
+			})),
+			WithRequestHook(func(req *http.Request) {
+				requestCount++
+			}),
+			WithResponseHook(func(resp *http.Response, err error, duration time.Duration) {
+				if resp != nil {
+					t.Errorf("expected nil response on transport error, got %v", resp)
+				}
+				hookErr = err
+			}),
+		)
+
+		if _, err := client.Server.Get(context.Background(), ServerID(321)); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if requestCount != 1 {
+			t.Errorf("expected request hook to be called once, got %d", requestCount)
+		}
+		if !errors.Is(hookErr, transportErr) {
+			t.Errorf("expected response hook to receive transport error, got %v", hookErr)
+		}
+	})
+}
+
+func TestWithTransport(t *testing.T) {
+	called := false
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"server":{"server_number":321}}`))
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	client := NewClient("test-user", "test-pass",
+		WithBaseURL(server.URL),
+		WithTransport(transport),
+		WithHTTPClient(httpClient),
+	)
+
+	if _, err := client.Server.Get(context.Background(), ServerID(321)); err != nil {
+		t.Fatalf("Server.Get returned error: %v", err)
+	}
+	if !called {
+		t.Error("expected custom transport to be used")
+	}
+	if httpClient.Transport != nil {
+		t.Error("expected the HTTP client passed via WithHTTPClient to be left unmodified")
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected timeout of the custom HTTP client to be kept, got %v", client.httpClient.Timeout)
+	}
+}
+
+func TestWithMaxConnsPerHost(t *testing.T) {
+	t.Run("applied to default transport", func(t *testing.T) {
+		client := NewClient("user", "pass", WithMaxConnsPerHost(16))
+
+		transport, ok := client.httpClient.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected *http.Transport, got %T", client.httpClient.Transport)
+		}
+		if transport.MaxConnsPerHost != 16 {
+			t.Errorf("expected MaxConnsPerHost 16, got %d", transport.MaxConnsPerHost)
+		}
+		if transport.MaxIdleConnsPerHost != 16 {
+			t.Errorf("expected MaxIdleConnsPerHost 16, got %d", transport.MaxIdleConnsPerHost)
+		}
+		if transport == http.DefaultTransport {
+			t.Error("expected http.DefaultTransport to be cloned, not modified")
+		}
+		if client.httpClient.Timeout != DefaultTimeout {
+			t.Errorf("expected default timeout to be kept, got %v", client.httpClient.Timeout)
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		client := NewClient("user", "pass")
+		if client.httpClient.Transport != nil {
+			t.Errorf("expected default transport, got %T", client.httpClient.Transport)
+		}
+	})
+
+	t.Run("WithHTTPClient overrides", func(t *testing.T) {
+		httpClient := &http.Client{Timeout: 5 * time.Second}
+		client := NewClient("user", "pass", WithMaxConnsPerHost(16), WithHTTPClient(httpClient))
+
+		if client.httpClient != httpClient {
+			t.Fatal("expected the custom HTTP client to be used")
+		}
+		if httpClient.Transport != nil {
+			t.Errorf("expected custom HTTP client to be left unmodified, got transport %T", httpClient.Transport)
+		}
+	})
+}
+
+func TestValidateUsername(t *testing.T) {
+	valid := []string{
+		"#ws+AbC12345",
+		"#ws+x",
+		"#WS+AbC12345",
+		"K1234567890",
+		"robot-user",
+	}
+	for _, username := range valid {
+		if err := ValidateUsername(username); err != nil {
+			t.Errorf("ValidateUsername(%q) = %v, want nil", username, err)
+		}
+	}
+
+	invalid := []struct {
+		username string
+		want     string
+	}{
+		{username: "", want: "empty"},
+		{username: "ws+AbC12345", want: "missing the leading '#'"},
+		{username: "#ws AbC12345", want: "'+' was replaced by a space"},
+		{username: "#ws+", want: "expected #ws+XXXXXXX"},
+		{username: "#wsAbC12345", want: "expected #ws+XXXXXXX"},
+		{username: "#ws-AbC12345", want: "expected #ws+XXXXXXX"},
+		{username: "'#ws+AbC12345'", want: "quotes"},
+		{username: "#ws+AbC12345\n", want: "whitespace"},
+		{username: " #ws+AbC12345", want: "whitespace"},
+		{username: "robot user", want: "whitespace"},
+	}
+	for _, tt := range invalid {
+		err := ValidateUsername(tt.username)
+		if err == nil {
+			t.Errorf("ValidateUsername(%q) = nil, want error", tt.username)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidateUsername(%q) = %q, want it to contain %q", tt.username, err, tt.want)
+		}
+	}
+}
+
+func TestForbiddenResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<html><body>403 Forbidden</body></html>"))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+	_, err := client.Server.List(context.Background())
+	if !IsForbiddenError(err) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "HTTP 403") {
+		t.Errorf("expected status in error message, got %v", err)
+	}
+}
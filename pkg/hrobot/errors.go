@@ -5,7 +5,9 @@ package hrobot
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 )
 
 // Error represents all possible errors from the hrobot library.
@@ -26,6 +28,19 @@ func (e *Error) Unwrap() error {
 	return e.Cause
 }
 
+// Code returns the error code of an API error, or "" for other kinds of
+// errors.
+func (e *Error) Code() ErrorCode {
+	if e.Kind != ErrKindAPI || !strings.HasPrefix(e.Message, "[") {
+		return ""
+	}
+	end := strings.Index(e.Message, "]")
+	if end < 0 {
+		return ""
+	}
+	return ErrorCode(e.Message[1:end])
+}
+
 // ErrorKind categorizes the error type.
 type ErrorKind string
 
@@ -76,9 +91,11 @@ type ErrorCode string
 const (
 	// Common errors.
 	ErrUnauthorized            ErrorCode = "UNAUTHORIZED"
+	ErrForbidden               ErrorCode = "FORBIDDEN"
 	ErrInvalidInput            ErrorCode = "INVALID_INPUT"
 	ErrInvalidInputServerIP    ErrorCode = "INVALID_INPUT_SERVER_IP"
 	ErrInvalidInputIPAddress   ErrorCode = "INVALID_INPUT_IP_ADDRESS"
+	ErrNotFound                ErrorCode = "NOT_FOUND"
 	ErrServerNotFound          ErrorCode = "SERVER_NOT_FOUND"
 	ErrIPNotFound              ErrorCode = "IP_NOT_FOUND"
 	ErrIPLocked                ErrorCode = "IP_LOCKED"
@@ -103,14 +120,28 @@ const (
 	ErrResetNotAvailable ErrorCode = "RESET_NOT_AVAILABLE"
 	ErrResetManualActive ErrorCode = "RESET_MANUAL_ACTIVE"
 
+	// Wake-on-LAN errors.
+	ErrWOLNotAvailable ErrorCode = "WOL_NOT_AVAILABLE"
+	ErrWOLFailed       ErrorCode = "WOL_FAILED"
+
 	// VNC errors.
 	ErrVNCDisabled     ErrorCode = "VNC_DISABLED"
 	ErrVNCNotAvailable ErrorCode = "VNC_NOT_AVAILABLE"
 
+	// Ordering errors.
+	ErrProductNotFound     ErrorCode = "PRODUCT_NOT_FOUND"
+	ErrProductNotAvailable ErrorCode = "PRODUCT_NOT_AVAILABLE"
+
 	// Reverse DNS errors.
 	ErrReverseDNSNotFound ErrorCode = "RDNS_NOT_FOUND"
 	ErrReverseDNSInvalid  ErrorCode = "RDNS_INVALID"
 
+	// Client-side errors (not returned by the Robot API itself).
+	ErrServerNameAmbiguous ErrorCode = "SERVER_NAME_AMBIGUOUS"
+	// ErrVSwitchServerFailed is raised by WaitForVSwitchReady when a server
+	// link of the vSwitch is in the terminal "failed" status.
+	ErrVSwitchServerFailed ErrorCode = "VSWITCH_SERVER_FAILED"
+
 	// Unknown error.
 	ErrUnknown ErrorCode = "UNKNOWN"
 )
@@ -181,16 +212,68 @@ func IsFirewallInProcessError(err error) bool {
 	return IsAPIError(err, ErrFirewallInProcess)
 }
 
+// IsWOLNotAvailableError checks if the error means the server does not support Wake-on-LAN.
+func IsWOLNotAvailableError(err error) bool {
+	return IsAPIError(err, ErrWOLNotAvailable)
+}
+
 // IsUnauthorizedError checks if the error is an unauthorized error.
 func IsUnauthorizedError(err error) bool {
 	return IsAPIError(err, ErrUnauthorized)
 }
 
+// IsForbiddenError checks if the error is a forbidden error. Hetzner rejects
+// requests with HTTP 403 when webservice access is restricted to certain IP
+// addresses and the caller's IP is not one of them.
+func IsForbiddenError(err error) bool {
+	return IsAPIError(err, ErrForbidden)
+}
+
 // IsFirewallRuleLimitExceededError checks if the error is a firewall rule limit exceeded error.
 func IsFirewallRuleLimitExceededError(err error) bool {
 	return IsAPIError(err, ErrFirewallRuleLimitExceeded)
 }
 
+// ErrFirewallChanged is returned by FirewallService.UpdateIfUnchanged when the
+// firewall changed between reading and writing it. It is detected by the
+// client, the Robot API has no such error code. Check for it with errors.Is.
+var ErrFirewallChanged = errors.New("firewall was modified since it was read")
+
+// orderingDisabledMessage is the name of the Robot account setting that Hetzner
+// refers to when an order is rejected because the setting is off.
+const orderingDisabledMessage = "ordering over the webservice"
+
+// IsOrderingDisabledError checks if the error indicates that "ordering over the
+// webservice" is not enabled for the account. Hetzner reports this as an
+// UNAUTHORIZED or INSUFFICIENT_PERMISSIONS error whose message names the setting.
+// A bare UNAUTHORIZED error from an order endpoint can also mean invalid
+// credentials, so callers may still need to verify the credentials separately.
+func IsOrderingDisabledError(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	if !IsUnauthorizedError(e) && !IsAPIError(e, ErrInsufficientPermissions) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(e.Message), orderingDisabledMessage)
+}
+
+// IsAuctionUnavailableError checks if an auction order failed because the
+// server is no longer offered, usually because it was sold in the meantime.
+// Hetzner reports this as PRODUCT_NOT_FOUND, PRODUCT_NOT_AVAILABLE or
+// NOT_FOUND, depending on how far the auction has moved on.
+func IsAuctionUnavailableError(err error) bool {
+	return IsAPIError(err, ErrProductNotFound) || IsAPIError(err, ErrProductNotAvailable) || IsAPIError(err, ErrNotFound)
+}
+
+// IsNetworkError checks if the error is a network error, i.e. the request did
+// not get a response from the API (DNS failure, connection refused, timeout).
+func IsNetworkError(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ErrKindNetwork
+}
+
 // IsInvalidInputError checks if the error is an invalid input error.
 func IsInvalidInputError(err error) bool {
 	return IsAPIError(err, ErrInvalidInput)
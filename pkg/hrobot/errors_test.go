@@ -5,6 +5,7 @@ package hrobot
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -136,6 +137,27 @@ func TestErrorUnwrap(t *testing.T) {
 	}
 }
 
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want ErrorCode
+	}{
+		{name: "API error", err: NewAPIError(ErrServerNotFound, "server not found"), want: ErrServerNotFound},
+		{name: "Unknown API error code", err: NewAPIError("SOMETHING_NEW", "message"), want: "SOMETHING_NEW"},
+		{name: "Network error", err: NewNetworkError("[NOT_FOUND] request failed", nil), want: ""},
+		{name: "Auth error", err: NewAuthError("invalid credentials"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Code(); got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsAPIError(t *testing.T) {
 	tests := []struct {
 		name string
@@ -266,6 +288,154 @@ func TestIsNotFoundError(t *testing.T) {
 	}
 }
 
+func TestIsOrderingDisabledError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Unauthorized ordering error",
+			err:  NewAPIError(ErrUnauthorized, "Ordering over the webservice is not enabled"),
+			want: true,
+		},
+		{
+			name: "Insufficient permissions ordering error",
+			err:  NewAPIError(ErrInsufficientPermissions, "Ordering over the webservice is not allowed"),
+			want: true,
+		},
+		{
+			name: "Wrapped ordering error",
+			err:  fmt.Errorf("order server: %w", NewAPIError(ErrUnauthorized, "Ordering over the webservice is not enabled")),
+			want: true,
+		},
+		{
+			name: "Unauthorized error mentioning a word containing order",
+			err:  NewAPIError(ErrUnauthorized, "Access denied at the border gateway"),
+			want: false,
+		},
+		{
+			name: "Insufficient permissions for an order without the setting",
+			err:  NewAPIError(ErrInsufficientPermissions, "no permission to order"),
+			want: false,
+		},
+		{
+			name: "Plain unauthorized error",
+			err:  NewAPIError(ErrUnauthorized, "Unable to authenticate"),
+			want: false,
+		},
+		{
+			name: "Other API error mentioning order",
+			err:  NewAPIError(ErrInvalidInput, "invalid order"),
+			want: false,
+		},
+		{
+			name: "Network error",
+			err:  NewNetworkError("order request failed", nil),
+			want: false,
+		},
+		{
+			name: "Nil error",
+			err:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsOrderingDisabledError(tt.err)
+			if got != tt.want {
+				t.Errorf("IsOrderingDisabledError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAuctionUnavailableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Product not found",
+			err:  NewAPIError(ErrProductNotFound, "Product not found"),
+			want: true,
+		},
+		{
+			name: "Product not available",
+			err:  NewAPIError(ErrProductNotAvailable, "Product is not available"),
+			want: true,
+		},
+		{
+			name: "Not found",
+			err:  NewAPIError(ErrNotFound, "Not found"),
+			want: true,
+		},
+		{
+			name: "Invalid input",
+			err:  NewAPIError(ErrInvalidInput, "invalid input"),
+			want: false,
+		},
+		{
+			name: "Network error",
+			err:  NewNetworkError("request failed", nil),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAuctionUnavailableError(tt.err); got != tt.want {
+				t.Errorf("IsAuctionUnavailableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Network error",
+			err:  NewNetworkError("request failed", errors.New("connection refused")),
+			want: true,
+		},
+		{
+			name: "Wrapped network error",
+			err:  fmt.Errorf("list servers: %w", NewNetworkError("request failed", errors.New("connection refused"))),
+			want: true,
+		},
+		{
+			name: "API error",
+			err:  NewAPIError(ErrNotFound, "not found"),
+			want: false,
+		},
+		{
+			name: "Parse error",
+			err:  NewParseError("invalid JSON", nil),
+			want: false,
+		},
+		{
+			name: "Nil error",
+			err:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsNetworkError(tt.err)
+			if got != tt.want {
+				t.Errorf("IsNetworkError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestErrorCodes(t *testing.T) {
 	// Test that all error codes are defined
 	codes := []ErrorCode{
@@ -64,7 +64,7 @@ func (f *FailoverService) Get(ctx context.Context, ip string) (*Failover, error)
 	path := fmt.Sprintf("/failover/%s", url.PathEscape(ip))
 
 	var result Failover
-	if err := f.client.Get(ctx, path, &result); err != nil {
+	if err := f.client.GetWrapped(ctx, path, "failover", &result); err != nil {
 		return nil, err
 	}
 
@@ -83,7 +83,7 @@ func (f *FailoverService) Update(ctx context.Context, ip, activeServerIP string)
 	formData.Set("active_server_ip", activeServerIP)
 
 	var result Failover
-	if err := f.client.Post(ctx, path, formData, &result); err != nil {
+	if err := f.client.PostWrapped(ctx, path, formData, "failover", &result); err != nil {
 		return nil, err
 	}
 
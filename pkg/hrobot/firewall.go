@@ -5,8 +5,13 @@ package hrobot
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/netip"
 	"net/url"
+	"strings"
 
 	"github.com/midwork-finds-jobs/terraform-provider-hrobot/pkg/hrobot/internal/urlencode"
 )
@@ -42,6 +47,76 @@ type FirewallRule struct {
 	TCPFlags   string    `json:"tcp_flags,omitempty"`
 }
 
+// NormalizeCIDR adds the host prefix (/32 for IPv4, /128 for IPv6) to an IP
+// address without CIDR notation. The Robot API stores single addresses with
+// the prefix, so normalizing keeps "1.2.3.4" and "1.2.3.4/32" comparable.
+func NormalizeCIDR(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	// If already has CIDR notation, return as-is
+	if strings.Contains(ip, "/") {
+		return ip
+	}
+	// Check if it's IPv6 (contains :)
+	if strings.Contains(ip, ":") {
+		return ip + "/128"
+	}
+	// Assume IPv4
+	return ip + "/32"
+}
+
+// Normalize returns the rule in canonical form: single addresses get their
+// host prefix, IPv6 addresses are written in their shortest lowercase form,
+// the IP version, action, protocol and TCP flags are lowercased and blanks
+// around values and inside port lists are removed.
+func (r FirewallRule) Normalize() FirewallRule {
+	r.IPVersion = IPVersion(strings.ToLower(strings.TrimSpace(string(r.IPVersion))))
+	r.Action = Action(strings.ToLower(strings.TrimSpace(string(r.Action))))
+	r.Protocol = Protocol(strings.ToLower(strings.TrimSpace(string(r.Protocol))))
+	r.SourceIP = canonicalCIDR(r.SourceIP)
+	r.DestIP = canonicalCIDR(r.DestIP)
+	r.SourcePort = strings.ReplaceAll(r.SourcePort, " ", "")
+	r.DestPort = strings.ReplaceAll(r.DestPort, " ", "")
+	r.TCPFlags = strings.ToLower(strings.TrimSpace(r.TCPFlags))
+	return r
+}
+
+// Equal reports whether both rules match the same traffic with the same
+// action once normalized. The name is only a label and is ignored.
+func (r FirewallRule) Equal(other FirewallRule) bool {
+	a, b := r.Normalize(), other.Normalize()
+	a.Name, b.Name = "", ""
+	return a == b
+}
+
+// MailBlockRule is the output rule Hetzner adds automatically to block
+// outgoing mail on ports 25 and 465.
+var MailBlockRule = FirewallRule{
+	Name:      "Block mail ports",
+	IPVersion: IPv4,
+	Action:    ActionDiscard,
+	Protocol:  ProtocolTCP,
+	DestPort:  "25,465",
+}
+
+// IsMailBlock reports whether the rule is Hetzner's auto-added MailBlockRule.
+// The rule may be added for either IP version, so the version is not compared.
+func (r FirewallRule) IsMailBlock() bool {
+	r.IPVersion = MailBlockRule.IPVersion
+	return r.Name == MailBlockRule.Name && r.Equal(MailBlockRule)
+}
+
+// canonicalCIDR normalizes an address with NormalizeCIDR and rewrites valid
+// prefixes in their canonical text form. Invalid values are kept as given.
+func canonicalCIDR(ip string) string {
+	ip = NormalizeCIDR(strings.TrimSpace(ip))
+	if prefix, err := netip.ParsePrefix(ip); err == nil {
+		return prefix.String()
+	}
+	return ip
+}
+
 // FirewallConfig represents the complete firewall configuration.
 type FirewallConfig struct {
 	ServerIP     string         `json:"server_ip"`
@@ -53,6 +128,19 @@ type FirewallConfig struct {
 	Rules        FirewallRules  `json:"rules"`
 }
 
+// Fingerprint returns a hash of the rules and settings of the configuration.
+// The status is left out so that a firewall moving from "in process" to
+// "active" is not reported as a modification.
+func (c *FirewallConfig) Fingerprint() string {
+	data, _ := json.Marshal(struct {
+		FilterIPv6   bool          `json:"filter_ipv6"`
+		WhitelistHOS bool          `json:"whitelist_hos"`
+		Rules        FirewallRules `json:"rules"`
+	}{c.FilterIPv6, c.WhitelistHOS, c.Rules})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // FirewallRules contains input and output rules.
 type FirewallRules struct {
 	Input  []FirewallRule `json:"input"`
@@ -63,7 +151,7 @@ type FirewallRules struct {
 func (f *FirewallService) Get(ctx context.Context, serverID ServerID) (*FirewallConfig, error) {
 	var config FirewallConfig
 	path := fmt.Sprintf("/firewall/%s", serverID.String())
-	err := f.client.Get(ctx, path, &config)
+	err := f.client.GetWrapped(ctx, path, "firewall", &config)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +166,17 @@ type UpdateConfig struct {
 	Rules        FirewallRules
 }
 
-// Update updates the firewall configuration for a server.
+// Update updates the firewall configuration for a server. If the API rejects
+// the update because the firewall is in process, Update waits for the firewall
+// to become ready and retries a few times before returning the error.
 func (f *FirewallService) Update(ctx context.Context, serverID ServerID, config UpdateConfig) (*FirewallConfig, error) {
+	return f.retryInProcess(ctx, serverID, func() (*FirewallConfig, error) {
+		return f.update(ctx, serverID, config)
+	})
+}
+
+// update posts the firewall configuration once.
+func (f *FirewallService) update(ctx context.Context, serverID ServerID, config UpdateConfig) (*FirewallConfig, error) {
 	path := fmt.Sprintf("/firewall/%s", serverID.String())
 
 	// Build the form data with hierarchical rule encoding
@@ -114,7 +211,7 @@ func (f *FirewallService) Update(ctx context.Context, serverID ServerID, config
 	formData := encoder.MergeValues(additional)
 
 	var result FirewallConfig
-	err := f.client.Post(ctx, path, formData, &result)
+	err := f.client.PostWrapped(ctx, path, formData, "firewall", &result)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +219,50 @@ func (f *FirewallService) Update(ctx context.Context, serverID ServerID, config
 	return &result, nil
 }
 
+// maxInProcessRetries is how often an update rejected with FIREWALL_IN_PROCESS
+// is retried after waiting for the firewall to become ready again.
+const maxInProcessRetries = 3
+
+// retryInProcess calls update and, while it fails because the firewall is in
+// process, waits for the firewall to become ready and tries again. A change made
+// by someone else between waiting and writing causes this, even after
+// WaitForFirewallReady.
+func (f *FirewallService) retryInProcess(ctx context.Context, serverID ServerID, update func() (*FirewallConfig, error)) (*FirewallConfig, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := update()
+		if err == nil || !IsFirewallInProcessError(err) || attempt == maxInProcessRetries {
+			return result, err
+		}
+		if err := f.WaitForFirewallReady(ctx, serverID); err != nil {
+			return nil, fmt.Errorf("firewall of server %s is in process: %w", serverID.String(), err)
+		}
+	}
+}
+
+// UpdateIfUnchanged updates the firewall configuration only if it still matches
+// the fingerprint captured when it was read (see FirewallConfig.Fingerprint).
+//
+// The Robot API has no native concurrency control, so this re-reads the firewall
+// right before writing. It narrows the window in which two read-modify-write
+// cycles can overwrite each other, but cannot close it completely.
+// Returns an error wrapping ErrFirewallChanged if the firewall was changed in between.
+// Like Update it retries while the firewall is in process, re-checking the
+// fingerprint before every attempt.
+func (f *FirewallService) UpdateIfUnchanged(ctx context.Context, serverID ServerID, fingerprint string, config UpdateConfig) (*FirewallConfig, error) {
+	return f.retryInProcess(ctx, serverID, func() (*FirewallConfig, error) {
+		current, err := f.Get(ctx, serverID)
+		if err != nil {
+			return nil, err
+		}
+
+		if current.Fingerprint() != fingerprint {
+			return nil, fmt.Errorf("server %s: %w", serverID.String(), ErrFirewallChanged)
+		}
+
+		return f.update(ctx, serverID, config)
+	})
+}
+
 // encodeRule converts a FirewallRule to a map for URL encoding.
 func (f *FirewallService) encodeRule(rule FirewallRule) map[string]string {
 	data := make(map[string]string)
@@ -163,7 +304,7 @@ func (f *FirewallService) Activate(ctx context.Context, serverID ServerID) (*Fir
 	data.Set("status", string(FirewallStatusActive))
 
 	var config FirewallConfig
-	err := f.client.Post(ctx, path, data, &config)
+	err := f.client.PostWrapped(ctx, path, data, "firewall", &config)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +320,7 @@ func (f *FirewallService) Disable(ctx context.Context, serverID ServerID) (*Fire
 	data.Set("status", string(FirewallStatusDisabled))
 
 	var config FirewallConfig
-	err := f.client.Post(ctx, path, data, &config)
+	err := f.client.PostWrapped(ctx, path, data, "firewall", &config)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +361,7 @@ type TemplateConfig struct {
 // ListTemplates retrieves all firewall templates.
 func (f *FirewallService) ListTemplates(ctx context.Context) ([]FirewallTemplate, error) {
 	var templates []FirewallTemplate
-	err := f.client.Get(ctx, "/firewall/template", &templates)
+	err := f.client.GetWrapped(ctx, "/firewall/template", "firewall_template", &templates)
 	if err != nil {
 		return nil, err
 	}
@@ -231,7 +372,7 @@ func (f *FirewallService) ListTemplates(ctx context.Context) ([]FirewallTemplate
 func (f *FirewallService) GetTemplate(ctx context.Context, templateID string) (*FirewallTemplate, error) {
 	var wrapper FirewallTemplateWrapper
 	path := fmt.Sprintf("/firewall/template/%s", templateID)
-	err := f.client.Get(ctx, path, &wrapper)
+	err := f.client.GetWrapped(ctx, path, "", &wrapper)
 	if err != nil {
 		return nil, err
 	}
@@ -275,7 +416,7 @@ func (f *FirewallService) CreateTemplate(ctx context.Context, config TemplateCon
 	formData := encoder.EncodeToString(additional)
 
 	var wrapper FirewallTemplateWrapper
-	err := f.client.PostRaw(ctx, "/firewall/template", formData, &wrapper)
+	err := f.client.PostRawWrapped(ctx, "/firewall/template", formData, "", &wrapper)
 	if err != nil {
 		return nil, err
 	}
@@ -322,7 +463,7 @@ func (f *FirewallService) UpdateTemplate(ctx context.Context, templateID string,
 	formData := encoder.EncodeToString(additional)
 
 	var wrapper FirewallTemplateWrapper
-	err := f.client.PostRaw(ctx, path, formData, &wrapper)
+	err := f.client.PostRawWrapped(ctx, path, formData, "", &wrapper)
 	if err != nil {
 		return nil, err
 	}
@@ -330,6 +471,25 @@ func (f *FirewallService) UpdateTemplate(ctx context.Context, templateID string,
 	return &wrapper.Template, nil
 }
 
+// SetDefaultTemplate marks a firewall template as the account default. Only
+// is_default is sent, so the name, settings and rules of the template are kept.
+//
+// POST /firewall/template/{template-id}
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#post-firewall-template-template-id
+func (f *FirewallService) SetDefaultTemplate(ctx context.Context, templateID string) (*FirewallTemplate, error) {
+	path := fmt.Sprintf("/firewall/template/%s", templateID)
+
+	data := url.Values{}
+	data.Set("is_default", "true")
+
+	var wrapper FirewallTemplateWrapper
+	if err := f.client.PostWrapped(ctx, path, data, "", &wrapper); err != nil {
+		return nil, err
+	}
+	return &wrapper.Template, nil
+}
+
 // DeleteTemplate deletes a firewall template.
 func (f *FirewallService) DeleteTemplate(ctx context.Context, templateID string) error {
 	path := fmt.Sprintf("/firewall/template/%s", templateID)
@@ -359,7 +519,7 @@ func (f *FirewallService) ApplyTemplate(ctx context.Context, serverID ServerID,
 	// Note: whitelist_hos cannot be passed with template_id according to API docs
 
 	var config FirewallConfig
-	err := f.client.Post(ctx, path, data, &config)
+	err := f.client.PostWrapped(ctx, path, data, "firewall", &config)
 	if err != nil {
 		return nil, err
 	}
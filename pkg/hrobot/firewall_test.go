@@ -6,6 +6,7 @@ package hrobot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -270,6 +271,457 @@ func TestFirewallService_Update(t *testing.T) {
 	}
 }
 
+func TestFirewallService_UpdateTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/firewall/template/5" {
+			t.Errorf("expected POST /firewall/template/5, got %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+
+		expected := map[string]string{
+			"name":                        "web",
+			"filter_ipv6":                 "true",
+			"whitelist_hos":               "false",
+			"is_default":                  "false",
+			"rules[input][0][name]":       "allow https",
+			"rules[input][0][ip_version]": "ipv4",
+			"rules[input][0][action]":     "accept",
+			"rules[input][0][protocol]":   "tcp",
+			"rules[input][0][dst_port]":   "443",
+			"rules[output][0][action]":    "accept",
+		}
+		for key, want := range expected {
+			if got := r.PostForm.Get(key); got != want {
+				t.Errorf("expected %s=%q, got %q", key, want, got)
+			}
+		}
+
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":            5,
+				"name":          "web",
+				"filter_ipv6":   true,
+				"whitelist_hos": false,
+				"is_default":    false,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	tmpl, err := client.Firewall.UpdateTemplate(context.Background(), "5", TemplateConfig{
+		Name:       "web",
+		FilterIPv6: true,
+		Rules: FirewallRules{
+			Input: []FirewallRule{
+				{Name: "allow https", IPVersion: IPv4, Action: ActionAccept, Protocol: ProtocolTCP, DestPort: "443"},
+			},
+			Output: []FirewallRule{{Action: ActionAccept}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Firewall.UpdateTemplate returned error: %v", err)
+	}
+	if tmpl.ID != 5 || tmpl.Name != "web" || !tmpl.FilterIPv6 {
+		t.Errorf("unexpected template: %+v", tmpl)
+	}
+}
+
+func TestFirewallService_SetDefaultTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/firewall/template/5" {
+			t.Errorf("expected POST /firewall/template/5, got %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if len(r.PostForm) != 1 || r.PostForm.Get("is_default") != "true" {
+			t.Errorf("expected only is_default=true to be sent, got %v", r.PostForm)
+		}
+
+		response := map[string]interface{}{
+			"firewall_template": map[string]interface{}{
+				"id":         5,
+				"name":       "web",
+				"is_default": true,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	tmpl, err := client.Firewall.SetDefaultTemplate(context.Background(), "5")
+	if err != nil {
+		t.Fatalf("Firewall.SetDefaultTemplate returned error: %v", err)
+	}
+	if tmpl.ID != 5 || !tmpl.IsDefault {
+		t.Errorf("expected template 5 to be the default, got %+v", tmpl)
+	}
+}
+
+func TestFirewallService_UpdateIfUnchanged_StaleWrite(t *testing.T) {
+	getCount := 0
+	postCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			getCount++
+			// The first read sees one rule; before the write another client
+			// has added a second rule.
+			input := []map[string]interface{}{
+				{"name": "allow ssh", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "dst_port": "22"},
+			}
+			if getCount > 1 {
+				input = append(input, map[string]interface{}{
+					"name": "allow https", "ip_version": "ipv4", "action": "accept", "protocol": "tcp", "dst_port": "443",
+				})
+			}
+			response := map[string]interface{}{
+				"firewall": map[string]interface{}{
+					"server_ip":     "123.123.123.123",
+					"server_number": 321,
+					"status":        "active",
+					"whitelist_hos": true,
+					"port":          "main",
+					"rules": map[string]interface{}{
+						"input":  input,
+						"output": []map[string]interface{}{},
+					},
+				},
+			}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				t.Fatalf("failed to encode response: %v", err)
+			}
+		case "POST":
+			postCount++
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	fw, err := client.Firewall.Get(ctx, ServerID(321))
+	if err != nil {
+		t.Fatalf("Firewall.Get returned error: %v", err)
+	}
+
+	updateConfig := UpdateConfig{
+		Status:       fw.Status,
+		WhitelistHOS: fw.WhitelistHOS,
+		Rules: FirewallRules{
+			Input: append([]FirewallRule{{Name: "allow http", IPVersion: IPv4, Action: ActionAccept, Protocol: ProtocolTCP, DestPort: "80"}}, fw.Rules.Input...),
+		},
+	}
+
+	_, err = client.Firewall.UpdateIfUnchanged(ctx, ServerID(321), fw.Fingerprint(), updateConfig)
+	if err == nil {
+		t.Fatal("expected stale write to be rejected, got nil error")
+	}
+	if !errors.Is(err, ErrFirewallChanged) {
+		t.Errorf("expected ErrFirewallChanged, got: %v", err)
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		t.Errorf("expected a client-side error, not an API error: %v", apiErr)
+	}
+	if postCount != 0 {
+		t.Errorf("expected no update to be sent, got %d POST request(s)", postCount)
+	}
+}
+
+func TestFirewallService_Update_RetriesInProcess(t *testing.T) {
+	tests := []struct {
+		name        string
+		inProcess   int // number of POSTs rejected with FIREWALL_IN_PROCESS
+		wantErr     bool
+		wantPosts   int
+		wantWaitGet int
+	}{
+		{
+			name:        "retry succeeds",
+			inProcess:   1,
+			wantPosts:   2,
+			wantWaitGet: 1,
+		},
+		{
+			name:        "gives up after bounded retries",
+			inProcess:   10,
+			wantErr:     true,
+			wantPosts:   maxInProcessRetries + 1,
+			wantWaitGet: maxInProcessRetries,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts := 0
+			gets := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" {
+					posts++
+					if posts <= tt.inProcess {
+						w.WriteHeader(http.StatusConflict)
+						_ = json.NewEncoder(w).Encode(map[string]interface{}{
+							"error": map[string]interface{}{
+								"status":  http.StatusConflict,
+								"code":    "FIREWALL_IN_PROCESS",
+								"message": "The firewall cannot be updated because it is in process",
+							},
+						})
+						return
+					}
+				} else {
+					gets++
+				}
+
+				// Reads during the wait find the firewall ready again.
+				response := map[string]interface{}{
+					"firewall": map[string]interface{}{
+						"server_ip":     "123.123.123.123",
+						"server_number": 321,
+						"status":        "active",
+						"whitelist_hos": true,
+						"port":          "main",
+						"rules": map[string]interface{}{
+							"input":  []map[string]interface{}{},
+							"output": []map[string]interface{}{},
+						},
+					},
+				}
+				if err := json.NewEncoder(w).Encode(response); err != nil {
+					t.Fatalf("failed to encode response: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+			fw, err := client.Firewall.Update(context.Background(), ServerID(321), UpdateConfig{Status: FirewallStatusActive})
+			if tt.wantErr {
+				if !IsFirewallInProcessError(err) {
+					t.Fatalf("expected a firewall in process error, got %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Update returned error: %v", err)
+				}
+				if fw.Status != FirewallStatusActive {
+					t.Errorf("expected status active, got %s", fw.Status)
+				}
+			}
+			if posts != tt.wantPosts {
+				t.Errorf("expected %d POST request(s), got %d", tt.wantPosts, posts)
+			}
+			if gets != tt.wantWaitGet {
+				t.Errorf("expected %d wait request(s), got %d", tt.wantWaitGet, gets)
+			}
+		})
+	}
+}
+
+func TestFirewallConfig_Fingerprint(t *testing.T) {
+	base := FirewallConfig{
+		Status:       FirewallStatusActive,
+		WhitelistHOS: true,
+		Rules: FirewallRules{
+			Input: []FirewallRule{{Name: "allow ssh", Action: ActionAccept, DestPort: "22"}},
+		},
+	}
+
+	inProcess := base
+	inProcess.Status = "in process"
+	if base.Fingerprint() != inProcess.Fingerprint() {
+		t.Error("expected status changes not to affect the fingerprint")
+	}
+
+	modified := base
+	modified.Rules.Input = []FirewallRule{{Name: "allow ssh", Action: ActionAccept, DestPort: "2222"}}
+	if base.Fingerprint() == modified.Fingerprint() {
+		t.Error("expected rule changes to change the fingerprint")
+	}
+}
+
+func TestNormalizeCIDR(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "empty", ip: "", want: ""},
+		{name: "IPv4 address", ip: "1.2.3.4", want: "1.2.3.4/32"},
+		{name: "IPv4 CIDR", ip: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{name: "IPv4 host CIDR", ip: "1.2.3.4/32", want: "1.2.3.4/32"},
+		{name: "IPv6 address", ip: "2001:db8::1", want: "2001:db8::1/128"},
+		{name: "IPv6 CIDR", ip: "2001:db8::/64", want: "2001:db8::/64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeCIDR(tt.ip); got != tt.want {
+				t.Errorf("NormalizeCIDR(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirewallRule_Normalize(t *testing.T) {
+	rule := FirewallRule{
+		Name:       "Allow SSH",
+		IPVersion:  "IPv6",
+		Action:     "Accept",
+		Protocol:   " TCP ",
+		SourceIP:   "2001:DB8:0::1",
+		DestIP:     "2001:db8::/64",
+		SourcePort: "1024 - 65535",
+		DestPort:   "22, 2222",
+		TCPFlags:   "SYN",
+	}
+	want := FirewallRule{
+		Name:       "Allow SSH",
+		IPVersion:  IPv6,
+		Action:     ActionAccept,
+		Protocol:   ProtocolTCP,
+		SourceIP:   "2001:db8::1/128",
+		DestIP:     "2001:db8::/64",
+		SourcePort: "1024-65535",
+		DestPort:   "22,2222",
+		TCPFlags:   "syn",
+	}
+	if got := rule.Normalize(); got != want {
+		t.Errorf("Normalize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFirewallRule_IsMailBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     FirewallRule
+		expected bool
+	}{
+		{
+			name: "auto-added mail rule",
+			rule: FirewallRule{
+				Name:     "Block mail ports",
+				Action:   ActionDiscard,
+				Protocol: ProtocolTCP,
+				DestPort: "25,465",
+			},
+			expected: true,
+		},
+		{
+			name: "auto-added IPv6 mail rule",
+			rule: FirewallRule{
+				Name:      "Block mail ports",
+				IPVersion: IPv6,
+				Action:    ActionDiscard,
+				Protocol:  ProtocolTCP,
+				DestPort:  "25,465",
+			},
+			expected: true,
+		},
+		{
+			name: "regular rule",
+			rule: FirewallRule{
+				Name:     "Allow SSH",
+				Action:   ActionAccept,
+				Protocol: ProtocolTCP,
+				DestPort: "22",
+			},
+			expected: false,
+		},
+		{
+			name: "different mail port",
+			rule: FirewallRule{
+				Name:     "Block mail ports",
+				Action:   ActionDiscard,
+				Protocol: ProtocolTCP,
+				DestPort: "25",
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.rule.IsMailBlock()
+			if result != tt.expected {
+				t.Errorf("IsMailBlock() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFirewallRule_Equal(t *testing.T) {
+	base := FirewallRule{
+		Name:      "Allow SSH",
+		IPVersion: IPv4,
+		Action:    ActionAccept,
+		Protocol:  ProtocolTCP,
+		SourceIP:  "1.2.3.4/32",
+		DestPort:  "22",
+	}
+	with := func(change func(r *FirewallRule)) FirewallRule {
+		r := base
+		change(&r)
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		other FirewallRule
+		want  bool
+	}{
+		{name: "identical", other: base, want: true},
+		{name: "different name", other: with(func(r *FirewallRule) { r.Name = "ssh from office" }), want: true},
+		{name: "address without prefix", other: with(func(r *FirewallRule) { r.SourceIP = "1.2.3.4" }), want: true},
+		{name: "address with blanks", other: with(func(r *FirewallRule) { r.SourceIP = " 1.2.3.4 " }), want: true},
+		{name: "uppercase action", other: with(func(r *FirewallRule) { r.Action = "ACCEPT" }), want: true},
+		{name: "uppercase protocol", other: with(func(r *FirewallRule) { r.Protocol = "Tcp" }), want: true},
+		{name: "uppercase IP version", other: with(func(r *FirewallRule) { r.IPVersion = "IPV4" }), want: true},
+		{name: "different prefix length", other: with(func(r *FirewallRule) { r.SourceIP = "1.2.3.4/24" }), want: false},
+		{name: "different address", other: with(func(r *FirewallRule) { r.SourceIP = "1.2.3.5" }), want: false},
+		{name: "source instead of destination", other: with(func(r *FirewallRule) { r.SourceIP, r.DestIP = "", "1.2.3.4/32" }), want: false},
+		{name: "different action", other: with(func(r *FirewallRule) { r.Action = ActionDiscard }), want: false},
+		{name: "different protocol", other: with(func(r *FirewallRule) { r.Protocol = ProtocolUDP }), want: false},
+		{name: "no protocol", other: with(func(r *FirewallRule) { r.Protocol = "" }), want: false},
+		{name: "different IP version", other: with(func(r *FirewallRule) { r.IPVersion = IPv6 }), want: false},
+		{name: "different port", other: with(func(r *FirewallRule) { r.DestPort = "2222" }), want: false},
+		{name: "source port set", other: with(func(r *FirewallRule) { r.SourcePort = "22" }), want: false},
+		{name: "TCP flags set", other: with(func(r *FirewallRule) { r.TCPFlags = "ack" }), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Equal(tt.other); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.other.Equal(base); got != tt.want {
+				t.Errorf("Equal() is not symmetric: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	v6 := FirewallRule{Action: ActionAccept, IPVersion: IPv6, SourceIP: "2001:db8::1"}
+	if !v6.Equal(FirewallRule{Action: ActionAccept, IPVersion: IPv6, SourceIP: "2001:DB8:0:0::1/128"}) {
+		t.Error("expected IPv6 addresses in different notations to be equal")
+	}
+	ports := FirewallRule{Action: ActionDiscard, Protocol: ProtocolTCP, DestPort: "25,465"}
+	if !ports.Equal(FirewallRule{Action: ActionDiscard, Protocol: ProtocolTCP, DestPort: "25, 465"}) {
+		t.Error("expected port lists with blanks to be equal")
+	}
+}
+
 func TestFirewallService_WaitForFirewallReady(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"strconv"
 )
 
 // IPService handles IP address related API operations.
@@ -23,7 +24,7 @@ func NewIPService(client *Client) *IPService {
 // List returns all IP addresses.
 func (i *IPService) List(ctx context.Context) ([]IPAddress, error) {
 	var ips []IPAddress
-	err := i.client.Get(ctx, "/ip", &ips)
+	err := i.client.GetWrapped(ctx, "/ip", "ip", &ips)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +35,7 @@ func (i *IPService) List(ctx context.Context) ([]IPAddress, error) {
 func (i *IPService) Get(ctx context.Context, ip net.IP) (*IPAddress, error) {
 	var ipAddr IPAddress
 	path := fmt.Sprintf("/ip/%s", ip.String())
-	err := i.client.Get(ctx, path, &ipAddr)
+	err := i.client.GetWrapped(ctx, path, "ip", &ipAddr)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +52,7 @@ type ReverseDNS struct {
 func (i *IPService) GetReverseDNS(ctx context.Context, ip net.IP) (*ReverseDNS, error) {
 	var rdns ReverseDNS
 	path := fmt.Sprintf("/rdns/%s", ip.String())
-	err := i.client.Get(ctx, path, &rdns)
+	err := i.client.GetWrapped(ctx, path, "rdns", &rdns)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +67,7 @@ func (i *IPService) SetReverseDNS(ctx context.Context, ip net.IP, ptr string) (*
 	data := url.Values{}
 	data.Set("ptr", ptr)
 
-	err := i.client.Post(ctx, path, data, &rdns)
+	err := i.client.PostWrapped(ctx, path, data, "rdns", &rdns)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +111,7 @@ func (i *IPService) GetTraffic(ctx context.Context, ip net.IP, trafficType strin
 
 	fullPath := fmt.Sprintf("%s?%s", path, params.Encode())
 
-	err := i.client.Get(ctx, fullPath, &traffic)
+	err := i.client.GetWrapped(ctx, fullPath, "traffic", &traffic)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +130,41 @@ func (i *IPService) SetTrafficWarnings(ctx context.Context, ip net.IP, enabled b
 		data.Set("traffic_warnings", "false")
 	}
 
-	return i.client.Post(ctx, path, data, nil)
+	return i.client.PostWrapped(ctx, path, data, "", nil)
+}
+
+// TrafficWarningSettings configures the traffic warnings of an IP address.
+// Hourly and daily limits are in MB, the monthly limit is in GB. A zero
+// limit leaves the current value unchanged.
+type TrafficWarningSettings struct {
+	Enabled bool
+	Hourly  int
+	Daily   int
+	Monthly int
+}
+
+// UpdateTrafficWarnings enables or disables traffic warnings for an IP and
+// sets the warning limits.
+func (i *IPService) UpdateTrafficWarnings(ctx context.Context, ip net.IP, settings TrafficWarningSettings) (*IPAddress, error) {
+	path := fmt.Sprintf("/ip/%s", ip.String())
+
+	data := url.Values{}
+	data.Set("traffic_warnings", strconv.FormatBool(settings.Enabled))
+	if settings.Hourly > 0 {
+		data.Set("traffic_hourly", strconv.Itoa(settings.Hourly))
+	}
+	if settings.Daily > 0 {
+		data.Set("traffic_daily", strconv.Itoa(settings.Daily))
+	}
+	if settings.Monthly > 0 {
+		data.Set("traffic_monthly", strconv.Itoa(settings.Monthly))
+	}
+
+	var ipAddr IPAddress
+	if err := i.client.PostWrapped(ctx, path, data, "ip", &ipAddr); err != nil {
+		return nil, err
+	}
+	return &ipAddr, nil
 }
 
 // CancelIP cancels an additional IP address.
@@ -139,7 +174,7 @@ func (i *IPService) CancelIP(ctx context.Context, ip net.IP, cancellationDate st
 	data := url.Values{}
 	data.Set("cancellation_date", cancellationDate)
 
-	return i.client.Post(ctx, path, data, nil)
+	return i.client.PostWrapped(ctx, path, data, "", nil)
 }
 
 // WithdrawIPCancellation withdraws an IP cancellation.
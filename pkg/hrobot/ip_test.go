@@ -179,6 +179,64 @@ func TestIPService_SetTrafficWarnings(t *testing.T) {
 	}
 }
 
+func TestIPService_UpdateTrafficWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ip/123.123.123.123" {
+			t.Errorf("expected path '/ip/123.123.123.123', got '%s'", r.URL.Path)
+		}
+		if r.Method != "POST" {
+			t.Errorf("expected POST request, got '%s'", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+
+		expected := map[string]string{
+			"traffic_warnings": "true",
+			"traffic_hourly":   "200",
+			"traffic_daily":    "2000",
+			"traffic_monthly":  "",
+		}
+		for key, want := range expected {
+			if got := r.FormValue(key); got != want {
+				t.Errorf("expected %s '%s', got '%s'", key, want, got)
+			}
+		}
+
+		response := map[string]interface{}{
+			"ip": map[string]interface{}{
+				"ip":               "123.123.123.123",
+				"server_ip":        "123.123.123.123",
+				"server_number":    321,
+				"locked":           false,
+				"traffic_warnings": true,
+				"traffic_hourly":   200,
+				"traffic_daily":    2000,
+				"traffic_monthly":  20,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	ip, err := client.IP.UpdateTrafficWarnings(ctx, net.ParseIP("123.123.123.123"), TrafficWarningSettings{
+		Enabled: true,
+		Hourly:  200,
+		Daily:   2000,
+	})
+	if err != nil {
+		t.Fatalf("IP.UpdateTrafficWarnings returned error: %v", err)
+	}
+	if !ip.TrafficWarnings || ip.TrafficHourly != 200 || ip.TrafficDaily != 2000 || ip.TrafficMonthly != 20 {
+		t.Errorf("unexpected traffic warning settings: %+v", ip)
+	}
+}
+
 func TestIPService_GetTraffic(t *testing.T) {
 	tests := []struct {
 		name        string
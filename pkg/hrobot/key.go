@@ -60,12 +60,28 @@ func (k *KeyService) List(ctx context.Context) ([]SSHKey, error) {
 func (k *KeyService) Get(ctx context.Context, fingerprint string) (*SSHKey, error) {
 	path := fmt.Sprintf("/key/%s", url.PathEscape(fingerprint))
 	var result SSHKey
-	if err := k.client.Get(ctx, path, &result); err != nil {
+	if err := k.client.GetWrapped(ctx, path, "key", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// GetByName retrieves the SSH key with the given name. The Robot API has no
+// lookup by name, so this lists all keys; it returns a NOT_FOUND API error
+// when no key has the name.
+func (k *KeyService) GetByName(ctx context.Context, name string) (*SSHKey, error) {
+	keys, err := k.List(ctx)
+	if err != nil && !IsAPIError(err, ErrNotFound) {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Name == name {
+			return &keys[i], nil
+		}
+	}
+	return nil, NewAPIError(ErrNotFound, fmt.Sprintf("SSH key with name '%s' not found", name))
+}
+
 // Create uploads a new SSH key.
 //
 // POST /key
@@ -78,7 +94,7 @@ func (k *KeyService) Create(ctx context.Context, name, data string) (*SSHKey, er
 	formData.Set("data", data)
 
 	var result SSHKey
-	if err := k.client.Post(ctx, path, formData, &result); err != nil {
+	if err := k.client.PostWrapped(ctx, path, formData, "key", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -95,7 +111,7 @@ func (k *KeyService) Rename(ctx context.Context, fingerprint, newName string) (*
 	formData.Set("name", newName)
 
 	var result SSHKey
-	if err := k.client.Post(ctx, path, formData, &result); err != nil {
+	if err := k.client.PostWrapped(ctx, path, formData, "key", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
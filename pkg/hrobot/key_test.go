@@ -6,6 +6,7 @@ package hrobot
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -123,6 +124,35 @@ func TestKeyService_Get(t *testing.T) {
 	}
 }
 
+func TestKeyService_GetByName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/key" {
+			t.Errorf("Expected path '/key', got '%s'", r.URL.Path)
+		}
+		fmt.Fprint(w, `[
+			{"key": {"name": "laptop", "fingerprint": "aa:bb", "type": "ED25519", "size": 256, "data": "ssh-ed25519 AAAA"}},
+			{"key": {"name": "desktop", "fingerprint": "cc:dd", "type": "ED25519", "size": 256, "data": "ssh-ed25519 BBBB"}}
+		]`)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	key, err := client.Key.GetByName(ctx, "desktop")
+	if err != nil {
+		t.Fatalf("Key.GetByName returned error: %v", err)
+	}
+	if key.Fingerprint != "cc:dd" {
+		t.Errorf("Expected fingerprint 'cc:dd', got '%s'", key.Fingerprint)
+	}
+
+	_, err = client.Key.GetByName(ctx, "missing")
+	if !IsAPIError(err, ErrNotFound) {
+		t.Errorf("Expected NOT_FOUND error for an unknown name, got %v", err)
+	}
+}
+
 func TestKeyService_Create(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/key" {
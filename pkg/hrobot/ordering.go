@@ -5,6 +5,7 @@ package hrobot
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -39,6 +40,8 @@ type ProductPrice struct {
 	Location   string           `json:"location"`
 	Price      ProductPriceInfo `json:"price"`
 	PriceSetup ProductPriceInfo `json:"price_setup"`
+	// Availability or lead time in this location. Empty when the API does not report it.
+	Availability string `json:"availability,omitempty"`
 }
 
 // ProductPriceInfo represents price details.
@@ -168,7 +171,26 @@ type MarketTransaction struct {
 // AddonTransaction represents an addon purchase transaction.
 type AddonTransaction struct {
 	Transaction
-	Product PurchasedAddon `json:"product"`
+	Product   PurchasedAddon  `json:"product"`
+	Resources []AddonResource `json:"resources"`
+}
+
+// AddonResource represents a resource (e.g. an IP address or subnet) assigned by an addon transaction.
+type AddonResource struct {
+	Type string `json:"type"` // "ip" or "subnet"
+	ID   string `json:"id"`
+}
+
+// AssignedIPs returns the IP addresses and subnets assigned by the transaction.
+// The result is empty while the transaction is still in process.
+func (t *AddonTransaction) AssignedIPs() []string {
+	var ips []string
+	for _, res := range t.Resources {
+		if res.Type == "ip" || res.Type == "subnet" {
+			ips = append(ips, res.ID)
+		}
+	}
+	return ips
 }
 
 // PurchasedMarketProduct represents a server purchased from the market.
@@ -191,6 +213,36 @@ type PurchasedMarketProduct struct {
 	NetworkSpeed *string  `json:"network_speed"`
 }
 
+// UnmarshalJSON accepts the product ID as a number, as auction transactions
+// return it, or as a string, as product transactions do.
+func (p *PurchasedMarketProduct) UnmarshalJSON(data []byte) error {
+	type Alias PurchasedMarketProduct
+	aux := &struct {
+		ID json.RawMessage `json:"id"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.ID = ""
+	if len(aux.ID) == 0 || string(aux.ID) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(aux.ID, &p.ID); err == nil {
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(aux.ID, &number); err != nil {
+		return fmt.Errorf("invalid product id %s: %w", aux.ID, err)
+	}
+	p.ID = number.String()
+	return nil
+}
+
 // PurchasedAddon represents an addon that was purchased.
 type PurchasedAddon struct {
 	ID   string `json:"id"`
@@ -244,7 +296,7 @@ func (o *OrderingService) PlaceMarketOrder(ctx context.Context, order MarketProd
 	}
 
 	var result MarketTransaction
-	if err := o.client.Post(ctx, path, formData, &result); err != nil {
+	if err := o.client.PostWrapped(ctx, path, formData, "transaction", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -302,7 +354,7 @@ func (o *OrderingService) PlaceProductOrder(ctx context.Context, order ProductOr
 	}
 
 	var result MarketTransaction
-	if err := o.client.Post(ctx, path, formData, &result); err != nil {
+	if err := o.client.PostWrapped(ctx, path, formData, "transaction", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -338,7 +390,7 @@ func (o *OrderingService) PlaceAddonOrder(ctx context.Context, order AddonOrder)
 	}
 
 	var result AddonTransaction
-	if err := o.client.Post(ctx, path, formData, &result); err != nil {
+	if err := o.client.PostWrapped(ctx, path, formData, "transaction", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -366,7 +418,21 @@ func (o *OrderingService) ListMarketTransactions(ctx context.Context) ([]MarketT
 func (o *OrderingService) GetMarketTransaction(ctx context.Context, transactionID string) (*MarketTransaction, error) {
 	path := fmt.Sprintf("/order/server_market/transaction/%s", url.PathEscape(transactionID))
 	var result MarketTransaction
-	if err := o.client.Get(ctx, path, &result); err != nil {
+	if err := o.client.GetWrapped(ctx, path, "transaction", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetProductTransaction retrieves a specific standard product transaction by ID.
+//
+// GET /order/server/transaction/{id}
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#get-order-server-transaction-id
+func (o *OrderingService) GetProductTransaction(ctx context.Context, transactionID string) (*MarketTransaction, error) {
+	path := fmt.Sprintf("/order/server/transaction/%s", url.PathEscape(transactionID))
+	var result MarketTransaction
+	if err := o.client.GetWrapped(ctx, path, "transaction", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -380,7 +446,7 @@ func (o *OrderingService) GetMarketTransaction(ctx context.Context, transactionI
 func (o *OrderingService) ListAddonTransactions(ctx context.Context) ([]AddonTransaction, error) {
 	path := "/order/server_addon/transaction"
 	var result []AddonTransaction
-	if err := o.client.GetWrappedList(ctx, path, "server_addon_transaction", &result); err != nil {
+	if err := o.client.GetWrappedList(ctx, path, "transaction", &result); err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -394,30 +460,68 @@ func (o *OrderingService) ListAddonTransactions(ctx context.Context) ([]AddonTra
 func (o *OrderingService) GetAddonTransaction(ctx context.Context, transactionID string) (*AddonTransaction, error) {
 	path := fmt.Sprintf("/order/server_addon/transaction/%s", url.PathEscape(transactionID))
 	var result AddonTransaction
-	if err := o.client.Get(ctx, path, &result); err != nil {
+	if err := o.client.GetWrapped(ctx, path, "transaction", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// MarketTransactionProgressFunc is called with the latest transaction state
+// and the time spent waiting after every status check.
+type MarketTransactionProgressFunc func(tx *MarketTransaction, elapsed time.Duration)
+
 // WaitForMarketTransactionCompletion polls the transaction status until it's completed or an error occurs.
 func (o *OrderingService) WaitForMarketTransactionCompletion(ctx context.Context, transactionID string, checkInterval time.Duration) (*MarketTransaction, error) {
+	return o.WaitForMarketTransactionCompletionWithProgress(ctx, transactionID, checkInterval, nil)
+}
+
+// WaitForMarketTransactionCompletionWithProgress works like
+// WaitForMarketTransactionCompletion and reports every status check to
+// progress, which may be nil.
+func (o *OrderingService) WaitForMarketTransactionCompletionWithProgress(ctx context.Context, transactionID string, checkInterval time.Duration, progress MarketTransactionProgressFunc) (*MarketTransaction, error) {
+	return waitForTransaction(ctx, func() (*MarketTransaction, error) {
+		return o.GetMarketTransaction(ctx, transactionID)
+	}, checkInterval, progress)
+}
+
+// WaitForProductTransactionCompletionWithProgress works like
+// WaitForMarketTransactionCompletionWithProgress for a standard product
+// order placed with PlaceProductOrder.
+func (o *OrderingService) WaitForProductTransactionCompletionWithProgress(ctx context.Context, transactionID string, checkInterval time.Duration, progress MarketTransactionProgressFunc) (*MarketTransaction, error) {
+	return waitForTransaction(ctx, func() (*MarketTransaction, error) {
+		return o.GetProductTransaction(ctx, transactionID)
+	}, checkInterval, progress)
+}
+
+// waitForTransaction polls get until the transaction is ready, cancelled or
+// failed.
+func waitForTransaction(ctx context.Context, get func() (*MarketTransaction, error), checkInterval time.Duration, progress MarketTransactionProgressFunc) (*MarketTransaction, error) {
 	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
-	// Initial check before first tick
-	tx, err := o.GetMarketTransaction(ctx, transactionID)
-	if err != nil {
-		return nil, err
-	}
+	start := time.Now()
+	check := func() (*MarketTransaction, bool, error) {
+		tx, err := get()
+		if err != nil {
+			return nil, true, err
+		}
 
-	fmt.Printf("[DEBUG] Transaction %s status: %s\n", transactionID, tx.Status)
+		if progress != nil {
+			progress(tx, time.Since(start))
+		}
+
+		switch tx.Status {
+		case "ready":
+			return tx, true, nil
+		case "cancelled", "error":
+			return tx, true, fmt.Errorf("transaction %s: %s", tx.Status, tx.Status)
+		}
+		return tx, false, nil
+	}
 
-	switch tx.Status {
-	case "ready":
-		return tx, nil
-	case "cancelled", "error":
-		return tx, fmt.Errorf("transaction %s: %s", tx.Status, tx.Status)
+	// Initial check before first tick
+	if tx, done, err := check(); done {
+		return tx, err
 	}
 
 	for {
@@ -425,18 +529,8 @@ func (o *OrderingService) WaitForMarketTransactionCompletion(ctx context.Context
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case <-ticker.C:
-			tx, err := o.GetMarketTransaction(ctx, transactionID)
-			if err != nil {
-				return nil, err
-			}
-
-			fmt.Printf("[DEBUG] Transaction %s status: %s\n", transactionID, tx.Status)
-
-			switch tx.Status {
-			case "ready":
-				return tx, nil
-			case "cancelled", "error":
-				return tx, fmt.Errorf("transaction %s: %s", tx.Status, tx.Status)
+			if tx, done, err := check(); done {
+				return tx, err
 			}
 			// Otherwise keep waiting
 		}
@@ -465,7 +559,7 @@ func (o *OrderingService) ListProducts(ctx context.Context) ([]Product, error) {
 func (o *OrderingService) GetProduct(ctx context.Context, productID string) (*Product, error) {
 	path := fmt.Sprintf("/order/server/product/%s", productID)
 	var result Product
-	if err := o.client.Get(ctx, path, &result); err != nil {
+	if err := o.client.GetWrapped(ctx, path, "product", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
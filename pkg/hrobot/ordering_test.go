@@ -0,0 +1,302 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hrobot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOrderingService_ListAddonTransactions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/order/server_addon/transaction" {
+			t.Errorf("expected path '/order/server_addon/transaction', got '%s'", r.URL.Path)
+		}
+		if r.Method != "GET" {
+			t.Errorf("expected GET request, got '%s'", r.Method)
+		}
+
+		response := []map[string]interface{}{
+			{
+				"transaction": map[string]interface{}{
+					"id":            "B20150121-344957-251478",
+					"date":          "2015-01-21T12:30:43+01:00",
+					"status":        "ready",
+					"server_number": 123,
+					"server_ip":     "123.123.123.123",
+					"product": map[string]interface{}{
+						"id":   "failover_subnet_ipv4_29",
+						"name": "Failover subnet /29",
+					},
+					"resources": []map[string]interface{}{
+						{"type": "subnet", "id": "123.123.124.0"},
+					},
+				},
+			},
+			{
+				"transaction": map[string]interface{}{
+					"id":            "B20150121-344957-251479",
+					"date":          "2015-01-22T08:00:00+01:00",
+					"status":        "in process",
+					"server_number": 123,
+					"server_ip":     "123.123.123.123",
+					"product": map[string]interface{}{
+						"id":   "additional_ipv4",
+						"name": "Additional IP address",
+					},
+					"resources": []map[string]interface{}{},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	transactions, err := client.Ordering.ListAddonTransactions(ctx)
+	if err != nil {
+		t.Fatalf("Ordering.ListAddonTransactions returned error: %v", err)
+	}
+
+	if len(transactions) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(transactions))
+	}
+
+	tx := transactions[0]
+	if tx.ID != "B20150121-344957-251478" {
+		t.Errorf("expected ID 'B20150121-344957-251478', got '%s'", tx.ID)
+	}
+	if tx.Status != "ready" {
+		t.Errorf("expected status 'ready', got '%s'", tx.Status)
+	}
+	if tx.ServerNumber == nil || *tx.ServerNumber != 123 {
+		t.Errorf("expected server number 123, got %v", tx.ServerNumber)
+	}
+	if tx.Product.ID != "failover_subnet_ipv4_29" {
+		t.Errorf("expected product ID 'failover_subnet_ipv4_29', got '%s'", tx.Product.ID)
+	}
+	if tx.Date.Year() != 2015 || tx.Date.Month() != 1 || tx.Date.Day() != 21 {
+		t.Errorf("expected date 2015-01-21, got %v", tx.Date)
+	}
+
+	assigned := tx.AssignedIPs()
+	if len(assigned) != 1 || assigned[0] != "123.123.124.0" {
+		t.Errorf("expected assigned subnet '123.123.124.0', got %v", assigned)
+	}
+
+	if len(transactions[1].AssignedIPs()) != 0 {
+		t.Errorf("expected no assigned IPs for in-process transaction, got %v", transactions[1].AssignedIPs())
+	}
+}
+
+func TestOrderingService_GetAddonTransaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/order/server_addon/transaction/B20150121-344957-251478" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+		if r.Method != "GET" {
+			t.Errorf("expected GET request, got '%s'", r.Method)
+		}
+
+		response := map[string]interface{}{
+			"transaction": map[string]interface{}{
+				"id":            "B20150121-344957-251478",
+				"date":          "2015-01-21T12:30:43+01:00",
+				"status":        "ready",
+				"server_number": 123,
+				"server_ip":     "123.123.123.123",
+				"product": map[string]interface{}{
+					"id":   "additional_ipv4",
+					"name": "Additional IP address",
+				},
+				"resources": []map[string]interface{}{
+					{"type": "ip", "id": "123.123.123.124"},
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	tx, err := client.Ordering.GetAddonTransaction(ctx, "B20150121-344957-251478")
+	if err != nil {
+		t.Fatalf("Ordering.GetAddonTransaction returned error: %v", err)
+	}
+
+	if tx.Product.Name != "Additional IP address" {
+		t.Errorf("expected product name 'Additional IP address', got '%s'", tx.Product.Name)
+	}
+	if tx.ServerIP == nil || *tx.ServerIP != "123.123.123.123" {
+		t.Errorf("expected server IP '123.123.123.123', got %v", tx.ServerIP)
+	}
+	if len(tx.Resources) != 1 || tx.Resources[0].Type != "ip" {
+		t.Fatalf("expected one 'ip' resource, got %v", tx.Resources)
+	}
+
+	assigned := tx.AssignedIPs()
+	if len(assigned) != 1 || assigned[0] != "123.123.123.124" {
+		t.Errorf("expected assigned IP '123.123.123.124', got %v", assigned)
+	}
+}
+
+func TestOrderingService_WaitForMarketTransactionCompletionWithProgress(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		status := "in process"
+		if calls > 1 {
+			status = "ready"
+		}
+		response := map[string]interface{}{
+			"transaction": map[string]interface{}{
+				"id":     "B20150121-344958-251479",
+				"date":   "2015-01-21T12:30:43+01:00",
+				"status": status,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	var statuses []string
+	tx, err := client.Ordering.WaitForMarketTransactionCompletionWithProgress(ctx, "B20150121-344958-251479", 10*time.Millisecond,
+		func(tx *MarketTransaction, elapsed time.Duration) {
+			statuses = append(statuses, tx.Status)
+		})
+	if err != nil {
+		t.Fatalf("Ordering.WaitForMarketTransactionCompletionWithProgress returned error: %v", err)
+	}
+
+	if tx.Status != "ready" {
+		t.Errorf("expected status 'ready', got '%s'", tx.Status)
+	}
+	if len(statuses) != 2 || statuses[0] != "in process" || statuses[1] != "ready" {
+		t.Errorf("expected progress for 'in process' and 'ready', got %v", statuses)
+	}
+}
+
+func TestOrderingService_WaitForProductTransactionCompletionWithProgress(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/order/server/transaction/B20150121-344958-251480" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		calls++
+		status := "in process"
+		if calls > 1 {
+			status = "ready"
+		}
+		response := map[string]interface{}{
+			"transaction": map[string]interface{}{
+				"id":            "B20150121-344958-251480",
+				"date":          "2015-01-21T12:30:43+01:00",
+				"status":        status,
+				"server_number": 321,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	tx, err := client.Ordering.WaitForProductTransactionCompletionWithProgress(context.Background(), "B20150121-344958-251480", 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("Ordering.WaitForProductTransactionCompletionWithProgress returned error: %v", err)
+	}
+	if tx.Status != "ready" || tx.ServerNumber == nil || *tx.ServerNumber != 321 {
+		t.Errorf("expected ready transaction for server 321, got %+v", tx)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 status checks, got %d", calls)
+	}
+}
+
+func TestOrderingService_PlaceMarketOrder_TestMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/order/server_market/transaction" || r.Method != "POST" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if r.PostForm.Get("test") != "true" {
+			t.Errorf("expected test=true, got %q", r.PostForm.Get("test"))
+		}
+		// A test order is validated but not processed, so there is no server yet.
+		fmt.Fprint(w, `{"transaction": {
+			"id": "B20150121-344958-251479",
+			"date": "2015-01-21T12:30:43+01:00",
+			"status": "in process",
+			"server_number": null,
+			"server_ip": null,
+			"authorized_key": [],
+			"host_key": [],
+			"comment": null,
+			"product": {"id": 283693, "name": "SB110", "dist": "Rescue system", "arch": 64, "lang": "en", "datacenter": null},
+			"addons": []
+		}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	tx, err := client.Ordering.PlaceMarketOrder(context.Background(), MarketProductOrder{
+		ProductID: 283693,
+		Auth:      AuthorizationMethod{Keys: []string{"aa:bb"}},
+		Test:      true,
+	})
+	if err != nil {
+		t.Fatalf("Ordering.PlaceMarketOrder returned error: %v", err)
+	}
+	if tx.ServerNumber != nil || tx.ServerIP != nil {
+		t.Errorf("expected no server for a test order, got %v / %v", tx.ServerNumber, tx.ServerIP)
+	}
+	if tx.Product.ID != "283693" || tx.Product.Name != "SB110" {
+		t.Errorf("expected product 283693 (SB110), got %q (%q)", tx.Product.ID, tx.Product.Name)
+	}
+}
+
+func TestPurchasedMarketProduct_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"id": 283693}`, "283693"},
+		{`{"id": "EX44"}`, "EX44"},
+		{`{"id": null}`, ""},
+		{`{}`, ""},
+	}
+
+	for _, tt := range tests {
+		var product PurchasedMarketProduct
+		if err := json.Unmarshal([]byte(tt.input), &product); err != nil {
+			t.Fatalf("failed to unmarshal %s: %v", tt.input, err)
+		}
+		if product.ID != tt.want {
+			t.Errorf("%s: expected ID %q, got %q", tt.input, tt.want, product.ID)
+		}
+	}
+}
@@ -45,7 +45,7 @@ func (r *RDNSService) List(ctx context.Context, serverIP string) ([]RDNS, error)
 	}
 
 	var result []RDNSListItem
-	if err := r.client.Get(ctx, path, &result); err != nil {
+	if err := r.client.GetWrapped(ctx, path, "", &result); err != nil {
 		return nil, err
 	}
 
@@ -67,7 +67,7 @@ func (r *RDNSService) Get(ctx context.Context, ip string) (*RDNS, error) {
 	path := fmt.Sprintf("/rdns/%s", url.PathEscape(ip))
 
 	var result RDNS
-	if err := r.client.Get(ctx, path, &result); err != nil {
+	if err := r.client.GetWrapped(ctx, path, "rdns", &result); err != nil {
 		return nil, err
 	}
 
@@ -87,7 +87,7 @@ func (r *RDNSService) Create(ctx context.Context, ip, ptr string) (*RDNS, error)
 	formData.Set("ptr", ptr)
 
 	var result RDNS
-	if err := r.client.Put(ctx, path, formData, &result); err != nil {
+	if err := r.client.PutWrapped(ctx, path, formData, "rdns", &result); err != nil {
 		return nil, err
 	}
 
@@ -107,7 +107,7 @@ func (r *RDNSService) Update(ctx context.Context, ip, ptr string) (*RDNS, error)
 	formData.Set("ptr", ptr)
 
 	var result RDNS
-	if err := r.client.Post(ctx, path, formData, &result); err != nil {
+	if err := r.client.PostWrapped(ctx, path, formData, "rdns", &result); err != nil {
 		return nil, err
 	}
 
@@ -23,7 +23,7 @@ func NewResetService(client *Client) *ResetService {
 func (r *ResetService) Get(ctx context.Context, serverID ServerID) (*Reset, error) {
 	var reset Reset
 	path := fmt.Sprintf("/reset/%s", serverID.String())
-	err := r.client.Get(ctx, path, &reset)
+	err := r.client.GetWrapped(ctx, path, "reset", &reset)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +38,7 @@ func (r *ResetService) Execute(ctx context.Context, serverID ServerID, resetType
 	data := url.Values{}
 	data.Set("type", string(resetType))
 
-	err := r.client.Post(ctx, path, data, &reset)
+	err := r.client.PostWrapped(ctx, path, data, "reset", &reset)
 	if err != nil {
 		return nil, err
 	}
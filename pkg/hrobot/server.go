@@ -6,6 +6,8 @@ package hrobot
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // ServerService handles server-related API operations.
@@ -28,17 +30,148 @@ func (s *ServerService) List(ctx context.Context) ([]Server, error) {
 	return servers, nil
 }
 
+// ServerListOptions selects servers for ListFiltered. Empty fields match
+// every server; set fields must all match.
+type ServerListOptions struct {
+	Status       ServerStatus // Exact status, e.g. ServerStatusReady
+	Datacenter   string       // Datacenter ("FSN1-DC14") or location ("FSN1"), ignoring case
+	NameContains string       // Substring of the server name, ignoring case
+}
+
+// Matches reports whether the server satisfies the options.
+func (o ServerListOptions) Matches(server *Server) bool {
+	if o.Status != "" && server.Status != o.Status {
+		return false
+	}
+	if o.Datacenter != "" && !strings.EqualFold(server.DC, o.Datacenter) &&
+		!strings.HasPrefix(strings.ToUpper(server.DC), strings.ToUpper(o.Datacenter)+"-") {
+		return false
+	}
+	if o.NameContains != "" && !strings.Contains(strings.ToLower(server.ServerName), strings.ToLower(o.NameContains)) {
+		return false
+	}
+	return true
+}
+
+// ListFiltered returns the servers matching opts, in the order of List.
+// The Robot API has no server filters, so this lists all servers and
+// filters locally.
+func (s *ServerService) ListFiltered(ctx context.Context, opts ServerListOptions) ([]Server, error) {
+	servers, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]Server, 0, len(servers))
+	for i := range servers {
+		if opts.Matches(&servers[i]) {
+			filtered = append(filtered, servers[i])
+		}
+	}
+	return filtered, nil
+}
+
 // Get returns details for a specific server.
 func (s *ServerService) Get(ctx context.Context, serverID ServerID) (*Server, error) {
 	var server Server
 	path := fmt.Sprintf("/server/%s", serverID.String())
-	err := s.client.Get(ctx, path, &server)
+	err := s.client.GetWrapped(ctx, path, "server", &server)
 	if err != nil {
 		return nil, err
 	}
 	return &server, nil
 }
 
+// ServersNamed returns the servers whose display name is exactly name, in the
+// order of servers. Names are not unique, so there may be more than one.
+func ServersNamed(servers []Server, name string) []Server {
+	var matches []Server
+	for _, server := range servers {
+		if server.ServerName == name {
+			matches = append(matches, server)
+		}
+	}
+	return matches
+}
+
+// GetByName returns the server with the given display name.
+// The Robot API has no name filter, so this lists all servers and matches locally.
+// Returns a SERVER_NOT_FOUND error if no server matches and a SERVER_NAME_AMBIGUOUS
+// error if more than one server carries the name.
+func (s *ServerService) GetByName(ctx context.Context, name string) (*Server, error) {
+	servers, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := ServersNamed(servers, name)
+	switch len(matches) {
+	case 0:
+		return nil, NewAPIError(ErrServerNotFound, fmt.Sprintf("no server named %q", name))
+	case 1:
+		return &matches[0], nil
+	default:
+		numbers := make([]string, len(matches))
+		for i, server := range matches {
+			numbers[i] = strconv.Itoa(server.ServerNumber)
+		}
+		return nil, NewAPIError(ErrServerNameAmbiguous, fmt.Sprintf("%d servers are named %q: %s", len(matches), name, strings.Join(numbers, ", ")))
+	}
+}
+
+// ListIPs returns the single IP addresses assigned to a server, including
+// the main IP. The IP list is fetched for the account and filtered by server
+// number, so servers without a main IPv4 are covered as well. A server
+// without IPs yields an empty list.
+//
+// GET /ip
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#get-ip
+func (s *ServerService) ListIPs(ctx context.Context, serverID ServerID) ([]IPAddress, error) {
+	var all []IPAddress
+	err := s.client.GetWrappedList(ctx, "/ip", "ip", &all)
+	if err != nil {
+		if IsAPIError(err, ErrNotFound) || IsAPIError(err, ErrIPNotFound) {
+			return []IPAddress{}, nil
+		}
+		return nil, err
+	}
+
+	ips := []IPAddress{}
+	for _, ip := range all {
+		if ip.ServerNumber == int(serverID) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+// ListSubnets returns the additional subnets assigned to a server. Like
+// ListIPs, the account's subnets are filtered by server number. A server
+// without subnets yields an empty list.
+//
+// GET /subnet
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#get-subnet
+func (s *ServerService) ListSubnets(ctx context.Context, serverID ServerID) ([]ServerSubnet, error) {
+	var all []ServerSubnet
+	err := s.client.GetWrappedList(ctx, "/subnet", "subnet", &all)
+	if err != nil {
+		if IsAPIError(err, ErrNotFound) {
+			return []ServerSubnet{}, nil
+		}
+		return nil, err
+	}
+
+	subnets := []ServerSubnet{}
+	for _, subnet := range all {
+		if subnet.ServerNumber == int(serverID) {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets, nil
+}
+
 // SetName sets the name for a server.
 func (s *ServerService) SetName(ctx context.Context, serverID ServerID, name string) (*Server, error) {
 	var server Server
@@ -47,7 +180,7 @@ func (s *ServerService) SetName(ctx context.Context, serverID ServerID, name str
 	data := make(map[string]string)
 	data["server_name"] = name
 
-	err := s.client.Post(ctx, path, encodeForm(data), &server)
+	err := s.client.PostWrapped(ctx, path, encodeForm(data), "server", &server)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +204,7 @@ func (s *ServerService) RequestCancellation(ctx context.Context, req Cancellatio
 		data["cancellation_reason"] = req.CancellationReason
 	}
 
-	return s.client.Post(ctx, path, encodeForm(data), nil)
+	return s.client.PostWrapped(ctx, path, encodeForm(data), "", nil)
 }
 
 // WithdrawCancellation withdraws a server cancellation request.
@@ -6,8 +6,10 @@ package hrobot
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -349,3 +351,261 @@ func TestServerService_ErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func newServerListTestServer(t *testing.T, names ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/server" {
+			t.Errorf("expected path '/server', got '%s'", r.URL.Path)
+		}
+
+		response := make([]map[string]interface{}, len(names))
+		for i, name := range names {
+			response[i] = map[string]interface{}{
+				"server": map[string]interface{}{
+					"server_ip":     fmt.Sprintf("123.123.123.%d", i+1),
+					"server_number": 100 + i,
+					"server_name":   name,
+					"product":       "EX41",
+					"dc":            "FSN1-DC5",
+					"traffic":       "unlimited",
+					"status":        "ready",
+					"cancelled":     false,
+					"paid_until":    "2024-12-31",
+				},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+}
+
+func TestServerService_GetByName(t *testing.T) {
+	server := newServerListTestServer(t, "web1", "db1", "web2")
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	result, err := client.Server.GetByName(context.Background(), "db1")
+	if err != nil {
+		t.Fatalf("Server.GetByName returned error: %v", err)
+	}
+	if result.ServerNumber != 101 {
+		t.Errorf("expected server number 101, got %d", result.ServerNumber)
+	}
+}
+
+func TestServerService_GetByName_NotFound(t *testing.T) {
+	server := newServerListTestServer(t, "web1", "db1")
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	_, err := client.Server.GetByName(context.Background(), "web")
+	if err == nil {
+		t.Fatal("expected error for unknown server name, got nil")
+	}
+	if !IsNotFoundError(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestServerService_GetByName_Ambiguous(t *testing.T) {
+	server := newServerListTestServer(t, "web", "db1", "web")
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	_, err := client.Server.GetByName(context.Background(), "web")
+	if err == nil {
+		t.Fatal("expected error for ambiguous server name, got nil")
+	}
+	if !IsAPIError(err, ErrServerNameAmbiguous) {
+		t.Errorf("expected ambiguous name error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "100") || !strings.Contains(err.Error(), "102") {
+		t.Errorf("expected error to list the matching server numbers, got: %v", err)
+	}
+}
+
+func TestServerService_ListIPs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ip" {
+			t.Errorf("expected path '/ip', got '%s'", r.URL.Path)
+		}
+		if r.Method != "GET" {
+			t.Errorf("expected GET request, got '%s'", r.Method)
+		}
+
+		response := []map[string]interface{}{
+			{"ip": map[string]interface{}{"ip": "123.123.123.123", "server_ip": "123.123.123.123", "server_number": 321, "locked": false, "separate_mac": nil, "traffic_warnings": false}},
+			{"ip": map[string]interface{}{"ip": "123.123.123.124", "server_ip": "123.123.123.123", "server_number": 321, "locked": false, "separate_mac": "00:50:56:00:00:01", "traffic_warnings": true}},
+			{"ip": map[string]interface{}{"ip": "10.0.0.1", "server_ip": "10.0.0.1", "server_number": 999, "locked": false}},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	ips, err := client.Server.ListIPs(ctx, ServerID(321))
+	if err != nil {
+		t.Fatalf("Server.ListIPs returned error: %v", err)
+	}
+
+	if len(ips) != 2 {
+		t.Fatalf("expected 2 IPs for server 321, got %d", len(ips))
+	}
+	if ips[1].IP.String() != "123.123.123.124" {
+		t.Errorf("expected IP '123.123.123.124', got '%s'", ips[1].IP)
+	}
+	if ips[1].SeparateMac != "00:50:56:00:00:01" {
+		t.Errorf("expected separate MAC '00:50:56:00:00:01', got '%s'", ips[1].SeparateMac)
+	}
+}
+
+func TestServerService_ListSubnets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subnet" {
+			t.Errorf("expected path '/subnet', got '%s'", r.URL.Path)
+		}
+
+		response := []map[string]interface{}{
+			{"subnet": map[string]interface{}{"ip": "2a01:4f8:111:4221::", "mask": 64, "gateway": "fe80::1", "server_ip": "123.123.123.123", "server_number": 321, "failover": false, "locked": false}},
+			{"subnet": map[string]interface{}{"ip": "123.123.124.0", "mask": 29, "gateway": "123.123.124.1", "server_ip": "123.123.123.123", "server_number": 321, "failover": true, "locked": false}},
+			{"subnet": map[string]interface{}{"ip": "10.0.1.0", "mask": 29, "gateway": "10.0.1.1", "server_ip": "10.0.0.1", "server_number": 999}},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	subnets, err := client.Server.ListSubnets(ctx, ServerID(321))
+	if err != nil {
+		t.Fatalf("Server.ListSubnets returned error: %v", err)
+	}
+
+	if len(subnets) != 2 {
+		t.Fatalf("expected 2 subnets for server 321, got %d", len(subnets))
+	}
+	if subnets[0].String() != "2a01:4f8:111:4221::/64" {
+		t.Errorf("expected subnet '2a01:4f8:111:4221::/64', got '%s'", subnets[0].String())
+	}
+	if !subnets[1].Failover {
+		t.Error("expected second subnet to be a failover subnet")
+	}
+	if subnets[1].Gateway.String() != "123.123.124.1" {
+		t.Errorf("expected gateway '123.123.124.1', got '%s'", subnets[1].Gateway)
+	}
+}
+
+func TestServerService_ListSubnets_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"status":404,"code":"NOT_FOUND","message":"Subnet not found"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	subnets, err := client.Server.ListSubnets(context.Background(), ServerID(321))
+	if err != nil {
+		t.Fatalf("Server.ListSubnets returned error: %v", err)
+	}
+	if len(subnets) != 0 {
+		t.Errorf("expected no subnets, got %d", len(subnets))
+	}
+}
+
+func TestServerService_ListFiltered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/server" {
+			t.Errorf("expected path '/server', got '%s'", r.URL.Path)
+		}
+
+		servers := []struct {
+			name   string
+			dc     string
+			status string
+		}{
+			{"web-1", "FSN1-DC14", "ready"},
+			{"web-2", "HEL1-DC2", "ready"},
+			{"DB-1", "FSN1-DC8", "in process"},
+			{"backup", "FSN1-DC14", "ready"},
+		}
+		response := make([]map[string]interface{}, len(servers))
+		for i, s := range servers {
+			response[i] = map[string]interface{}{
+				"server": map[string]interface{}{
+					"server_number": 100 + i,
+					"server_name":   s.name,
+					"dc":            s.dc,
+					"status":        s.status,
+				},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	tests := []struct {
+		name string
+		opts ServerListOptions
+		want []int
+	}{
+		{name: "no options", opts: ServerListOptions{}, want: []int{100, 101, 102, 103}},
+		{name: "status", opts: ServerListOptions{Status: ServerStatusInProcess}, want: []int{102}},
+		{name: "location", opts: ServerListOptions{Datacenter: "fsn1"}, want: []int{100, 102, 103}},
+		{name: "datacenter", opts: ServerListOptions{Datacenter: "FSN1-DC14"}, want: []int{100, 103}},
+		{name: "partial location", opts: ServerListOptions{Datacenter: "FSN"}, want: []int{}},
+		{name: "name substring", opts: ServerListOptions{NameContains: "web"}, want: []int{100, 101}},
+		{name: "name ignores case", opts: ServerListOptions{NameContains: "db"}, want: []int{102}},
+		{name: "status and datacenter", opts: ServerListOptions{Status: ServerStatusReady, Datacenter: "FSN1"}, want: []int{100, 103}},
+		{name: "all options", opts: ServerListOptions{Status: ServerStatusReady, Datacenter: "FSN1", NameContains: "web"}, want: []int{100}},
+		{name: "no match", opts: ServerListOptions{Status: ServerStatusCancelled}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers, err := client.Server.ListFiltered(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("Server.ListFiltered returned error: %v", err)
+			}
+
+			got := make([]int, len(servers))
+			for i, s := range servers {
+				got[i] = s.ServerNumber
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected servers %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestServersNamed(t *testing.T) {
+	servers := []Server{
+		{ServerNumber: 100, ServerName: "web"},
+		{ServerNumber: 101, ServerName: "web1"},
+		{ServerNumber: 102, ServerName: "web"},
+	}
+
+	matches := ServersNamed(servers, "web")
+	if len(matches) != 2 || matches[0].ServerNumber != 100 || matches[1].ServerNumber != 102 {
+		t.Errorf("expected servers 100 and 102, got %+v", matches)
+	}
+	if matches := ServersNamed(servers, "Web"); len(matches) != 0 {
+		t.Errorf("expected names to be matched exactly, got %+v", matches)
+	}
+}
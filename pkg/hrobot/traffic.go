@@ -4,8 +4,12 @@
 package hrobot
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/url"
+	"time"
 )
 
 // TrafficService provides access to traffic related functions in the Hetzner Robot API.
@@ -35,6 +39,47 @@ type ServerTrafficData struct {
 	Data map[string]map[string]TrafficStats `json:"data"` // IP -> Date -> Traffic
 }
 
+// UnmarshalJSON decodes traffic data. The API returns an empty array instead
+// of an object for data, or for the values of an IP, when there is no traffic
+// in the period.
+func (d *ServerTrafficData) UnmarshalJSON(b []byte) error {
+	type alias ServerTrafficData
+	var raw struct {
+		alias
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*d = ServerTrafficData(raw.alias)
+	d.Data = map[string]map[string]TrafficStats{}
+	if isEmptyJSON(raw.Data) {
+		return nil
+	}
+
+	var perIP map[string]json.RawMessage
+	if err := json.Unmarshal(raw.Data, &perIP); err != nil {
+		return err
+	}
+	for ip, values := range perIP {
+		stats := map[string]TrafficStats{}
+		if !isEmptyJSON(values) {
+			if err := json.Unmarshal(values, &stats); err != nil {
+				return err
+			}
+		}
+		d.Data[ip] = stats
+	}
+	return nil
+}
+
+// isEmptyJSON reports whether data is absent, null or an empty array.
+func isEmptyJSON(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) == 0 || bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte("[]"))
+}
+
 // TrafficStats represents traffic statistics for a specific time period.
 type TrafficStats struct {
 	In  float64 `json:"in"`  // Incoming traffic in GB
@@ -78,9 +123,62 @@ func (t *TrafficService) Get(ctx context.Context, params TrafficGetParams) (*Ser
 
 	// Try parsing directly as ServerTrafficData (without wrapper)
 	var result ServerTrafficData
-	if err := t.client.Post(ctx, path, formData, &result); err != nil {
+	if err := t.client.PostWrapped(ctx, path, formData, "traffic", &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
+
+// MonthlyTraffic is the traffic of an IP aggregated over one calendar month.
+type MonthlyTraffic struct {
+	IP    string  `json:"ip"`
+	Month string  `json:"month"` // YYYY-MM
+	Days  int     `json:"days"`  // Number of days with traffic data
+	In    float64 `json:"in"`    // Incoming traffic in GB
+	Out   float64 `json:"out"`   // Outgoing traffic in GB
+	Sum   float64 `json:"sum"`   // Total traffic in GB
+}
+
+// GetMonthly retrieves the traffic of an IP for a calendar month, summed over
+// the daily values. A month without data returns zero totals. For the current
+// month the period ends today.
+//
+// POST /traffic
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#get-traffic
+func (t *TrafficService) GetMonthly(ctx context.Context, ip string, year int, month time.Month) (*MonthlyTraffic, error) {
+	if month < time.January || month > time.December {
+		return nil, fmt.Errorf("invalid month: %d", month)
+	}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	if today := time.Now().UTC().Truncate(24 * time.Hour); last.After(today) {
+		last = today
+	}
+	if last.Before(first) {
+		return nil, fmt.Errorf("month %s is in the future", first.Format("2006-01"))
+	}
+
+	data, err := t.Get(ctx, TrafficGetParams{
+		Type:         TrafficTypeMonth,
+		From:         first.Format("2006-01-02"),
+		To:           last.Format("2006-01-02"),
+		IP:           ip,
+		SingleValues: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MonthlyTraffic{IP: ip, Month: first.Format("2006-01")}
+	for _, stats := range data.Data[ip] {
+		result.Days++
+		result.In += stats.In
+		result.Out += stats.Out
+		result.Sum += stats.Sum
+	}
+
+	return result, nil
+}
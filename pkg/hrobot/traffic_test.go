@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hrobot
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTrafficService_GetMonthly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/traffic" {
+			t.Errorf("expected path '/traffic', got '%s'", r.URL.Path)
+		}
+		if r.Method != "POST" {
+			t.Errorf("expected POST request, got '%s'", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+
+		expected := map[string]string{
+			"type":          "month",
+			"from":          "2024-02-01",
+			"to":            "2024-02-29",
+			"ip":            "123.123.123.123",
+			"single_values": "true",
+		}
+		for key, value := range expected {
+			if got := r.PostForm.Get(key); got != value {
+				t.Errorf("expected %s '%s', got '%s'", key, value, got)
+			}
+		}
+
+		_, _ = w.Write([]byte(`{
+			"traffic": {
+				"type": "month",
+				"from": "2024-02-01",
+				"to": "2024-02-29",
+				"data": {
+					"123.123.123.123": {
+						"01": {"in": 1.5, "out": 0.5, "sum": 2},
+						"02": {"in": 2.25, "out": 0.75, "sum": 3},
+						"29": {"in": 0.25, "out": 0.25, "sum": 0.5}
+					}
+				}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+	traffic, err := client.Traffic.GetMonthly(context.Background(), "123.123.123.123", 2024, time.February)
+	if err != nil {
+		t.Fatalf("Traffic.GetMonthly returned error: %v", err)
+	}
+
+	if traffic.Month != "2024-02" {
+		t.Errorf("expected month '2024-02', got '%s'", traffic.Month)
+	}
+	if traffic.Days != 3 {
+		t.Errorf("expected 3 days, got %d", traffic.Days)
+	}
+	if math.Abs(traffic.In-4) > 1e-9 || math.Abs(traffic.Out-1.5) > 1e-9 || math.Abs(traffic.Sum-5.5) > 1e-9 {
+		t.Errorf("expected in 4, out 1.5, sum 5.5, got in %v, out %v, sum %v", traffic.In, traffic.Out, traffic.Sum)
+	}
+}
+
+func TestTrafficService_GetMonthly_NoData(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{name: "empty data array", response: `{"traffic": {"type": "month", "from": "2023-01-01", "to": "2023-01-31", "data": []}}`},
+		{name: "empty ip values", response: `{"traffic": {"type": "month", "from": "2023-01-01", "to": "2023-01-31", "data": {"123.123.123.123": []}}}`},
+		{name: "empty data object", response: `{"traffic": {"type": "month", "from": "2023-01-01", "to": "2023-01-31", "data": {}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+
+			traffic, err := client.Traffic.GetMonthly(context.Background(), "123.123.123.123", 2023, time.January)
+			if err != nil {
+				t.Fatalf("Traffic.GetMonthly returned error: %v", err)
+			}
+			if traffic.Days != 0 || traffic.Sum != 0 {
+				t.Errorf("expected no traffic, got %+v", traffic)
+			}
+		})
+	}
+}
+
+func TestTrafficService_GetMonthly_InvalidMonth(t *testing.T) {
+	client := NewClient("test-user", "test-pass")
+
+	if _, err := client.Traffic.GetMonthly(context.Background(), "123.123.123.123", 2024, 13); err == nil {
+		t.Error("expected error for month 13")
+	}
+	if _, err := client.Traffic.GetMonthly(context.Background(), "123.123.123.123", time.Now().Year()+1, time.January); err == nil {
+		t.Error("expected error for a month in the future")
+	}
+}
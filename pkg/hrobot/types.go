@@ -39,6 +39,38 @@ const (
 	ProtocolGRE  Protocol = "gre"
 )
 
+// ParseAction converts a string such as "accept" to an Action. Case and
+// surrounding blanks are ignored.
+func ParseAction(s string) (Action, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(s))); action {
+	case ActionAccept, ActionDiscard:
+		return action, nil
+	}
+	return "", fmt.Errorf("action must be 'accept' or 'discard', got %q", s)
+}
+
+// ParseProtocol converts a string such as "tcp" to a Protocol. Case and
+// surrounding blanks are ignored. An empty string is not a protocol; rules
+// without a protocol match all protocols.
+func ParseProtocol(s string) (Protocol, error) {
+	switch protocol := Protocol(strings.ToLower(strings.TrimSpace(s))); protocol {
+	case ProtocolTCP, ProtocolUDP, ProtocolICMP, ProtocolESP, ProtocolGRE:
+		return protocol, nil
+	}
+	return "", fmt.Errorf("protocol must be one of: tcp, udp, icmp, esp, gre, got %q", s)
+}
+
+// HasPorts reports whether rules for the protocol can filter on ports. Only
+// TCP and UDP have ports; an empty protocol matches all protocols and is not
+// restricted.
+func (p Protocol) HasPorts() bool {
+	switch p {
+	case "", ProtocolTCP, ProtocolUDP:
+		return true
+	}
+	return false
+}
+
 // ServerID represents a server identifier.
 type ServerID int
 
@@ -59,6 +91,26 @@ type IPAddress struct {
 	TrafficMonthly  int    `json:"traffic_monthly"`
 }
 
+// ServerSubnet represents an additional subnet with its metadata.
+type ServerSubnet struct {
+	IP              net.IP `json:"ip"`
+	Mask            int    `json:"mask"`
+	Gateway         net.IP `json:"gateway"`
+	ServerIP        net.IP `json:"server_ip"`
+	ServerNumber    int    `json:"server_number"`
+	Failover        bool   `json:"failover"`
+	Locked          bool   `json:"locked"`
+	TrafficWarnings bool   `json:"traffic_warnings"`
+	TrafficHourly   int    `json:"traffic_hourly"`
+	TrafficDaily    int    `json:"traffic_daily"`
+	TrafficMonthly  int    `json:"traffic_monthly"`
+}
+
+// String returns the subnet in CIDR notation.
+func (s ServerSubnet) String() string {
+	return fmt.Sprintf("%s/%d", s.IP.String(), s.Mask)
+}
+
 // ServerStatus represents the status of a server.
 type ServerStatus string
 
@@ -155,27 +207,39 @@ func init() {
 	}
 }
 
-// UnmarshalJSON parses timestamp and converts to Berlin time.
+// berlinTimeFormats are the timestamp formats of the Hetzner API. Timestamps
+// without a zone are in Berlin time.
+var berlinTimeFormats = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// UnmarshalJSON parses a timestamp as a date or a date and time, and
+// converts it to Berlin time. An empty string or null yields the zero time.
 func (bt *BerlinTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		bt.Time = time.Time{}
+		return nil
+	}
+
 	var str string
 	if err := json.Unmarshal(data, &str); err != nil {
-		return err
+		return fmt.Errorf("unable to parse timestamp %s: expected a string", data)
 	}
-
-	// Parse various timestamp formats from Hetzner API
-	formats := []string{
-		"2006-01-02 15:04:05",
-		time.RFC3339,
-		"2006-01-02T15:04:05",
-		"2006-01-02",
+	str = strings.TrimSpace(str)
+	if str == "" {
+		bt.Time = time.Time{}
+		return nil
 	}
 
-	var t time.Time
-	var err error
-	for _, format := range formats {
-		t, err = time.ParseInLocation(format, str, berlinLocation)
+	for _, format := range berlinTimeFormats {
+		t, err := time.ParseInLocation(format, str, berlinLocation)
 		if err == nil {
-			bt.Time = t
+			// Timestamps with their own offset are converted as well
+			bt.Time = t.In(berlinLocation)
 			return nil
 		}
 	}
@@ -183,15 +247,26 @@ func (bt *BerlinTime) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("unable to parse timestamp: %s", str)
 }
 
+// MarshalJSON formats the time in Berlin time like the Hetzner API does. The
+// zero time is encoded as null.
 func (bt BerlinTime) MarshalJSON() ([]byte, error) {
+	if bt.IsZero() {
+		return []byte("null"), nil
+	}
 	return json.Marshal(bt.In(berlinLocation).Format("2006-01-02 15:04:05"))
 }
 
 // StringFloat represents a float that is encoded as a string in JSON.
+// Both "39.90" and the German notation "39,90" are accepted, as are bare numbers.
 type StringFloat float64
 
 // UnmarshalJSON handles string-encoded floats.
 func (sf *StringFloat) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*sf = 0
+		return nil
+	}
+
 	var str string
 	if err := json.Unmarshal(data, &str); err != nil {
 		// Try as number directly
@@ -203,8 +278,7 @@ func (sf *StringFloat) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
-	// Parse string as float
-	f, err := strconv.ParseFloat(str, 64)
+	f, err := parseDecimal(str)
 	if err != nil {
 		return fmt.Errorf("invalid float string: %s", str)
 	}
@@ -212,14 +286,46 @@ func (sf *StringFloat) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// parseDecimal parses a decimal number that uses either a dot or a comma as
+// decimal separator. When both occur, the last one is the decimal separator
+// and the other one groups thousands ("1.234,56" or "1,234.56").
+func parseDecimal(str string) (float64, error) {
+	str = strings.TrimSpace(str)
+
+	dot := strings.LastIndex(str, ".")
+	comma := strings.LastIndex(str, ",")
+	switch {
+	case comma > dot:
+		str = strings.ReplaceAll(str, ".", "")
+		str = strings.Replace(str, ",", ".", 1)
+	case dot > comma && comma >= 0:
+		str = strings.ReplaceAll(str, ",", "")
+	}
+
+	return strconv.ParseFloat(str, 64)
+}
+
+// MarshalJSON encodes the value as a JSON number, so 39.90 is encoded as
+// 39.9. UnmarshalJSON accepts the number, so values round-trip.
 func (sf StringFloat) MarshalJSON() ([]byte, error) {
-	return json.Marshal(fmt.Sprintf("%.4f", float64(sf)))
+	return json.Marshal(float64(sf))
 }
 
+// Float64 returns the value as a float64.
 func (sf StringFloat) Float64() float64 {
 	return float64(sf)
 }
 
+// String formats the value for display with two decimals, or up to four
+// when needed, e.g. "39.90" and "0.064" for hourly prices.
+func (sf StringFloat) String() string {
+	s := strconv.FormatFloat(float64(sf), 'f', 4, 64)
+	for strings.HasSuffix(s, "0") && len(s)-strings.IndexByte(s, '.') > 3 {
+		s = strings.TrimSuffix(s, "0")
+	}
+	return s
+}
+
 // PortRange represents a port or range of ports.
 type PortRange struct {
 	Start uint16
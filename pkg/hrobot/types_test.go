@@ -116,6 +116,7 @@ func TestBerlinTimeUnmarshalJSON(t *testing.T) {
 		name    string
 		input   string
 		want    string // Expected time in Berlin
+		zero    bool   // Expect the zero time
 		wantErr bool
 	}{
 		{
@@ -130,6 +131,41 @@ func TestBerlinTimeUnmarshalJSON(t *testing.T) {
 			want:    "2025-10-24 00:00:00 +0200 CEST",
 			wantErr: false,
 		},
+		{
+			name:  "date only in winter",
+			input: `"2025-01-15"`,
+			want:  "2025-01-15 00:00:00 +0100 CET",
+		},
+		{
+			name:  "datetime without seconds",
+			input: `"2025-10-24 14:30"`,
+			want:  "2025-10-24 14:30:00 +0200 CEST",
+		},
+		{
+			name:  "RFC 3339 in UTC is converted to Berlin time",
+			input: `"2025-10-24T12:30:00Z"`,
+			want:  "2025-10-24 14:30:00 +0200 CEST",
+		},
+		{
+			name:  "empty string",
+			input: `""`,
+			zero:  true,
+		},
+		{
+			name:  "null",
+			input: `null`,
+			zero:  true,
+		},
+		{
+			name:    "unknown format",
+			input:   `"24.10.2025"`,
+			wantErr: true,
+		},
+		{
+			name:    "number",
+			input:   `1729773000`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -142,6 +178,15 @@ func TestBerlinTimeUnmarshalJSON(t *testing.T) {
 			if tt.wantErr {
 				return
 			}
+			if tt.zero {
+				if !bt.IsZero() {
+					t.Errorf("Time = %s, want the zero time", bt)
+				}
+				return
+			}
+			if bt.Location().String() != "Europe/Berlin" {
+				t.Errorf("Location = %s, want Europe/Berlin", bt.Location())
+			}
 			if bt.Format("2006-01-02 15:04:05 -0700 MST") != tt.want {
 				t.Errorf("Time = %s, want %s", bt.Format("2006-01-02 15:04:05 -0700 MST"), tt.want)
 			}
@@ -215,6 +260,81 @@ func TestParsePortRange(t *testing.T) {
 	}
 }
 
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Action
+		wantErr bool
+	}{
+		{input: "accept", want: ActionAccept},
+		{input: "discard", want: ActionDiscard},
+		{input: "ACCEPT", want: ActionAccept},
+		{input: " Discard ", want: ActionDiscard},
+		{input: "", wantErr: true},
+		{input: "allow", wantErr: true},
+		{input: "drop", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAction(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAction(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAction(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Protocol
+		wantErr bool
+	}{
+		{input: "tcp", want: ProtocolTCP},
+		{input: "udp", want: ProtocolUDP},
+		{input: "icmp", want: ProtocolICMP},
+		{input: "esp", want: ProtocolESP},
+		{input: "gre", want: ProtocolGRE},
+		{input: "TCP", want: ProtocolTCP},
+		{input: " Gre ", want: ProtocolGRE},
+		{input: "", wantErr: true},
+		{input: "sctp", wantErr: true},
+		{input: "icmpv6", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseProtocol(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProtocol(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseProtocol(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtocol_HasPorts(t *testing.T) {
+	want := map[Protocol]bool{
+		"":           true,
+		ProtocolTCP:  true,
+		ProtocolUDP:  true,
+		ProtocolICMP: false,
+		ProtocolESP:  false,
+		ProtocolGRE:  false,
+	}
+	for protocol, hasPorts := range want {
+		if got := protocol.HasPorts(); got != hasPorts {
+			t.Errorf("Protocol(%q).HasPorts() = %v, want %v", protocol, got, hasPorts)
+		}
+	}
+}
+
 func TestPortRangeString(t *testing.T) {
 	tests := []struct {
 		name string
@@ -394,6 +514,29 @@ func TestIPAddressUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestBerlinTimeMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(BerlinTime{Time: time.Date(2025, 10, 24, 12, 30, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if string(data) != `"2025-10-24 14:30:00"` {
+		t.Errorf("MarshalJSON() = %s, want \"2025-10-24 14:30:00\"", data)
+	}
+
+	// The zero time round-trips through null
+	data, err = json.Marshal(BerlinTime{})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if string(data) != "null" {
+		t.Errorf("MarshalJSON() of the zero time = %s, want null", data)
+	}
+	var bt BerlinTime
+	if err := json.Unmarshal(data, &bt); err != nil || !bt.IsZero() {
+		t.Errorf("expected null to decode to the zero time, got %s, %v", bt, err)
+	}
+}
+
 func TestBerlinTimeLocation(t *testing.T) {
 	// Test that BerlinTime uses Europe/Berlin location
 	bt := BerlinTime{Time: time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC)}
@@ -413,3 +556,148 @@ func TestBerlinTimeLocation(t *testing.T) {
 		t.Errorf("Berlin offset = %d, want %d (UTC+2)", offset, expectedOffset)
 	}
 }
+
+func TestStringFloatUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{name: "dot decimal string", input: `"39.90"`, want: 39.90},
+		{name: "comma decimal string", input: `"39,90"`, want: 39.90},
+		{name: "integer string", input: `"39"`, want: 39},
+		{name: "bare number", input: `39.9`, want: 39.9},
+		{name: "bare integer", input: `40`, want: 40},
+		{name: "german thousands separator", input: `"1.234,56"`, want: 1234.56},
+		{name: "english thousands separator", input: `"1,234.56"`, want: 1234.56},
+		{name: "surrounding whitespace", input: `" 39,90 "`, want: 39.90},
+		{name: "four decimals", input: `"0,0640"`, want: 0.064},
+		{name: "null", input: `null`, want: 0},
+		{name: "empty string", input: `""`, wantErr: true},
+		{name: "not a number", input: `"free"`, wantErr: true},
+		{name: "boolean", input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sf StringFloat
+			err := json.Unmarshal([]byte(tt.input), &sf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if sf.Float64() != tt.want {
+				t.Errorf("Float64() = %v, want %v", sf.Float64(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStringFloatMarshalJSON(t *testing.T) {
+	tests := []struct {
+		value StringFloat
+		want  string
+	}{
+		{value: 39.90, want: "39.9"},
+		{value: 40, want: "40"},
+		{value: 0.064, want: "0.064"},
+		{value: 1234.56, want: "1234.56"},
+		{value: 0, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", data, tt.want)
+			}
+
+			var back StringFloat
+			if err := json.Unmarshal(data, &back); err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if back != tt.value {
+				t.Errorf("round-trip = %v, want %v", back, tt.value)
+			}
+		})
+	}
+}
+
+func TestStringFloat_RoundTripPrice(t *testing.T) {
+	// German notation from the API is emitted as numbers and decodes again
+	var price ProductPriceInfo
+	if err := json.Unmarshal([]byte(`{"net":"39,90","gross":"47,48","hourly_net":"0,0640","hourly_gross":"0,0762"}`), &price); err != nil {
+		t.Fatalf("failed to unmarshal price: %v", err)
+	}
+
+	data, err := json.Marshal(price)
+	if err != nil {
+		t.Fatalf("failed to marshal price: %v", err)
+	}
+	want := `{"net":39.9,"gross":47.48,"hourly_net":0.064,"hourly_gross":0.0762}`
+	if string(data) != want {
+		t.Errorf("unexpected JSON:\ngot:  %s\nwant: %s", data, want)
+	}
+
+	var back ProductPriceInfo
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("failed to unmarshal marshaled price: %v", err)
+	}
+	if back != price {
+		t.Errorf("round-trip = %+v, want %+v", back, price)
+	}
+}
+
+func TestStringFloatString(t *testing.T) {
+	tests := []struct {
+		value StringFloat
+		want  string
+	}{
+		{value: 39.90, want: "39.90"},
+		{value: 40, want: "40.00"},
+		{value: 0.064, want: "0.064"},
+		{value: 0.0762, want: "0.0762"},
+		{value: 1234.5, want: "1234.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("StringFloat(%v).String() = %q, want %q", float64(tt.value), got, tt.want)
+		}
+	}
+}
+
+func TestStringFloat_CommaDecimalPrice(t *testing.T) {
+	var price ProductPriceInfo
+	input := `{"net":"39,90","gross":"47,48","hourly_net":"0,0640","hourly_gross":"0,0762"}`
+	if err := json.Unmarshal([]byte(input), &price); err != nil {
+		t.Fatalf("failed to unmarshal price: %v", err)
+	}
+
+	if price.Net.Float64() != 39.90 {
+		t.Errorf("expected net 39.90, got %v", price.Net.Float64())
+	}
+	if price.Gross.Float64() != 47.48 {
+		t.Errorf("expected gross 47.48, got %v", price.Gross.Float64())
+	}
+	if price.HourlyNet.Float64() != 0.064 {
+		t.Errorf("expected hourly net 0.064, got %v", price.HourlyNet.Float64())
+	}
+
+	var server AuctionServer
+	if err := json.Unmarshal([]byte(`{"id":1,"price":"39,90","price_vat":"47,48"}`), &server); err != nil {
+		t.Fatalf("failed to unmarshal auction server: %v", err)
+	}
+	if server.Price.Float64() != 39.90 {
+		t.Errorf("expected price 39.90, got %v", server.Price.Float64())
+	}
+	if server.PriceVAT.Float64() != 47.48 {
+		t.Errorf("expected price_vat 47.48, got %v", server.PriceVAT.Float64())
+	}
+}
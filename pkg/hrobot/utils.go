@@ -19,16 +19,56 @@ func encodeForm(data map[string]string) url.Values {
 	return values
 }
 
+// WaitOption configures how a Wait* method polls.
+type WaitOption func(*waitConfig)
+
+// waitConfig holds the polling settings of waitForCondition.
+type waitConfig struct {
+	timeout  time.Duration
+	minDelay time.Duration
+	maxDelay time.Duration
+}
+
+// WithWaitTimeout stops waiting after d. A timeout of 0 waits until the
+// context is done or the retry limit is reached.
+func WithWaitTimeout(d time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.timeout = d
+	}
+}
+
+// WithWaitBackoff sets the delay before the first retry and the maximum delay
+// between retries. The delay doubles after every retry.
+func WithWaitBackoff(minDelay, maxDelay time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.minDelay = minDelay
+		c.maxDelay = maxDelay
+	}
+}
+
 // waitForCondition polls a condition function with exponential backoff until it returns true or context times out.
-func waitForCondition(ctx context.Context, condition func() (bool, error)) error {
-	const (
-		minDelay   = 2 * time.Second
-		maxDelay   = 30 * time.Second
-		maxRetries = 30 // max ~15 minutes with exponential backoff
-	)
+func waitForCondition(ctx context.Context, condition func() (bool, error), opts ...WaitOption) error {
+	const maxRetries = 30 // max ~15 minutes with the default backoff
+
+	cfg := waitConfig{
+		minDelay: 2 * time.Second,
+		maxDelay: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	// With a timeout the context decides when to stop, not the retry limit
+	retries := maxRetries
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+		retries = -1
+	}
 
-	delay := minDelay
-	for i := 0; i < maxRetries; i++ {
+	delay := cfg.minDelay
+	for i := 0; retries < 0 || i < retries; i++ {
 		// Check if context is cancelled
 		select {
 		case <-ctx.Done():
@@ -56,8 +96,8 @@ func waitForCondition(ctx context.Context, condition func() (bool, error)) error
 
 		// Exponential backoff with max delay
 		delay *= 2
-		if delay > maxDelay {
-			delay = maxDelay
+		if delay > cfg.maxDelay {
+			delay = cfg.maxDelay
 		}
 	}
 
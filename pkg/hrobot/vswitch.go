@@ -5,10 +5,12 @@ package hrobot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // VSwitchService provides access to vSwitch related functions in the Hetzner Robot API.
@@ -71,7 +73,7 @@ type CloudNetwork struct {
 func (v *VSwitchService) List(ctx context.Context) ([]VSwitchListItem, error) {
 	path := "/vswitch"
 	var result []VSwitchListItem
-	if err := v.client.Get(ctx, path, &result); err != nil {
+	if err := v.client.GetWrapped(ctx, path, "vswitch", &result); err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -85,12 +87,42 @@ func (v *VSwitchService) List(ctx context.Context) ([]VSwitchListItem, error) {
 func (v *VSwitchService) Get(ctx context.Context, id int) (*VSwitch, error) {
 	path := fmt.Sprintf("/vswitch/%d", id)
 	var result VSwitch
-	if err := v.client.Get(ctx, path, &result); err != nil {
+	if err := v.client.GetWrapped(ctx, path, "vswitch", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// ListForServer retrieves all vSwitches the given server is connected to.
+// The API has no reverse lookup, so every vSwitch is fetched and filtered.
+//
+// GET /vswitch
+//
+// GET /vswitch/{vswitch-id}
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#get-vswitch-vswitch-id
+func (v *VSwitchService) ListForServer(ctx context.Context, serverNumber int) ([]VSwitch, error) {
+	items, err := v.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []VSwitch
+	for _, item := range items {
+		vswitch, err := v.Get(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, server := range vswitch.Servers {
+			if server.ServerNumber == serverNumber {
+				result = append(result, *vswitch)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
 // Create creates a new vSwitch.
 //
 // POST /vswitch
@@ -103,7 +135,7 @@ func (v *VSwitchService) Create(ctx context.Context, name string, vlan int) (*VS
 	formData.Set("vlan", strconv.Itoa(vlan))
 
 	var result VSwitch
-	if err := v.client.Post(ctx, path, formData, &result); err != nil {
+	if err := v.client.PostWrapped(ctx, path, formData, "vswitch", &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -121,7 +153,7 @@ func (v *VSwitchService) Update(ctx context.Context, id int, name string, vlan i
 	formData.Set("vlan", strconv.Itoa(vlan))
 
 	// API returns no output for successful updates
-	return v.client.Post(ctx, path, formData, nil)
+	return v.client.PostWrapped(ctx, path, formData, "", nil)
 }
 
 // Delete cancels a vSwitch.
@@ -155,7 +187,7 @@ func (v *VSwitchService) AddServers(ctx context.Context, id int, servers []strin
 	formData := strings.Join(formParts, "&")
 
 	// Use PostRaw to avoid url.Values encoding the brackets
-	return v.client.PostRaw(ctx, path, formData, nil)
+	return v.client.PostRawWrapped(ctx, path, formData, "", nil)
 }
 
 // RemoveServers removes one or more servers from a vSwitch.
@@ -177,24 +209,54 @@ func (v *VSwitchService) RemoveServers(ctx context.Context, id int, servers []st
 	formData := strings.Join(formParts, "&")
 
 	// For now, use PostRaw - we may need to enhance the client to support DELETE with body
-	return v.client.PostRaw(ctx, path, formData, nil)
+	return v.client.PostRawWrapped(ctx, path, formData, "", nil)
 }
 
+// DefaultVSwitchWaitTimeout is how long WaitForVSwitchReady waits unless
+// WithWaitTimeout is given.
+const DefaultVSwitchWaitTimeout = 15 * time.Minute
+
 // WaitForVSwitchReady waits for a vSwitch to finish processing and become ready.
 // This is useful after adding or removing servers, as the API returns VSWITCH_IN_PROCESS
 // errors if operations are attempted while the vSwitch is processing.
-func (v *VSwitchService) WaitForVSwitchReady(ctx context.Context, id int) error {
-	return waitForCondition(ctx, func() (bool, error) {
+//
+// It polls with exponential backoff for up to DefaultVSwitchWaitTimeout; use
+// WithWaitTimeout and WithWaitBackoff to change this. When a server link
+// reaches the terminal "failed" status it stops waiting and returns an
+// ErrVSwitchServerFailed error.
+func (v *VSwitchService) WaitForVSwitchReady(ctx context.Context, id int, opts ...WaitOption) error {
+	opts = append([]WaitOption{WithWaitTimeout(DefaultVSwitchWaitTimeout)}, opts...)
+
+	var failed error
+	var pending []string
+	err := waitForCondition(ctx, func() (bool, error) {
 		vswitch, err := v.Get(ctx, id)
 		if err != nil {
 			return false, err
 		}
-		// Check if all servers are in "ready" status (not "processing" or "failed")
+
+		pending = pending[:0]
 		for _, server := range vswitch.Servers {
-			if server.Status != "ready" {
-				return false, nil
+			switch server.Status {
+			case "ready":
+			case "failed":
+				failed = NewAPIError(ErrVSwitchServerFailed, fmt.Sprintf(
+					"server %d (%s) failed to connect to vswitch %d; remove the server from the vswitch and add it again, or contact Hetzner support if it keeps failing",
+					server.ServerNumber, server.ServerIP, id))
+				return false, failed
+			default:
+				pending = append(pending, strconv.Itoa(server.ServerNumber))
 			}
 		}
-		return true, nil
-	})
+		return len(pending) == 0, nil
+	}, opts...)
+
+	if failed != nil {
+		return failed
+	}
+	if errors.Is(err, context.DeadlineExceeded) && len(pending) > 0 {
+		return fmt.Errorf("timed out waiting for vswitch %d to become ready (servers still in process: %s): %w",
+			id, strings.Join(pending, ", "), err)
+	}
+	return err
 }
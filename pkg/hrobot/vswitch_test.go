@@ -6,12 +6,15 @@ package hrobot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestVSwitchService_List(t *testing.T) {
@@ -455,3 +458,158 @@ func TestVSwitchService_IntegerConversion(t *testing.T) {
 		})
 	}
 }
+
+// newVSwitchStatusServer serves vSwitch 12345 with the server link statuses
+// returned by statuses for each request.
+func newVSwitchStatusServer(t *testing.T, statuses func(request int) []string) (*httptest.Server, *int) {
+	t.Helper()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var servers []map[string]interface{}
+		for i, status := range statuses(requests) {
+			servers = append(servers, map[string]interface{}{
+				"server_ip":     "123.123.123." + strconv.Itoa(i+1),
+				"server_number": 321 + i,
+				"status":        status,
+			})
+		}
+		response := map[string]interface{}{
+			"vswitch": map[string]interface{}{
+				"id":     12345,
+				"name":   "test-vswitch",
+				"vlan":   4000,
+				"server": servers,
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestVSwitchService_WaitForVSwitchReady(t *testing.T) {
+	server, requests := newVSwitchStatusServer(t, func(request int) []string {
+		if request < 3 {
+			return []string{"ready", "in process"}
+		}
+		return []string{"ready", "ready"}
+	})
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	err := client.VSwitch.WaitForVSwitchReady(context.Background(), 12345, WithWaitBackoff(time.Millisecond, 2*time.Millisecond))
+	if err != nil {
+		t.Fatalf("WaitForVSwitchReady returned error: %v", err)
+	}
+	if *requests != 3 {
+		t.Errorf("expected 3 requests, got %d", *requests)
+	}
+}
+
+func TestVSwitchService_WaitForVSwitchReady_Failed(t *testing.T) {
+	server, requests := newVSwitchStatusServer(t, func(request int) []string {
+		if request < 2 {
+			return []string{"ready", "in process"}
+		}
+		return []string{"ready", "failed"}
+	})
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	err := client.VSwitch.WaitForVSwitchReady(context.Background(), 12345, WithWaitBackoff(time.Millisecond, 2*time.Millisecond))
+	if err == nil {
+		t.Fatal("expected error for failed server link, got nil")
+	}
+	if !IsAPIError(err, ErrVSwitchServerFailed) {
+		t.Errorf("expected VSWITCH_SERVER_FAILED error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "server 322 (123.123.123.2) failed to connect to vswitch 12345") {
+		t.Errorf("expected failed server in error message, got %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("expected to stop polling after the failure, got %d requests", *requests)
+	}
+}
+
+func TestVSwitchService_WaitForVSwitchReady_Timeout(t *testing.T) {
+	server, _ := newVSwitchStatusServer(t, func(int) []string {
+		return []string{"in process"}
+	})
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	err := client.VSwitch.WaitForVSwitchReady(context.Background(), 12345,
+		WithWaitTimeout(50*time.Millisecond), WithWaitBackoff(5*time.Millisecond, 10*time.Millisecond))
+	if err == nil {
+		t.Fatal("expected timeout error, got nil")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "servers still in process: 321") {
+		t.Errorf("expected pending servers in error message, got %v", err)
+	}
+}
+
+func TestVSwitchService_ListForServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch r.URL.Path {
+		case "/vswitch":
+			response = []map[string]interface{}{
+				{"id": 1, "name": "backend", "vlan": 4000, "cancelled": false},
+				{"id": 2, "name": "storage", "vlan": 4001, "cancelled": false},
+			}
+		case "/vswitch/1":
+			response = map[string]interface{}{
+				"vswitch": map[string]interface{}{
+					"id": 1, "name": "backend", "vlan": 4000,
+					"server": []map[string]interface{}{
+						{"server_ip": "123.123.123.123", "server_number": 321, "status": "ready"},
+						{"server_ip": "123.123.123.124", "server_number": 322, "status": "ready"},
+					},
+				},
+			}
+		case "/vswitch/2":
+			response = map[string]interface{}{
+				"vswitch": map[string]interface{}{
+					"id": 2, "name": "storage", "vlan": 4001,
+					"server": []map[string]interface{}{
+						{"server_ip": "123.123.123.124", "server_number": 322, "status": "ready"},
+					},
+				},
+			}
+		default:
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-user", "test-pass", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	vswitches, err := client.VSwitch.ListForServer(ctx, 321)
+	if err != nil {
+		t.Fatalf("VSwitch.ListForServer returned error: %v", err)
+	}
+	if len(vswitches) != 1 {
+		t.Fatalf("expected 1 vSwitch, got %d", len(vswitches))
+	}
+	if vswitches[0].ID != 1 || vswitches[0].Name != "backend" {
+		t.Errorf("expected vSwitch 1 (backend), got %d (%s)", vswitches[0].ID, vswitches[0].Name)
+	}
+
+	vswitches, err = client.VSwitch.ListForServer(ctx, 999)
+	if err != nil {
+		t.Fatalf("VSwitch.ListForServer returned error: %v", err)
+	}
+	if len(vswitches) != 0 {
+		t.Errorf("expected no vSwitches for an unconnected server, got %d", len(vswitches))
+	}
+}
@@ -30,12 +30,30 @@ type WOLWrapper struct {
 	WOL WOLResponse `json:"wol"`
 }
 
+// Get returns the Wake-on-LAN data of a server. Servers without Wake-on-LAN
+// support return an error for which IsWOLNotAvailableError is true.
+//
+// GET /wol/{server-id}
+//
+// See: https://robot.hetzner.com/doc/webservice/en.html#get-wol-server-id
+func (w *WOLService) Get(ctx context.Context, serverID ServerID) (*WOLResponse, error) {
+	var wol WOLResponse
+	path := fmt.Sprintf("/wol/%s", serverID.String())
+
+	err := w.client.GetWrapped(ctx, path, "wol", &wol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wol, nil
+}
+
 // Send sends a Wake-on-LAN packet to the server.
 func (w *WOLService) Send(ctx context.Context, serverID ServerID) (*WOLResponse, error) {
 	var wrapper WOLWrapper
 	path := fmt.Sprintf("/wol/%s", serverID.String())
 
-	err := w.client.Post(ctx, path, nil, &wrapper)
+	err := w.client.PostWrapped(ctx, path, nil, "", &wrapper)
 	if err != nil {
 		return nil, err
 	}